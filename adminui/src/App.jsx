@@ -5,6 +5,7 @@ import Home from "./pages/Home.jsx";
 import Configuration from "./pages/Configuration.tsx";
 import {AuthStatus, useAuth} from "./hooks/account/useAuth.ts";
 import {Login} from "./pages/Login.tsx";
+import {ChangePassword} from "./pages/ChangePassword.tsx";
 import EntityTypesList from "./pages/EntityTypesList.jsx";
 import UsersList from "./pages/UsersList.jsx";
 import ACLList from "./pages/ACLList.jsx";
@@ -13,7 +14,7 @@ import EntitiesBrowser from "./pages/EntitiesBrowser.jsx";
 
 export default function App() {
 
-    const { status } = useAuth();
+    const { status, account } = useAuth();
 
     if (status === AuthStatus.Unknown) {
         return (
@@ -33,6 +34,14 @@ export default function App() {
         );
     }
 
+    if (account?.must_change_password) {
+        return (
+            <div className="container mt-5">
+                <ChangePassword />
+            </div>
+        );
+    }
+
     const router = createBrowserRouter([
         {
             path: "/admin",
@@ -1,4 +1,5 @@
 export type Account = {
     id: number;
     username: string;
+    must_change_password?: boolean;
 };
@@ -36,6 +36,15 @@ export function useAuth() {
             .catch(() => setAccount(null));
     }, []);
 
+    const changePassword = useCallback((password: string) => {
+        if (!account) {
+            return Promise.reject();
+        }
+
+        return apiFetch(`/api/security/user/${account.username}/password`, { method: "PUT", json: { password } })
+            .then(() => setAccount(null));
+    }, [account]);
+
     const logout = useCallback(() => {
         apiFetch("/api/security/logout", { method: "GET" })
             .finally(() => setAccount(null));
@@ -46,6 +55,7 @@ export function useAuth() {
         status,
         authenticate,
         login,
+        changePassword,
         logout,
     };
 }
@@ -0,0 +1,40 @@
+import {FormEventHandler, useState} from "react";
+import {useAuth} from "../hooks/account/useAuth";
+
+export function ChangePassword() {
+  const { changePassword } = useAuth();
+  const [error, setError] = useState<string | null>(null);
+
+  const handleSubmit: FormEventHandler<HTMLFormElement> = (e) => {
+    e.preventDefault();
+    const data = new FormData(e.currentTarget);
+    const password = data.get("password")!.toString();
+
+    if (password !== data.get("confirmation")!.toString()) {
+      setError("Passwords do not match");
+      return;
+    }
+
+    changePassword(password).catch((err) => {
+      setError(err?.data?.error ?? "Unable to change password");
+    });
+  };
+
+  return (
+    <form onSubmit={handleSubmit}>
+      <div className="alert alert-warning">
+        You must change your password before continuing.
+      </div>
+      {error && <div className="alert alert-danger">{error}</div>}
+      <div className="mb-3">
+        <label className="form-label">New password</label>
+        <input type="password" className="form-control" name="password" />
+      </div>
+      <div className="mb-3">
+        <label className="form-label">Confirm password</label>
+        <input type="password" className="form-control" name="confirmation" />
+      </div>
+      <button className="btn btn-primary">Change password</button>
+    </form>
+  );
+}
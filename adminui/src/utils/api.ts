@@ -6,14 +6,21 @@ export async function apiFetch<T>(
     method ??= json ? "POST" : "GET";
     const body = json ? JSON.stringify(json) : undefined;
 
+    const headers: Record<string, string> = {
+        accept: "application/json",
+        "content-type": "application/json",
+    };
+
+    const csrf = readCookie("edb_csrf");
+    if (csrf && method !== "GET" && method !== "HEAD") {
+        headers["X-CSRF-Token"] = csrf;
+    }
+
     const r = await fetch(base + url, {
         method,
         credentials: "include",
         body,
-        headers: {
-            accept: "application/json",
-            "content-type": "application/json",
-        },
+        headers,
     });
 
     if (r.status === 204) {
@@ -36,6 +43,18 @@ export async function apiFetch<T>(
     throw new ApiError(r.status, data || {});
 }
 
+function readCookie(name: string): string | null {
+    const prefix = `${name}=`;
+    for (const part of document.cookie.split(";")) {
+        const cookie = part.trim();
+        if (cookie.startsWith(prefix)) {
+            return decodeURIComponent(cookie.slice(prefix.length));
+        }
+    }
+
+    return null;
+}
+
 class ApiError extends Error {
     constructor(public status: number, public data: Record<string, unknown>) {
         if (status === 401) {
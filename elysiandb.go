@@ -35,11 +35,13 @@ func main() {
 
 	globals.SetConfig(cfg)
 
-	boot.InitDB()
-	security.InitAdminUserIfNotExists()
-
 	args := flag.Args()
 
+	if len(args) == 0 || cmd.RequiresStorage(args[0]) {
+		boot.InitDB()
+		security.InitAdminUserIfNotExists()
+	}
+
 	if len(args) == 0 {
 		cmd.StartServer()
 		return
@@ -1,16 +1,19 @@
 package acl
 
 import (
+	"context"
+
+	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/security"
 )
 
-func CanCreateEntity(entity string) bool {
-	if !security.UserAuthenticationIsEnabled() {
+func CanCreateEntity(ctx context.Context, entity string) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
 		return true
 	}
 
-	username := security.GetCurrentUsername()
-	acl := GetACLEntityForUsername(entity, username)
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
 	if acl == nil {
 		return false
 	}
@@ -18,18 +21,18 @@ func CanCreateEntity(entity string) bool {
 	return acl.Can(PermissionCreate)
 }
 
-func CanDeleteEntity(entity string, data map[string]any) bool {
-	if !security.UserAuthenticationIsEnabled() {
+func CanDeleteEntity(ctx context.Context, entity string, data map[string]any) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
 		return true
 	}
 
-	username := security.GetCurrentUsername()
-	acl := GetACLEntityForUsername(entity, username)
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
 	if acl == nil {
 		return false
 	}
 
-	if acl.Can(PermissionDelete) {
+	if acl.Can(PermissionDelete) || IsSharedWithCurrentUser(ctx, data, ShareDelete) {
 		return true
 	}
 
@@ -41,18 +44,18 @@ func CanDeleteEntity(entity string, data map[string]any) bool {
 	return acl.Can(PermissionOwningDelete) && dataUsername == username
 }
 
-func CanUpdateEntity(entity string, data map[string]any) bool {
-	if !security.UserAuthenticationIsEnabled() {
+func CanUpdateEntity(ctx context.Context, entity string, data map[string]any) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
 		return true
 	}
 
-	username := security.GetCurrentUsername()
-	acl := GetACLEntityForUsername(entity, username)
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
 	if acl == nil {
 		return false
 	}
 
-	if acl.Can(PermissionUpdate) {
+	if acl.Can(PermissionUpdate) || IsSharedWithCurrentUser(ctx, data, ShareUpdate) {
 		return true
 	}
 
@@ -64,13 +67,13 @@ func CanUpdateEntity(entity string, data map[string]any) bool {
 	return acl.Can(PermissionOwningUpdate) && dataUsername == username
 }
 
-func CanUpdateListOfEntities(entity string, data []map[string]any) bool {
-	if !security.UserAuthenticationIsEnabled() {
+func CanUpdateListOfEntities(ctx context.Context, entity string, data []map[string]any) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
 		return true
 	}
 
-	username := security.GetCurrentUsername()
-	acl := GetACLEntityForUsername(entity, username)
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
 	if acl == nil {
 		return false
 	}
@@ -80,6 +83,10 @@ func CanUpdateListOfEntities(entity string, data []map[string]any) bool {
 	}
 
 	for _, item := range data {
+		if IsSharedWithCurrentUser(ctx, item, ShareUpdate) {
+			continue
+		}
+
 		dataUsername, ok := item[UsernameField].(string)
 		if !ok || dataUsername == "" {
 			return false
@@ -93,13 +100,13 @@ func CanUpdateListOfEntities(entity string, data []map[string]any) bool {
 	return true
 }
 
-func CanReadEntity(entity string, data map[string]any) bool {
-	if !security.UserAuthenticationIsEnabled() {
+func CanReadEntity(ctx context.Context, entity string, data map[string]any) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
 		return true
 	}
 
-	username := security.GetCurrentUsername()
-	acl := GetACLEntityForUsername(entity, username)
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
 	if acl == nil {
 		return false
 	}
@@ -109,39 +116,48 @@ func CanReadEntity(entity string, data map[string]any) bool {
 	}
 
 	dataUsername, ok := data[UsernameField].(string)
-	if !ok || dataUsername == "" {
-		return false
+	if ok && dataUsername != "" && acl.Can(PermissionOwningRead) && dataUsername == username {
+		return true
 	}
 
-	return acl.Can(PermissionOwningRead) && dataUsername == username
+	return matchesReadFilter(ctx, entity, data)
 }
 
-func FilterListOfEntities(entity string, data []map[string]any) []map[string]any {
-	if !security.UserAuthenticationIsEnabled() {
+func FilterListOfEntities(ctx context.Context, entity string, data []map[string]any) []map[string]any {
+	if !security.IdentityAuthenticationIsEnabled() {
 		return data
 	}
 
-	username := security.GetCurrentUsername()
-	acl := GetACLEntityForUsername(entity, username)
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
 	if acl == nil {
 		return []map[string]any{}
 	}
 
 	if acl.Can(PermissionRead) {
-		return data
+		return HideFieldsInList(ctx, entity, data)
+	}
+
+	filter, allowed := ReadFilterForCurrentUser(ctx, entity)
+	if !allowed {
+		return []map[string]any{}
 	}
 
 	filteredData := make([]map[string]any, 0)
 	for _, item := range data {
-		dataUsername, ok := item[UsernameField].(string)
-		if !ok || dataUsername == "" {
-			continue
-		}
-
-		if acl.Can(PermissionOwningRead) && dataUsername == username {
+		if filter.IsEmpty() || engine.MatchFilterNode(filter, item) {
 			filteredData = append(filteredData, item)
 		}
 	}
 
-	return filteredData
+	return HideFieldsInList(ctx, entity, filteredData)
+}
+
+func currentACL(ctx context.Context, entity, username string) *ACL {
+	acl := GetACLEntityForUsername(entity, username)
+	if acl != nil || !security.JWTAuthenticationIsEnabled() {
+		return acl
+	}
+
+	return ResolveACL(entity, username, security.CurrentPrincipal(ctx).Role)
 }
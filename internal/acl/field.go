@@ -0,0 +1,332 @@
+package acl
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+type FieldRule string
+
+const (
+	FieldRuleReadWrite FieldRule = "read_write"
+	FieldRuleHidden    FieldRule = "hidden"
+	FieldRuleReadOnly  FieldRule = "read_only"
+	FieldRuleWriteOnce FieldRule = "write_once"
+)
+
+func StringToFieldRule(s string) (FieldRule, bool) {
+	switch FieldRule(s) {
+	case FieldRuleReadWrite, FieldRuleHidden, FieldRuleReadOnly, FieldRuleWriteOnce:
+		return FieldRule(s), true
+	}
+
+	return "", false
+}
+
+func parseFieldRules(raw any) map[string]FieldRule {
+	rules := map[string]FieldRule{}
+
+	switch fields := raw.(type) {
+	case map[string]string:
+		for k, v := range fields {
+			if rule, ok := StringToFieldRule(v); ok {
+				rules[k] = rule
+			}
+		}
+	case map[string]any:
+		for k, v := range fields {
+			if s, ok := v.(string); ok {
+				if rule, ok := StringToFieldRule(s); ok {
+					rules[k] = rule
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+func fieldRulesToDataMap(rules map[string]FieldRule) map[string]string {
+	out := make(map[string]string, len(rules))
+	for k, v := range rules {
+		out[k] = string(v)
+	}
+
+	return out
+}
+
+func UpdateACLFieldsForUsername(entity, username string, rules map[string]FieldRule) error {
+	existing := GetStoredACLForUsername(entity, username)
+	if existing == nil {
+		return errACLNotFound(username, entity)
+	}
+
+	existing.Fields = rules
+
+	engine.WriteEntity(ACLEntity, existing.ToDataMap())
+	purgeCachedReads(entity)
+
+	return nil
+}
+
+func purgeCachedReads(entity string) {
+	if globals.GetConfig().Api.Cache.Enabled && cache.CacheStore != nil {
+		cache.CacheStore.Purge(entity)
+	}
+}
+
+func FieldRulesForCurrentUser(ctx context.Context, entity string) map[string]FieldRule {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return nil
+	}
+
+	current := security.CurrentPrincipal(ctx)
+	username := current.Username
+	rules := GetFieldRulesForRole(entity, current.Role)
+
+	for _, group := range security.GroupsForUser(username) {
+		if groupACL := GetGroupACL(entity, group); groupACL != nil {
+			for field, rule := range groupACL.Fields {
+				rules[field] = rule
+			}
+		}
+	}
+
+	if acl := GetStoredACLForUsername(entity, username); acl != nil {
+		for field, rule := range acl.Fields {
+			rules[field] = rule
+		}
+	}
+
+	for field, rule := range rules {
+		if rule == FieldRuleReadWrite {
+			delete(rules, field)
+		}
+	}
+
+	return rules
+}
+
+func HasFieldRules(ctx context.Context, entity string) bool {
+	return len(FieldRulesForCurrentUser(ctx, entity)) > 0
+}
+
+func HiddenFieldsInQuery(ctx context.Context, entity string, fields ...string) []string {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return nil
+	}
+
+	var used []string
+	for _, hidden := range newFieldRulesResolver(ctx).hiddenFields(entity) {
+		for _, field := range fields {
+			if fieldsOverlap(field, hidden) {
+				used = append(used, hidden)
+				break
+			}
+		}
+	}
+
+	sort.Strings(used)
+
+	return used
+}
+
+func HasHiddenFields(ctx context.Context, entity string) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return false
+	}
+
+	return len(newFieldRulesResolver(ctx).hiddenFields(entity)) > 0
+}
+
+func fieldsOverlap(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+func HideFields(ctx context.Context, entity string, data map[string]any) map[string]any {
+	if !security.IdentityAuthenticationIsEnabled() || data == nil {
+		return data
+	}
+
+	return newFieldRulesResolver(ctx).hide(entity, data)
+}
+
+func HideFieldsInList(ctx context.Context, entity string, data []map[string]any) []map[string]any {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return data
+	}
+
+	resolver := newFieldRulesResolver(ctx)
+
+	out := make([]map[string]any, len(data))
+	for i, item := range data {
+		out[i] = resolver.hide(entity, item)
+	}
+
+	return out
+}
+
+func ForbiddenFieldsForCreate(ctx context.Context, entity string, data map[string]any) []string {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return nil
+	}
+
+	var forbidden []string
+	for field, rule := range FieldRulesForCurrentUser(ctx, entity) {
+		if (rule == FieldRuleHidden || rule == FieldRuleReadOnly) && fieldIsPresent(data, field) {
+			forbidden = append(forbidden, field)
+		}
+	}
+
+	sort.Strings(forbidden)
+
+	return forbidden
+}
+
+func ForbiddenFieldsForUpdate(ctx context.Context, entity string, data map[string]any, existing map[string]any) []string {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return nil
+	}
+
+	var forbidden []string
+	for field, rule := range FieldRulesForCurrentUser(ctx, entity) {
+		if !fieldIsPresent(data, field) {
+			continue
+		}
+
+		switch rule {
+		case FieldRuleHidden, FieldRuleReadOnly:
+			forbidden = append(forbidden, field)
+		case FieldRuleWriteOnce:
+			if value, ok := fieldValue(existing, field); ok && value != nil {
+				forbidden = append(forbidden, field)
+			}
+		}
+	}
+
+	sort.Strings(forbidden)
+
+	return forbidden
+}
+
+type fieldRulesResolver struct {
+	ctx   context.Context
+	rules map[string]map[string]FieldRule
+}
+
+func newFieldRulesResolver(ctx context.Context) *fieldRulesResolver {
+	return &fieldRulesResolver{ctx: ctx, rules: map[string]map[string]FieldRule{}}
+}
+
+func (r *fieldRulesResolver) hiddenFields(entity string) []string {
+	rules, ok := r.rules[entity]
+	if !ok {
+		rules = FieldRulesForCurrentUser(r.ctx, entity)
+		r.rules[entity] = rules
+	}
+
+	var hidden []string
+	for field, rule := range rules {
+		if rule == FieldRuleHidden {
+			hidden = append(hidden, field)
+		}
+	}
+
+	return hidden
+}
+
+func (r *fieldRulesResolver) hide(entity string, data map[string]any) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		out[k] = r.hideIncluded(v)
+	}
+
+	for _, field := range r.hiddenFields(entity) {
+		removeField(out, field)
+	}
+
+	return out
+}
+
+func (r *fieldRulesResolver) hideIncluded(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if entity, ok := v["@entity"].(string); ok {
+			return r.hide(entity, v)
+		}
+	case []map[string]any:
+		out := make([]map[string]any, len(v))
+		for i, item := range v {
+			if entity, ok := item["@entity"].(string); ok {
+				out[i] = r.hide(entity, item)
+			} else {
+				out[i] = item
+			}
+		}
+
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = r.hideIncluded(item)
+		}
+
+		return out
+	}
+
+	return value
+}
+
+func fieldValue(data map[string]any, path string) (any, bool) {
+	var current any = data
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func fieldIsPresent(data map[string]any, path string) bool {
+	_, ok := fieldValue(data, path)
+	return ok
+}
+
+func removeField(data map[string]any, path string) {
+	parts := strings.SplitN(path, ".", 2)
+
+	if len(parts) == 1 {
+		delete(data, parts[0])
+		return
+	}
+
+	nested, ok := data[parts[0]].(map[string]any)
+	if !ok {
+		return
+	}
+
+	copied := make(map[string]any, len(nested))
+	for k, v := range nested {
+		copied[k] = v
+	}
+
+	removeField(copied, parts[1])
+	data[parts[0]] = copied
+}
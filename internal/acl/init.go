@@ -48,9 +48,11 @@ var stringToPermission = map[string]Permission{
 }
 
 type ACL struct {
-	Username    string              `json:"username"`
-	Entity      string              `json:"entity"`
-	Permissions map[Permission]bool `json:"permissions"`
+	Username    string               `json:"username"`
+	Entity      string               `json:"entity"`
+	Permissions map[Permission]bool  `json:"permissions"`
+	Fields      map[string]FieldRule `json:"fields"`
+	Inherited   bool                 `json:"inherited"`
 }
 
 func NewPermissions() map[Permission]bool {
@@ -82,17 +84,48 @@ func DefaultPermissionsForRole(role security.Role) map[Permission]bool {
 }
 
 func (a *ACL) ToDataMap() map[string]any {
-	perms := make(map[string]bool, len(a.Permissions))
-	for p, v := range a.Permissions {
-		perms[permissionStrings[p]] = v
-	}
-
 	return map[string]any{
 		"id":          GetACLEntityId(a.Username, a.Entity),
 		"username":    a.Username,
 		"entity":      a.Entity,
-		"permissions": perms,
+		"permissions": permissionsToDataMap(a.Permissions),
+		"fields":      fieldRulesToDataMap(a.Fields),
+		"inherited":   a.Inherited,
+	}
+}
+
+func permissionsToDataMap(permissions map[Permission]bool) map[string]bool {
+	perms := make(map[string]bool, len(permissions))
+	for p, v := range permissions {
+		perms[permissionStrings[p]] = v
 	}
+
+	return perms
+}
+
+func parsePermissions(raw any) map[Permission]bool {
+	permissions := NewPermissions()
+
+	switch perms := raw.(type) {
+
+	case map[string]bool:
+		for k, v := range perms {
+			if p, ok := stringToPermission[k]; ok {
+				permissions[p] = v
+			}
+		}
+
+	case map[string]any:
+		for k, v := range perms {
+			if p, ok := stringToPermission[k]; ok {
+				if allowed, ok := v.(bool); ok {
+					permissions[p] = allowed
+				}
+			}
+		}
+	}
+
+	return permissions
 }
 
 func (a *ACL) Can(p Permission) bool {
@@ -148,6 +181,7 @@ func GenerateACLFoAllrEntities() []ACL {
 				Username:    username,
 				Entity:      entity,
 				Permissions: DefaultPermissionsForRole(role),
+				Inherited:   true,
 			})
 		}
 	}
@@ -159,56 +193,58 @@ func GetACLEntityId(username, entity string) string {
 	return username + "::" + entity
 }
 
-func GetACLEntityForUsername(entity, username string) *ACL {
+func GetStoredACLForUsername(entity, username string) *ACL {
 	data := engine.ReadEntityById(ACLEntity, GetACLEntityId(username, entity))
 	if data == nil {
 		return nil
 	}
 
-	acl := &ACL{
-		Permissions: NewPermissions(),
-	}
+	acl := &ACL{}
 
 	acl.Username, _ = data["username"].(string)
 	acl.Entity, _ = data["entity"].(string)
+	acl.Fields = parseFieldRules(data["fields"])
+	acl.Inherited, _ = data["inherited"].(bool)
+	acl.Permissions = parsePermissions(data["permissions"])
 
-	raw := data["permissions"]
-
-	switch perms := raw.(type) {
+	return acl
+}
 
-	case map[string]bool:
-		for k, v := range perms {
-			if p, ok := stringToPermission[k]; ok {
-				acl.Permissions[p] = v
-			}
-		}
+func GetACLEntityForUsername(entity, username string) *ACL {
+	stored := GetStoredACLForUsername(entity, username)
+	if stored == nil || !stored.Inherited {
+		return stored
+	}
 
-	case map[string]any:
-		for k, v := range perms {
-			if p, ok := stringToPermission[k]; ok {
-				if allowed, ok := v.(bool); ok {
-					acl.Permissions[p] = allowed
-				}
-			}
-		}
+	user, err := security.GetBasicUserByUsername(username)
+	if err != nil {
+		return stored
 	}
 
-	return acl
+	role, _ := user["role"].(string)
+
+	return ResolveACL(entity, username, security.Role(role))
 }
 
 func UpdateACLEntityForUsername(entity, username string, permissions map[Permission]bool) error {
-	existing := GetACLEntityForUsername(entity, username)
+	existing := GetStoredACLForUsername(entity, username)
 	if existing == nil {
-		return fmt.Errorf("ACL does not exist for username %s and entity %s", username, entity)
+		return errACLNotFound(username, entity)
 	}
 
 	existing.Permissions = permissions
+	existing.Inherited = false
 
 	engine.WriteEntity(ACLEntity, existing.ToDataMap())
+	purgeCachedReads(entity)
 
 	return nil
 }
 
+func errACLNotFound(username, entity string) error {
+	return fmt.Errorf("ACL does not exist for username %s and entity %s", username, entity)
+}
+
 func StringToPermission(s string) (Permission, bool) {
 	p, ok := stringToPermission[s]
 	return p, ok
@@ -221,6 +257,7 @@ func DeleteUserACls(username string) error {
 		id := GetACLEntityId(username, entity)
 		if engine.EntityExists(ACLEntity, id) {
 			engine.DeleteEntityById(ACLEntity, id)
+			purgeCachedReads(entity)
 		}
 	}
 
@@ -242,6 +279,7 @@ func DeleteACLForEntityType(entity string) error {
 		id := GetACLEntityId(username, entity)
 		if engine.EntityExists(ACLEntity, id) {
 			engine.DeleteEntityById(ACLEntity, id)
+			purgeCachedReads(entity)
 		}
 	}
 
@@ -273,18 +311,22 @@ func ResetACLEntityToDefault(entity, username string) error {
 
 	permissions := DefaultPermissionsForRole(role)
 
-	existing := GetACLEntityForUsername(entity, username)
+	existing := GetStoredACLForUsername(entity, username)
 	if existing == nil {
 		existing = &ACL{
 			Username:    username,
 			Entity:      entity,
 			Permissions: permissions,
+			Inherited:   true,
 		}
 	} else {
 		existing.Permissions = permissions
+		existing.Fields = nil
+		existing.Inherited = true
 	}
 
 	engine.WriteEntity(ACLEntity, existing.ToDataMap())
+	purgeCachedReads(entity)
 
 	return nil
 }
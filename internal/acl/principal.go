@@ -0,0 +1,191 @@
+package acl
+
+import (
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+const (
+	RoleACLEntity  = globals.CoreFieldsPrefix + "role_acl"
+	GroupACLEntity = globals.CoreFieldsPrefix + "group_acl"
+)
+
+type PrincipalACL struct {
+	Principal   string
+	Entity      string
+	Permissions map[Permission]bool
+	Fields      map[string]FieldRule
+	Filter      map[string]any
+}
+
+func (p *PrincipalACL) toDataMap(kind string) map[string]any {
+	data := map[string]any{
+		"id":     GetPrincipalACLEntityId(p.Principal, p.Entity),
+		kind:     p.Principal,
+		"entity": p.Entity,
+		"fields": fieldRulesToDataMap(p.Fields),
+	}
+
+	if p.Permissions != nil {
+		data["permissions"] = permissionsToDataMap(p.Permissions)
+	}
+
+	if len(p.Filter) > 0 {
+		data["filter"] = p.Filter
+	}
+
+	return data
+}
+
+func (p *PrincipalACL) ToRoleDataMap() map[string]any {
+	return p.toDataMap("role")
+}
+
+func (p *PrincipalACL) ToGroupDataMap() map[string]any {
+	return p.toDataMap("group")
+}
+
+func GetPrincipalACLEntityId(principal, entity string) string {
+	return principal + "::" + entity
+}
+
+func getPrincipalACL(coreEntity, principal, entity string) *PrincipalACL {
+	if principal == "" {
+		return nil
+	}
+
+	data := engine.ReadEntityById(coreEntity, GetPrincipalACLEntityId(principal, entity))
+	if data == nil {
+		return nil
+	}
+
+	acl := &PrincipalACL{
+		Principal: principal,
+		Entity:    entity,
+		Fields:    parseFieldRules(data["fields"]),
+	}
+
+	if raw, ok := data["permissions"]; ok && raw != nil {
+		acl.Permissions = parsePermissions(raw)
+	}
+
+	if filter, ok := data["filter"].(map[string]any); ok && len(filter) > 0 {
+		acl.Filter = filter
+	}
+
+	return acl
+}
+
+func GetRoleACL(entity string, role security.Role) *PrincipalACL {
+	return getPrincipalACL(RoleACLEntity, string(role), entity)
+}
+
+func GetGroupACL(entity, group string) *PrincipalACL {
+	return getPrincipalACL(GroupACLEntity, group, entity)
+}
+
+func SetRoleACL(acl *PrincipalACL) {
+	engine.WriteEntity(RoleACLEntity, acl.ToRoleDataMap())
+	purgeCachedReads(acl.Entity)
+}
+
+func SetGroupACL(acl *PrincipalACL) {
+	engine.WriteEntity(GroupACLEntity, acl.ToGroupDataMap())
+	purgeCachedReads(acl.Entity)
+}
+
+func DeleteRoleACLs(role security.Role) {
+	for _, entity := range engine.ListPublicEntityTypes() {
+		id := GetPrincipalACLEntityId(string(role), entity)
+		if engine.EntityExists(RoleACLEntity, id) {
+			engine.DeleteEntityById(RoleACLEntity, id)
+			purgeCachedReads(entity)
+		}
+	}
+}
+
+func DeleteGroupACLs(group string) {
+	for _, entity := range engine.ListPublicEntityTypes() {
+		id := GetPrincipalACLEntityId(group, entity)
+		if engine.EntityExists(GroupACLEntity, id) {
+			engine.DeleteEntityById(GroupACLEntity, id)
+			purgeCachedReads(entity)
+		}
+	}
+}
+
+func GetFieldRulesForRole(entity string, role security.Role) map[string]FieldRule {
+	acl := GetRoleACL(entity, role)
+	if acl == nil {
+		return map[string]FieldRule{}
+	}
+
+	return acl.Fields
+}
+
+func SetFieldRulesForRole(entity string, role security.Role, rules map[string]FieldRule) {
+	acl := GetRoleACL(entity, role)
+	if acl == nil {
+		acl = &PrincipalACL{Principal: string(role), Entity: entity}
+	}
+
+	acl.Fields = rules
+	SetRoleACL(acl)
+}
+
+func RolePermissions(entity string, role security.Role) map[Permission]bool {
+	if acl := GetRoleACL(entity, role); acl != nil && acl.Permissions != nil {
+		return copyPermissions(acl.Permissions)
+	}
+
+	return DefaultPermissionsForRole(role)
+}
+
+func InheritedPermissions(entity string, role security.Role, groups []string) map[Permission]bool {
+	perms := RolePermissions(entity, role)
+
+	for _, group := range groups {
+		acl := GetGroupACL(entity, group)
+		if acl == nil {
+			continue
+		}
+
+		for p, allowed := range acl.Permissions {
+			if allowed {
+				perms[p] = true
+			}
+		}
+	}
+
+	return perms
+}
+
+func ResolveACL(entity, username string, role security.Role) *ACL {
+	stored := GetStoredACLForUsername(entity, username)
+	if stored != nil && !stored.Inherited {
+		return stored
+	}
+
+	acl := &ACL{
+		Username:    username,
+		Entity:      entity,
+		Permissions: InheritedPermissions(entity, role, security.GroupsForUser(username)),
+		Inherited:   true,
+	}
+
+	if stored != nil {
+		acl.Fields = stored.Fields
+	}
+
+	return acl
+}
+
+func copyPermissions(perms map[Permission]bool) map[Permission]bool {
+	out := NewPermissions()
+	for p, v := range perms {
+		out[p] = v
+	}
+
+	return out
+}
@@ -0,0 +1,89 @@
+package acl
+
+import (
+	"context"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+func ReadFilterForCurrentUser(ctx context.Context, entity string) (query.FilterNode, bool) {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return query.FilterNode{}, true
+	}
+
+	username := security.CurrentPrincipal(ctx).Username
+	acl := currentACL(ctx, entity, username)
+	if acl == nil {
+		return query.FilterNode{}, false
+	}
+
+	if acl.Can(PermissionRead) {
+		return query.FilterNode{}, true
+	}
+
+	grants := make([]query.FilterNode, 0)
+	if acl.Can(PermissionOwningRead) && username != "" {
+		grants = append(grants, query.FilterNode{
+			Leaf: map[string]map[string]string{UsernameField: {"eq": username}},
+		})
+	}
+
+	if username != "" {
+		grants = append(grants, sharedReadFilterForCurrentUser(ctx))
+	}
+
+	grants = append(grants, RowFiltersForCurrentUser(ctx, entity)...)
+
+	switch len(grants) {
+	case 0:
+		return query.FilterNode{}, false
+	case 1:
+		return grants[0], true
+	}
+
+	return query.FilterNode{Or: grants}, true
+}
+
+func RowFiltersForCurrentUser(ctx context.Context, entity string) []query.FilterNode {
+	current := security.CurrentPrincipal(ctx)
+	username := current.Username
+
+	principals := []*PrincipalACL{GetRoleACL(entity, current.Role)}
+	for _, group := range security.GroupsForUser(username) {
+		principals = append(principals, GetGroupACL(entity, group))
+	}
+
+	var user map[string]any
+	filters := make([]query.FilterNode, 0)
+	for _, principal := range principals {
+		if principal == nil || len(principal.Filter) == 0 {
+			continue
+		}
+
+		node, err := query.ParseFilterNode(principal.Filter)
+		if err != nil || node.IsEmpty() {
+			continue
+		}
+
+		if user == nil {
+			user = security.CurrentUserAttributes(ctx)
+		}
+
+		if resolved, ok := query.ResolveUserPlaceholders(node, user); ok {
+			filters = append(filters, resolved)
+		}
+	}
+
+	return filters
+}
+
+func matchesReadFilter(ctx context.Context, entity string, data map[string]any) bool {
+	filter, allowed := ReadFilterForCurrentUser(ctx, entity)
+	if !allowed {
+		return false
+	}
+
+	return filter.IsEmpty() || engine.MatchFilterNode(filter, data)
+}
@@ -0,0 +1,241 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+const SharesField = globals.CoreFieldsPrefix + "shares"
+
+const (
+	ShareRead   = "read"
+	ShareUpdate = "update"
+	ShareDelete = "delete"
+
+	shareUserPrefix  = "user:"
+	shareGroupPrefix = "group:"
+)
+
+type ShareGrant struct {
+	Users       []string
+	Groups      []string
+	Permissions []string
+}
+
+func IsValidSharePermission(permission string) bool {
+	switch permission {
+	case ShareRead, ShareUpdate, ShareDelete:
+		return true
+	}
+
+	return false
+}
+
+func (g ShareGrant) principals() []string {
+	principals := make([]string, 0, len(g.Users)+len(g.Groups))
+	for _, user := range g.Users {
+		if user != "" {
+			principals = append(principals, shareUserPrefix+user)
+		}
+	}
+
+	for _, group := range g.Groups {
+		if group != "" {
+			principals = append(principals, shareGroupPrefix+group)
+		}
+	}
+
+	return principals
+}
+
+func (g ShareGrant) permissions() []string {
+	perms := map[string]bool{}
+	for _, p := range g.Permissions {
+		if IsValidSharePermission(p) {
+			perms[p] = true
+		}
+	}
+
+	if perms[ShareUpdate] || perms[ShareDelete] {
+		perms[ShareRead] = true
+	}
+
+	out := make([]string, 0, len(perms))
+	for p := range perms {
+		out = append(out, p)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+func GetShares(data map[string]any) map[string][]string {
+	shares := map[string][]string{}
+
+	raw, ok := data[SharesField].(map[string]any)
+	if !ok {
+		return shares
+	}
+
+	for _, permission := range []string{ShareRead, ShareUpdate, ShareDelete} {
+		switch list := raw[permission].(type) {
+		case []string:
+			shares[permission] = append([]string{}, list...)
+		case []any:
+			for _, item := range list {
+				if s, ok := item.(string); ok {
+					shares[permission] = append(shares[permission], s)
+				}
+			}
+		}
+	}
+
+	return shares
+}
+
+func ShareEntity(entity, id string, grant ShareGrant) (map[string]any, error) {
+	return updateShares(entity, id, func(shares map[string][]string) {
+		for _, permission := range grant.permissions() {
+			for _, principal := range grant.principals() {
+				if !containsString(shares[permission], principal) {
+					shares[permission] = append(shares[permission], principal)
+				}
+			}
+		}
+	})
+}
+
+func UnshareEntity(entity, id string, grant ShareGrant) (map[string]any, error) {
+	permissions := grant.Permissions
+	if len(permissions) == 0 {
+		permissions = []string{ShareRead, ShareUpdate, ShareDelete}
+	}
+
+	return updateShares(entity, id, func(shares map[string][]string) {
+		for _, permission := range permissions {
+			kept := make([]string, 0, len(shares[permission]))
+			for _, principal := range shares[permission] {
+				if !containsString(grant.principals(), principal) {
+					kept = append(kept, principal)
+				}
+			}
+
+			shares[permission] = kept
+		}
+	})
+}
+
+func TransferOwnership(entity, id, username string) (map[string]any, error) {
+	if username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+
+	if engine.ReadEntityById(entity, id) == nil {
+		return nil, fmt.Errorf("entity not found")
+	}
+
+	return engine.UpdateEntityById(entity, id, map[string]any{UsernameField: username}), nil
+}
+
+func CanManageSharing(ctx context.Context, data map[string]any) bool {
+	if !security.IdentityAuthenticationIsEnabled() {
+		return false
+	}
+
+	current := security.CurrentPrincipal(ctx)
+	if current.Role == security.RoleAdmin {
+		return true
+	}
+
+	owner, ok := data[UsernameField].(string)
+
+	return ok && owner != "" && owner == current.Username
+}
+
+func StripProtectedFields(data map[string]any) {
+	if !security.IdentityAuthenticationIsEnabled() || data == nil {
+		return
+	}
+
+	delete(data, UsernameField)
+	delete(data, SharesField)
+}
+
+func IsSharedWithCurrentUser(ctx context.Context, data map[string]any, permission string) bool {
+	granted := GetShares(data)[permission]
+	for _, principal := range sharePrincipalsForCurrentUser(ctx) {
+		if containsString(granted, principal) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sharedReadFilterForCurrentUser(ctx context.Context) query.FilterNode {
+	return query.FilterNode{
+		Leaf: map[string]map[string]string{
+			SharesField + "." + ShareRead: {"any": strings.Join(sharePrincipalsForCurrentUser(ctx), ",")},
+		},
+	}
+}
+
+func sharePrincipalsForCurrentUser(ctx context.Context) []string {
+	username := security.CurrentPrincipal(ctx).Username
+	if username == "" {
+		return nil
+	}
+
+	principals := []string{shareUserPrefix + username}
+	for _, group := range security.GroupsForUser(username) {
+		principals = append(principals, shareGroupPrefix+group)
+	}
+
+	return principals
+}
+
+func updateShares(entity, id string, apply func(shares map[string][]string)) (map[string]any, error) {
+	data := engine.ReadEntityById(entity, id)
+	if data == nil {
+		return nil, fmt.Errorf("entity not found")
+	}
+
+	shares := GetShares(data)
+	apply(shares)
+
+	stored := map[string]any{}
+	for permission, principals := range shares {
+		if len(principals) == 0 {
+			continue
+		}
+
+		sort.Strings(principals)
+
+		list := make([]any, len(principals))
+		for i, principal := range principals {
+			list[i] = principal
+		}
+
+		stored[permission] = list
+	}
+
+	return engine.UpdateEntityById(entity, id, map[string]any{SharesField: stored}), nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,17 +4,28 @@ import (
 	"bytes"
 	"fmt"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cespare/xxhash/v2"
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/metrics"
 	"github.com/taymour/elysiandb/internal/storage"
 )
 
 var (
 	DirtyFields sync.Map // key: entity+"|"+field -> struct{}
 	fieldLocks  sync.Map // key: entity+"|"+field -> *sync.Mutex
+
+	indexRebuilds        = metrics.NewCounterVec("elysiandb_index_rebuilds_total", "Field index rebuilds per entity.", "entity")
+	indexRebuildDuration = metrics.NewHistogramVec(
+		"elysiandb_index_rebuild_duration_seconds",
+		"Time spent rebuilding a field index.",
+		metrics.DefaultBuckets,
+		"entity",
+	)
 )
 
 func ProcessNextDirtyField() {
@@ -86,16 +97,14 @@ func markFieldAndNestedDirty(entity, field string, value interface{}) {
 	}
 }
 
-func ensureFieldIndexFresh(entity, field string) {
+func ensureFieldIndexFresh(entity, field string) *storage.OrderedIndex {
 	key := fieldKey(entity, field)
-	_, isDirty := DirtyFields.Load(key)
-	ascKey := globals.ApiEntityIndexFieldSortAscKey(entity, field)
-	descKey := globals.ApiEntityIndexFieldSortDescKey(entity, field)
-	ascData, _ := storage.GetByKey(ascKey)
-	descData, _ := storage.GetByKey(descKey)
+	name := globals.ApiEntityIndexFieldSortKey(entity, field)
 
-	if !isDirty && ascData != nil && descData != nil {
-		return
+	if _, isDirty := DirtyFields.Load(key); !isDirty {
+		if idx, ok := storage.GetIndex(name); ok {
+			return idx
+		}
 	}
 
 	mtx := getFieldLock(entity, field)
@@ -103,16 +112,20 @@ func ensureFieldIndexFresh(entity, field string) {
 
 	defer mtx.Unlock()
 
-	_, isDirty = DirtyFields.Load(key)
-	ascData, _ = storage.GetByKey(ascKey)
-	descData, _ = storage.GetByKey(descKey)
-
-	if !isDirty && ascData != nil && descData != nil {
-		return
+	if _, isDirty := DirtyFields.Load(key); !isDirty {
+		if idx, ok := storage.GetIndex(name); ok {
+			return idx
+		}
 	}
 
-	rebuildIndexForField(entity, field)
+	start := time.Now()
+	idx := storeFieldIndex(entity, field)
 	DirtyFields.Delete(key)
+
+	indexRebuilds.Inc(entity)
+	indexRebuildDuration.ObserveSince(start, entity)
+
+	return idx
 }
 
 func RebuildAllIndexes() {
@@ -126,72 +139,112 @@ func RebuildAllIndexes() {
 	}
 }
 
-func rebuildIndexForField(entity, field string) {
-	type indexJob struct {
-		key string
-		asc bool
+func ReconcileIndexes() {
+	for _, entity := range ListEntityTypes() {
+		reconcileEntityIndexes(entity)
 	}
+}
 
-	ascKey := globals.ApiEntityIndexFieldSortAscKey(entity, field)
-	descKey := globals.ApiEntityIndexFieldSortDescKey(entity, field)
-	jobs := []indexJob{
-		{key: ascKey, asc: true},
-		{key: descKey, asc: false},
+func reconcileEntityIndexes(entity string) {
+	prefix := globals.ApiSingleEntityKey(entity, "")
+	keys := storage.ListJsonKeysByPrefix(prefix)
+	sort.Strings(keys)
+
+	stored := make(map[string]bool, len(keys))
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id := strings.TrimPrefix(key, prefix)
+		stored[id] = true
+		ids = append(ids, id)
 	}
 
-	sem := make(chan struct{}, runtime.NumCPU()*2)
-	wg := sync.WaitGroup{}
-
-	for _, job := range jobs {
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(j indexJob) {
-			defer wg.Done()
-			defer func() { <-sem }()
-			ids := GetSortedEntityIdsByField(entity, field, j.asc)
-			storage.PutKeyValue(j.key, encodeIDs(ids))
-		}(job)
+	master := storage.GetOrCreateIndex(globals.ApiEntityIndexIdKey(entity))
+	for _, id := range master.Ids(true, 0, 0) {
+		if !stored[id] {
+			master.Delete(id)
+		}
 	}
 
-	wg.Wait()
+	for _, id := range ids {
+		master.Append(id)
+	}
+
+	for _, field := range GetListForIndexedFields(entity) {
+		idx, ok := storage.GetIndex(globals.ApiEntityIndexFieldSortKey(entity, field))
+		if !ok {
+			rebuildIndexForField(entity, field)
+			continue
+		}
+
+		for _, id := range idx.Ids(true, 0, 0) {
+			if !stored[id] {
+				idx.Delete(id)
+			}
+		}
+
+		for _, id := range ids {
+			if data, _ := storage.GetJsonByKeyNoCopy(prefix + id); data != nil {
+				idx.Set(id, indexSortKeyFor(entity, data, field))
+			}
+		}
+	}
+}
+
+func rebuildIndexForField(entity, field string) *storage.OrderedIndex {
+	mtx := getFieldLock(entity, field)
+	mtx.Lock()
+
+	defer mtx.Unlock()
+
+	return storeFieldIndex(entity, field)
+}
+
+func storeFieldIndex(entity, field string) *storage.OrderedIndex {
+	idx := buildFieldIndex(entity, field)
+	storage.PutIndex(globals.ApiEntityIndexFieldSortKey(entity, field), idx)
 	AddFieldToIndexedFields(entity, field)
+
+	return idx
 }
 
-func RemoveIdFromIndexes(entity, id string) {
-	idIndexKey := globals.ApiEntityIndexIdKey(entity)
-	raw, _ := storage.GetByKey(idIndexKey)
-	ids := decodeIDs(raw)
-	changed := false
-	newIds := newIdsWithout(ids, id, &changed)
-	if changed {
-		storage.PutKeyValue(idIndexKey, encodeIDs(newIds))
+func buildFieldIndex(entity, field string) *storage.OrderedIndex {
+	idx := storage.NewOrderedIndex()
+	for _, id := range listIds(entity, "", true, 0, 0) {
+		data, _ := storage.GetJsonByKeyNoCopy(globals.ApiSingleEntityKey(entity, id))
+		if data != nil {
+			idx.Set(id, indexSortKeyFor(entity, data, field))
+		}
 	}
 
-	fields := GetListForIndexedFields(entity)
-	for _, f := range fields {
-		MarkFieldDirty(entity, f)
-	}
+	return idx
+}
+
+func masterIndex(entity string) *storage.OrderedIndex {
+	return storage.GetOrCreateIndex(globals.ApiEntityIndexIdKey(entity))
+}
+
+func RemoveIdFromIndexes(entity, id string) {
+	masterIndex(entity).Delete(id)
+	RemoveIdFromNonMasterIndexes(entity, id)
+	markLinkedFieldsDirty(entity)
 }
 
 func RemoveIdFromNonMasterIndexes(entity, id string) {
-	fields := GetListForIndexedFields(entity)
-	for _, field := range fields {
-		for _, sortKey := range []string{
-			globals.ApiEntityIndexFieldSortAscKey(entity, field),
-			globals.ApiEntityIndexFieldSortDescKey(entity, field),
-		} {
-			raw, _ := storage.GetByKey(sortKey)
-			if len(raw) == 0 {
-				continue
-			}
+	for _, field := range GetListForIndexedFields(entity) {
+		withFieldIndex(entity, field, func(idx *storage.OrderedIndex) {
+			idx.Delete(id)
+		})
+	}
+}
 
-			ids := decodeIDs(raw)
-			changed := false
-			newIds := newIdsWithout(ids, id, &changed)
-			if changed {
-				storage.PutKeyValue(sortKey, encodeIDs(newIds))
-			}
-		}
+func withFieldIndex(entity, field string, fn func(idx *storage.OrderedIndex)) {
+	mtx := getFieldLock(entity, field)
+	mtx.Lock()
+
+	defer mtx.Unlock()
+
+	if idx, ok := storage.GetIndex(globals.ApiEntityIndexFieldSortKey(entity, field)); ok {
+		fn(idx)
 	}
 }
 
@@ -225,23 +278,19 @@ func encodeIDs(ids []string) []byte {
 }
 
 func AddIdToindexes(entity, id string) {
-	k := globals.ApiEntityIndexIdKey(entity)
-	raw, _ := storage.GetByKey(k)
-	ids := decodeIDs(raw)
-	for _, v := range ids {
-		if v == id {
-			return
-		}
-	}
-
-	ids = append(ids, id)
-	_ = storage.PutKeyValue(k, encodeIDs(ids))
+	masterIndex(entity).Append(id)
 }
 
 func RemoveEntityIndexes(entity string) {
+	markLinkedFieldsDirty(entity)
+
 	storage.DeleteByWildcardKey(
 		globals.ApiEntityIndexPatternKey(entity),
 	)
+
+	storage.DeleteIndexesByPattern(
+		globals.ApiEntityIndexPatternKey(entity),
+	)
 }
 
 func EnsureFieldIndex(entity, field, id string, value any) {
@@ -273,12 +322,9 @@ func EnsureFieldIndex(entity, field, id string, value any) {
 
 func IndexExistsForField(entity, field string) bool {
 	ensureFieldIndexFresh(entity, field)
-	ascKey := globals.ApiEntityIndexFieldSortAscKey(entity, field)
-	descKey := globals.ApiEntityIndexFieldSortDescKey(entity, field)
-	ascData, _ := storage.GetByKey(ascKey)
-	descData, _ := storage.GetByKey(descKey)
+	_, ok := storage.GetIndex(globals.ApiEntityIndexFieldSortKey(entity, field))
 
-	return ascData != nil && descData != nil
+	return ok
 }
 
 func GetListForIndexedFields(entity string) []string {
@@ -319,58 +365,62 @@ func UpdateIndexesForEntity(entity, id string, oldData, newData map[string]any)
 		}
 	}
 
-	for k, v := range newData {
-		if k == "id" {
-			continue
+	unchanged := func(field string) bool {
+		if oldData == nil || newData == nil {
+			return false
 		}
 
-		markFieldAndNestedDirty(entity, k, v)
+		root := strings.SplitN(field, ".", 2)[0]
+		oldVal, oldExists := oldData[root]
+		newVal, newExists := newData[root]
+
+		return oldExists == newExists && safeKey(oldVal) == safeKey(newVal)
 	}
 
-	for k, newVal := range newData {
-		if k == "id" {
+	for _, field := range GetListForIndexedFields(entity) {
+		if unchanged(field) {
 			continue
 		}
 
-		oldVal, exists := oldData[k]
-		if !exists || safeKey(oldVal) != safeKey(newVal) {
-			markFieldAndNestedDirty(entity, k, newVal)
-		}
+		withFieldIndex(entity, field, func(idx *storage.OrderedIndex) {
+			if newData == nil {
+				idx.Delete(id)
+				return
+			}
+
+			idx.Set(id, indexSortKeyFor(entity, newData, field))
+		})
 	}
 
-	for k := range oldData {
-		if k == "id" {
-			continue
-		}
+	markLinkedFieldsDirty(entity)
+}
+
+func addLinkedField(linked, entity, field string) {
+	key := globals.ApiEntityIndexLinkedFieldsKey(linked)
+	raw, _ := storage.GetByKey(key)
 
-		if _, exists := newData[k]; !exists {
-			MarkFieldDirty(entity, k)
+	dependent := fieldKey(entity, field)
+	dependents := decodeIDs(raw)
+	for _, existing := range dependents {
+		if existing == dependent {
+			return
 		}
 	}
+
+	storage.PutKeyValue(key, encodeIDs(append(dependents, dependent)))
+}
+
+func markLinkedFieldsDirty(linked string) {
+	raw, _ := storage.GetByKey(globals.ApiEntityIndexLinkedFieldsKey(linked))
+	for _, dependent := range decodeIDs(raw) {
+		DirtyFields.Store(dependent, struct{}{})
+	}
 }
 
 func DeleteIndexesForField(entity, field string) {
-	storage.DeleteByWildcardKey(
+	storage.DeleteIndexesByPattern(
 		globals.ApiEntityIndexFieldAllKey(entity, field),
 	)
 
 	MarkFieldDirty(entity, field)
 }
-
-func newIdsWithout(ids []string, id string, changed *bool) []string {
-	if len(ids) == 0 {
-		return ids
-	}
-
-	out := ids[:0]
-	for _, existing := range ids {
-		if existing == id {
-			*changed = true
-			continue
-		}
-
-		out = append(out, existing)
-	}
-
-	return out
-}
@@ -47,6 +47,10 @@ func ApplyQueryFilter(data []map[string]any, filter query.FilterNode) []map[stri
 	return filtered
 }
 
+func MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return matchFilterNode(node, entity)
+}
+
 func matchFilterNode(node query.FilterNode, entity map[string]any) bool {
 	if node.Leaf != nil {
 		return matchLeafStrict(entity, node.Leaf)
@@ -1,31 +1,52 @@
 package api_storage
 
 import (
-	"sort"
+	"encoding/json"
 	"strings"
 	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/storage"
 )
 
 func getSortNestedValue(m map[string]any, path string) any {
-	parts := strings.Split(path, ".")
+	value, _ := resolveSortPath(m, path)
+	return value
+}
+
+func resolveSortPath(m map[string]any, path string) (any, []string) {
 	var cur any = m
-	for _, p := range parts {
-		if mm, ok := cur.(map[string]any); ok {
-			cur = mm[p]
-		} else {
-			return nil
+	var links []string
+	for _, p := range strings.Split(path, ".") {
+		mm, ok := cur.(map[string]any)
+		if !ok {
+			return nil, links
 		}
-	}
 
-	if s, ok := cur.(string); ok {
-		if t, ok := parseDateForSort(s); ok {
-			return t
+		v, exists := mm[p]
+		if !exists {
+			if entity, linked := linkedEntityForSort(mm); entity != "" {
+				links = append(links, entity)
+				v = linked[p]
+			}
 		}
 
-		return s
+		cur = v
 	}
 
-	return cur
+	return cur, links
+}
+
+func linkedEntityForSort(m map[string]any) (string, map[string]any) {
+	entity, _ := m["@entity"].(string)
+	id, _ := m["id"].(string)
+	if entity == "" || id == "" {
+		return "", nil
+	}
+
+	data, _ := storage.GetJsonByKeyNoCopy(globals.ApiSingleEntityKey(entity, id))
+
+	return entity, data
 }
 
 func parseDateForSort(s string) (time.Time, bool) {
@@ -40,94 +61,49 @@ func parseDateForSort(s string) (time.Time, bool) {
 	return time.Time{}, false
 }
 
-func GetSortedEntityIdsByField(entity, field string, ascending bool) []string {
-	data := ListEntities(entity, 0, 0, "", ascending, map[string]map[string]string{}, "", "all")
-
-	sort.Slice(data, func(i, j int) bool {
-		a := getSortNestedValue(data[i], field)
-		b := getSortNestedValue(data[j], field)
-
-		switch va := a.(type) {
-		case int:
-			vb, _ := b.(int)
-			if ascending {
-				return va < vb
-			}
-
-			return va > vb
-		case float64:
-			vb, _ := b.(float64)
-			if ascending {
-				return va < vb
-			}
-
-			return va > vb
-		case time.Time:
-			switch vb := b.(type) {
-			case time.Time:
-				if ascending {
-					return va.Before(vb)
-				}
-
-				return va.After(vb)
-			case string:
-				if tb, ok := parseDateForSort(vb); ok {
-					if ascending {
-						return va.Before(tb)
-					}
-
-					return va.After(tb)
-				}
-
-				if ascending {
-					return true
-				}
-
-				return false
-			default:
-				if ascending {
-					return true
-				}
-
-				return false
-			}
-		case string:
-			if ta, ok := parseDateForSort(va); ok {
-				switch vb := b.(type) {
-				case time.Time:
-					if ascending {
-						return ta.Before(vb)
-					}
-
-					return ta.After(vb)
-				case string:
-					if tb, ok2 := parseDateForSort(vb); ok2 {
-						if ascending {
-							return ta.Before(tb)
-						}
-
-						return ta.After(tb)
-					}
-				}
-			}
+func indexSortKeyFor(entity string, data map[string]any, field string) storage.SortKey {
+	value, links := resolveSortPath(data, field)
+	for _, linked := range links {
+		addLinkedField(linked, entity, field)
+	}
 
-			vb, _ := b.(string)
-			if ascending {
-				return va < vb
-			}
+	return sortKeyOf(value)
+}
 
-			return va > vb
-		default:
-			return false
+func sortKeyOf(value any) storage.SortKey {
+	switch v := value.(type) {
+	case nil:
+		return storage.SortKey{Kind: storage.SortKindMissing}
+	case bool:
+		if v {
+			return storage.SortKey{Kind: storage.SortKindBool, Num: 1}
 		}
-	})
 
-	var ids []string
-	for _, item := range data {
-		if idVal, ok := item["id"].(string); ok {
-			ids = append(ids, idVal)
+		return storage.SortKey{Kind: storage.SortKindBool}
+	case float64:
+		return storage.SortKey{Kind: storage.SortKindNumber, Num: v}
+	case float32:
+		return storage.SortKey{Kind: storage.SortKindNumber, Num: float64(v)}
+	case int:
+		return storage.SortKey{Kind: storage.SortKindNumber, Num: float64(v)}
+	case int32:
+		return storage.SortKey{Kind: storage.SortKindNumber, Num: float64(v)}
+	case int64:
+		return storage.SortKey{Kind: storage.SortKindNumber, Num: float64(v)}
+	case time.Time:
+		return storage.SortKey{Kind: storage.SortKindTime, Int: v.UnixNano()}
+	case string:
+		if t, ok := parseDateForSort(v); ok {
+			return storage.SortKey{Kind: storage.SortKindTime, Int: t.UnixNano()}
 		}
+
+		return storage.SortKey{Kind: storage.SortKindString, Str: v}
+	default:
+		raw, _ := json.Marshal(v)
+		return storage.SortKey{Kind: storage.SortKindOther, Str: string(raw)}
 	}
+}
 
-	return ids
+func GetSortedEntityIdsByField(entity, field string, ascending bool) []string {
+	return buildFieldIndex(entity, field).Ids(ascending, 0, 0)
 }
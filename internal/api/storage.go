@@ -1,6 +1,7 @@
 package api_storage
 
 import (
+	"context"
 	"fmt"
 	"maps"
 	"slices"
@@ -9,8 +10,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/query"
 	"github.com/taymour/elysiandb/internal/schema"
 	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/taymour/elysiandb/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const CoreEntityTypePrefix = "_elysiandb_core_"
@@ -99,16 +103,7 @@ func persistEntity(entity string, data map[string]any) {
 	storage.PutJsonValue(key, data)
 	AddIdToindexes(entity, id)
 	AddEntityType(entity)
-
-	if old != nil {
-		UpdateIndexesForEntity(entity, id, old, data)
-	} else {
-		for k := range data {
-			if k != "id" {
-				markFieldAndNestedDirty(entity, k, data[k])
-			}
-		}
-	}
+	UpdateIndexesForEntity(entity, id, old, data)
 }
 
 func updateSchemaIfNeeded(entity string, data map[string]any) {
@@ -184,6 +179,17 @@ func ReadEntityById(entity, id string) map[string]any {
 	return data
 }
 
+func ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	out := make(map[string]map[string]any, len(ids))
+	for _, id := range ids {
+		if data := ReadEntityById(entity, id); data != nil {
+			out[id] = data
+		}
+	}
+
+	return out
+}
+
 func ListEntities(
 	entity string,
 	limit int,
@@ -194,20 +200,48 @@ func ListEntities(
 	search string,
 	includesParam string,
 ) []map[string]any {
-	idList, err := GetListOfIds(entity, sortField, sortAscending)
-	if err != nil {
-		return []map[string]any{}
+	return ListEntitiesWithFilterNode(entity, limit, offset, sortField, sortAscending, filters, search, includesParam, query.FilterNode{})
+}
+
+func ListEntitiesWithFilterNode(
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return ListEntitiesWithFilterNodeContext(context.Background(), entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
+
+func ListEntitiesWithFilterNodeContext(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	restricted := len(filters) > 0 || !node.IsEmpty()
+
+	var ids []string
+	if restricted {
+		ids = GetListOfIds(entity, sortField, sortAscending)
+	} else {
+		ids = listIds(entity, sortField, sortAscending, offset, limit)
 	}
 
-	ids := decodeIDs(idList)
 	if len(ids) == 0 {
 		return []map[string]any{}
 	}
 
-	if len(filters) == 0 {
-		ids = applyOffsetLimit(ids, offset, limit)
-	}
-
 	all := make([]map[string]any, 0, len(ids))
 	for _, id := range ids {
 		entityData := ReadEntityById(entity, id)
@@ -220,11 +254,17 @@ func ListEntities(
 	includesParam = MergeIncludes(includesParam, autoInc)
 
 	if includesParam != "" {
+		_, span := tracing.Start(ctx, "ApplyIncludes", attribute.String("elysiandb.includes", includesParam))
 		all = ApplyIncludes(all, includesParam)
+		span.End()
 	}
 
 	filtered := make([]map[string]any, 0, len(all))
 	for _, e := range all {
+		if !node.IsEmpty() && !MatchFilterNode(node, e) {
+			continue
+		}
+
 		if search == "" && FiltersMatchEntity(e, filters) {
 			filtered = append(filtered, e)
 		}
@@ -234,13 +274,24 @@ func ListEntities(
 		}
 	}
 
-	if len(filters) > 0 {
+	if restricted {
 		return applyOffsetLimit(filtered, offset, limit)
 	}
 
 	return filtered
 }
 
+func SearchList(entities []map[string]any, search string, offset, limit int) []map[string]any {
+	matched := make([]map[string]any, 0, len(entities))
+	for _, e := range entities {
+		if SearchMatchesEntity(e, search) {
+			matched = append(matched, e)
+		}
+	}
+
+	return applyOffsetLimit(matched, offset, limit)
+}
+
 func ApplyFiltersToList(
 	entities []map[string]any,
 	filters map[string]map[string]string,
@@ -273,26 +324,21 @@ func applyOffsetLimit[T any](in []T, offset, limit int) []T {
 	return in[start:end]
 }
 
-func GetListOfIds(entity, sortField string, sortAscending bool) ([]byte, error) {
-	if sortField == "" {
-		idIndexKey := globals.ApiEntityIndexIdKey(entity)
-		return storage.GetByKey(idIndexKey)
-	}
+func GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	return listIds(entity, sortField, sortAscending, 0, 0)
+}
 
-	ensureFieldIndexFresh(entity, sortField)
-	if !IndexExistsForField(entity, sortField) {
-		return []byte{}, nil
-	}
+func listIds(entity, sortField string, sortAscending bool, offset, limit int) []string {
+	if sortField == "" {
+		idx, ok := storage.GetIndex(globals.ApiEntityIndexIdKey(entity))
+		if !ok {
+			return []string{}
+		}
 
-	if sortAscending {
-		return storage.GetByKey(
-			globals.ApiEntityIndexFieldSortAscKey(entity, sortField),
-		)
+		return idx.Ids(true, offset, limit)
 	}
 
-	return storage.GetByKey(
-		globals.ApiEntityIndexFieldSortDescKey(entity, sortField),
-	)
+	return ensureFieldIndexFresh(entity, sortField).Ids(sortAscending, offset, limit)
 }
 
 func DeleteEntityById(entity, id string) {
@@ -349,7 +395,6 @@ func UpdateEntityById(entity, id string, updated map[string]any) map[string]any
 	}
 
 	persistEntity(entity, existing)
-	UpdateIndexesForEntity(entity, id, old, existing)
 	updateSchemaIfNeeded(entity, existing)
 
 	return existing
@@ -412,7 +457,7 @@ func ImportAll(data map[string][]map[string]any) {
 
 		for _, item := range items {
 			if errs := WriteEntity(entity, item); len(errs) > 0 {
-				log.Error(fmt.Sprintf("Error importing entity %s: %+v", entity, errs))
+				log.WithFields(log.Fields{"entity": entity, "errors": fmt.Sprintf("%+v", errs)}).Error("Error importing entity")
 			}
 		}
 
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/valyala/fasthttp"
+)
+
+const Filename = "audit.ndjson"
+
+const maxMemoryEvents = 10000
+
+const (
+	CategoryAuth        = "auth"
+	CategoryUser        = "user"
+	CategoryRole        = "role"
+	CategoryGroup       = "group"
+	CategoryACL         = "acl"
+	CategoryHook        = "hook"
+	CategorySchema      = "schema"
+	CategoryDestructive = "destructive"
+	CategoryReplication = "replication"
+	CategoryBackup      = "backup"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+type Event struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor"`
+	Role      string    `json:"role,omitempty"`
+	SourceIP  string    `json:"source_ip,omitempty"`
+	Category  string    `json:"category"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+	Before    any       `json:"before,omitempty"`
+	After     any       `json:"after,omitempty"`
+}
+
+type store struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	memory [][]byte
+}
+
+var Store = &store{}
+
+func IsEnabled() bool {
+	return globals.GetConfig().Security.Audit.Enabled
+}
+
+func Path() string {
+	return fmt.Sprintf("%s/%s", globals.GetConfig().Store.Folder, Filename)
+}
+
+func Record(ctx *fasthttp.RequestCtx, category, action, target string, before, after any) {
+	if !IsEnabled() {
+		return
+	}
+
+	event := NewEvent(ctx, category, action, target)
+	event.Before = before
+	event.After = after
+
+	write(event)
+}
+
+func RecordFailure(ctx *fasthttp.RequestCtx, category, action, target, reason string) {
+	if !IsEnabled() {
+		return
+	}
+
+	event := NewEvent(ctx, category, action, target)
+	event.Outcome = OutcomeFailure
+	event.Reason = reason
+
+	write(event)
+}
+
+func NewEvent(ctx *fasthttp.RequestCtx, category, action, target string) Event {
+	event := Event{
+		ID:        uuid.New().String(),
+		Timestamp: time.Now().UTC(),
+		Actor:     "anonymous",
+		Category:  category,
+		Action:    action,
+		Target:    target,
+		Outcome:   OutcomeSuccess,
+	}
+
+	if ctx == nil {
+		event.Actor = "system"
+		return event
+	}
+
+	if username, ok := ctx.UserValue("username").(string); ok && username != "" {
+		event.Actor = username
+	} else if globals.GetConfig().Security.Authentication.Enabled && globals.GetConfig().Security.Authentication.Mode == "token" {
+		event.Actor = "token"
+	}
+
+	if role := ctx.UserValue("role"); role != nil {
+		event.Role = fmt.Sprint(role)
+	}
+
+	event.SourceIP = ctx.RemoteIP().String()
+
+	return event
+}
+
+func write(event Event) {
+	if err := Store.Append(event); err != nil {
+		log.Error("Error writing audit event: ", err)
+	}
+}
+
+func (s *store) Append(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if globals.GetConfig().Store.IsEphemeral() {
+		s.memory = append(s.memory, line)
+		if len(s.memory) > maxMemoryEvents {
+			s.memory = s.memory[len(s.memory)-maxMemoryEvents:]
+		}
+
+		return nil
+	}
+
+	path := Path()
+	if s.file == nil || s.path != path {
+		if s.file != nil {
+			_ = s.file.Close()
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			s.file = nil
+			return err
+		}
+
+		s.file = file
+		s.path = path
+	}
+
+	_, err = s.file.Write(append(line, '\n'))
+
+	return err
+}
+
+func (s *store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memory = nil
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
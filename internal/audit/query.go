@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+const maxLineSize = 16 * 1024 * 1024
+
+type Filter struct {
+	Actor    string
+	Category string
+	Action   string
+	Target   string
+	Outcome  string
+	SourceIP string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+func (f Filter) Matches(event Event) bool {
+	if f.Actor != "" && f.Actor != event.Actor {
+		return false
+	}
+
+	if f.Category != "" && f.Category != event.Category {
+		return false
+	}
+
+	if f.Action != "" && f.Action != event.Action {
+		return false
+	}
+
+	if f.Target != "" && !storage.MatchGlob(f.Target, event.Target) {
+		return false
+	}
+
+	if f.Outcome != "" && f.Outcome != event.Outcome {
+		return false
+	}
+
+	if f.SourceIP != "" && f.SourceIP != event.SourceIP {
+		return false
+	}
+
+	if !f.From.IsZero() && event.Timestamp.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && event.Timestamp.After(f.To) {
+		return false
+	}
+
+	return true
+}
+
+func Query(filter Filter) ([]Event, error) {
+	events := make([]Event, 0)
+
+	err := scan(filter, func(event Event, _ []byte) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	if filter.Offset > 0 {
+		if filter.Offset >= len(events) {
+			return []Event{}, nil
+		}
+
+		events = events[filter.Offset:]
+	}
+
+	if filter.Limit > 0 && filter.Limit < len(events) {
+		events = events[:filter.Limit]
+	}
+
+	return events, nil
+}
+
+func Export(w io.Writer, filter Filter) error {
+	skipped, written := 0, 0
+
+	return scan(filter, func(_ Event, line []byte) error {
+		if skipped < filter.Offset {
+			skipped++
+			return nil
+		}
+
+		if filter.Limit > 0 && written >= filter.Limit {
+			return nil
+		}
+
+		written++
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+
+		_, err := w.Write([]byte{'\n'})
+		return err
+	})
+}
+
+func scan(filter Filter, fn func(Event, []byte) error) error {
+	Store.mu.Lock()
+	defer Store.mu.Unlock()
+
+	if globals.GetConfig().Store.IsEphemeral() {
+		for _, line := range Store.memory {
+			if err := scanLine(filter, line, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	file, err := os.Open(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		if err := scanLine(filter, scanner.Bytes(), fn); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func scanLine(filter Filter, line []byte, fn func(Event, []byte) error) error {
+	if len(line) == 0 {
+		return nil
+	}
+
+	var event Event
+	if err := json.Unmarshal(line, &event); err != nil {
+		return nil
+	}
+
+	if !filter.Matches(event) {
+		return nil
+	}
+
+	return fn(event, line)
+}
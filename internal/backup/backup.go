@@ -0,0 +1,216 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+const (
+	FormatVersion = 1
+	ManifestFile  = "manifest.json"
+	DumpFile      = "dump.json"
+	ContentType   = "application/gzip"
+)
+
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type Manifest struct {
+	Format     int       `json:"format"`
+	Version    string    `json:"version"`
+	Engine     string    `json:"engine"`
+	CreatedAt  time.Time `json:"createdAt"`
+	WalArchive bool      `json:"walArchive"`
+	WalStart   uint64    `json:"walStart"`
+	WalEnd     uint64    `json:"walEnd"`
+	Files      []File    `json:"files"`
+}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+func FileName(at time.Time) string {
+	return "elysiandb-backup-" + at.UTC().Format("20060102T150405Z") + ".tar.gz"
+}
+
+func Write(w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{
+		Format:  FormatVersion,
+		Version: globals.VERSION,
+		Engine:  engine.Current().Name(),
+	}
+
+	entries, err := collect(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.CreatedAt = time.Now().UTC()
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		if err := writeEntry(tw, e.name, e.data, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(e.data)
+		manifest.Files = append(manifest.Files, File{
+			Name:   e.name,
+			Size:   int64(len(e.data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeEntry(tw, ManifestFile, raw, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func WriteFile(path string) (*Manifest, error) {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := Write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func collect(manifest *Manifest) ([]entry, error) {
+	manifest.WalArchive = recovery.ArchivingEnabled()
+	manifest.WalStart = recovery.Sequence()
+	snapshot := storage.TakeSnapshot()
+	manifest.WalEnd = recovery.Sequence()
+
+	kv, err := json.Marshal(snapshot.KeyValues)
+	if err != nil {
+		return nil, fmt.Errorf("encoding key/value store: %w", err)
+	}
+
+	expirations, err := json.Marshal(groupExpirations(snapshot.Expirations))
+	if err != nil {
+		return nil, fmt.Errorf("encoding expirations: %w", err)
+	}
+
+	entries := []entry{
+		{name: storage.DataFile, data: kv},
+		{name: storage.ExpirationDataFile, data: expirations},
+	}
+
+	if engine.CurrentCapabilities().ExternalStorage {
+		dump, err := json.Marshal(engine.DumpAll())
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s dump: %w", engine.Current().Name(), err)
+		}
+
+		entries = append(entries, entry{name: DumpFile, data: dump})
+	} else {
+		documents, err := json.Marshal(snapshot.Json)
+		if err != nil {
+			return nil, fmt.Errorf("encoding json store: %w", err)
+		}
+
+		entries = append(entries, entry{name: storage.JsonDataFile, data: documents})
+	}
+
+	if err := security.FlushSessions(); err != nil {
+		log.Warn("Backup: unable to flush sessions, using the last saved copy: ", err)
+	}
+
+	for _, name := range []string{security.KeyFilename, security.SessionsFilename} {
+		data, err := os.ReadFile(filepath.Join(globals.GetConfig().Store.Folder, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+
+		entries = append(entries, entry{name: name, data: data})
+	}
+
+	return entries, nil
+}
+
+func groupExpirations(expirations map[string]int64) map[string][]string {
+	grouped := make(map[string][]string)
+	for key, ts := range expirations {
+		bucket := strconv.FormatInt(ts, 10)
+		grouped[bucket] = append(grouped[bucket], key)
+	}
+
+	return grouped
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err := tw.Write(data)
+
+	return err
+}
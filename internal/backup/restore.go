@@ -0,0 +1,287 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+type Archive struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+func Read(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, header.Name)
+		}
+
+		if _, exists := files[header.Name]; exists {
+			return nil, fmt.Errorf("%w: duplicate entry %q", ErrInvalidArchive, header.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		files[header.Name] = data
+	}
+
+	raw, ok := files[ManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, ManifestFile)
+	}
+
+	delete(files, ManifestFile)
+
+	a := &Archive{files: files}
+	if err := json.Unmarshal(raw, &a.Manifest); err != nil {
+		return nil, fmt.Errorf("%w: unreadable manifest: %v", ErrInvalidArchive, err)
+	}
+
+	if err := a.verify(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func ReadFile(path string) (*Archive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+func (a *Archive) verify() error {
+	if a.Manifest.Format != FormatVersion {
+		return fmt.Errorf("%w: unsupported format %d", ErrInvalidArchive, a.Manifest.Format)
+	}
+
+	listed := make(map[string]bool, len(a.Manifest.Files))
+	for _, f := range a.Manifest.Files {
+		data, ok := a.files[f.Name]
+		if !ok {
+			return fmt.Errorf("%w: missing %s", ErrInvalidArchive, f.Name)
+		}
+
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidArchive, f.Name)
+		}
+
+		listed[f.Name] = true
+	}
+
+	for name := range a.files {
+		if !listed[name] {
+			return fmt.Errorf("%w: %s is not listed in the manifest", ErrInvalidArchive, name)
+		}
+	}
+
+	required := []string{storage.DataFile, storage.ExpirationDataFile, storage.JsonDataFile}
+	if a.HasDump() {
+		required[2] = DumpFile
+	}
+
+	for _, name := range required {
+		if !listed[name] {
+			return fmt.Errorf("%w: missing %s", ErrInvalidArchive, name)
+		}
+	}
+
+	return nil
+}
+
+func (a *Archive) HasDump() bool {
+	_, ok := a.files[DumpFile]
+	return ok
+}
+
+func Restore(a *Archive) error {
+	if err := a.checkEngine(); err != nil {
+		return err
+	}
+
+	snapshot, dump, err := a.decode()
+	if err != nil {
+		return err
+	}
+
+	return a.load(snapshot, dump)
+}
+
+func RestoreToPoint(a *Archive, walFolder string, target recovery.Target) (recovery.ReplayResult, error) {
+	var result recovery.ReplayResult
+
+	if err := a.checkEngine(); err != nil {
+		return result, err
+	}
+
+	if a.HasDump() {
+		return result, fmt.Errorf("point-in-time recovery needs an archive of the internal engine")
+	}
+
+	if !a.Manifest.WalArchive {
+		return result, fmt.Errorf("archive was taken while wal archiving was disabled")
+	}
+
+	if walFolder == "" {
+		return result, recovery.ErrNoArchive
+	}
+
+	if target.Seq > 0 && target.Seq < a.Manifest.WalEnd {
+		return result, fmt.Errorf("target sequence %d is before the base backup (sequence %d)", target.Seq, a.Manifest.WalEnd)
+	}
+
+	if !target.Time.IsZero() && target.Time.Before(a.Manifest.CreatedAt) {
+		return result, fmt.Errorf("target time %s is before the base backup (%s)", target.Time.Format(time.RFC3339), a.Manifest.CreatedAt.Format(time.RFC3339))
+	}
+
+	snapshot, _, err := a.decode()
+	if err != nil {
+		return result, err
+	}
+
+	result, err = recovery.ReplayArchive(walFolder, a.Manifest.WalStart, target, snapshot.ApplyWal)
+	if err != nil {
+		return result, err
+	}
+
+	return result, a.load(snapshot, nil)
+}
+
+func (a *Archive) checkEngine() error {
+	if current := engine.Current().Name(); a.Manifest.Engine != current {
+		return fmt.Errorf("archive was taken with the %s engine, this node uses %s", a.Manifest.Engine, current)
+	}
+
+	return nil
+}
+
+func (a *Archive) load(snapshot *storage.Snapshot, dump map[string][]map[string]any) error {
+	if dump != nil {
+		engine.DeleteAll()
+	}
+
+	storage.RestoreSnapshot(snapshot)
+
+	if dump != nil {
+		engine.ImportAll(dump)
+	} else {
+		api_storage.ReconcileIndexes()
+	}
+
+	storage.WriteToDB()
+
+	return a.restoreSecurityFiles()
+}
+
+func (a *Archive) decode() (*storage.Snapshot, map[string][]map[string]any, error) {
+	snapshot := &storage.Snapshot{Expirations: map[string]int64{}}
+	if err := json.Unmarshal(a.files[storage.DataFile], &snapshot.KeyValues); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, storage.DataFile, err)
+	}
+
+	var grouped map[string][]string
+	if err := json.Unmarshal(a.files[storage.ExpirationDataFile], &grouped); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, storage.ExpirationDataFile, err)
+	}
+
+	for bucket, keys := range grouped {
+		ts, err := strconv.ParseInt(bucket, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: invalid timestamp %q", ErrInvalidArchive, storage.ExpirationDataFile, bucket)
+		}
+
+		for _, key := range keys {
+			snapshot.Expirations[key] = ts
+		}
+	}
+
+	if snapshot.KeyValues == nil {
+		snapshot.KeyValues = map[string][]byte{}
+	}
+
+	if a.HasDump() {
+		var dump map[string][]map[string]any
+		if err := json.Unmarshal(a.files[DumpFile], &dump); err != nil {
+			return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, DumpFile, err)
+		}
+
+		return snapshot, dump, nil
+	}
+
+	if err := json.Unmarshal(a.files[storage.JsonDataFile], &snapshot.Json); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, storage.JsonDataFile, err)
+	}
+
+	if snapshot.Json == nil {
+		snapshot.Json = map[string]map[string]any{}
+	}
+
+	return snapshot, nil, nil
+}
+
+func (a *Archive) restoreSecurityFiles() error {
+	folder := globals.GetConfig().Store.Folder
+
+	for _, name := range []string{security.KeyFilename, security.SessionsFilename} {
+		path := filepath.Join(folder, name)
+
+		data, ok := a.files[name]
+		if !ok {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			continue
+		}
+
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
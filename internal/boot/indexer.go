@@ -8,7 +8,7 @@ import (
 )
 
 func BootLazyIndexRebuilder() {
-	api_storage.RebuildAllIndexes()
+	api_storage.ReconcileIndexes()
 
 	if globals.GetConfig().Server.HTTP.Enabled {
 		for i := 0; i < globals.GetConfig().Api.Index.Workers; i++ {
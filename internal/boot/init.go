@@ -16,12 +16,15 @@ func InitDB() {
 
 	if cfg.Api.Cache.Enabled {
 		cache.InitCache(time.Duration(cfg.Api.Cache.CleanupIntervalSeconds) * time.Second)
+		cache.EntityExistsFunc = engine.EntityTypeExists
 	}
 
 	storage.LoadDB()
 	storage.LoadJsonDB()
 
 	if cfg.Store.CrashRecovery.Enabled {
+		recovery.InitSequence()
+
 		recovery.ReplayJsonRecoveryLog(storage.PutJsonValue, storage.DeleteJsonByKey)
 		recovery.ActivateJsonRecoveryLog(storage.WriteJsonDB)
 
@@ -30,16 +33,13 @@ func InitDB() {
 	}
 
 	BootSaver()
+	BootSessionSaver()
 	BootExpirationHandler()
 	BootLazyIndexRebuilder()
 
-	if engine.IsEngineMongoDB() {
-		fmt.Printf("%sStorage engine: %s%s\n", globals.Gold, "MongoDB", globals.Reset)
-		InitMongoDBConnection()
-	} else if engine.IsEngineInternal() {
-		fmt.Printf("%sStorage engine: %s%s\n", globals.Gold, "Internal", globals.Reset)
-	} else {
-		panic("Unsupported engine: " + cfg.Engine.Name)
+	fmt.Printf("%sStorage engine: %s%s\n", globals.Gold, engine.Current().Label(), globals.Reset)
+	if err := engine.Open(); err != nil {
+		panic(err)
 	}
 
 	BootACL()
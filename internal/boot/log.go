@@ -3,12 +3,19 @@ package boot
 import (
 	"time"
 
+	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/log"
 )
 
 func BootLogger() {
-	d := time.Duration(globals.GetConfig().Log.FlushIntervalSeconds) * time.Second
+	cfg := globals.GetConfig().Log
+
+	if err := ConfigureLogger(cfg); err != nil {
+		log.Fatal("invalid log configuration", err)
+	}
+
+	d := time.Duration(cfg.FlushIntervalSeconds) * time.Second
 	if d <= 0 {
 		return
 	}
@@ -16,6 +23,48 @@ func BootLogger() {
 	go WriteLogsPeriodically(d)
 }
 
+func ConfigureLogger(cfg configuration.LogConfig) error {
+	level, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+
+	encoder, err := log.NewEncoder(cfg.Format)
+	if err != nil {
+		return err
+	}
+
+	sinks := []log.Sink{log.NewStdoutSink(encoder, cfg.FlushIntervalSeconds > 0)}
+
+	if cfg.File.Enabled {
+		format := cfg.File.Format
+		if format == "" {
+			format = "json"
+		}
+
+		fileEncoder, err := log.NewEncoder(format)
+		if err != nil {
+			return err
+		}
+
+		if console, ok := fileEncoder.(log.ConsoleEncoder); ok {
+			console.Color = false
+			fileEncoder = console
+		}
+
+		file, err := log.NewRotatingFileSink(cfg.File.Path, fileEncoder, int64(cfg.File.MaxSizeMB)*1024*1024, cfg.File.MaxBackups)
+		if err != nil {
+			return err
+		}
+
+		sinks = append(sinks, file)
+	}
+
+	log.Configure(level, sinks...)
+
+	return nil
+}
+
 func WriteLogsPeriodically(interval time.Duration) {
 	for {
 		log.WriteLogs()
@@ -0,0 +1,7 @@
+package boot
+
+import "github.com/taymour/elysiandb/internal/metrics"
+
+func BootMetrics() {
+	metrics.SetEnabled(true)
+}
@@ -0,0 +1,13 @@
+package boot
+
+import (
+	"github.com/taymour/elysiandb/internal/replication"
+)
+
+func BootReplication() {
+	replication.Start()
+}
+
+func ShutdownReplication() {
+	replication.Stop()
+}
@@ -8,7 +8,12 @@ import (
 )
 
 func BootSaver() {
-	d := time.Duration(globals.GetConfig().Store.FlushIntervalSeconds) * time.Second
+	cfg := globals.GetConfig().Store
+	if cfg.IsEphemeral() {
+		return
+	}
+
+	d := time.Duration(cfg.FlushIntervalSeconds) * time.Second
 	if d <= 0 {
 		return
 	}
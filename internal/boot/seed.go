@@ -0,0 +1,13 @@
+package boot
+
+import (
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/seed"
+)
+
+func BootSeed() {
+	if err := seed.Load(globals.GetConfig().Seed.Files); err != nil {
+		log.Fatal("Error loading seed fixtures", err)
+	}
+}
@@ -22,13 +22,16 @@ func StartHTTP() {
 	routing.RegisterRoutes(r)
 
 	srv := &fasthttp.Server{
-		Handler:               security.CORS(r.Handler),
+		Handler:               security.CORS(routing.BufferBody(r.Handler)),
 		Name:                  "ElysianDB",
 		NoDefaultServerHeader: true,
 		ReduceMemoryUsage:     true,
 
 		Concurrency: 100_000,
 
+		StreamRequestBody:  true,
+		MaxRequestBodySize: routing.MaxBufferedBodySize,
+
 		ReadBufferSize:  64 << 10,
 		WriteBufferSize: 64 << 10,
 
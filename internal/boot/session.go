@@ -0,0 +1,32 @@
+package boot
+
+import (
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+func BootSessionSaver() {
+	if !security.UserAuthenticationIsEnabled() || globals.GetConfig().Store.IsEphemeral() {
+		return
+	}
+
+	d := time.Duration(globals.GetConfig().Security.Session.PersistIntervalSeconds) * time.Second
+	if d <= 0 {
+		d = security.DefaultSessionPersistIntervalSeconds * time.Second
+	}
+
+	go saveSessionsPeriodically(d)
+}
+
+func saveSessionsPeriodically(interval time.Duration) {
+	for {
+		if err := security.FlushSessions(); err != nil {
+			log.Error("Error persisting sessions: ", err)
+		}
+
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,25 @@
+package boot
+
+import (
+	"context"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/tracing"
+)
+
+func BootTracing() {
+	if err := tracing.Init(globals.GetConfig().Tracing); err != nil {
+		log.Fatal("unable to start tracing", err)
+	}
+}
+
+func ShutdownTracing() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.Error("Error flushing traces: ", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/metrics"
 )
 
 type cacheItem struct {
@@ -16,9 +17,10 @@ type cacheItem struct {
 }
 
 type cacheEntity struct {
-	mu   sync.RWMutex
-	data map[[32]byte]cacheItem
-	ids  map[string]cacheItem
+	mu    sync.RWMutex
+	data  map[[32]byte]cacheItem
+	ids   map[string]cacheItem
+	label string
 }
 
 type cacheStore struct {
@@ -29,6 +31,16 @@ type cacheStore struct {
 
 var CacheStore *cacheStore
 
+var EntityExistsFunc func(entity string) bool
+
+const unknownEntityLabel = "unknown"
+
+var (
+	cacheHits   = metrics.NewCounterVec("elysiandb_cache_hits_total", "API cache hits per entity.", "entity")
+	cacheMisses = metrics.NewCounterVec("elysiandb_cache_misses_total", "API cache misses per entity.", "entity")
+	_           = metrics.NewGaugeVecFunc("elysiandb_cache_hit_ratio", "API cache hit ratio per entity.", cacheHitRatios, "entity")
+)
+
 func InitCache(ttl time.Duration) {
 	CacheStore = &cacheStore{
 		entities: make(map[string]*cacheEntity),
@@ -50,6 +62,7 @@ func (s *cacheStore) Get(entity string, hash []byte) []byte {
 	s.mu.RUnlock()
 
 	if !exists {
+		cacheMisses.Inc(metricLabel(entity))
 		return nil
 	}
 
@@ -58,9 +71,12 @@ func (s *cacheStore) Get(entity string, hash []byte) []byte {
 	e.mu.RUnlock()
 
 	if !ok {
+		cacheMisses.Inc(e.label)
 		return nil
 	}
 
+	cacheHits.Inc(e.label)
+
 	return it.v
 }
 
@@ -71,15 +87,7 @@ func (s *cacheStore) Set(entity string, hash, value []byte) {
 
 	var key [32]byte
 	copy(key[:], hash)
-	s.mu.Lock()
-	e, exists := s.entities[entity]
-
-	if !exists {
-		e = &cacheEntity{data: make(map[[32]byte]cacheItem), ids: make(map[string]cacheItem)}
-		s.entities[entity] = e
-	}
-
-	s.mu.Unlock()
+	e := s.entity(entity)
 	exp := time.Now().Add(s.ttl).UnixNano()
 	e.mu.Lock()
 	e.data[key] = cacheItem{v: value, exp: exp}
@@ -92,6 +100,7 @@ func (s *cacheStore) GetById(entity, id string) []byte {
 	s.mu.RUnlock()
 
 	if !exists {
+		cacheMisses.Inc(metricLabel(entity))
 		return nil
 	}
 
@@ -100,33 +109,69 @@ func (s *cacheStore) GetById(entity, id string) []byte {
 	e.mu.RUnlock()
 
 	if !ok {
+		cacheMisses.Inc(e.label)
 		return nil
 	}
 
+	cacheHits.Inc(e.label)
+
 	return it.v
 }
 
 func (s *cacheStore) SetById(entity, id string, value []byte) {
-	s.mu.Lock()
-	e, exists := s.entities[entity]
-	if !exists {
-		e = &cacheEntity{data: make(map[[32]byte]cacheItem), ids: make(map[string]cacheItem)}
-		s.entities[entity] = e
-	}
-
-	s.mu.Unlock()
+	e := s.entity(entity)
 	exp := time.Now().Add(s.ttl).UnixNano()
 	e.mu.Lock()
 	e.ids[id] = cacheItem{v: value, exp: exp}
 	e.mu.Unlock()
 }
 
+func (s *cacheStore) entity(entity string) *cacheEntity {
+	s.mu.RLock()
+	e, exists := s.entities[entity]
+	s.mu.RUnlock()
+
+	if exists {
+		return e
+	}
+
+	created := &cacheEntity{
+		data:  make(map[[32]byte]cacheItem),
+		ids:   make(map[string]cacheItem),
+		label: metricLabel(entity),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, exists = s.entities[entity]; !exists {
+		e = created
+		s.entities[entity] = e
+	}
+
+	return e
+}
+
+func metricLabel(entity string) string {
+	if EntityExistsFunc == nil || EntityExistsFunc(entity) {
+		return entity
+	}
+
+	return unknownEntityLabel
+}
+
 func (s *cacheStore) Purge(entity string) {
 	s.mu.Lock()
 	delete(s.entities, entity)
 	s.mu.Unlock()
 }
 
+func (s *cacheStore) PurgeAll() {
+	s.mu.Lock()
+	s.entities = make(map[string]*cacheEntity)
+	s.mu.Unlock()
+}
+
 func HashQuery(
 	entity string,
 	limit int,
@@ -229,3 +274,34 @@ func (s *cacheStore) CleanExpired() {
 		e.mu.Unlock()
 	}
 }
+
+func cacheHitRatios() []metrics.Sample {
+	totals := map[string][2]uint64{}
+	cacheHits.Each(func(labels []string, v uint64) {
+		t := totals[labels[0]]
+		t[0] = v
+		totals[labels[0]] = t
+	})
+	cacheMisses.Each(func(labels []string, v uint64) {
+		t := totals[labels[0]]
+		t[1] = v
+		totals[labels[0]] = t
+	})
+
+	entities := make([]string, 0, len(totals))
+	for entity := range totals {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	samples := make([]metrics.Sample, 0, len(entities))
+	for _, entity := range entities {
+		t := totals[entity]
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{entity},
+			Value:       float64(t[0]) / float64(t[0]+t[1]),
+		})
+	}
+
+	return samples
+}
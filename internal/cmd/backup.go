@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/backup"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/recovery"
+)
+
+func Backup() {
+	path := flag.Arg(1)
+	if path == "" {
+		path = backup.FileName(time.Now())
+	}
+
+	manifest, err := backup.WriteFile(path)
+	if err != nil {
+		Printf("%sBackup failed: %v%s\n", globals.Red, err, globals.Reset)
+		os.Exit(1)
+	}
+
+	Printf("%sBackup written%s  %s\n", globals.Gold, globals.Reset, path)
+	printManifest(manifest)
+}
+
+func Restore() {
+	path := flag.Arg(1)
+	if path == "" || strings.HasPrefix(path, "--") {
+		Printf("%s\n", "Usage: elysiandb restore <archive> [--until <time>|latest] [--until-seq <n>] [--wal <folder>] --force")
+		os.Exit(1)
+	}
+
+	target, pointInTime, err := restoreTarget()
+	if err != nil {
+		Printf("%s%v%s\n", globals.Red, err, globals.Reset)
+		os.Exit(1)
+	}
+
+	archive, err := backup.ReadFile(path)
+	if err != nil {
+		Printf("%sCannot restore %s: %v%s\n", globals.Red, path, err, globals.Reset)
+		os.Exit(1)
+	}
+
+	Printf("%sArchive verified%s  %s\n", globals.Gold, globals.Reset, path)
+	printManifest(&archive.Manifest)
+
+	Printf("%s\n", "Restoring replaces all data in this node's storage. This action cannot be undone.")
+	Printf("%s\n", "Make sure ElysianDB is down before proceeding.")
+	Printf("%s\n", "(yes/no)")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	if strings.TrimSpace(response) != "yes" {
+		Printf("%s\n", "Restore aborted.")
+		return
+	}
+
+	if !slices.Contains(os.Args, "--force") {
+		Printf("%s\n", "Restore requires --force flag to proceed.")
+		os.Exit(1)
+	}
+
+	if !pointInTime {
+		if err := backup.Restore(archive); err != nil {
+			Printf("%sRestore failed: %v%s\n", globals.Red, err, globals.Reset)
+			os.Exit(1)
+		}
+
+		Printf("%sRestore completed%s  %s\n", globals.Gold, globals.Reset, globals.GetConfig().Store.Folder)
+		return
+	}
+
+	walFolder := recovery.ArchiveFolder()
+	if folder, ok := optionValue("--wal"); ok {
+		walFolder = folder
+	}
+
+	result, err := backup.RestoreToPoint(archive, walFolder, target)
+	for _, gap := range result.Gaps {
+		Printf("%sWarning: no archived wal segment holds sequences %d to %d%s\n", globals.Gold, gap.From, gap.To, globals.Reset)
+	}
+
+	if err != nil {
+		Printf("%sRestore failed: %v%s\n", globals.Red, err, globals.Reset)
+		os.Exit(1)
+	}
+
+	Printf("%sRestore completed%s  %s\n", globals.Gold, globals.Reset, globals.GetConfig().Store.Folder)
+	Printf("  replayed %d wal records", result.Records)
+	if result.Records > 0 {
+		Printf(", last sequence %d at %s", result.LastSeq, result.LastTime.UTC().Format(time.RFC3339Nano))
+	}
+	Printf("\n")
+}
+
+func restoreTarget() (recovery.Target, bool, error) {
+	var target recovery.Target
+
+	until, hasUntil := optionValue("--until")
+	untilSeq, hasUntilSeq := optionValue("--until-seq")
+
+	if hasUntil && hasUntilSeq {
+		return target, false, fmt.Errorf("use either --until or --until-seq")
+	}
+
+	if hasUntilSeq {
+		seq, err := strconv.ParseUint(untilSeq, 10, 64)
+		if err != nil || seq == 0 {
+			return target, false, fmt.Errorf("invalid --until-seq value %q", untilSeq)
+		}
+
+		target.Seq = seq
+		return target, true, nil
+	}
+
+	if !hasUntil {
+		return target, false, nil
+	}
+
+	if until == "latest" {
+		return target, true, nil
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, until)
+	if err != nil {
+		return target, false, fmt.Errorf("invalid --until value %q, expected an RFC 3339 time or latest", until)
+	}
+
+	target.Time = at
+	return target, true, nil
+}
+
+func optionValue(name string) (string, bool) {
+	for i, arg := range os.Args {
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, true
+		}
+
+		if arg == name && i+1 < len(os.Args) {
+			return os.Args[i+1], true
+		}
+	}
+
+	return "", false
+}
+
+func printManifest(m *backup.Manifest) {
+	Printf("  engine   %s\n", m.Engine)
+	Printf("  created  %s (ElysianDB %s)\n", m.CreatedAt.Format(time.RFC3339), m.Version)
+	if m.WalArchive {
+		Printf("  wal      sequence %d\n", m.WalEnd)
+	}
+
+	for _, f := range m.Files {
+		Printf("  %-28s %10d bytes  sha256:%s\n", f.Name, f.Size, f.SHA256)
+	}
+}
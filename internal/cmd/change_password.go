@@ -51,5 +51,7 @@ func ChangePassword() {
 		return
 	}
 
+	_ = security.FlushSessions()
+
 	Printf("%sPassword updated successfully for user '%s'.%s\n", globals.Gold, username, globals.Reset)
 }
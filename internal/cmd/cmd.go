@@ -19,6 +19,11 @@ const (
 	HelpCommand           = "help"
 	ChangePasswordCommand = "change-password"
 	ResetCommand          = "reset"
+	PromoteCommand        = "promote"
+	BackupCommand         = "backup"
+	RestoreCommand        = "restore"
+	ExportCommand         = "export"
+	ImportCommand         = "import"
 )
 
 func GetAvailableCommands() map[string]string {
@@ -28,6 +33,11 @@ func GetAvailableCommands() map[string]string {
 		DeleteUserCommand:     "Delete an existing user (needs security.authentication.mode = basic or user)",
 		ChangePasswordCommand: "Change password for an existing user (needs security.authentication.mode = basic or user)",
 		ResetCommand:          "Reset the database by deleting all stored data and resets users (requires --force flag)",
+		BackupCommand:         "Write a consistent, compressed backup archive of the storage (optional output file)",
+		RestoreCommand:        "Verify a backup archive and load it, optionally replaying archived wal up to --until or --until-seq (requires --force flag)",
+		ExportCommand:         "Stream entities to an NDJSON file, optionally --entities a,b and --include schemas,hooks,acls (optional output file)",
+		ImportCommand:         "Import an NDJSON export or JSON dump with --mode replace, upsert or skip-existing and report failed records",
+		PromoteCommand:        "Promote a running replication follower to leader (optional node URL, defaults to this config's HTTP server)",
 		HelpCommand:           "List available commands",
 	}
 }
@@ -39,10 +49,19 @@ func GetHandlers() map[string]func() {
 		ServerCommand:         StartServer,
 		ChangePasswordCommand: ChangePassword,
 		ResetCommand:          ResetAll,
+		PromoteCommand:        Promote,
+		BackupCommand:         Backup,
+		RestoreCommand:        Restore,
+		ExportCommand:         Export,
+		ImportCommand:         Import,
 		HelpCommand:           PrintHelp,
 	}
 }
 
+func RequiresStorage(command string) bool {
+	return command != PromoteCommand
+}
+
 func PrintHelp() {
 	commands := GetAvailableCommands()
 
@@ -26,6 +26,7 @@ func DeleteUser() {
 	}
 
 	security.DeleteBasicUser(username)
+	_ = security.FlushSessions()
 
 	Printf("%sUser '%s' deleted successfully.%s\n", globals.Gold, username, globals.Reset)
 }
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/replication"
+)
+
+var PromoteClient = &http.Client{Timeout: 30 * time.Second}
+
+func Promote() {
+	cfg := globals.GetConfig()
+	if cfg.Replication.Role == "" || cfg.Replication.Token == "" {
+		Printf("%sReplication is disabled in the configuration.%s\n", globals.Gold, globals.Reset)
+		return
+	}
+
+	target := strings.TrimRight(flag.Arg(1), "/")
+	if target == "" {
+		target = localNodeURL()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target+"/replication/promote", nil)
+	if err != nil {
+		Printf("%sInvalid node URL %q: %v%s\n", globals.Red, target, err, globals.Reset)
+		return
+	}
+
+	req.Header.Set(replication.TokenHeader, cfg.Replication.Token)
+
+	resp, err := PromoteClient.Do(req)
+	if err != nil {
+		Printf("%sFailed to reach %s: %v%s\n", globals.Red, target, err, globals.Reset)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		Printf("%sPromotion failed (%d): %s%s\n", globals.Red, resp.StatusCode, strings.TrimSpace(string(body)), globals.Reset)
+		return
+	}
+
+	Printf("%sPromoted%s  %s is now the replication leader.\n", globals.Gold, globals.Reset, target)
+	Printf("%s\n", strings.TrimSpace(string(body)))
+}
+
+func localNodeURL() string {
+	server := globals.GetConfig().Server.HTTP
+
+	host := server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(server.Port)))
+}
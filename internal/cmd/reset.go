@@ -2,14 +2,11 @@ package cmd
 
 import (
 	"bufio"
-	"context"
 	"os"
 	"strings"
 
-	"github.com/taymour/elysiandb/internal/boot"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
-	"github.com/taymour/elysiandb/internal/mongodb"
 )
 
 func ResetAll() {
@@ -44,13 +41,16 @@ func ResetAll() {
 
 	os.RemoveAll(dir)
 
-	if engine.IsEngineMongoDB() {
-		boot.InitMongoDBConnection()
-		defer globals.MongoClient.Disconnect(context.Background())
+	if engine.CurrentCapabilities().ExternalStorage {
+		if err := engine.Open(); err != nil {
+			Printf("Failed to open storage engine: %v\n", err)
+			os.Exit(1)
+		}
+		defer engine.Close()
 
 		types := engine.ListEntityTypes()
 		for _, t := range types {
-			err := mongodb.DeleteEntityType(t)
+			err := engine.DeleteEntityType(t)
 			if err != nil {
 				Printf("Failed to delete entity type '%s': %v\n", t, err)
 			}
@@ -9,28 +9,58 @@ import (
 	"github.com/taymour/elysiandb/internal/boot"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
 	"github.com/taymour/elysiandb/internal/storage"
 )
 
 func StartServer() {
 	cfg := globals.GetConfig()
 
+	storageLocation := cfg.Store.Folder
+	if cfg.Store.IsEphemeral() {
+		storageLocation = "in memory, nothing is persisted"
+	}
+
 	Printf(
 		"%s%sStorage%s  %s%s%s\n",
 		globals.Violet,
 		globals.Bold,
 		globals.Reset,
 		globals.Gray,
-		globals.GetConfig().Store.Folder,
+		storageLocation,
 		globals.Reset,
 	)
 
-	if cfg.Stats.Enabled && engine.IsEngineInternal() {
+	if cfg.Stats.Enabled && engine.CurrentCapabilities().KeyValueStats {
 		boot.BootStats()
 	}
 
+	if cfg.Metrics.Enabled {
+		boot.BootMetrics()
+	}
+
+	if cfg.Tracing.Enabled {
+		boot.BootTracing()
+	}
+
 	boot.BootLogger()
 
+	if len(cfg.Seed.Files) > 0 {
+		boot.BootSeed()
+	}
+
+	if cfg.Replication.Role != "" {
+		boot.BootReplication()
+		Printf(
+			"%sReplication%s  %s%s%s\n",
+			globals.Gold,
+			globals.Reset,
+			globals.Bold,
+			cfg.Replication.Role,
+			globals.Reset,
+		)
+	}
+
 	Printf(
 		"%s%sReady%s   %sServing a KV datastore with %sInstant REST API%s.\n",
 		globals.Blue,
@@ -84,17 +114,26 @@ func StartServer() {
 	defer stop()
 	<-ctx.Done()
 
-	storage.WriteToDB()
-	Printf(
-		"\n%sPersisted%s  %sAll data flushed to disk.%s\n",
-		globals.Gold,
-		globals.Reset,
-		globals.Gray,
-		globals.Reset,
-	)
+	if cfg.Replication.Role != "" {
+		boot.ShutdownReplication()
+	}
+
+	if !cfg.Store.IsEphemeral() {
+		storage.WriteToDB()
+		_ = security.FlushSessions()
+		Printf(
+			"\n%sPersisted%s  %sAll data flushed to disk.%s\n",
+			globals.Gold,
+			globals.Reset,
+			globals.Gray,
+			globals.Reset,
+		)
+	}
+
+	_ = engine.Close()
 
-	if globals.MongoClient != nil {
-		_ = globals.MongoClient.Disconnect(context.Background())
+	if cfg.Tracing.Enabled {
+		boot.ShutdownTracing()
 	}
 
 	Printf(
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/taymour/elysiandb/internal/transfer"
+)
+
+func Export() {
+	path := flag.Arg(1)
+	if path == "" || strings.HasPrefix(path, "--") {
+		path = transfer.FileName(time.Now())
+	}
+
+	entities, _ := optionValue("--entities")
+	include, _ := optionValue("--include")
+
+	opts := transfer.ExportOptions{Entities: transfer.ParseList(entities)}
+	if err := opts.Include(transfer.ParseList(include)); err != nil {
+		Printf("%s%v%s\n", globals.Red, err, globals.Reset)
+		os.Exit(1)
+	}
+
+	counts, err := transfer.ExportFile(path, opts)
+	if err != nil {
+		Printf("%sExport failed: %v%s\n", globals.Red, err, globals.Reset)
+		os.Exit(1)
+	}
+
+	Printf("%sExport written%s  %s\n", globals.Gold, globals.Reset, path)
+	printCounts(counts)
+}
+
+func Import() {
+	path := flag.Arg(1)
+	if path == "" || strings.HasPrefix(path, "--") {
+		Printf("%s\n", "Usage: elysiandb import <file> [--mode replace|upsert|skip-existing]")
+		os.Exit(1)
+	}
+
+	mode, _ := optionValue("--mode")
+	if mode != "" && !transfer.IsMode(mode) {
+		Printf("%sUnknown import mode %q, expected replace, upsert or skip-existing%s\n", globals.Red, mode, globals.Reset)
+		os.Exit(1)
+	}
+
+	report, err := transfer.ImportFile(path, transfer.ImportOptions{Mode: mode, CoreRecords: true})
+	if report != nil {
+		storage.WriteToDB()
+	}
+
+	if err != nil {
+		Printf("%sImport failed: %v%s\n", globals.Red, err, globals.Reset)
+		if report == nil {
+			os.Exit(1)
+		}
+	}
+
+	Printf("%sImport finished%s  %s (%s)\n", globals.Gold, globals.Reset, path, report.Mode)
+	printCounts(report.Imported)
+
+	for entity, n := range report.Skipped {
+		Printf("  %-28s %10d skipped\n", entity, n)
+	}
+
+	if report.Schemas+report.ACLs+report.Hooks > 0 {
+		Printf("  schemas %d, acls %d, hooks %d\n", report.Schemas, report.ACLs, report.Hooks)
+	}
+
+	for _, e := range report.Errors {
+		Printf("%s  line %d: %s%s\n", globals.Red, e.Line, e.Error, globals.Reset)
+	}
+
+	if report.Failed > len(report.Errors) {
+		Printf("%s  ... and %d more failed records%s\n", globals.Red, report.Failed-len(report.Errors), globals.Reset)
+	}
+
+	if err != nil || report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printCounts(counts map[string]int) {
+	entities := make([]string, 0, len(counts))
+	for entity := range counts {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	for _, entity := range entities {
+		Printf("  %-28s %10d records\n", entity, counts[entity])
+	}
+}
@@ -3,6 +3,7 @@ package configuration
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 
 	"github.com/taymour/elysiandb/internal/log"
@@ -11,12 +12,87 @@ import (
 
 type SecurityConfig struct {
 	Authentication AuthenticationConfig `yaml:"authentication"`
+	Session        SessionConfig        `yaml:"session"`
+	RateLimit      RateLimitConfig      `yaml:"rateLimit"`
+	Quota          QuotaConfig          `yaml:"quota"`
+	Password       PasswordPolicyConfig `yaml:"password"`
+	Lockout        LockoutConfig        `yaml:"lockout"`
+	Audit          AuditConfig          `yaml:"audit"`
+}
+
+type RateConfig struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst"`
+}
+
+type RateLimitConfig struct {
+	Enabled   bool                  `yaml:"enabled"`
+	Global    RateConfig            `yaml:"global"`
+	PerClient RateConfig            `yaml:"perClient"`
+	PerIP     RateConfig            `yaml:"perIP"`
+	Groups    map[string]RateConfig `yaml:"groups"`
+	Clients   map[string]RateConfig `yaml:"clients"`
+}
+
+type QuotaLimitConfig struct {
+	DailyWrites     int64 `yaml:"dailyWrites"`
+	DailyWriteBytes int64 `yaml:"dailyWriteBytes"`
+}
+
+type QuotaConfig struct {
+	QuotaLimitConfig `yaml:",inline"`
+	Enabled          bool                        `yaml:"enabled"`
+	Users            map[string]QuotaLimitConfig `yaml:"users"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength        int  `yaml:"minLength"`
+	RequireUppercase bool `yaml:"requireUppercase"`
+	RequireLowercase bool `yaml:"requireLowercase"`
+	RequireDigit     bool `yaml:"requireDigit"`
+	RequireSymbol    bool `yaml:"requireSymbol"`
+}
+
+type LockoutConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	MaxAttempts      int  `yaml:"maxAttempts"`
+	BaseDelaySeconds int  `yaml:"baseDelaySeconds"`
+	MaxDelaySeconds  int  `yaml:"maxDelaySeconds"`
+}
+
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type SessionConfig struct {
+	TTLSeconds             int        `yaml:"ttlSeconds"`
+	MaxLifetimeSeconds     int        `yaml:"maxLifetimeSeconds"`
+	PersistIntervalSeconds int        `yaml:"persistIntervalSeconds"`
+	CSRF                   CSRFConfig `yaml:"csrf"`
+}
+
+type CSRFConfig struct {
+	Enabled bool `yaml:"enabled"`
 }
 
 type AuthenticationConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Mode    string `yaml:"mode"`
-	Token   string `yaml:"token"`
+	Enabled bool      `yaml:"enabled"`
+	Mode    string    `yaml:"mode"`
+	Token   string    `yaml:"token"`
+	JWT     JWTConfig `yaml:"jwt"`
+}
+
+type JWTConfig struct {
+	Secret             string   `yaml:"secret"`
+	JWKSFile           string   `yaml:"jwksFile"`
+	JWKSURL            string   `yaml:"jwksUrl"`
+	JWKSRefreshSeconds int      `yaml:"jwksRefreshSeconds"`
+	Issuer             string   `yaml:"issuer"`
+	Audience           string   `yaml:"audience"`
+	UsernameClaim      string   `yaml:"usernameClaim"`
+	RoleClaim          string   `yaml:"roleClaim"`
+	AdminRoles         []string `yaml:"adminRoles"`
+	LeewaySeconds      int      `yaml:"leewaySeconds"`
 }
 
 type AdminUIConfig struct {
@@ -29,18 +105,56 @@ type ServersConfig struct {
 }
 
 type LogConfig struct {
-	FlushIntervalSeconds int `yaml:"flushIntervalSeconds"`
+	FlushIntervalSeconds int           `yaml:"flushIntervalSeconds"`
+	Level                string        `yaml:"level"`
+	Format               string        `yaml:"format"`
+	AccessLog            bool          `yaml:"accessLog"`
+	File                 LogFileConfig `yaml:"file"`
+}
+
+type LogFileConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Path       string `yaml:"path"`
+	Format     string `yaml:"format"`
+	MaxSizeMB  int    `yaml:"maxSizeMB"`
+	MaxBackups int    `yaml:"maxBackups"`
 }
 
 type ServerConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Host    string `yaml:"host"`
-	Port    int    `yaml:"port"`
+	Enabled bool       `yaml:"enabled"`
+	Host    string     `yaml:"host"`
+	Port    int        `yaml:"port"`
+	CORS    CORSConfig `yaml:"cors"`
+}
+
+type CORSPolicyConfig struct {
+	AllowedOrigins   []string `yaml:"allowedOrigins"`
+	AllowedMethods   []string `yaml:"allowedMethods"`
+	AllowedHeaders   []string `yaml:"allowedHeaders"`
+	ExposedHeaders   []string `yaml:"exposedHeaders"`
+	AllowCredentials bool     `yaml:"allowCredentials"`
+	MaxAgeSeconds    int      `yaml:"maxAgeSeconds"`
+}
+
+type CORSConfig struct {
+	CORSPolicyConfig `yaml:",inline"`
+	Routes           []CORSRouteConfig `yaml:"routes"`
+}
+
+type CORSRouteConfig struct {
+	Path             string `yaml:"path"`
+	CORSPolicyConfig `yaml:",inline"`
 }
 
 type CrashRecoveryConfig struct {
-	Enabled  bool  `yaml:"enabled"`
-	MaxLogMB int64 `yaml:"maxLogMB"`
+	Enabled  bool             `yaml:"enabled"`
+	MaxLogMB int64            `yaml:"maxLogMB"`
+	Archive  WalArchiveConfig `yaml:"archive"`
+}
+
+type WalArchiveConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Folder  string `yaml:"folder"`
 }
 
 type StoreConfig struct {
@@ -48,28 +162,61 @@ type StoreConfig struct {
 	Shards               int                 `yaml:"shards"`
 	FlushIntervalSeconds int                 `yaml:"flushIntervalSeconds"`
 	CrashRecovery        CrashRecoveryConfig `yaml:"crashRecovery"`
+	MaxMemoryMB          int                 `yaml:"maxMemoryMB"`
+	EvictionPolicy       string              `yaml:"evictionPolicy"`
+	Persistence          string              `yaml:"persistence"`
+}
+
+func (c StoreConfig) IsEphemeral() bool {
+	return c.Persistence == "none"
+}
+
+type SeedConfig struct {
+	Files []string `yaml:"files"`
 }
 
 type StatsConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type TracingConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	ServiceName string            `yaml:"serviceName"`
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	SampleRatio *float64          `yaml:"sampleRatio"`
+}
+
 type ApiSchemaConfig struct {
 	Enabled bool `yaml:"enabled"`
 	Strict  bool `yaml:"strict"`
 }
 
 type ApiConfig struct {
-	Index  ApiIndexConfig  `yaml:"index"`
-	Cache  ApiCacheConfig  `yaml:"cache"`
-	Schema ApiSchemaConfig `yaml:"schema"`
-	Hooks  HooksConfig     `yaml:"hooks"`
+	Index   ApiIndexConfig   `yaml:"index"`
+	Cache   ApiCacheConfig   `yaml:"cache"`
+	Schema  ApiSchemaConfig  `yaml:"schema"`
+	Hooks   HooksConfig      `yaml:"hooks"`
+	OpenAPI ApiOpenAPIConfig `yaml:"openapi"`
+	GraphQL ApiGraphQLConfig `yaml:"graphql"`
 }
 
 type HooksConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+type ApiOpenAPIConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type ApiGraphQLConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
 type ApiIndexConfig struct {
 	Workers int `yaml:"workers"`
 }
@@ -79,20 +226,32 @@ type ApiCacheConfig struct {
 	CleanupIntervalSeconds int  `yaml:"cleanupIntervalSeconds"`
 }
 
+type ReplicationConfig struct {
+	Role        string `yaml:"role"`
+	Leader      string `yaml:"leader"`
+	Token       string `yaml:"token"`
+	BacklogSize int    `yaml:"backlogSize"`
+}
+
 type EngineConfig struct {
-	Name string `yaml:"name"`
-	URI  string `yaml:"uri"`
+	Name        string              `yaml:"name"`
+	URI         string              `yaml:"uri"`
+	SortIndexes map[string][]string `yaml:"sortIndexes"`
 }
 
 type Config struct {
-	Store    StoreConfig    `yaml:"store"`
-	Server   ServersConfig  `yaml:"server"`
-	Log      LogConfig      `yaml:"log"`
-	Security SecurityConfig `yaml:"security"`
-	Stats    StatsConfig    `yaml:"stats"`
-	Api      ApiConfig      `yaml:"api"`
-	AdminUI  AdminUIConfig  `yaml:"adminui"`
-	Engine   EngineConfig   `yaml:"engine"`
+	Store       StoreConfig       `yaml:"store"`
+	Server      ServersConfig     `yaml:"server"`
+	Log         LogConfig         `yaml:"log"`
+	Security    SecurityConfig    `yaml:"security"`
+	Stats       StatsConfig       `yaml:"stats"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Api         ApiConfig         `yaml:"api"`
+	AdminUI     AdminUIConfig     `yaml:"adminui"`
+	Engine      EngineConfig      `yaml:"engine"`
+	Replication ReplicationConfig `yaml:"replication"`
+	Seed        SeedConfig        `yaml:"seed"`
 }
 
 func (c *Config) ToJson() string {
@@ -126,5 +285,197 @@ func LoadConfig(path string) (*Config, error) {
 		}
 	}
 
+	if cfg.Security.Authentication.Enabled && cfg.Security.Authentication.Mode == "jwt" {
+		jwt := cfg.Security.Authentication.JWT
+		if jwt.Secret == "" && jwt.JWKSFile == "" && jwt.JWKSURL == "" {
+			return nil, fmt.Errorf("jwt authentication is enabled but no secret, jwksFile or jwksUrl is provided in the configuration")
+		}
+	}
+
+	if err := validateCORS(cfg.Server.HTTP.CORS); err != nil {
+		return nil, err
+	}
+
+	if err := validateLog(cfg.Log); err != nil {
+		return nil, err
+	}
+
+	if err := validateTracing(cfg.Tracing); err != nil {
+		return nil, err
+	}
+
+	if err := validateReplication(cfg.Replication, cfg.Engine); err != nil {
+		return nil, err
+	}
+
+	if err := validateWalArchive(cfg.Store); err != nil {
+		return nil, err
+	}
+
+	if err := validateMemoryLimit(cfg.Store); err != nil {
+		return nil, err
+	}
+
+	if err := validatePersistence(cfg.Store, cfg.Engine); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Seed.Files) > 0 && cfg.Replication.Role == "follower" {
+		return nil, fmt.Errorf("seed fixtures cannot be loaded on a replication follower")
+	}
+
+	if cfg.Store.IsEphemeral() {
+		cfg.Store.CrashRecovery = CrashRecoveryConfig{}
+		cfg.Log.File.Enabled = false
+	}
+
 	return &cfg, nil
 }
+
+func validateWalArchive(cfg StoreConfig) error {
+	if !cfg.CrashRecovery.Archive.Enabled {
+		return nil
+	}
+
+	if !cfg.CrashRecovery.Enabled {
+		return fmt.Errorf("wal archiving requires store.crashRecovery.enabled")
+	}
+
+	if cfg.CrashRecovery.Archive.Folder == "" {
+		return fmt.Errorf("wal archiving is enabled but no folder is provided in the configuration")
+	}
+
+	return nil
+}
+
+func validatePersistence(cfg StoreConfig, engine EngineConfig) error {
+	switch cfg.Persistence {
+	case "", "disk":
+		return nil
+	case "none":
+	default:
+		return fmt.Errorf("store persistence must be \"disk\" or \"none\", got %q", cfg.Persistence)
+	}
+
+	if engine.Name != "" && engine.Name != "internal" {
+		return fmt.Errorf("store persistence none is only supported by the internal engine")
+	}
+
+	if cfg.CrashRecovery.Archive.Enabled {
+		return fmt.Errorf("wal archiving cannot be combined with store persistence none")
+	}
+
+	return nil
+}
+
+func validateMemoryLimit(cfg StoreConfig) error {
+	if cfg.MaxMemoryMB < 0 {
+		return fmt.Errorf("store maxMemoryMB cannot be negative")
+	}
+
+	switch cfg.EvictionPolicy {
+	case "", "noeviction", "allkeys-lru", "allkeys-lfu", "volatile-ttl":
+		return nil
+	default:
+		return fmt.Errorf("store evictionPolicy must be \"noeviction\", \"allkeys-lru\", \"allkeys-lfu\" or \"volatile-ttl\", got %q", cfg.EvictionPolicy)
+	}
+}
+
+func validateLog(cfg LogConfig) error {
+	if _, err := log.ParseLevel(cfg.Level); err != nil {
+		return err
+	}
+
+	if _, err := log.NewEncoder(cfg.Format); err != nil {
+		return err
+	}
+
+	if !cfg.File.Enabled {
+		return nil
+	}
+
+	if cfg.File.Path == "" {
+		return fmt.Errorf("log file is enabled but no path is provided in the configuration")
+	}
+
+	if _, err := log.NewEncoder(cfg.File.Format); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateCORS(cfg CORSConfig) error {
+	policies := []CORSPolicyConfig{cfg.CORSPolicyConfig}
+	for _, route := range cfg.Routes {
+		if route.Path == "" {
+			return fmt.Errorf("cors route override requires a path")
+		}
+
+		policies = append(policies, route.CORSPolicyConfig)
+	}
+
+	for _, policy := range policies {
+		if !policy.AllowCredentials {
+			continue
+		}
+
+		for _, origin := range policy.AllowedOrigins {
+			if origin == "*" {
+				return fmt.Errorf("cors allowedOrigins '*' cannot be combined with allowCredentials")
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateTracing(cfg TracingConfig) error {
+	if cfg.SampleRatio != nil && (*cfg.SampleRatio < 0 || *cfg.SampleRatio > 1) {
+		return fmt.Errorf("tracing sampleRatio must be between 0 and 1")
+	}
+
+	if cfg.Endpoint == "" {
+		return nil
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("tracing endpoint must be an http(s) URL, got %q", cfg.Endpoint)
+	}
+
+	return nil
+}
+
+func validateReplication(cfg ReplicationConfig, engine EngineConfig) error {
+	switch cfg.Role {
+	case "":
+		return nil
+	case "leader", "follower":
+	default:
+		return fmt.Errorf("replication role must be \"leader\" or \"follower\", got %q", cfg.Role)
+	}
+
+	if engine.Name != "" && engine.Name != "internal" {
+		return fmt.Errorf("replication is only supported by the internal engine")
+	}
+
+	if cfg.Token == "" {
+		return fmt.Errorf("replication is enabled but no token is provided in the configuration")
+	}
+
+	if cfg.BacklogSize < 0 {
+		return fmt.Errorf("replication backlogSize cannot be negative")
+	}
+
+	if cfg.Role != "follower" {
+		return nil
+	}
+
+	u, err := url.Parse(cfg.Leader)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("replication leader must be an http(s) URL, got %q", cfg.Leader)
+	}
+
+	return nil
+}
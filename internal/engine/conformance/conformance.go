@@ -0,0 +1,515 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/query"
+)
+
+type Setup func(t *testing.T) engine.Engine
+
+type testCase struct {
+	name string
+	run  func(t *testing.T, e engine.Engine)
+}
+
+var cases = []testCase{
+	{"Identity", testIdentity},
+	{"WriteAndReadById", testWriteAndReadById},
+	{"WriteGeneratesId", testWriteGeneratesId},
+	{"WriteList", testWriteList},
+	{"ReadEntitiesByIds", testReadEntitiesByIds},
+	{"UpdateEntityById", testUpdateEntityById},
+	{"UpdateListOfEntities", testUpdateListOfEntities},
+	{"DeleteEntityById", testDeleteEntityById},
+	{"DeleteAllEntities", testDeleteAllEntities},
+	{"DeleteAll", testDeleteAll},
+	{"ListPagination", testListPagination},
+	{"ListSort", testListSort},
+	{"ListFilters", testListFilters},
+	{"ListFilterNode", testListFilterNode},
+	{"ExecuteQuery", testExecuteQuery},
+	{"Includes", testIncludes},
+	{"NestedEntityCreation", testNestedEntityCreation},
+	{"EntityTypes", testEntityTypes},
+	{"ManualSchema", testManualSchema},
+	{"DumpAndImport", testDumpAndImport},
+	{"FilterHelpers", testFilterHelpers},
+	{"Transaction", testTransaction},
+}
+
+func Run(t *testing.T, setup Setup) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, setup(t))
+		})
+	}
+}
+
+func seedBooks(t *testing.T, e engine.Engine) {
+	t.Helper()
+
+	books := []map[string]any{
+		{"id": "b1", "title": "Dune", "pages": float64(412), "genre": "scifi"},
+		{"id": "b2", "title": "Emma", "pages": float64(474), "genre": "classic"},
+		{"id": "b3", "title": "Hyperion", "pages": float64(482), "genre": "scifi"},
+		{"id": "b4", "title": "Ulysses", "pages": float64(730), "genre": "classic"},
+	}
+
+	for _, errs := range e.WriteListOfEntities("book", books) {
+		if len(errs) > 0 {
+			t.Fatalf("seed: %v", errs)
+		}
+	}
+}
+
+func ids(items []map[string]any) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		id, _ := item["id"].(string)
+		out = append(out, id)
+	}
+
+	return out
+}
+
+func sortedIds(items []map[string]any) []string {
+	out := ids(items)
+	slices.Sort(out)
+
+	return out
+}
+
+func number(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	default:
+		return 0
+	}
+}
+
+func expectIds(t *testing.T, label string, got []string, want ...string) {
+	t.Helper()
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s: got %v, want %v", label, got, want)
+	}
+}
+
+func list(e engine.Engine, entity string, limit, offset int, sortField string, asc bool, filters map[string]map[string]string, includes string, node query.FilterNode) []map[string]any {
+	return e.ListEntities(context.Background(), entity, limit, offset, sortField, asc, filters, "", includes, node)
+}
+
+func testIdentity(t *testing.T, e engine.Engine) {
+	got, ok := engine.Get(e.Name())
+	if e.Name() == "" || !ok || got.Name() != e.Name() {
+		t.Fatalf("engine %q is not registered under its name", e.Name())
+	}
+
+	if e.Label() == "" {
+		t.Fatal("engine label must not be empty")
+	}
+}
+
+func testWriteAndReadById(t *testing.T, e engine.Engine) {
+	errs := e.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune", "pages": float64(412), "tags": []any{"a", "b"}})
+	if len(errs) > 0 {
+		t.Fatalf("write: %v", errs)
+	}
+
+	got := e.ReadEntityById("book", "b1")
+	if got == nil {
+		t.Fatal("entity not found after write")
+	}
+
+	if got["id"] != "b1" || got["title"] != "Dune" || number(got["pages"]) != 412 {
+		t.Fatalf("unexpected entity %v", got)
+	}
+
+	if tags, ok := got["tags"].([]any); !ok || len(tags) != 2 || tags[0] != "a" {
+		t.Fatalf("unexpected tags %#v", got["tags"])
+	}
+
+	if !e.EntityExists("book", "b1") {
+		t.Fatal("EntityExists should report a written entity")
+	}
+
+	if e.ReadEntityById("book", "missing") != nil || e.EntityExists("book", "missing") {
+		t.Fatal("missing entity should not be found")
+	}
+}
+
+func testWriteGeneratesId(t *testing.T, e engine.Engine) {
+	data := map[string]any{"title": "Untitled"}
+	if errs := e.WriteEntity("book", data); len(errs) > 0 {
+		t.Fatalf("write: %v", errs)
+	}
+
+	id, _ := data["id"].(string)
+	if id == "" {
+		t.Fatalf("expected a generated id, got %v", data["id"])
+	}
+
+	if got := e.ReadEntityById("book", id); got == nil || got["title"] != "Untitled" {
+		t.Fatalf("entity with generated id not readable: %v", got)
+	}
+}
+
+func testWriteList(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	expectIds(t, "list", sortedIds(list(e, "book", 0, 0, "", true, nil, "", query.FilterNode{})), "b1", "b2", "b3", "b4")
+}
+
+func testReadEntitiesByIds(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	got := e.ReadEntitiesByIds("book", []string{"b1", "b3", "missing"})
+	if len(got) != 2 || got["b1"]["title"] != "Dune" || got["b3"]["title"] != "Hyperion" {
+		t.Fatalf("unexpected batch read %v", got)
+	}
+}
+
+func testUpdateEntityById(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	updated := e.UpdateEntityById("book", "b1", map[string]any{"title": "Dune Messiah"})
+	if updated == nil || updated["title"] != "Dune Messiah" || number(updated["pages"]) != 412 {
+		t.Fatalf("unexpected update result %v", updated)
+	}
+
+	got := e.ReadEntityById("book", "b1")
+	if got["title"] != "Dune Messiah" || got["genre"] != "scifi" {
+		t.Fatalf("update not persisted or not merged: %v", got)
+	}
+
+	if e.UpdateEntityById("book", "missing", map[string]any{"title": "x"}) != nil {
+		t.Fatal("updating a missing entity should return nil")
+	}
+
+	if e.EntityExists("book", "missing") {
+		t.Fatal("updating a missing entity should not create it")
+	}
+}
+
+func testUpdateListOfEntities(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	results := e.UpdateListOfEntities("book", []map[string]any{
+		{"id": "b1", "pages": float64(1)},
+		{"id": "missing", "pages": float64(2)},
+		{"pages": float64(3)},
+	})
+
+	expectIds(t, "updated", ids(results), "b1")
+
+	if number(e.ReadEntityById("book", "b1")["pages"]) != 1 {
+		t.Fatal("batch update not persisted")
+	}
+}
+
+func testDeleteEntityById(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	e.DeleteEntityById("book", "b2")
+
+	if e.EntityExists("book", "b2") {
+		t.Fatal("entity still exists after delete")
+	}
+
+	expectIds(t, "remaining", sortedIds(list(e, "book", 0, 0, "", true, nil, "", query.FilterNode{})), "b1", "b3", "b4")
+}
+
+func testDeleteAllEntities(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+	e.WriteEntity("author", map[string]any{"id": "a1", "name": "Frank"})
+
+	e.DeleteAllEntities("book")
+
+	if got := list(e, "book", 0, 0, "", true, nil, "", query.FilterNode{}); len(got) != 0 {
+		t.Fatalf("books remain after DeleteAllEntities: %v", ids(got))
+	}
+
+	if !e.EntityExists("author", "a1") {
+		t.Fatal("DeleteAllEntities removed another entity type")
+	}
+}
+
+func testDeleteAll(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+	e.WriteEntity("author", map[string]any{"id": "a1", "name": "Frank"})
+
+	e.DeleteAll()
+
+	if e.EntityExists("book", "b1") || e.EntityExists("author", "a1") {
+		t.Fatal("entities remain after DeleteAll")
+	}
+}
+
+func testListPagination(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	page := list(e, "book", 2, 1, "pages", true, nil, "", query.FilterNode{})
+	expectIds(t, "page", ids(page), "b2", "b3")
+
+	if got := list(e, "book", 10, 10, "pages", true, nil, "", query.FilterNode{}); len(got) != 0 {
+		t.Fatalf("offset past the end should be empty, got %v", ids(got))
+	}
+}
+
+func testListSort(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	expectIds(t, "pages asc", ids(list(e, "book", 0, 0, "pages", true, nil, "", query.FilterNode{})), "b1", "b2", "b3", "b4")
+	expectIds(t, "pages desc", ids(list(e, "book", 0, 0, "pages", false, nil, "", query.FilterNode{})), "b4", "b3", "b2", "b1")
+	expectIds(t, "title desc", ids(list(e, "book", 0, 0, "title", false, nil, "", query.FilterNode{})), "b4", "b3", "b2", "b1")
+
+	e.UpdateEntityById("book", "b4", map[string]any{"pages": float64(1)})
+	expectIds(t, "pages asc after update", ids(list(e, "book", 0, 0, "pages", true, nil, "", query.FilterNode{})), "b4", "b1", "b2", "b3")
+}
+
+func testListFilters(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	cases := []struct {
+		filters map[string]map[string]string
+		want    []string
+	}{
+		{map[string]map[string]string{"genre": {"eq": "scifi"}}, []string{"b1", "b3"}},
+		{map[string]map[string]string{"genre": {"neq": "scifi"}}, []string{"b2", "b4"}},
+		{map[string]map[string]string{"title": {"eq": "*yp*"}}, []string{"b3"}},
+		{map[string]map[string]string{"pages": {"gt": "474"}}, []string{"b3", "b4"}},
+		{map[string]map[string]string{"pages": {"lte": "474"}}, []string{"b1", "b2"}},
+		{map[string]map[string]string{"genre": {"eq": "classic"}, "pages": {"lt": "500"}}, []string{"b2"}},
+	}
+
+	for _, c := range cases {
+		got := sortedIds(list(e, "book", 0, 0, "", true, c.filters, "", query.FilterNode{}))
+		expectIds(t, fmt.Sprintf("filter %v", c.filters), got, c.want...)
+	}
+
+	page := list(e, "book", 1, 1, "pages", true, map[string]map[string]string{"genre": {"eq": "scifi"}}, "", query.FilterNode{})
+	expectIds(t, "filtered page", ids(page), "b3")
+}
+
+func testListFilterNode(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	node := query.FilterNode{Or: []query.FilterNode{
+		{Leaf: map[string]map[string]string{"title": {"eq": "Dune"}}},
+		{Leaf: map[string]map[string]string{"pages": {"gte": "730"}}},
+	}}
+
+	expectIds(t, "or node", sortedIds(list(e, "book", 0, 0, "", true, nil, "", node)), "b1", "b4")
+}
+
+func testExecuteQuery(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	got, err := e.ExecuteQuery(query.Query{
+		Entity: "book",
+		Limit:  1,
+		Offset: 1,
+		Filter: query.FilterNode{Leaf: map[string]map[string]string{"genre": {"eq": "classic"}}},
+		Sorts:  map[string]string{"pages": "desc"},
+	})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	expectIds(t, "query", ids(got), "b2")
+}
+
+func testIncludes(t *testing.T, e engine.Engine) {
+	e.WriteEntity("author", map[string]any{"id": "a1", "name": "Ursula"})
+	e.WriteEntity("book", map[string]any{"id": "b1", "title": "Earthsea", "author": map[string]any{"@entity": "author", "id": "a1"}})
+
+	raw := e.ReadEntityById("book", "b1")
+	link, ok := raw["author"].(map[string]any)
+	if !ok || link["id"] != "a1" {
+		t.Fatalf("link not stored as a reference: %v", raw["author"])
+	}
+
+	for _, includes := range []string{"author", "all"} {
+		items := list(e, "book", 0, 0, "", true, nil, includes, query.FilterNode{})
+		if len(items) != 1 {
+			t.Fatalf("includes=%s: unexpected items %v", includes, items)
+		}
+
+		author, _ := items[0]["author"].(map[string]any)
+		if author["name"] != "Ursula" {
+			t.Fatalf("includes=%s: author not resolved: %v", includes, items[0]["author"])
+		}
+	}
+
+	resolved := e.ApplyIncludes([]map[string]any{e.ReadEntityById("book", "b1")}, "author")
+	if author, _ := resolved[0]["author"].(map[string]any); author["name"] != "Ursula" {
+		t.Fatalf("ApplyIncludes did not resolve author: %v", resolved[0]["author"])
+	}
+}
+
+func testNestedEntityCreation(t *testing.T, e engine.Engine) {
+	e.WriteEntity("book", map[string]any{"id": "b1", "author": map[string]any{"@entity": "author", "id": "a9", "name": "Frank"}})
+
+	author := e.ReadEntityById("author", "a9")
+	if author == nil || author["name"] != "Frank" {
+		t.Fatalf("nested entity not created: %v", author)
+	}
+
+	if !e.EntityTypeExists("author") {
+		t.Fatal("nested entity type not registered")
+	}
+}
+
+func testEntityTypes(t *testing.T, e engine.Engine) {
+	if err := e.CreateEntityType("shelf"); err != nil {
+		t.Fatalf("create type: %v", err)
+	}
+
+	if err := e.CreateEntityType("shelf"); err == nil {
+		t.Fatal("creating an existing type should fail")
+	}
+
+	e.WriteEntity("book", map[string]any{"id": "b1"})
+	e.WriteEntity("_elysiandb_core_probe", map[string]any{"id": "p1"})
+
+	types := e.ListEntityTypes()
+	for _, want := range []string{"shelf", "book", "_elysiandb_core_probe"} {
+		if !slices.Contains(types, want) || !e.EntityTypeExists(want) {
+			t.Fatalf("type %q missing from %v", want, types)
+		}
+	}
+
+	public := e.ListPublicEntityTypes()
+	if slices.Contains(public, "_elysiandb_core_probe") || !slices.Contains(public, "book") {
+		t.Fatalf("unexpected public types %v", public)
+	}
+
+	if err := e.DeleteEntityType("book"); err != nil {
+		t.Fatalf("delete type: %v", err)
+	}
+
+	if e.EntityTypeExists("book") || e.EntityExists("book", "b1") {
+		t.Fatal("type and its entities should be deleted")
+	}
+
+	if err := e.DeleteEntityType("book"); err == nil {
+		t.Fatal("deleting a missing type should fail")
+	}
+}
+
+func testManualSchema(t *testing.T, e engine.Engine) {
+	e.UpdateEntitySchema("book", map[string]any{
+		"title": map[string]any{"type": "string", "required": true},
+		"pages": map[string]any{"type": "number"},
+	})
+
+	s := e.GetEntitySchema("book")
+	if s == nil || s["_manual"] != true {
+		t.Fatalf("manual schema not stored: %v", s)
+	}
+
+	fields, _ := s["fields"].(map[string]any)
+	title, _ := fields["title"].(map[string]any)
+	if title["type"] != "string" || title["required"] != true {
+		t.Fatalf("unexpected schema fields %v", fields)
+	}
+
+	if e.GetEntitySchema("unknown") != nil {
+		t.Fatal("schema of an unknown entity should be nil")
+	}
+}
+
+func testDumpAndImport(t *testing.T, e engine.Engine) {
+	e.ImportAll(map[string][]map[string]any{
+		"book":   {{"id": "b1", "title": "Dune"}, {"id": "b2", "title": "Emma"}},
+		"author": {{"id": "a1", "name": "Frank"}},
+	})
+
+	if got := e.CountAllEntities(); got != 3 {
+		t.Fatalf("CountAllEntities = %d, want 3", got)
+	}
+
+	dump := e.DumpAll()
+	books, ok := dump["book"].([]map[string]any)
+	if !ok {
+		t.Fatalf("unexpected dump shape %T", dump["book"])
+	}
+
+	expectIds(t, "dumped books", sortedIds(books), "b1", "b2")
+
+	if _, ok := dump["author"]; !ok {
+		t.Fatal("author missing from dump")
+	}
+}
+
+func testFilterHelpers(t *testing.T, e engine.Engine) {
+	data := map[string]any{"id": "b1", "title": "Dune", "pages": float64(412)}
+
+	filtered := e.FilterFields(data, []string{"title"})
+	if len(filtered) != 1 || filtered["title"] != "Dune" {
+		t.Fatalf("unexpected FilterFields result %v", filtered)
+	}
+
+	if !e.MatchFilterNode(query.FilterNode{Leaf: map[string]map[string]string{"title": {"eq": "Du*"}}}, data) {
+		t.Fatal("MatchFilterNode should match")
+	}
+
+	if e.MatchFilterNode(query.FilterNode{Leaf: map[string]map[string]string{"title": {"eq": "Emma"}}}, data) {
+		t.Fatal("MatchFilterNode should not match")
+	}
+}
+
+func testTransaction(t *testing.T, e engine.Engine) {
+	seedBooks(t, e)
+
+	err := engine.RunInTransactionOn(e, func(w engine.Writer) error {
+		w.WriteEntity("book", map[string]any{"id": "b5", "title": "Solaris"})
+		w.UpdateEntityById("book", "b1", map[string]any{"pages": float64(1)})
+		w.DeleteEntityById("book", "b2")
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if !e.EntityExists("book", "b5") || e.EntityExists("book", "b2") || number(e.ReadEntityById("book", "b1")["pages"]) != 1 {
+		t.Fatal("committed operations not applied")
+	}
+
+	if !e.Capabilities().Transactions {
+		return
+	}
+
+	failure := errors.New("abort")
+	err = engine.RunInTransactionOn(e, func(w engine.Writer) error {
+		w.WriteEntity("book", map[string]any{"id": "b6", "title": "Ubik"})
+		w.DeleteEntityById("book", "b3")
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the transaction error, got %v", err)
+	}
+
+	if e.EntityExists("book", "b6") || !e.EntityExists("book", "b3") {
+		t.Fatal("rolled back operations were applied")
+	}
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+type Capabilities struct {
+	Migrations      bool
+	KeyValueStats   bool
+	ExternalSchema  bool
+	ExternalStorage bool
+	Transactions    bool
+}
+
+type Writer interface {
+	WriteEntity(entity string, data map[string]any) []schema.ValidationError
+	UpdateEntityById(entity, id string, updated map[string]any) map[string]any
+	DeleteEntityById(entity, id string)
+}
+
+type Transactional interface {
+	Transaction(fn func(w Writer) error) error
+}
+
+type Engine interface {
+	Name() string
+	Label() string
+	Capabilities() Capabilities
+	Open() error
+	Close() error
+
+	WriteEntity(entity string, data map[string]any) []schema.ValidationError
+	WriteListOfEntities(entity string, list []map[string]any) [][]schema.ValidationError
+	ReadEntityById(entity, id string) map[string]any
+	ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any
+	EntityExists(entity, id string) bool
+	UpdateEntityById(entity, id string, updated map[string]any) map[string]any
+	UpdateListOfEntities(entity string, updates []map[string]any) []map[string]any
+	DeleteEntityById(entity, id string)
+	DeleteAllEntities(entity string)
+	DeleteAll()
+
+	ListEntities(
+		ctx context.Context,
+		entity string,
+		limit int,
+		offset int,
+		sortField string,
+		sortAscending bool,
+		filters map[string]map[string]string,
+		search string,
+		includesParam string,
+		node query.FilterNode,
+	) []map[string]any
+	GetListOfIds(entity, sortField string, sortAscending bool) []string
+	ExecuteQuery(q query.Query) ([]map[string]any, error)
+	MatchFilterNode(node query.FilterNode, entity map[string]any) bool
+	ApplyFiltersToList(entities []map[string]any, filters map[string]map[string]string) []map[string]any
+	ApplyIncludes(data []map[string]any, includesParam string) []map[string]any
+	FilterFields(data map[string]any, fields []string) map[string]any
+
+	GetEntitySchema(entity string) map[string]any
+	UpdateEntitySchema(entity string, fieldsRaw map[string]any) map[string]any
+	CreateEntityType(entity string) error
+	DeleteEntityType(entity string) error
+	AddEntityType(entity string)
+	EntityTypeExists(entity string) bool
+	ListEntityTypes() []string
+	ListPublicEntityTypes() []string
+
+	DumpAll() map[string]any
+	ImportAll(data map[string][]map[string]any)
+	CountAllEntities() int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Engine{
+		EngineInternal: internalEngine{},
+		EngineMongoDB:  mongoEngine{},
+		EngineSQLite:   sqliteEngine{},
+	}
+)
+
+func Register(e Engine) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[e.Name()]; exists {
+		panic("storage engine already registered: " + e.Name())
+	}
+
+	registry[e.Name()] = e
+}
+
+func Get(name string) (Engine, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	e, ok := registry[name]
+
+	return e, ok
+}
+
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func Current() Engine {
+	e, ok := Get(globals.GetEngine())
+	if !ok {
+		ThrowErrorIfNotValidEngine()
+	}
+
+	return e
+}
+
+func Open() error {
+	e := Current()
+	if err := e.Open(); err != nil {
+		return fmt.Errorf("opening %s engine: %w", e.Name(), err)
+	}
+
+	return nil
+}
+
+func Close() error {
+	return Current().Close()
+}
+
+func CurrentCapabilities() Capabilities {
+	return Current().Capabilities()
+}
+
+func SchemaData(entity string) map[string]any {
+	if !CurrentCapabilities().ExternalSchema {
+		return nil
+	}
+
+	return GetEntitySchema(entity)
+}
+
+func RunInTransaction(fn func(w Writer) error) error {
+	return RunInTransactionOn(Current(), fn)
+}
+
+func RunInTransactionOn(e Engine, fn func(w Writer) error) error {
+	if t, ok := e.(Transactional); ok && e.Capabilities().Transactions {
+		return t.Transaction(fn)
+	}
+
+	return fn(e)
+}
+
+func IsEngineInternal() bool {
+	return globals.GetEngine() == EngineInternal
+}
+
+func IsEngineMongoDB() bool {
+	return globals.GetEngine() == EngineMongoDB
+}
+
+func ThrowErrorIfNotValidEngine() {
+	engine := globals.GetEngine()
+	panic("Invalid storage engine: " + engine + ". Supported engines: '" + strings.Join(Names(), "', '") + "'.")
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+type internalEngine struct{}
+
+func (internalEngine) Name() string {
+	return EngineInternal
+}
+
+func (internalEngine) Label() string {
+	return "Internal"
+}
+
+func (internalEngine) Capabilities() Capabilities {
+	return Capabilities{
+		Migrations:    true,
+		KeyValueStats: true,
+	}
+}
+
+func (internalEngine) Open() error {
+	return nil
+}
+
+func (internalEngine) Close() error {
+	return nil
+}
+
+func (internalEngine) WriteEntity(entity string, data map[string]any) []schema.ValidationError {
+	return api_storage.WriteEntity(entity, data)
+}
+
+func (internalEngine) WriteListOfEntities(entity string, list []map[string]any) [][]schema.ValidationError {
+	return api_storage.WriteListOfEntities(entity, list)
+}
+
+func (internalEngine) ReadEntityById(entity, id string) map[string]any {
+	return api_storage.ReadEntityById(entity, id)
+}
+
+func (internalEngine) ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	return api_storage.ReadEntitiesByIds(entity, ids)
+}
+
+func (internalEngine) EntityExists(entity, id string) bool {
+	return api_storage.EntityExists(entity, id)
+}
+
+func (internalEngine) UpdateEntityById(entity, id string, updated map[string]any) map[string]any {
+	return api_storage.UpdateEntityById(entity, id, updated)
+}
+
+func (internalEngine) UpdateListOfEntities(entity string, updates []map[string]any) []map[string]any {
+	return api_storage.UpdateListOfEntities(entity, updates)
+}
+
+func (internalEngine) DeleteEntityById(entity, id string) {
+	api_storage.DeleteEntityById(entity, id)
+}
+
+func (internalEngine) DeleteAllEntities(entity string) {
+	api_storage.DeleteAllEntities(entity)
+}
+
+func (internalEngine) DeleteAll() {
+	api_storage.DeleteAll()
+}
+
+func (internalEngine) ListEntities(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return api_storage.ListEntitiesWithFilterNodeContext(ctx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
+
+func (internalEngine) GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	return api_storage.GetListOfIds(entity, sortField, sortAscending)
+}
+
+func (internalEngine) ExecuteQuery(q query.Query) ([]map[string]any, error) {
+	return api_storage.ExecuteQuery(q)
+}
+
+func (internalEngine) MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return api_storage.MatchFilterNode(node, entity)
+}
+
+func (internalEngine) ApplyFiltersToList(entities []map[string]any, filters map[string]map[string]string) []map[string]any {
+	return api_storage.ApplyFiltersToList(entities, filters)
+}
+
+func (internalEngine) ApplyIncludes(data []map[string]any, includesParam string) []map[string]any {
+	return api_storage.ApplyIncludes(data, includesParam)
+}
+
+func (internalEngine) FilterFields(data map[string]any, fields []string) map[string]any {
+	return api_storage.FilterFields(data, fields)
+}
+
+func (internalEngine) GetEntitySchema(entity string) map[string]any {
+	return api_storage.GetEntitySchema(entity)
+}
+
+func (internalEngine) UpdateEntitySchema(entity string, fieldsRaw map[string]any) map[string]any {
+	return api_storage.UpdateEntitySchema(entity, fieldsRaw)
+}
+
+func (internalEngine) CreateEntityType(entity string) error {
+	return api_storage.CreateEntityType(entity)
+}
+
+func (internalEngine) DeleteEntityType(entity string) error {
+	return api_storage.DeleteEntityType(entity)
+}
+
+func (internalEngine) AddEntityType(entity string) {
+	api_storage.AddEntityType(entity)
+}
+
+func (internalEngine) EntityTypeExists(entity string) bool {
+	return api_storage.EntityTypeExists(entity)
+}
+
+func (internalEngine) ListEntityTypes() []string {
+	return api_storage.ListEntityTypes()
+}
+
+func (internalEngine) ListPublicEntityTypes() []string {
+	return api_storage.ListPublicEntityTypes()
+}
+
+func (internalEngine) DumpAll() map[string]any {
+	return api_storage.DumpAll()
+}
+
+func (internalEngine) ImportAll(data map[string][]map[string]any) {
+	api_storage.ImportAll(data)
+}
+
+func (internalEngine) CountAllEntities() int {
+	return api_storage.CountAllEntities()
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/taymour/elysiandb/internal/mongodb"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+type mongoEngine struct{}
+
+func (mongoEngine) Name() string {
+	return EngineMongoDB
+}
+
+func (mongoEngine) Label() string {
+	return "MongoDB"
+}
+
+func (mongoEngine) Capabilities() Capabilities {
+	return Capabilities{
+		ExternalSchema:  true,
+		ExternalStorage: true,
+	}
+}
+
+func (mongoEngine) Open() error {
+	return mongodb.Connect()
+}
+
+func (mongoEngine) Close() error {
+	return mongodb.Disconnect()
+}
+
+func (mongoEngine) WriteEntity(entity string, data map[string]any) []schema.ValidationError {
+	return mongodb.WriteEntity(entity, data)
+}
+
+func (mongoEngine) WriteListOfEntities(entity string, list []map[string]any) [][]schema.ValidationError {
+	return mongodb.WriteListOfEntities(entity, list)
+}
+
+func (mongoEngine) ReadEntityById(entity, id string) map[string]any {
+	return mongodb.ReadEntityById(entity, id)
+}
+
+func (mongoEngine) ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	return mongodb.ReadEntitiesByIds(entity, ids)
+}
+
+func (mongoEngine) EntityExists(entity, id string) bool {
+	return mongodb.EntityExists(entity, id)
+}
+
+func (mongoEngine) UpdateEntityById(entity, id string, updated map[string]any) map[string]any {
+	return mongodb.UpdateEntityById(entity, id, updated)
+}
+
+func (mongoEngine) UpdateListOfEntities(entity string, updates []map[string]any) []map[string]any {
+	return mongodb.UpdateListOfEntities(entity, updates)
+}
+
+func (mongoEngine) DeleteEntityById(entity, id string) {
+	mongodb.DeleteEntityById(entity, id)
+}
+
+func (mongoEngine) DeleteAllEntities(entity string) {
+	mongodb.DeleteAllEntities(entity)
+}
+
+func (mongoEngine) DeleteAll() {
+	mongodb.DeleteAll()
+}
+
+func (mongoEngine) ListEntities(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return mongodb.ListEntitiesWithFilterNodeContext(ctx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
+
+func (mongoEngine) GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	return mongodb.GetListOfIds(entity, sortField, sortAscending)
+}
+
+func (mongoEngine) ExecuteQuery(q query.Query) ([]map[string]any, error) {
+	return mongodb.ExecuteQuery(q)
+}
+
+func (mongoEngine) MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return mongodb.MatchFilterNode(node, entity)
+}
+
+func (mongoEngine) ApplyFiltersToList(entities []map[string]any, filters map[string]map[string]string) []map[string]any {
+	return mongodb.ApplyFiltersToList(entities, filters)
+}
+
+func (mongoEngine) ApplyIncludes(data []map[string]any, includesParam string) []map[string]any {
+	return mongodb.ApplyIncludes(data, includesParam)
+}
+
+func (mongoEngine) FilterFields(data map[string]any, fields []string) map[string]any {
+	return mongodb.FilterFields(data, fields)
+}
+
+func (mongoEngine) GetEntitySchema(entity string) map[string]any {
+	return mongodb.GetEntitySchema(entity)
+}
+
+func (mongoEngine) UpdateEntitySchema(entity string, fieldsRaw map[string]any) map[string]any {
+	return mongodb.UpdateEntitySchema(entity, fieldsRaw)
+}
+
+func (mongoEngine) CreateEntityType(entity string) error {
+	return mongodb.CreateEntityType(entity)
+}
+
+func (mongoEngine) DeleteEntityType(entity string) error {
+	return mongodb.DeleteEntityType(entity)
+}
+
+func (mongoEngine) AddEntityType(entity string) {
+	mongodb.AddEntityType(entity)
+}
+
+func (mongoEngine) EntityTypeExists(entity string) bool {
+	return mongodb.EntityTypeExists(entity)
+}
+
+func (mongoEngine) ListEntityTypes() []string {
+	return mongodb.ListEntityTypes()
+}
+
+func (mongoEngine) ListPublicEntityTypes() []string {
+	return mongodb.ListPublicEntityTypes()
+}
+
+func (mongoEngine) DumpAll() map[string]any {
+	return mongodb.DumpAll()
+}
+
+func (mongoEngine) ImportAll(data map[string][]map[string]any) {
+	mongodb.ImportAll(data)
+}
+
+func (mongoEngine) CountAllEntities() int {
+	return mongodb.CountAllEntities()
+}
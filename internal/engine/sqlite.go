@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/sqlite"
+)
+
+type sqliteEngine struct{}
+
+func (sqliteEngine) Name() string {
+	return EngineSQLite
+}
+
+func (sqliteEngine) Label() string {
+	return "SQLite"
+}
+
+func (sqliteEngine) Capabilities() Capabilities {
+	return Capabilities{
+		ExternalSchema:  true,
+		ExternalStorage: true,
+		Transactions:    true,
+	}
+}
+
+func (sqliteEngine) Open() error {
+	return sqlite.Connect()
+}
+
+func (sqliteEngine) Close() error {
+	return sqlite.Disconnect()
+}
+
+func (sqliteEngine) Transaction(fn func(w Writer) error) error {
+	return sqlite.InTransaction(func(s sqlite.Session) error {
+		return fn(s)
+	})
+}
+
+func (sqliteEngine) WriteEntity(entity string, data map[string]any) []schema.ValidationError {
+	return sqlite.WriteEntity(entity, data)
+}
+
+func (sqliteEngine) WriteListOfEntities(entity string, list []map[string]any) [][]schema.ValidationError {
+	return sqlite.WriteListOfEntities(entity, list)
+}
+
+func (sqliteEngine) ReadEntityById(entity, id string) map[string]any {
+	return sqlite.ReadEntityById(entity, id)
+}
+
+func (sqliteEngine) ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	return sqlite.ReadEntitiesByIds(entity, ids)
+}
+
+func (sqliteEngine) EntityExists(entity, id string) bool {
+	return sqlite.EntityExists(entity, id)
+}
+
+func (sqliteEngine) UpdateEntityById(entity, id string, updated map[string]any) map[string]any {
+	return sqlite.UpdateEntityById(entity, id, updated)
+}
+
+func (sqliteEngine) UpdateListOfEntities(entity string, updates []map[string]any) []map[string]any {
+	return sqlite.UpdateListOfEntities(entity, updates)
+}
+
+func (sqliteEngine) DeleteEntityById(entity, id string) {
+	sqlite.DeleteEntityById(entity, id)
+}
+
+func (sqliteEngine) DeleteAllEntities(entity string) {
+	sqlite.DeleteAllEntities(entity)
+}
+
+func (sqliteEngine) DeleteAll() {
+	sqlite.DeleteAll()
+}
+
+func (sqliteEngine) ListEntities(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return sqlite.ListEntitiesWithFilterNodeContext(ctx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
+
+func (sqliteEngine) GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	return sqlite.GetListOfIds(entity, sortField, sortAscending)
+}
+
+func (sqliteEngine) ExecuteQuery(q query.Query) ([]map[string]any, error) {
+	return sqlite.ExecuteQuery(q)
+}
+
+func (sqliteEngine) MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return sqlite.MatchFilterNode(node, entity)
+}
+
+func (sqliteEngine) ApplyFiltersToList(entities []map[string]any, filters map[string]map[string]string) []map[string]any {
+	return sqlite.ApplyFiltersToList(entities, filters)
+}
+
+func (sqliteEngine) ApplyIncludes(data []map[string]any, includesParam string) []map[string]any {
+	return sqlite.ApplyIncludes(data, includesParam)
+}
+
+func (sqliteEngine) FilterFields(data map[string]any, fields []string) map[string]any {
+	return sqlite.FilterFields(data, fields)
+}
+
+func (sqliteEngine) GetEntitySchema(entity string) map[string]any {
+	return sqlite.GetEntitySchema(entity)
+}
+
+func (sqliteEngine) UpdateEntitySchema(entity string, fieldsRaw map[string]any) map[string]any {
+	return sqlite.UpdateEntitySchema(entity, fieldsRaw)
+}
+
+func (sqliteEngine) CreateEntityType(entity string) error {
+	return sqlite.CreateEntityType(entity)
+}
+
+func (sqliteEngine) DeleteEntityType(entity string) error {
+	return sqlite.DeleteEntityType(entity)
+}
+
+func (sqliteEngine) AddEntityType(entity string) {
+	sqlite.AddEntityType(entity)
+}
+
+func (sqliteEngine) EntityTypeExists(entity string) bool {
+	return sqlite.EntityTypeExists(entity)
+}
+
+func (sqliteEngine) ListEntityTypes() []string {
+	return sqlite.ListEntityTypes()
+}
+
+func (sqliteEngine) ListPublicEntityTypes() []string {
+	return sqlite.ListPublicEntityTypes()
+}
+
+func (sqliteEngine) DumpAll() map[string]any {
+	return sqlite.DumpAll()
+}
+
+func (sqliteEngine) ImportAll(data map[string][]map[string]any) {
+	sqlite.ImportAll(data)
+}
+
+func (sqliteEngine) CountAllEntities() int {
+	return sqlite.CountAllEntities()
+}
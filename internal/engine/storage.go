@@ -1,9 +1,8 @@
 package engine
 
 import (
-	api_storage "github.com/taymour/elysiandb/internal/api"
-	"github.com/taymour/elysiandb/internal/globals"
-	"github.com/taymour/elysiandb/internal/mongodb"
+	"context"
+
 	"github.com/taymour/elysiandb/internal/query"
 	"github.com/taymour/elysiandb/internal/schema"
 )
@@ -11,202 +10,67 @@ import (
 const (
 	EngineInternal = "internal"
 	EngineMongoDB  = "mongodb"
+	EngineSQLite   = "sqlite"
 )
 
 func ExecuteQuery(q query.Query) ([]map[string]any, error) {
-	if IsEngineInternal() {
-		return api_storage.ExecuteQuery(q)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.ExecuteQuery(q)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil, nil
+	return Current().ExecuteQuery(q)
 }
 
 func FilterFields(data map[string]any, fields []string) map[string]any {
-	if IsEngineInternal() {
-		return api_storage.FilterFields(data, fields)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.FilterFields(data, fields)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().FilterFields(data, fields)
 }
 
 func ApplyIncludes(data []map[string]interface{}, includesParam string) []map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.ApplyIncludes(data, includesParam)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.ApplyIncludes(data, includesParam)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().ApplyIncludes(data, includesParam)
 }
 
 func WriteEntity(entity string, data map[string]interface{}) []schema.ValidationError {
-	if IsEngineInternal() {
-		return api_storage.WriteEntity(entity, data)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.WriteEntity(entity, data)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().WriteEntity(entity, data)
 }
 
 func UpdateEntitySchema(entity string, fieldsRaw map[string]interface{}) map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.UpdateEntitySchema(entity, fieldsRaw)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.UpdateEntitySchema(entity, fieldsRaw)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().UpdateEntitySchema(entity, fieldsRaw)
 }
 
 func CreateEntityType(entity string) error {
-	if IsEngineInternal() {
-		return api_storage.CreateEntityType(entity)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.CreateEntityType(entity)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().CreateEntityType(entity)
 }
 
 func DeleteEntityType(entity string) error {
-	if IsEngineInternal() {
-		return api_storage.DeleteEntityType(entity)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.DeleteEntityType(entity)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().DeleteEntityType(entity)
 }
 
 func WriteListOfEntities(entity string, list []map[string]interface{}) [][]schema.ValidationError {
-	if IsEngineInternal() {
-		return api_storage.WriteListOfEntities(entity, list)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.WriteListOfEntities(entity, list)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().WriteListOfEntities(entity, list)
 }
 
 func AddEntityType(entity string) {
-	if IsEngineInternal() {
-		api_storage.AddEntityType(entity)
-		return
-	}
-
-	if IsEngineMongoDB() {
-		mongodb.AddEntityType(entity)
-		return
-	}
-
-	ThrowErrorIfNotValidEngine()
+	Current().AddEntityType(entity)
 }
 
 func GetEntitySchema(entity string) map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.GetEntitySchema(entity)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.GetEntitySchema(entity)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().GetEntitySchema(entity)
 }
 
 func EntityTypeExists(entity string) bool {
-	if IsEngineInternal() {
-		return api_storage.EntityTypeExists(entity)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.EntityTypeExists(entity)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return false
+	return Current().EntityTypeExists(entity)
 }
 
 func ListEntityTypes() []string {
-	if IsEngineInternal() {
-		return api_storage.ListEntityTypes()
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.ListEntityTypes()
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().ListEntityTypes()
 }
 
 func ListPublicEntityTypes() []string {
-	if IsEngineInternal() {
-		return api_storage.ListPublicEntityTypes()
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.ListPublicEntityTypes()
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().ListPublicEntityTypes()
 }
 
 func ReadEntityById(entity, id string) map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.ReadEntityById(entity, id)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.ReadEntityById(entity, id)
-	}
-
-	ThrowErrorIfNotValidEngine()
+	return Current().ReadEntityById(entity, id)
+}
 
-	return nil
+func ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	return Current().ReadEntitiesByIds(entity, ids)
 }
 
 func ListEntities(
@@ -219,185 +83,85 @@ func ListEntities(
 	search string,
 	includesParam string,
 ) []map[string]any {
-	if IsEngineInternal() {
-		return api_storage.ListEntities(entity, limit, offset, sortField, sortAscending, filters, search, includesParam)
-	}
+	return ListEntitiesWithFilterNodeContext(context.Background(), entity, limit, offset, sortField, sortAscending, filters, search, includesParam, query.FilterNode{})
+}
 
-	if IsEngineMongoDB() {
-		return mongodb.ListEntities(entity, limit, offset, sortField, sortAscending, filters, search, includesParam)
-	}
+func ListEntitiesWithFilterNode(
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return ListEntitiesWithFilterNodeContext(context.Background(), entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
 
-	ThrowErrorIfNotValidEngine()
+func ListEntitiesWithFilterNodeContext(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return Current().ListEntities(ctx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
 
-	return nil
+func MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return Current().MatchFilterNode(node, entity)
 }
 
 func ApplyFiltersToList(
 	entities []map[string]any,
 	filters map[string]map[string]string,
 ) []map[string]any {
-	if IsEngineInternal() {
-		return api_storage.ApplyFiltersToList(entities, filters)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.ApplyFiltersToList(entities, filters)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().ApplyFiltersToList(entities, filters)
 }
 
-func GetListOfIds(entity, sortField string, sortAscending bool) ([]byte, error) {
-	if IsEngineInternal() {
-		return api_storage.GetListOfIds(entity, sortField, sortAscending)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.GetListOfIds(entity, sortField, sortAscending)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil, nil
+func GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	return Current().GetListOfIds(entity, sortField, sortAscending)
 }
 
 func DeleteEntityById(entity, id string) {
-	if IsEngineInternal() {
-		api_storage.DeleteEntityById(entity, id)
-		return
-	}
-
-	if IsEngineMongoDB() {
-		mongodb.DeleteEntityById(entity, id)
-		return
-	}
-
-	ThrowErrorIfNotValidEngine()
+	Current().DeleteEntityById(entity, id)
 }
 
 func DeleteAllEntities(entity string) {
-	if IsEngineInternal() {
-		api_storage.DeleteAllEntities(entity)
-		return
-	}
-
-	if IsEngineMongoDB() {
-		mongodb.DeleteAllEntities(entity)
-		return
-	}
-
-	ThrowErrorIfNotValidEngine()
+	Current().DeleteAllEntities(entity)
 }
 
 func DeleteAll() {
-	if IsEngineInternal() {
-		api_storage.DeleteAll()
-		return
-	}
-
-	if IsEngineMongoDB() {
-		mongodb.DeleteAll()
-		return
-	}
-
-	ThrowErrorIfNotValidEngine()
+	Current().DeleteAll()
 }
 
 func UpdateEntityById(entity, id string, updated map[string]interface{}) map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.UpdateEntityById(entity, id, updated)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.UpdateEntityById(entity, id, updated)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().UpdateEntityById(entity, id, updated)
 }
 
 func UpdateListOfEntities(entity string, updates []map[string]interface{}) []map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.UpdateListOfEntities(entity, updates)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.UpdateListOfEntities(entity, updates)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().UpdateListOfEntities(entity, updates)
 }
 
 func DumpAll() map[string]interface{} {
-	if IsEngineInternal() {
-		return api_storage.DumpAll()
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.DumpAll()
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return nil
+	return Current().DumpAll()
 }
 
 func EntityExists(entity, id string) bool {
-	if IsEngineInternal() {
-		return api_storage.EntityExists(entity, id)
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.EntityExists(entity, id)
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return false
+	return Current().EntityExists(entity, id)
 }
 
 func CountAllEntities() int {
-	if IsEngineInternal() {
-		return api_storage.CountAllEntities()
-	}
-
-	if IsEngineMongoDB() {
-		return mongodb.CountAllEntities()
-	}
-
-	ThrowErrorIfNotValidEngine()
-
-	return 0
+	return Current().CountAllEntities()
 }
 
 func ImportAll(data map[string][]map[string]interface{}) {
-	if IsEngineInternal() {
-		api_storage.ImportAll(data)
-		return
-	}
-
-	if IsEngineMongoDB() {
-		mongodb.ImportAll(data)
-		return
-	}
-
-	ThrowErrorIfNotValidEngine()
-}
-
-func IsEngineInternal() bool {
-	return globals.GetEngine() == EngineInternal
-}
-
-func IsEngineMongoDB() bool {
-	return globals.GetEngine() == EngineMongoDB
-}
-
-func ThrowErrorIfNotValidEngine() {
-	engine := globals.GetConfig().Engine.Name
-	panic("Invalid storage engine: " + engine + ". Only '" + EngineInternal + "' is supported.")
+	Current().ImportAll(data)
 }
@@ -5,17 +5,17 @@ import "fmt"
 const CoreFieldsPrefix = "_elysiandb_core_"
 
 const (
-	ApiEntityTypesListPattern          = "api:entity:types:list"
-	ApiEntityPattern                   = "api:entity:%s"
-	ApiEntitiesPattern                 = "api:entity:%s:*"
-	ApiSingleEntityPattern             = "api:entity:%s:id:%s"
-	ApiEntityIndexIdPattern            = "api:entity:%s:internal:index:id"
-	ApiEntityIndexPattern              = "api:entity:%s:internal:index:*"
-	ApiEntityIndexFieldFilterPattern   = "api:entity:%s:internal:index:field:%s:filter"
-	ApiEntityIndexAllFieldsPattern     = "api:entity:%s:internal:index:fields:all"
-	ApiEntityIndexFieldAllPattern      = "api:entity:%s:internal:index:field:%s:*"
-	ApiEntityIndexFieldSortAscPattern  = "api:entity:%s:internal:index:field:%s:sort:asc"
-	ApiEntityIndexFieldSortDescPattern = "api:entity:%s:internal:index:field:%s:sort:desc"
+	ApiEntityTypesListPattern         = "api:entity:types:list"
+	ApiEntityPattern                  = "api:entity:%s"
+	ApiEntitiesPattern                = "api:entity:%s:*"
+	ApiSingleEntityPattern            = "api:entity:%s:id:%s"
+	ApiEntityIndexIdPattern           = "api:entity:%s:internal:index:id"
+	ApiEntityIndexPattern             = "api:entity:%s:internal:index:*"
+	ApiEntityIndexFieldFilterPattern  = "api:entity:%s:internal:index:field:%s:filter"
+	ApiEntityIndexAllFieldsPattern    = "api:entity:%s:internal:index:fields:all"
+	ApiEntityIndexFieldAllPattern     = "api:entity:%s:internal:index:field:%s:*"
+	ApiEntityIndexFieldSortPattern    = "api:entity:%s:internal:index:field:%s:sort"
+	ApiEntityIndexLinkedFieldsPattern = "api:entity:%s:internal:index:linked:all"
 )
 
 func ApiAllEntityTypesListKey() string {
@@ -42,18 +42,18 @@ func ApiEntityIndexFieldKey(entity, field string) string {
 	return fmt.Sprintf(ApiEntityIndexFieldFilterPattern, entity, field)
 }
 
-func ApiEntityIndexFieldSortAscKey(entity, field string) string {
-	return fmt.Sprintf(ApiEntityIndexFieldSortAscPattern, entity, field)
-}
-
-func ApiEntityIndexFieldSortDescKey(entity, field string) string {
-	return fmt.Sprintf(ApiEntityIndexFieldSortDescPattern, entity, field)
+func ApiEntityIndexFieldSortKey(entity, field string) string {
+	return fmt.Sprintf(ApiEntityIndexFieldSortPattern, entity, field)
 }
 
 func ApiEntityIndexAllFieldsKey(entity string) string {
 	return fmt.Sprintf(ApiEntityIndexAllFieldsPattern, entity)
 }
 
+func ApiEntityIndexLinkedFieldsKey(entity string) string {
+	return fmt.Sprintf(ApiEntityIndexLinkedFieldsPattern, entity)
+}
+
 func ApiEntityIndexPatternKey(entity string) string {
 	return fmt.Sprintf(ApiEntityIndexPattern, entity)
 }
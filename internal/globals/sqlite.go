@@ -0,0 +1,5 @@
+package globals
+
+import "database/sql"
+
+var SQLiteDB *sql.DB
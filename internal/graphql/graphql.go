@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/tracing"
+)
+
+type Request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+var (
+	mu          sync.Mutex
+	cached      *gql.Schema
+	fingerprint uint64
+)
+
+func Execute(ctx context.Context, req Request) *gql.Result {
+	ctx, span := tracing.Start(ctx, "graphql.Execute")
+
+	s, err := Schema()
+	if err != nil {
+		tracing.EndWithError(span, err)
+		return &gql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}}
+	}
+
+	result := gql.Do(gql.Params{
+		Schema:         *s,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoader(ctx),
+	})
+	span.End()
+
+	return result
+}
+
+func Schema() (*gql.Schema, error) {
+	entities := loadEntities()
+
+	raw, err := json.Marshal(entities)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(raw)
+	sum := h.Sum64()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cached != nil && sum == fingerprint {
+		return cached, nil
+	}
+
+	s, err := newBuilder(entities).build()
+	if err != nil {
+		return nil, err
+	}
+
+	cached = &s
+	fingerprint = sum
+
+	return cached, nil
+}
+
+func loadEntities() map[string]map[string]schema.Field {
+	types := engine.ListPublicEntityTypes()
+	sort.Strings(types)
+
+	entities := make(map[string]map[string]schema.Field, len(types))
+	for _, entity := range types {
+		fields := map[string]schema.Field{}
+		if data := engine.GetEntitySchema(entity); data != nil {
+			if raw, ok := data["fields"].(map[string]any); ok {
+				fields = schema.MapToFields(raw)
+			}
+		}
+
+		entities[entity] = fields
+	}
+
+	return entities
+}
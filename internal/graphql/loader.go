@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/engine"
+)
+
+var ReadEntitiesByIds = engine.ReadEntitiesByIds
+
+type loaderKey struct{}
+
+type entityBatch struct {
+	pending []string
+	loaded  map[string]map[string]any
+}
+
+type reverseBatch struct {
+	source string
+	field  string
+	query  listQuery
+	loaded bool
+	groups map[string][]map[string]any
+}
+
+type loader struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	entities map[string]*entityBatch
+	reverse  map[string]*reverseBatch
+}
+
+func withLoader(ctx context.Context) context.Context {
+	l := &loader{
+		ctx:      ctx,
+		entities: map[string]*entityBatch{},
+		reverse:  map[string]*reverseBatch{},
+	}
+
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *loader {
+	if l, ok := ctx.Value(loaderKey{}).(*loader); ok {
+		return l
+	}
+
+	return withLoader(ctx).Value(loaderKey{}).(*loader)
+}
+
+func (l *loader) load(entity, id string) func() (any, error) {
+	l.mu.Lock()
+	b, ok := l.entities[entity]
+	if !ok {
+		b = &entityBatch{loaded: map[string]map[string]any{}}
+		l.entities[entity] = b
+	}
+
+	if _, done := b.loaded[id]; !done {
+		b.pending = append(b.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.flushEntity(entity, b)
+
+		if data := b.loaded[id]; data != nil {
+			return data, nil
+		}
+
+		return nil, nil
+	}
+}
+
+func (l *loader) flushEntity(entity string, b *entityBatch) {
+	if len(b.pending) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(b.pending))
+	seen := map[string]bool{}
+	for _, id := range b.pending {
+		if _, done := b.loaded[id]; done || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	b.pending = nil
+
+	if len(ids) == 0 {
+		return
+	}
+
+	docs := ReadEntitiesByIds(entity, ids)
+	for _, id := range ids {
+		data := docs[id]
+		if data == nil || !acl.CanReadEntity(l.ctx, entity, data) {
+			b.loaded[id] = nil
+			continue
+		}
+
+		b.loaded[id] = postRead(l.ctx, entity, acl.HideFields(l.ctx, entity, data))
+	}
+}
+
+func (l *loader) loadReverse(source, field, key string, q listQuery, parentID string) func() (any, error) {
+	l.mu.Lock()
+	b, ok := l.reverse[key]
+	if !ok {
+		b = &reverseBatch{source: source, field: field, query: q}
+		l.reverse[key] = b
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.flushReverse(b)
+
+		return paginate(b.groups[parentID], b.query.offset, b.query.limit), nil
+	}
+}
+
+func (l *loader) flushReverse(b *reverseBatch) {
+	if b.loaded {
+		return
+	}
+
+	all := b.query
+	all.limit, all.offset = 0, 0
+
+	b.groups = map[string][]map[string]any{}
+	for _, item := range readList(l.ctx, b.source, all) {
+		for _, id := range linkIDs(item[b.field]) {
+			b.groups[id] = append(b.groups[id], item)
+		}
+	}
+	b.loaded = true
+}
+
+func linkID(ref any) string {
+	m, ok := ref.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	id, _ := m["id"].(string)
+
+	return id
+}
+
+func linkIDs(ref any) []string {
+	switch v := ref.(type) {
+	case map[string]any:
+		if id := linkID(v); id != "" {
+			return []string{id}
+		}
+	case []any:
+		ids := make([]string, 0, len(v))
+		seen := map[string]bool{}
+		for _, item := range v {
+			if id := linkID(item); id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+
+		return ids
+	}
+
+	return nil
+}
+
+func paginate(items []map[string]any, offset, limit int) []map[string]any {
+	if offset >= len(items) {
+		return []map[string]any{}
+	}
+
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
@@ -0,0 +1,220 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	gql "github.com/graphql-go/graphql"
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/replication"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+var (
+	ErrForbidden      = errors.New("forbidden")
+	ErrEntityNotFound = errors.New("entity not found")
+	ErrReadOnly       = errors.New("read-only follower, send writes to the leader")
+)
+
+func writable(resolve gql.FieldResolveFn) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		if replication.IsFollower() {
+			return nil, ErrReadOnly
+		}
+
+		return resolve(p)
+	}
+}
+
+func resolveCreate(entity string, links map[string]string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		data, _ := p.Args["data"].(map[string]any)
+		tagLinks(data, links)
+
+		if err := validateStrict(entity, data); err != nil {
+			return nil, err
+		}
+
+		if err := prepareCreate(p.Context, entity, data); err != nil {
+			return nil, err
+		}
+
+		if errs := engine.WriteEntity(entity, data); len(errs) > 0 {
+			return nil, validationError(errs)
+		}
+
+		finalizeCreate(entity)
+
+		return data, nil
+	}
+}
+
+func resolveCreateMany(entity string, links map[string]string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		raw, _ := p.Args["data"].([]any)
+
+		list := make([]map[string]any, 0, len(raw))
+		for _, item := range raw {
+			if m, ok := item.(map[string]any); ok {
+				tagLinks(m, links)
+				list = append(list, m)
+			}
+		}
+
+		if err := validateStrict(entity, list...); err != nil {
+			return nil, err
+		}
+
+		for _, data := range list {
+			if err := prepareCreate(p.Context, entity, data); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, errs := range engine.WriteListOfEntities(entity, list) {
+			if len(errs) > 0 {
+				return nil, validationError(errs)
+			}
+		}
+
+		finalizeCreate(entity)
+
+		return list, nil
+	}
+}
+
+func resolveUpdate(entity string, links map[string]string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		id, _ := p.Args["id"].(string)
+		data, _ := p.Args["data"].(map[string]any)
+		tagLinks(data, links)
+
+		if err := validateStrict(entity, data); err != nil {
+			return nil, err
+		}
+
+		acl.StripProtectedFields(data)
+
+		existing := engine.ReadEntityById(entity, id)
+		target := existing
+		if target == nil {
+			target = data
+		}
+
+		if !acl.CanUpdateEntity(p.Context, entity, target) {
+			return nil, ErrForbidden
+		}
+
+		if forbidden := acl.ForbiddenFieldsForUpdate(p.Context, entity, data, existing); len(forbidden) > 0 {
+			return nil, forbiddenFieldsError(forbidden)
+		}
+
+		updated := acl.HideFields(p.Context, entity, engine.UpdateEntityById(entity, id, data))
+		purgeCache(entity)
+
+		if updated == nil {
+			return nil, nil
+		}
+
+		return updated, nil
+	}
+}
+
+func resolveDelete(entity string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		id, _ := p.Args["id"].(string)
+
+		data := engine.ReadEntityById(entity, id)
+		if data == nil {
+			return false, ErrEntityNotFound
+		}
+
+		if !acl.CanDeleteEntity(p.Context, entity, data) {
+			return false, ErrForbidden
+		}
+
+		engine.DeleteEntityById(entity, id)
+		purgeCache(entity)
+
+		return true, nil
+	}
+}
+
+func prepareCreate(ctx context.Context, entity string, data map[string]any) error {
+	if id, ok := data["id"].(string); !ok || id == "" {
+		data["id"] = uuid.New().String()
+	}
+
+	if forbidden := acl.ForbiddenFieldsForCreate(ctx, entity, data); len(forbidden) > 0 {
+		return forbiddenFieldsError(forbidden)
+	}
+
+	acl.StripProtectedFields(data)
+	if security.IdentityAuthenticationIsEnabled() {
+		data[acl.UsernameField] = security.CurrentPrincipal(ctx).Username
+	}
+
+	return nil
+}
+
+func tagLinks(data map[string]any, links map[string]string) {
+	for field, target := range links {
+		switch v := data[field].(type) {
+		case map[string]any:
+			if _, ok := v["@entity"]; !ok {
+				v["@entity"] = target
+			}
+		case []any:
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					if _, ok := m["@entity"]; !ok {
+						m["@entity"] = target
+					}
+				}
+			}
+		}
+	}
+}
+
+func validateStrict(entity string, items ...map[string]any) error {
+	schemaData := engine.SchemaData(entity)
+
+	if !globals.GetConfig().Api.Schema.Strict || !schema.IsManualSchema(entity, schemaData) {
+		return nil
+	}
+
+	for _, item := range items {
+		if errs := schema.ValidateEntity(entity, item, schemaData); len(errs) > 0 {
+			return validationError(errs)
+		}
+	}
+
+	return nil
+}
+
+func validationError(errs []schema.ValidationError) error {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, fmt.Sprintf("%s: %s", e.Field, e.Message))
+	}
+
+	return fmt.Errorf("schema validation failed: %s", strings.Join(messages, "; "))
+}
+
+func finalizeCreate(entity string) {
+	purgeCache(entity)
+	acl.InitACL()
+}
+
+func purgeCache(entity string) {
+	if globals.GetConfig().Api.Cache.Enabled {
+		cache.CacheStore.Purge(entity)
+	}
+}
@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/taymour/elysiandb/internal/acl"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/query"
+)
+
+var ErrAccessDenied = errors.New("access denied")
+
+func readList(ctx context.Context, entity string, q listQuery) []map[string]any {
+	data := []map[string]any{}
+
+	rowFilter, allowed := acl.ReadFilterForCurrentUser(ctx, entity)
+	if allowed && q.search != "" && acl.HasHiddenFields(ctx, entity) {
+		data = engine.ListEntitiesWithFilterNodeContext(ctx, entity, 0, 0, q.sortField, q.sortAscending, nil, "", "", rowFilter)
+		data = api_storage.SearchList(acl.HideFieldsInList(ctx, entity, data), q.search, q.offset, q.limit)
+	} else if allowed {
+		data = engine.ListEntitiesWithFilterNodeContext(ctx, entity, q.limit, q.offset, q.sortField, q.sortAscending, q.filters, q.search, "", rowFilter)
+		data = acl.HideFieldsInList(ctx, entity, data)
+	}
+
+	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPreReadHooks(entity) {
+		for i, item := range data {
+			data[i] = hook.ApplyPreReadHooksForEntity(ctx, entity, item)
+		}
+
+		data = engine.ApplyFiltersToList(data, q.filters)
+	}
+
+	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPostReadHooks(entity) {
+		for i, item := range data {
+			data[i] = hook.ApplyPostReadHooksForEntity(ctx, entity, item)
+		}
+	}
+
+	return data
+}
+
+func readOne(ctx context.Context, entity, id string) (map[string]any, error) {
+	data := engine.ReadEntityById(entity, id)
+	if data == nil {
+		return nil, nil
+	}
+
+	if !acl.CanReadEntity(ctx, entity, data) {
+		return nil, ErrAccessDenied
+	}
+
+	return postRead(ctx, entity, acl.HideFields(ctx, entity, data)), nil
+}
+
+func postRead(ctx context.Context, entity string, data map[string]any) map[string]any {
+	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPostReadHooks(entity) {
+		return hook.ApplyPostReadHooksForEntity(ctx, entity, data)
+	}
+
+	return data
+}
+
+func source(p gql.ResolveParams) map[string]any {
+	m, _ := p.Source.(map[string]any)
+
+	return m
+}
+
+func resolveList(entity string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		q := parseListArgs(p.Args)
+		if err := checkHiddenFields(p.Context, entity, q); err != nil {
+			return nil, err
+		}
+
+		return readList(p.Context, entity, q), nil
+	}
+}
+
+func resolveCount(entity string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		q := parseListArgs(p.Args)
+		if err := checkHiddenFields(p.Context, entity, q); err != nil {
+			return nil, err
+		}
+
+		return len(readList(p.Context, entity, q)), nil
+	}
+}
+
+func checkHiddenFields(ctx context.Context, entity string, q listQuery) error {
+	if hidden := acl.HiddenFieldsInQuery(ctx, entity, append(query.LeafFields(q.filters), q.sortField)...); len(hidden) > 0 {
+		return forbiddenFieldsError(hidden)
+	}
+
+	return nil
+}
+
+func resolveById(entity string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		id, _ := p.Args["id"].(string)
+
+		data, err := readOne(p.Context, entity, id)
+		if data == nil {
+			return nil, err
+		}
+
+		return data, nil
+	}
+}
+
+func resolveLink(target, field string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		id := linkID(source(p)[field])
+		if id == "" {
+			return nil, nil
+		}
+
+		return loaderFrom(p.Context).load(target, id), nil
+	}
+}
+
+func resolveLinkList(target, field string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		l := loaderFrom(p.Context)
+
+		thunks := []func() (any, error){}
+		for _, id := range linkIDs(source(p)[field]) {
+			thunks = append(thunks, l.load(target, id))
+		}
+
+		return func() (any, error) {
+			out := []any{}
+			for _, thunk := range thunks {
+				if data, _ := thunk(); data != nil {
+					out = append(out, data)
+				}
+			}
+
+			return out, nil
+		}, nil
+	}
+}
+
+func resolveReverse(sourceEntity, field string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		parentID, _ := source(p)["id"].(string)
+		if parentID == "" {
+			return []map[string]any{}, nil
+		}
+
+		args, err := json.Marshal(p.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		q := parseListArgs(p.Args)
+		if err := checkHiddenFields(p.Context, sourceEntity, q); err != nil {
+			return nil, err
+		}
+
+		key := sourceEntity + "\x00" + field + "\x00" + string(args)
+
+		return loaderFrom(p.Context).loadReverse(sourceEntity, field, key, q, parentID), nil
+	}
+}
+
+func forbiddenFieldsError(fields []string) error {
+	return fmt.Errorf("forbidden fields: %s", strings.Join(fields, ", "))
+}
@@ -0,0 +1,337 @@
+package graphql
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+var validName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+type reverseLink struct {
+	source string
+	field  string
+}
+
+type builder struct {
+	entities map[string]map[string]schema.Field
+	order    []string
+	types    map[string]*gql.Object
+	inputs   map[string]*gql.InputObject
+	typeName map[string]string
+	used     map[string]bool
+	reverse  map[string][]reverseLink
+}
+
+func newBuilder(entities map[string]map[string]schema.Field) *builder {
+	b := &builder{
+		entities: entities,
+		types:    map[string]*gql.Object{},
+		inputs:   map[string]*gql.InputObject{},
+		typeName: map[string]string{},
+		used:     map[string]bool{},
+		reverse:  map[string][]reverseLink{},
+	}
+
+	for _, name := range []string{"Query", "Mutation", "JSON", "Filter", "FilterOperator", "Sort", "SortDirection"} {
+		b.used[name] = true
+	}
+
+	for entity := range entities {
+		b.order = append(b.order, entity)
+	}
+	sort.Strings(b.order)
+
+	for _, entity := range b.order {
+		b.typeName[entity] = b.uniqueName(pascal(identifier(entity)))
+	}
+
+	for _, source := range b.order {
+		for _, name := range sortedFieldNames(entities[source]) {
+			f := entities[source][name]
+			if _, ok := entities[f.Entity]; ok && isLink(f) && validName.MatchString(name) {
+				b.reverse[f.Entity] = append(b.reverse[f.Entity], reverseLink{source: source, field: name})
+			}
+		}
+	}
+
+	return b
+}
+
+func (b *builder) build() (gql.Schema, error) {
+	for _, entity := range b.order {
+		b.types[entity] = b.entityType(entity)
+		b.inputs[entity] = b.inputType(entity)
+	}
+
+	queryFields := gql.Fields{
+		"entityTypes": &gql.Field{
+			Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String))),
+			Resolve: func(p gql.ResolveParams) (any, error) {
+				return b.order, nil
+			},
+		},
+	}
+	mutationFields := gql.Fields{}
+
+	for _, entity := range b.order {
+		name := identifier(entity)
+		typeName := b.typeName[entity]
+
+		if _, exists := queryFields[name]; exists {
+			continue
+		}
+
+		queryFields[name] = &gql.Field{
+			Type:    gql.NewNonNull(gql.NewList(gql.NewNonNull(b.types[entity]))),
+			Args:    listArgs(),
+			Resolve: resolveList(entity),
+		}
+		queryFields[name+"ById"] = &gql.Field{
+			Type:    b.types[entity],
+			Args:    gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+			Resolve: resolveById(entity),
+		}
+		queryFields[name+"Count"] = &gql.Field{
+			Type: gql.NewNonNull(gql.Int),
+			Args: gql.FieldConfigArgument{
+				"filter": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(filterInput))},
+				"search": &gql.ArgumentConfig{Type: gql.String},
+			},
+			Resolve: resolveCount(entity),
+		}
+
+		mutationFields["create"+typeName] = &gql.Field{
+			Type:    b.types[entity],
+			Args:    gql.FieldConfigArgument{"data": &gql.ArgumentConfig{Type: gql.NewNonNull(b.inputs[entity])}},
+			Resolve: writable(resolveCreate(entity, b.links(entity))),
+		}
+		mutationFields["createMany"+typeName] = &gql.Field{
+			Type:    gql.NewList(b.types[entity]),
+			Args:    gql.FieldConfigArgument{"data": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(b.inputs[entity])))}},
+			Resolve: writable(resolveCreateMany(entity, b.links(entity))),
+		}
+		mutationFields["update"+typeName] = &gql.Field{
+			Type: b.types[entity],
+			Args: gql.FieldConfigArgument{
+				"id":   &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				"data": &gql.ArgumentConfig{Type: gql.NewNonNull(b.inputs[entity])},
+			},
+			Resolve: writable(resolveUpdate(entity, b.links(entity))),
+		}
+		mutationFields["delete"+typeName] = &gql.Field{
+			Type:    gql.NewNonNull(gql.Boolean),
+			Args:    gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+			Resolve: writable(resolveDelete(entity)),
+		}
+	}
+
+	cfg := gql.SchemaConfig{
+		Query: gql.NewObject(gql.ObjectConfig{Name: "Query", Fields: queryFields}),
+	}
+
+	if len(mutationFields) > 0 {
+		cfg.Mutation = gql.NewObject(gql.ObjectConfig{Name: "Mutation", Fields: mutationFields})
+	}
+
+	return gql.NewSchema(cfg)
+}
+
+func (b *builder) entityType(entity string) *gql.Object {
+	name := b.typeName[entity]
+
+	return gql.NewObject(gql.ObjectConfig{
+		Name: name,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			fields := gql.Fields{"id": &gql.Field{Type: gql.NewNonNull(gql.ID)}}
+			b.addFields(fields, name, b.entities[entity])
+
+			for _, link := range b.reverse[entity] {
+				fieldName := identifier(link.source) + "By" + pascal(link.field)
+				if _, exists := fields[fieldName]; exists {
+					continue
+				}
+
+				fields[fieldName] = &gql.Field{
+					Type:    gql.NewNonNull(gql.NewList(gql.NewNonNull(b.types[link.source]))),
+					Args:    listArgs(),
+					Resolve: resolveReverse(link.source, link.field),
+				}
+			}
+
+			return fields
+		}),
+	})
+}
+
+func (b *builder) addFields(fields gql.Fields, parent string, defs map[string]schema.Field) {
+	for _, name := range sortedFieldNames(defs) {
+		if !validName.MatchString(name) || strings.HasPrefix(name, "__") {
+			continue
+		}
+
+		if _, exists := fields[name]; exists {
+			continue
+		}
+
+		fields[name] = b.outputField(parent, name, defs[name])
+	}
+}
+
+func (b *builder) outputField(parent, name string, f schema.Field) *gql.Field {
+	if target, ok := b.types[f.Entity]; ok && isLink(f) {
+		if f.Type == "array" {
+			return &gql.Field{Type: gql.NewList(target), Resolve: resolveLinkList(f.Entity, name)}
+		}
+
+		return &gql.Field{Type: target, Resolve: resolveLink(f.Entity, name)}
+	}
+
+	return &gql.Field{Type: b.outputType(parent, name, f)}
+}
+
+func (b *builder) outputType(parent, name string, f schema.Field) gql.Output {
+	switch f.Type {
+	case "string":
+		return gql.String
+	case "number":
+		return gql.Float
+	case "boolean":
+		return gql.Boolean
+	case "object":
+		if len(f.Fields) == 0 || isLink(f) {
+			return jsonScalar
+		}
+
+		return b.nestedType(parent, name, f.Fields)
+	case "array":
+		if len(f.Fields) == 0 || isLink(f) {
+			return gql.NewList(jsonScalar)
+		}
+
+		return gql.NewList(b.nestedType(parent, name, f.Fields))
+	default:
+		return jsonScalar
+	}
+}
+
+func (b *builder) nestedType(parent, name string, defs map[string]schema.Field) *gql.Object {
+	typeName := b.uniqueName(parent + "_" + pascal(name))
+
+	return gql.NewObject(gql.ObjectConfig{
+		Name: typeName,
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			fields := gql.Fields{}
+			b.addFields(fields, typeName, defs)
+			if len(fields) == 0 {
+				fields["_raw"] = &gql.Field{
+					Type: jsonScalar,
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return p.Source, nil
+					},
+				}
+			}
+
+			return fields
+		}),
+	})
+}
+
+func (b *builder) inputType(entity string) *gql.InputObject {
+	fields := gql.InputObjectConfigFieldMap{"id": &gql.InputObjectFieldConfig{Type: gql.ID}}
+	for _, name := range sortedFieldNames(b.entities[entity]) {
+		if !validName.MatchString(name) || strings.HasPrefix(name, "__") || name == "id" {
+			continue
+		}
+
+		var t gql.Input
+		switch b.entities[entity][name].Type {
+		case "string":
+			t = gql.String
+		case "number":
+			t = gql.Float
+		case "boolean":
+			t = gql.Boolean
+		default:
+			t = jsonScalar
+		}
+
+		fields[name] = &gql.InputObjectFieldConfig{Type: t}
+	}
+
+	return gql.NewInputObject(gql.InputObjectConfig{
+		Name:   b.uniqueName(b.typeName[entity] + "Input"),
+		Fields: fields,
+	})
+}
+
+func (b *builder) links(entity string) map[string]string {
+	links := map[string]string{}
+	for name, f := range b.entities[entity] {
+		if _, ok := b.entities[f.Entity]; ok && isLink(f) {
+			links[name] = f.Entity
+		}
+	}
+
+	return links
+}
+
+func (b *builder) uniqueName(name string) string {
+	candidate := name
+	for i := 2; b.used[candidate]; i++ {
+		candidate = name + "_" + strconv.Itoa(i)
+	}
+	b.used[candidate] = true
+
+	return candidate
+}
+
+func isLink(f schema.Field) bool {
+	return f.Entity != "" && (f.Type == "object" || f.Type == "array")
+}
+
+func sortedFieldNames(fields map[string]schema.Field) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func identifier(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+
+	out := sb.String()
+	if out == "" || strings.HasPrefix(out, "__") {
+		out = "e" + out
+	}
+
+	return out
+}
+
+func pascal(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
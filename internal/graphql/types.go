@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"strconv"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+var jsonScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Arbitrary JSON value",
+	Serialize: func(value any) any {
+		return value
+	},
+	ParseValue: func(value any) any {
+		return value
+	},
+	ParseLiteral: parseLiteral,
+})
+
+var filterOperator = gql.NewEnum(gql.EnumConfig{
+	Name: "FilterOperator",
+	Values: gql.EnumValueConfigMap{
+		"eq":           &gql.EnumValueConfig{Value: "eq"},
+		"neq":          &gql.EnumValueConfig{Value: "neq"},
+		"lt":           &gql.EnumValueConfig{Value: "lt"},
+		"lte":          &gql.EnumValueConfig{Value: "lte"},
+		"gt":           &gql.EnumValueConfig{Value: "gt"},
+		"gte":          &gql.EnumValueConfig{Value: "gte"},
+		"contains":     &gql.EnumValueConfig{Value: "contains"},
+		"not_contains": &gql.EnumValueConfig{Value: "not_contains"},
+		"all":          &gql.EnumValueConfig{Value: "all"},
+		"any":          &gql.EnumValueConfig{Value: "any"},
+		"none":         &gql.EnumValueConfig{Value: "none"},
+	},
+})
+
+var sortDirection = gql.NewEnum(gql.EnumConfig{
+	Name: "SortDirection",
+	Values: gql.EnumValueConfigMap{
+		"asc":  &gql.EnumValueConfig{Value: "asc"},
+		"desc": &gql.EnumValueConfig{Value: "desc"},
+	},
+})
+
+var filterInput = gql.NewInputObject(gql.InputObjectConfig{
+	Name: "Filter",
+	Fields: gql.InputObjectConfigFieldMap{
+		"field": &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+		"op":    &gql.InputObjectFieldConfig{Type: filterOperator, DefaultValue: "eq"},
+		"value": &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+	},
+})
+
+var sortInput = gql.NewInputObject(gql.InputObjectConfig{
+	Name: "Sort",
+	Fields: gql.InputObjectConfigFieldMap{
+		"field":     &gql.InputObjectFieldConfig{Type: gql.NewNonNull(gql.String)},
+		"direction": &gql.InputObjectFieldConfig{Type: sortDirection, DefaultValue: "asc"},
+	},
+})
+
+func listArgs() gql.FieldConfigArgument {
+	return gql.FieldConfigArgument{
+		"filter": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(filterInput))},
+		"sort":   &gql.ArgumentConfig{Type: sortInput},
+		"limit":  &gql.ArgumentConfig{Type: gql.Int},
+		"offset": &gql.ArgumentConfig{Type: gql.Int},
+		"search": &gql.ArgumentConfig{Type: gql.String},
+	}
+}
+
+type listQuery struct {
+	limit         int
+	offset        int
+	sortField     string
+	sortAscending bool
+	filters       map[string]map[string]string
+	search        string
+}
+
+func parseListArgs(args map[string]any) listQuery {
+	q := listQuery{sortAscending: true, filters: map[string]map[string]string{}}
+
+	if v, ok := args["limit"].(int); ok && v > 0 {
+		q.limit = v
+	}
+
+	if v, ok := args["offset"].(int); ok && v > 0 {
+		q.offset = v
+	}
+
+	if v, ok := args["search"].(string); ok {
+		q.search = v
+	}
+
+	if s, ok := args["sort"].(map[string]any); ok {
+		q.sortField, _ = s["field"].(string)
+		q.sortAscending = s["direction"] != "desc"
+	}
+
+	if list, ok := args["filter"].([]any); ok {
+		for _, item := range list {
+			f, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			field, _ := f["field"].(string)
+			op, _ := f["op"].(string)
+			value, _ := f["value"].(string)
+			if op == "" {
+				op = "eq"
+			}
+
+			if _, ok := q.filters[field]; !ok {
+				q.filters[field] = map[string]string{}
+			}
+			q.filters[field][op] = value
+		}
+	}
+
+	return q
+}
+
+func parseLiteral(value ast.Value) any {
+	switch v := value.(type) {
+	case *ast.StringValue:
+		return v.Value
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.EnumValue:
+		return v.Value
+	case *ast.IntValue:
+		n, _ := strconv.ParseFloat(v.Value, 64)
+		return n
+	case *ast.FloatValue:
+		n, _ := strconv.ParseFloat(v.Value, 64)
+		return n
+	case *ast.ListValue:
+		out := make([]any, 0, len(v.Values))
+		for _, item := range v.Values {
+			out = append(out, parseLiteral(item))
+		}
+
+		return out
+	case *ast.ObjectValue:
+		out := make(map[string]any, len(v.Fields))
+		for _, field := range v.Fields {
+			out[field.Name.Value] = parseLiteral(field.Value)
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
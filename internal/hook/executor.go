@@ -1,6 +1,7 @@
 package hook
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/dop251/goja"
@@ -9,6 +10,7 @@ import (
 )
 
 func applyScript(
+	ctx context.Context,
 	script string,
 	fnName string,
 	entity map[string]any,
@@ -16,7 +18,7 @@ func applyScript(
 ) error {
 	vm := goja.New()
 
-	ctx := map[string]any{
+	scriptCtx := map[string]any{
 		"entity": entity,
 		"query": func(call goja.FunctionCall) goja.Value {
 			if len(call.Arguments) < 2 {
@@ -53,14 +55,14 @@ func applyScript(
 			)
 
 			if !bypassAcl {
-				results = acl.FilterListOfEntities(targetEntity, results)
+				results = acl.FilterListOfEntities(ctx, targetEntity, results)
 			}
 
 			return vm.ToValue(results)
 		},
 	}
 
-	if err := vm.Set("ctx", ctx); err != nil {
+	if err := vm.Set("ctx", scriptCtx); err != nil {
 		return err
 	}
 
@@ -79,17 +81,19 @@ func applyScript(
 }
 
 func ApplyPostReadScript(
+	ctx context.Context,
 	script string,
 	entity map[string]any,
 	bypassAcl bool,
 ) error {
-	return applyScript(script, "postRead", entity, bypassAcl)
+	return applyScript(ctx, script, "postRead", entity, bypassAcl)
 }
 
 func ApplyPreReadScript(
+	ctx context.Context,
 	script string,
 	entity map[string]any,
 	bypassAcl bool,
 ) error {
-	return applyScript(script, "preRead", entity, bypassAcl)
+	return applyScript(ctx, script, "preRead", entity, bypassAcl)
 }
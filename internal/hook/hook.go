@@ -1,11 +1,14 @@
 package hook
 
 import (
+	"context"
 	"sort"
+	"time"
 
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/metrics"
 )
 
 const HookEntity = "_elysiandb_core_hook"
@@ -155,7 +158,14 @@ func CreateHookEntity() error {
 	return nil
 }
 
-func ApplyPostReadHooksForEntity(entity string, data map[string]any) map[string]any {
+var hookDuration = metrics.NewHistogramVec(
+	"elysiandb_hook_duration_seconds",
+	"Time spent executing a hook script.",
+	metrics.DefaultBuckets,
+	"entity", "event",
+)
+
+func ApplyPostReadHooksForEntity(ctx context.Context, entity string, data map[string]any) map[string]any {
 	if !globals.GetConfig().Api.Hooks.Enabled {
 		return data
 	}
@@ -173,15 +183,19 @@ func ApplyPostReadHooksForEntity(entity string, data map[string]any) map[string]
 			continue
 		}
 
-		if err := ApplyPostReadScript(hook.Script, enriched, hook.ByPassACL); err != nil {
-			log.Error("Error applying post-read hook " + hook.ID + ": " + err.Error())
+		start := time.Now()
+		err := ApplyPostReadScript(ctx, hook.Script, enriched, hook.ByPassACL)
+		hookDuration.ObserveSince(start, entity, HookEventPostRead)
+
+		if err != nil {
+			log.WithFields(log.Fields{"hook": hook.ID, "entity": entity, "error": err}).Error("Error applying post-read hook")
 		}
 	}
 
 	return enriched
 }
 
-func ApplyPreReadHooksForEntity(entity string, data map[string]any) map[string]any {
+func ApplyPreReadHooksForEntity(ctx context.Context, entity string, data map[string]any) map[string]any {
 	if !globals.GetConfig().Api.Hooks.Enabled {
 		return data
 	}
@@ -199,8 +213,12 @@ func ApplyPreReadHooksForEntity(entity string, data map[string]any) map[string]a
 			continue
 		}
 
-		if err := ApplyPreReadScript(hook.Script, enriched, hook.ByPassACL); err != nil {
-			log.Error("Error applying pre-read hook " + hook.ID + ": " + err.Error())
+		start := time.Now()
+		err := ApplyPreReadScript(ctx, hook.Script, enriched, hook.ByPassACL)
+		hookDuration.ObserveSince(start, entity, HookEventPreRead)
+
+		if err != nil {
+			log.WithFields(log.Fields{"hook": hook.ID, "entity": entity, "error": err}).Error("Error applying pre-read hook")
 		}
 	}
 
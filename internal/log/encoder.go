@@ -0,0 +1,108 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	reset   = "\033[0m"
+	red     = "\033[31m"
+	green   = "\033[32m"
+	yellow  = "\033[33m"
+	magenta = "\033[35m"
+	cyan    = "\033[36m"
+)
+
+type Encoder interface {
+	Encode(buf *bytes.Buffer, entry Entry)
+}
+
+type ConsoleEncoder struct {
+	Color bool
+}
+
+type JSONEncoder struct{}
+
+func NewEncoder(format string) (Encoder, error) {
+	switch strings.ToLower(format) {
+	case "", "console":
+		return ConsoleEncoder{Color: true}, nil
+	case "json":
+		return JSONEncoder{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+func (e ConsoleEncoder) Encode(buf *bytes.Buffer, entry Entry) {
+	label := strings.ToUpper(entry.Level.String())
+	if e.Color {
+		label = levelColor(entry.Level) + label + reset
+	}
+
+	fmt.Fprintf(buf, "[%s] %s %s", entry.Time.Format("2006-01-02 15:04:05"), label, entry.Message)
+
+	for _, key := range sortedKeys(entry.Fields) {
+		fmt.Fprintf(buf, " %s=%v", key, entry.Fields[key])
+	}
+
+	buf.WriteByte('\n')
+}
+
+func (JSONEncoder) Encode(buf *bytes.Buffer, entry Entry) {
+	data := make(map[string]any, len(entry.Fields)+3)
+	for k, v := range entry.Fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+
+	data["time"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	data["level"] = entry.Level.String()
+	data["msg"] = entry.Message
+
+	line, err := json.Marshal(data)
+	if err != nil {
+		line, _ = json.Marshal(map[string]any{
+			"time":  data["time"],
+			"level": data["level"],
+			"msg":   entry.Message,
+			"error": err.Error(),
+		})
+	}
+
+	buf.Write(line)
+	buf.WriteByte('\n')
+}
+
+func levelColor(level Level) string {
+	switch level {
+	case LevelDebug:
+		return magenta
+	case LevelInfo:
+		return cyan
+	case LevelSuccess:
+		return green
+	case LevelWarn:
+		return yellow
+	}
+
+	return red
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
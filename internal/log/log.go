@@ -7,82 +7,166 @@ import (
 	"time"
 )
 
-const (
-	reset   = "\033[0m"
-	red     = "\033[31m"
-	green   = "\033[32m"
-	yellow  = "\033[33m"
-	magenta = "\033[35m"
-	cyan    = "\033[36m"
-)
+type Fields map[string]any
+
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+}
+
+type Logger struct {
+	fields Fields
+}
 
-type logsContainer struct {
+var (
 	mu       sync.Mutex
-	LogsList []string
+	minLevel = LevelDebug
+	sinks    = []Sink{NewStdoutSink(ConsoleEncoder{Color: true}, true)}
+)
+
+func Configure(level Level, newSinks ...Sink) {
+	mu.Lock()
+	previous := sinks
+	minLevel = level
+	sinks = newSinks
+	mu.Unlock()
+
+	for _, sink := range previous {
+		_ = sink.Close()
+	}
 }
 
-var Logs = logsContainer{}
+func GetLevel() Level {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return minLevel
+}
 
-func Info(args ...interface{}) {
-	go log("INFO", cyan, args...)
+func Enabled(level Level) bool {
+	return level >= GetLevel()
 }
 
-func DirectInfo(args ...interface{}) {
-	directLog("INFO", cyan, args...)
+func With(key string, value any) *Logger {
+	return &Logger{fields: Fields{key: value}}
 }
 
-func DirectError(args ...interface{}) {
-	directLog("ERROR", red, args...)
+func WithFields(fields Fields) *Logger {
+	return (&Logger{}).WithFields(fields)
 }
 
-func Success(args ...interface{}) {
-	go log("SUCCESS", green, args...)
+func (l *Logger) With(key string, value any) *Logger {
+	return l.WithFields(Fields{key: value})
 }
 
-func Warn(args ...interface{}) {
-	go log("WARN", yellow, args...)
+func (l *Logger) WithFields(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Logger{fields: merged}
 }
 
-func Error(args ...interface{}) {
-	go log("ERROR", red, args...)
+func (l *Logger) Debug(args ...any) {
+	l.log(LevelDebug, args...)
 }
 
-func Debug(args ...interface{}) {
-	go log("DEBUG", magenta, args...)
+func (l *Logger) Info(args ...any) {
+	l.log(LevelInfo, args...)
 }
 
-func Fatal(message string, err error) {
-	fmt.Printf("FATAL: %s: %v\n", message, err)
-	os.Exit(1)
+func (l *Logger) Success(args ...any) {
+	l.log(LevelSuccess, args...)
 }
 
-func log(level, color string, args ...interface{}) {
-	now := time.Now().Format("2006-01-02 15:04:05")
-	msg := fmt.Sprint(args...)
+func (l *Logger) Warn(args ...any) {
+	l.log(LevelWarn, args...)
+}
 
-	levelBadge := fmt.Sprintf("%s%s%s", color, level, reset)
+func (l *Logger) Error(args ...any) {
+	l.log(LevelError, args...)
+}
 
-	Logs.mu.Lock()
-	defer Logs.mu.Unlock()
-	Logs.LogsList = append(Logs.LogsList, fmt.Sprintf("[%s] %s %s", now, levelBadge, msg))
+func (l *Logger) log(level Level, args ...any) {
+	write(Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: fmt.Sprint(args...),
+		Fields:  l.fields,
+	})
 }
 
-func directLog(level, color string, args ...interface{}) {
-	now := time.Now().Format("2006-01-02 15:04:05")
-	msg := fmt.Sprint(args...)
+var std = &Logger{}
 
-	levelBadge := fmt.Sprintf("%s%s%s", color, level, reset)
+func Info(args ...any) {
+	std.Info(args...)
+}
 
-	fmt.Printf("[%s] %s %s\n", now, levelBadge, msg)
+func DirectInfo(args ...any) {
+	std.Info(args...)
+	WriteLogs()
+}
+
+func DirectError(args ...any) {
+	std.Error(args...)
+	WriteLogs()
+}
+
+func Success(args ...any) {
+	std.Success(args...)
+}
+
+func Warn(args ...any) {
+	std.Warn(args...)
+}
+
+func Error(args ...any) {
+	std.Error(args...)
+}
+
+func Debug(args ...any) {
+	std.Debug(args...)
+}
+
+func Fatal(message string, err error) {
+	write(Entry{
+		Time:    time.Now(),
+		Level:   LevelFatal,
+		Message: message,
+		Fields:  Fields{"error": err},
+	})
+	WriteLogs()
+	os.Exit(1)
 }
 
 func WriteLogs() {
-	Logs.mu.Lock()
-	logsList := Logs.LogsList
-	Logs.LogsList = Logs.LogsList[:0]
-	Logs.mu.Unlock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "log: flush failed: %v\n", err)
+		}
+	}
+}
+
+func write(entry Entry) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if entry.Level < minLevel {
+		return
+	}
 
-	for _, log := range logsList {
-		fmt.Println(log)
+	for _, sink := range sinks {
+		if err := sink.Write(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "log: write failed: %v\n", err)
+		}
 	}
 }
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	RequestIDHeader = "X-Request-Id"
+
+	maxRequestIDLength = 128
+)
+
+func EnsureRequestID(ctx *fasthttp.RequestCtx) string {
+	if id, ok := ctx.UserValue("request_id").(string); ok && id != "" {
+		return id
+	}
+
+	id := string(ctx.Request.Header.Peek(RequestIDHeader))
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+
+	ctx.SetUserValue("request_id", id)
+	ctx.Response.Header.Set(RequestIDHeader, id)
+
+	return id
+}
+
+func ForRequest(ctx *fasthttp.RequestCtx) *Logger {
+	fields := Fields{"request_id": EnsureRequestID(ctx)}
+
+	if username, ok := ctx.UserValue("username").(string); ok && username != "" {
+		fields["user"] = username
+	}
+
+	if entity, ok := ctx.UserValue("entity").(string); ok && entity != "" {
+		fields["entity"] = entity
+	}
+
+	return WithFields(fields)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+type Sink interface {
+	Write(entry Entry) error
+	Flush() error
+	Close() error
+}
+
+type StreamSink struct {
+	mu       sync.Mutex
+	encoder  Encoder
+	output   func() io.Writer
+	buffered bool
+	buf      bytes.Buffer
+}
+
+func NewStreamSink(encoder Encoder, output func() io.Writer, buffered bool) *StreamSink {
+	return &StreamSink{encoder: encoder, output: output, buffered: buffered}
+}
+
+func NewStdoutSink(encoder Encoder, buffered bool) *StreamSink {
+	return NewStreamSink(encoder, func() io.Writer { return os.Stdout }, buffered)
+}
+
+func (s *StreamSink) Write(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.encoder.Encode(&s.buf, entry)
+	if s.buffered {
+		return nil
+	}
+
+	return s.flushLocked()
+}
+
+func (s *StreamSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flushLocked()
+}
+
+func (s *StreamSink) Close() error {
+	return s.Flush()
+}
+
+func (s *StreamSink) flushLocked() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := s.output().Write(s.buf.Bytes())
+	s.buf.Reset()
+
+	return err
+}
@@ -0,0 +1,382 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	describe() (name, help, kind string)
+	write(w *bufio.Writer)
+}
+
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+var (
+	Default = NewRegistry()
+	enabled atomic.Bool
+)
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func SetEnabled(v bool) {
+	enabled.Store(v)
+}
+
+func Enabled() bool {
+	return enabled.Load()
+}
+
+func (r *Registry) register(c collector) {
+	name, _, _ := c.describe()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.collectors[name]; exists {
+		panic("metrics: duplicate metric " + name)
+	}
+
+	r.collectors[name] = c
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	collectors := make([]collector, len(names))
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		name, help, kind := c.describe()
+		fmt.Fprintf(bw, "# HELP %s %s\n", name, help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, kind)
+		c.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+type series[T any] struct {
+	mu     sync.RWMutex
+	values map[string]*T
+	labels map[string][]string
+}
+
+func newSeries[T any]() series[T] {
+	return series[T]{values: make(map[string]*T), labels: make(map[string][]string)}
+}
+
+func (s *series[T]) get(labelValues []string, create func() *T) *T {
+	key := strings.Join(labelValues, "\xff")
+
+	s.mu.RLock()
+	v, ok := s.values[key]
+	s.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, ok := s.values[key]; ok {
+		return v
+	}
+
+	v = create()
+	s.values[key] = v
+	s.labels[key] = append([]string(nil), labelValues...)
+
+	return v
+}
+
+func (s *series[T]) lookup(labelValues []string) (*T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.values[strings.Join(labelValues, "\xff")]
+
+	return v, ok
+}
+
+func (s *series[T]) each(fn func(labelValues []string, v *T)) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s.mu.RLock()
+		v, labels := s.values[key], s.labels[key]
+		s.mu.RUnlock()
+
+		if v != nil {
+			fn(labels, v)
+		}
+	}
+}
+
+func (s *series[T]) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = make(map[string]*T)
+	s.labels = make(map[string][]string)
+}
+
+type CounterVec struct {
+	desc
+	series series[atomic.Uint64]
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name, help, labels}, series: newSeries[atomic.Uint64]()}
+	Default.register(c)
+
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(n uint64, labelValues ...string) {
+	if !Enabled() {
+		return
+	}
+
+	c.series.get(labelValues, func() *atomic.Uint64 { return &atomic.Uint64{} }).Add(n)
+}
+
+func (c *CounterVec) Value(labelValues ...string) uint64 {
+	if v, ok := c.series.lookup(labelValues); ok {
+		return v.Load()
+	}
+
+	return 0
+}
+
+func (c *CounterVec) Each(fn func(labelValues []string, value uint64)) {
+	c.series.each(func(labelValues []string, v *atomic.Uint64) {
+		fn(labelValues, v.Load())
+	})
+}
+
+func (c *CounterVec) Reset() {
+	c.series.reset()
+}
+
+func (c *CounterVec) describe() (string, string, string) {
+	return c.name, c.help, "counter"
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.Each(func(labelValues []string, value uint64) {
+		fmt.Fprintf(w, "%s%s %d\n", c.name, formatLabels(c.labels, labelValues), value)
+	})
+}
+
+type histogramValue struct {
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type HistogramVec struct {
+	desc
+	buckets []float64
+	series  series[histogramValue]
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		desc:    desc{name, help, labels},
+		buckets: buckets,
+		series:  newSeries[histogramValue](),
+	}
+	Default.register(h)
+
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if !Enabled() {
+		return
+	}
+
+	v := h.series.get(labelValues, func() *histogramValue {
+		return &histogramValue{counts: make([]uint64, len(h.buckets))}
+	})
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			v.counts[i]++
+		}
+	}
+	v.count++
+	v.sum += value
+}
+
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	v, ok := h.series.lookup(labelValues)
+	if !ok {
+		return 0
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.count
+}
+
+func (h *HistogramVec) Reset() {
+	h.series.reset()
+}
+
+func (h *HistogramVec) describe() (string, string, string) {
+	return h.name, h.help, "histogram"
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	labels := append(append([]string(nil), h.labels...), "le")
+
+	h.series.each(func(labelValues []string, v *histogramValue) {
+		v.mu.Lock()
+		counts := append([]uint64(nil), v.counts...)
+		count, sum := v.count, v.sum
+		v.mu.Unlock()
+
+		values := append(append([]string(nil), labelValues...), "")
+		for i, bound := range h.buckets {
+			values[len(values)-1] = formatFloat(bound)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), counts[i])
+		}
+		values[len(values)-1] = "+Inf"
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), count)
+
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, labelValues), formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, labelValues), count)
+	})
+}
+
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+type FuncCollector struct {
+	desc
+	kind string
+	fn   func() []Sample
+}
+
+func NewGaugeFunc(name, help string, fn func() float64) *FuncCollector {
+	return NewGaugeVecFunc(name, help, func() []Sample {
+		return []Sample{{Value: fn()}}
+	})
+}
+
+func NewGaugeVecFunc(name, help string, fn func() []Sample, labels ...string) *FuncCollector {
+	return newFuncCollector(name, help, "gauge", fn, labels)
+}
+
+func NewCounterFunc(name, help string, fn func() float64) *FuncCollector {
+	return newFuncCollector(name, help, "counter", func() []Sample {
+		return []Sample{{Value: fn()}}
+	}, nil)
+}
+
+func newFuncCollector(name, help, kind string, fn func() []Sample, labels []string) *FuncCollector {
+	f := &FuncCollector{desc: desc{name, help, labels}, kind: kind, fn: fn}
+	Default.register(f)
+
+	return f
+}
+
+func (f *FuncCollector) describe() (string, string, string) {
+	return f.name, f.help, f.kind
+}
+
+func (f *FuncCollector) write(w *bufio.Writer) {
+	for _, sample := range f.fn() {
+		fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(f.labels, sample.LabelValues), formatFloat(sample.Value))
+	}
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
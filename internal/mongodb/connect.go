@@ -1,4 +1,4 @@
-package boot
+package mongodb
 
 import (
 	"context"
@@ -9,19 +9,33 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-func InitMongoDBConnection() {
+func Connect() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(options.Client().ApplyURI(globals.GetConfig().Engine.URI))
+	client, err := mongo.Connect(options.Client().ApplyURI(globals.GetConfig().Engine.URI).SetMonitor(CommandMonitor()))
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	if err := client.Ping(ctx, nil); err != nil {
-		panic(err)
+		return err
 	}
 
 	globals.MongoClient = client
 	globals.MongoDB = client.Database("elysiandb")
+
+	return nil
+}
+
+func Disconnect() error {
+	if globals.MongoClient == nil {
+		return nil
+	}
+
+	err := globals.MongoClient.Disconnect(context.Background())
+	globals.MongoClient = nil
+	globals.MongoDB = nil
+
+	return err
 }
@@ -48,8 +48,8 @@ func ParseIncludes(includesParam string) (bool, [][]string) {
 	return false, out
 }
 
-func BuildSpecsFromSample(entity string, includeAll bool, paths [][]string) []IncludeSpec {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func BuildSpecsFromSample(ctx context.Context, entity string, includeAll bool, paths [][]string) []IncludeSpec {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var sample map[string]any
@@ -358,12 +358,12 @@ func CollectAllRefMaps(root any, out *[]struct {
 	}
 }
 
-func LoadDocsByIds(entity string, ids []string) map[string]map[string]any {
+func LoadDocsByIds(ctx context.Context, entity string, ids []string) map[string]map[string]any {
 	if len(ids) == 0 {
 		return map[string]map[string]any{}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 
 	defer cancel()
 
@@ -416,7 +416,7 @@ func ApplyLoadedRefs(refs []struct {
 	}
 }
 
-func ResolveIncludesPaths(items []map[string]any, paths [][]string) {
+func ResolveIncludesPaths(ctx context.Context, items []map[string]any, paths [][]string) {
 	if len(paths) == 0 || len(items) == 0 {
 		return
 	}
@@ -454,7 +454,7 @@ func ResolveIncludesPaths(items []map[string]any, paths [][]string) {
 				ids = append(ids, id)
 			}
 
-			loaded[ent] = LoadDocsByIds(ent, ids)
+			loaded[ent] = LoadDocsByIds(ctx, ent, ids)
 		}
 
 		ApplyLoadedRefs(refs, loaded)
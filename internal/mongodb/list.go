@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/tracing"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
@@ -17,19 +19,51 @@ func ListEntities(
 	search string,
 	includesParam string,
 ) []map[string]any {
-	ctx := context.Background()
+	return ListEntitiesWithFilterNode(entity, limit, offset, sortField, sortAscending, filters, search, includesParam, query.FilterNode{})
+}
+
+func ListEntitiesWithFilterNode(
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return ListEntitiesWithFilterNodeContext(context.Background(), entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
+
+func ListEntitiesWithFilterNodeContext(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
 	q := BuildMongoFilters(filters)
 
+	if !node.IsEmpty() {
+		q = bson.M{"$and": []bson.M{q, BuildMongoExpr(node)}}
+	}
+
 	includeAll, paths := ParseIncludes(includesParam)
 
-	rootSpecs := BuildSpecsFromSample(entity, includeAll, paths)
+	rootSpecs := BuildSpecsFromSample(ctx, entity, includeAll, paths)
 
 	leafPaths := ExtractLeafIncludePaths(paths)
 
 	if len(rootSpecs) == 0 {
 		out := FindEntitiesSimple(ctx, entity, q, limit, offset, sortField, sortAscending)
 
-		ResolveLeafIncludes(out, includeAll, leafPaths)
+		resolveLeafIncludesTraced(ctx, out, includeAll, leafPaths)
 
 		return out
 	}
@@ -47,11 +81,22 @@ func ListEntities(
 
 	out = AddIncludeEntityTags(out, rootSpecs)
 
-	ResolveLeafIncludes(out, includeAll, leafPaths)
+	resolveLeafIncludesTraced(ctx, out, includeAll, leafPaths)
 
 	return out
 }
 
+func resolveLeafIncludesTraced(ctx context.Context, items []map[string]any, includeAll bool, leafPaths [][]string) {
+	if !includeAll && len(leafPaths) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "ApplyIncludes")
+	defer span.End()
+
+	ResolveLeafIncludes(ctx, items, includeAll, leafPaths)
+}
+
 func ExtractLeafIncludePaths(paths [][]string) [][]string {
 	out := make([][]string, 0)
 
@@ -64,15 +109,15 @@ func ExtractLeafIncludePaths(paths [][]string) [][]string {
 	return out
 }
 
-func ResolveLeafIncludes(items []map[string]any, includeAll bool, leafPaths [][]string) {
+func ResolveLeafIncludes(ctx context.Context, items []map[string]any, includeAll bool, leafPaths [][]string) {
 	if includeAll {
-		ResolveIncludesAllRecursive(items, 8)
+		ResolveIncludesAllRecursive(ctx, items, 8)
 
 		return
 	}
 
 	if len(leafPaths) > 0 {
-		ResolveIncludesPaths(items, leafPaths)
+		ResolveIncludesPaths(ctx, items, leafPaths)
 	}
 }
 
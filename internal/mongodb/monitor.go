@@ -0,0 +1,60 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/metrics"
+	"github.com/taymour/elysiandb/internal/tracing"
+	"go.mongodb.org/mongo-driver/v2/event"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var commandDuration = metrics.NewHistogramVec(
+	"elysiandb_mongodb_command_duration_seconds",
+	"Latency of MongoDB commands issued by the engine.",
+	metrics.DefaultBuckets,
+	"command", "outcome",
+)
+
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+var commandSpans sync.Map
+
+func CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if !tracing.Enabled() || !trace.SpanContextFromContext(ctx).IsValid() {
+				return
+			}
+
+			_, span := tracing.Tracer().Start(ctx, "mongodb."+e.CommandName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+				semconv.DBSystemNameMongoDB,
+				semconv.DBNamespace(e.DatabaseName),
+				semconv.DBOperationName(e.CommandName),
+			))
+			commandSpans.Store(commandKey{e.ConnectionID, e.RequestID}, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			commandDuration.Observe(e.Duration.Seconds(), e.CommandName, "success")
+			endCommandSpan(e.CommandFinishedEvent, nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			commandDuration.Observe(e.Duration.Seconds(), e.CommandName, "failure")
+			endCommandSpan(e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
+
+func endCommandSpan(e event.CommandFinishedEvent, err error) {
+	value, ok := commandSpans.LoadAndDelete(commandKey{e.ConnectionID, e.RequestID})
+	if !ok {
+		return
+	}
+
+	tracing.EndWithError(value.(trace.Span), err)
+}
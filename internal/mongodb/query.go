@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	api_storage "github.com/taymour/elysiandb/internal/api"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/query"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -47,7 +48,7 @@ func ListEntitiesWithExpr(
 	ctx := context.Background()
 
 	includeAll, paths := ParseIncludes(includesParam)
-	rootSpecs := BuildSpecsFromSample(entity, includeAll, paths)
+	rootSpecs := BuildSpecsFromSample(ctx, entity, includeAll, paths)
 
 	leafPaths := make([][]string, 0)
 	for _, p := range paths {
@@ -74,9 +75,9 @@ func ListEntitiesWithExpr(
 		}
 
 		if includeAll {
-			ResolveIncludesAllRecursive(out, 8)
+			ResolveIncludesAllRecursive(ctx, out, 8)
 		} else if len(leafPaths) > 0 {
-			ResolveIncludesPaths(out, leafPaths)
+			ResolveIncludesPaths(ctx, out, leafPaths)
 		}
 
 		return out
@@ -166,9 +167,9 @@ func ListEntitiesWithExpr(
 	out = AddIncludeEntityTags(out, rootSpecs)
 
 	if includeAll {
-		ResolveIncludesAllRecursive(out, 8)
+		ResolveIncludesAllRecursive(ctx, out, 8)
 	} else if len(leafPaths) > 0 {
-		ResolveIncludesPaths(out, leafPaths)
+		ResolveIncludesPaths(ctx, out, leafPaths)
 	}
 
 	return out
@@ -298,3 +299,7 @@ func BuildMongoExpr(node query.FilterNode) bson.M {
 
 	return bson.M{}
 }
+
+func MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return api_storage.MatchFilterNode(node, entity)
+}
@@ -62,7 +62,7 @@ func FindOptions(limit int, offset int, sortField string, sortAscending bool) op
 	return opts
 }
 
-func ResolveIncludesAllRecursive(items []map[string]any, maxDepth int) {
+func ResolveIncludesAllRecursive(ctx context.Context, items []map[string]any, maxDepth int) {
 	if len(items) == 0 || maxDepth <= 0 {
 		return
 	}
@@ -98,7 +98,7 @@ func ResolveIncludesAllRecursive(items []map[string]any, maxDepth int) {
 			for id := range set {
 				ids = append(ids, id)
 			}
-			loaded[ent] = LoadDocsByIds(ent, ids)
+			loaded[ent] = LoadDocsByIds(ctx, ent, ids)
 		}
 
 		ApplyLoadedRefs(refs, loaded)
@@ -243,11 +243,15 @@ func ReadEntityById(entity, id string) map[string]any {
 	return NormalizeMongoDocument(raw)
 }
 
+func ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	return LoadDocsByIds(context.Background(), entity, ids)
+}
+
 func ApplyFiltersToList(entities []map[string]any, filters map[string]map[string]string) []map[string]any {
 	return entities
 }
 
-func GetListOfIds(entity, sortField string, sortAscending bool) ([]byte, error) {
+func GetListOfIds(entity, sortField string, sortAscending bool) []string {
 	return api_storage.GetListOfIds(entity, sortField, sortAscending)
 }
 
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+)
+
+const Version = "3.0.3"
+
+type source struct {
+	Entities map[string]map[string]any `json:"entities"`
+	AuthMode string                    `json:"authMode"`
+}
+
+var (
+	mu          sync.Mutex
+	cached      []byte
+	fingerprint uint64
+	generated   bool
+)
+
+func Spec() ([]byte, error) {
+	src := loadSource()
+
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(raw)
+	sum := h.Sum64()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if generated && sum == fingerprint {
+		return cached, nil
+	}
+
+	data, err := json.Marshal(build(src))
+	if err != nil {
+		return nil, err
+	}
+
+	cached = data
+	fingerprint = sum
+	generated = true
+
+	return cached, nil
+}
+
+func Generate() map[string]any {
+	return build(loadSource())
+}
+
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cached = nil
+	fingerprint = 0
+	generated = false
+}
+
+func loadSource() source {
+	src := source{Entities: map[string]map[string]any{}}
+
+	types := engine.ListPublicEntityTypes()
+	sort.Strings(types)
+
+	for _, entity := range types {
+		src.Entities[entity] = engine.GetEntitySchema(entity)
+	}
+
+	if cfg := globals.GetConfig(); cfg != nil && cfg.Security.Authentication.Enabled {
+		src.AuthMode = cfg.Security.Authentication.Mode
+	}
+
+	return src
+}
+
+func build(src source) map[string]any {
+	entities := make([]string, 0, len(src.Entities))
+	for entity := range src.Entities {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	schemas := baseSchemas()
+	paths := sharedPaths()
+
+	for _, entity := range entities {
+		schemas[schemaName(entity)] = entitySchema(src.Entities[entity])
+		schemas[schemaName(entity)+"Input"] = entityInputSchema(src.Entities[entity])
+
+		for path, item := range entityPaths(entity) {
+			paths[path] = item
+		}
+	}
+
+	components := map[string]any{
+		"schemas":    schemas,
+		"parameters": sharedParameters(),
+	}
+
+	doc := map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":       "ElysianDB",
+			"version":     globals.VERSION,
+			"description": "REST API generated from the entity schemas stored in ElysianDB.",
+		},
+		"paths":      paths,
+		"components": components,
+	}
+
+	if scheme, ok := securityScheme(src.AuthMode); ok {
+		components["securitySchemes"] = map[string]any{securitySchemeName: scheme}
+		doc["security"] = []any{map[string]any{securitySchemeName: []string{}}}
+	}
+
+	return doc
+}
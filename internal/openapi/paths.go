@@ -0,0 +1,327 @@
+package openapi
+
+func paramRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/parameters/" + name}
+}
+
+func pathParam(name, description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          "path",
+		"required":    true,
+		"description": description,
+		"schema":      schema,
+	}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{"description": description, "content": jsonContent(schema)}
+}
+
+func emptyResponse(description string) map[string]any {
+	return map[string]any{"description": description}
+}
+
+func jsonBody(schema map[string]any) map[string]any {
+	return map[string]any{"required": true, "content": jsonContent(schema)}
+}
+
+func arrayOf(schema map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": schema}
+}
+
+func oneOf(schemas ...map[string]any) map[string]any {
+	return map[string]any{"oneOf": schemas}
+}
+
+func operation(id, summary string, tags []string, params []any, body map[string]any, responses map[string]any) map[string]any {
+	op := map[string]any{
+		"operationId": id,
+		"summary":     summary,
+		"tags":        tags,
+		"responses":   responses,
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if body != nil {
+		op["requestBody"] = body
+	}
+
+	return op
+}
+
+func errorResponse(description string) map[string]any {
+	return jsonResponse(description, schemaRef("Error"))
+}
+
+func sharedParameters() map[string]any {
+	return map[string]any{
+		"limit": map[string]any{
+			"name": "limit", "in": "query",
+			"description": "Maximum number of entities to return (0 means no limit)",
+			"schema":      map[string]any{"type": "integer", "minimum": 0},
+		},
+		"offset": map[string]any{
+			"name": "offset", "in": "query",
+			"description": "Number of entities to skip",
+			"schema":      map[string]any{"type": "integer", "minimum": 0},
+		},
+		"sort": map[string]any{
+			"name": "sort", "in": "query", "style": "deepObject", "explode": true,
+			"description": "Sort by a field, e.g. sort[title]=asc",
+			"schema": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string", "enum": []string{"asc", "desc"}},
+			},
+		},
+		"filter": map[string]any{
+			"name": "filter", "in": "query", "style": "deepObject", "explode": true,
+			"description": "Filter by field and operator, e.g. filter[title][eq]=foo. " +
+				"Operators: eq, neq, lt, lte, gt, gte, contains, not_contains, all, any, none",
+			"schema": map[string]any{
+				"type": "object",
+				"additionalProperties": map[string]any{
+					"oneOf": []any{
+						map[string]any{"type": "string"},
+						map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+		"search": map[string]any{
+			"name": "search", "in": "query",
+			"description": "Full-text search across string fields",
+			"schema":      map[string]any{"type": "string"},
+		},
+		"fields": map[string]any{
+			"name": "fields", "in": "query",
+			"description": "Comma separated list of fields to return",
+			"schema":      map[string]any{"type": "string"},
+		},
+		"includes": map[string]any{
+			"name": "includes", "in": "query",
+			"description": "Comma separated list of relations to resolve, e.g. author,author.job",
+			"schema":      map[string]any{"type": "string"},
+		},
+		"countOnly": map[string]any{
+			"name": "countOnly", "in": "query",
+			"description": "Return only the number of matching entities",
+			"schema":      map[string]any{"type": "boolean"},
+		},
+	}
+}
+
+func entityPaths(entity string) map[string]any {
+	name := schemaName(entity)
+	tags := []string{entity}
+	ref := schemaRef(name)
+	inputRef := schemaRef(name + "Input")
+	idParam := pathParam("id", "Entity identifier", map[string]any{"type": "string"})
+	validation := jsonResponse("Invalid payload or schema validation failure", oneOf(schemaRef("ValidationError"), schemaRef("Error")))
+
+	return map[string]any{
+		"/api/" + entity: map[string]any{
+			"get": operation("list_"+name, "List "+entity, tags,
+				[]any{
+					paramRef("limit"), paramRef("offset"), paramRef("sort"), paramRef("filter"),
+					paramRef("search"), paramRef("fields"), paramRef("includes"), paramRef("countOnly"),
+				},
+				nil,
+				map[string]any{
+					"200": jsonResponse("Matching entities, or their count when countOnly is set", oneOf(arrayOf(ref), schemaRef("Count"))),
+				},
+			),
+			"post": operation("create_"+name, "Create one or many "+entity, tags, nil,
+				jsonBody(oneOf(inputRef, arrayOf(inputRef))),
+				map[string]any{
+					"200": jsonResponse("Created entities", oneOf(ref, arrayOf(ref))),
+					"400": validation,
+					"403": errorResponse("Forbidden"),
+				},
+			),
+			"put": operation("update_list_"+name, "Update many "+entity+" by id", tags, nil,
+				jsonBody(arrayOf(inputRef)),
+				map[string]any{
+					"200": jsonResponse("Updated entities", arrayOf(ref)),
+					"400": validation,
+					"403": errorResponse("Forbidden"),
+				},
+			),
+			"delete": operation("destroy_"+name, "Delete every "+entity, tags, nil, nil,
+				map[string]any{
+					"204": emptyResponse("Entities deleted"),
+					"403": errorResponse("Only admin users can destroy entities"),
+				},
+			),
+		},
+		"/api/" + entity + "/{id}": map[string]any{
+			"get": operation("get_"+name, "Get a "+entity+" by id", tags,
+				[]any{idParam, paramRef("fields"), paramRef("includes")},
+				nil,
+				map[string]any{
+					"200": jsonResponse("The entity", ref),
+					"404": emptyResponse("Entity not found"),
+				},
+			),
+			"put": operation("update_"+name, "Update a "+entity+" by id", tags,
+				[]any{idParam},
+				jsonBody(inputRef),
+				map[string]any{
+					"200": jsonResponse("Updated entity", ref),
+					"400": validation,
+					"403": errorResponse("Forbidden"),
+					"404": emptyResponse("Entity not found"),
+				},
+			),
+			"delete": operation("delete_"+name, "Delete a "+entity+" by id", tags,
+				[]any{idParam}, nil,
+				map[string]any{
+					"204": emptyResponse("Entity deleted"),
+					"403": emptyResponse("Forbidden"),
+					"404": emptyResponse("Entity not found"),
+				},
+			),
+		},
+		"/api/" + entity + "/count": map[string]any{
+			"get": operation("count_"+name, "Count "+entity, tags, nil, nil,
+				map[string]any{"200": jsonResponse("Number of readable entities", schemaRef("Count"))},
+			),
+		},
+		"/api/" + entity + "/{id}/exists": map[string]any{
+			"get": operation("exists_"+name, "Check whether a "+entity+" exists", tags,
+				[]any{idParam}, nil,
+				map[string]any{"200": jsonResponse("Existence flag", schemaRef("Exists"))},
+			),
+		},
+	}
+}
+
+func sharedPaths() map[string]any {
+	queryTags := []string{"query"}
+	txTags := []string{"transactions"}
+	kvTags := []string{"kv"}
+
+	txParam := pathParam("txId", "Transaction identifier", map[string]any{"type": "string"})
+	entityParam := pathParam("entity", "Entity type", map[string]any{"type": "string"})
+	idParam := pathParam("id", "Entity identifier", map[string]any{"type": "string"})
+	keyParam := pathParam("key", "Key, wildcards (*) are supported on reads", map[string]any{"type": "string"})
+	object := map[string]any{"type": "object", "additionalProperties": true}
+
+	return map[string]any{
+		"/api/query": map[string]any{
+			"post": operation("query", "Run a structured query", queryTags, nil,
+				jsonBody(schemaRef("Query")),
+				map[string]any{
+					"200": jsonResponse("Matching entities, or their count when countOnly is set", oneOf(arrayOf(object), schemaRef("Count"))),
+					"400": errorResponse("Invalid query"),
+				},
+			),
+		},
+		"/api/tx/begin": map[string]any{
+			"post": operation("tx_begin", "Begin a transaction", txTags, nil, nil,
+				map[string]any{"200": jsonResponse("Transaction opened", schemaRef("Transaction"))},
+			),
+		},
+		"/api/tx/{txId}/entity/{entity}": map[string]any{
+			"post": operation("tx_write", "Stage an entity write", txTags,
+				[]any{txParam, entityParam},
+				jsonBody(object),
+				map[string]any{
+					"200": emptyResponse("Write staged"),
+					"400": errorResponse("Invalid payload, unknown entity or transaction"),
+				},
+			),
+		},
+		"/api/tx/{txId}/entity/{entity}/{id}": map[string]any{
+			"put": operation("tx_update", "Stage an entity update", txTags,
+				[]any{txParam, entityParam, idParam},
+				jsonBody(object),
+				map[string]any{
+					"200": emptyResponse("Update staged"),
+					"400": errorResponse("Invalid payload, unknown entity or transaction"),
+				},
+			),
+			"delete": operation("tx_delete", "Stage an entity deletion", txTags,
+				[]any{txParam, entityParam, idParam}, nil,
+				map[string]any{
+					"200": emptyResponse("Deletion staged"),
+					"400": errorResponse("Unknown transaction"),
+				},
+			),
+		},
+		"/api/tx/{txId}/commit": map[string]any{
+			"post": operation("tx_commit", "Commit a transaction", txTags,
+				[]any{txParam}, nil,
+				map[string]any{
+					"200": emptyResponse("Transaction committed"),
+					"400": errorResponse("Commit failed"),
+				},
+			),
+		},
+		"/api/tx/{txId}/rollback": map[string]any{
+			"post": operation("tx_rollback", "Roll back a transaction", txTags,
+				[]any{txParam}, nil,
+				map[string]any{
+					"200": emptyResponse("Transaction rolled back"),
+					"500": errorResponse("Rollback failed"),
+				},
+			),
+		},
+		"/kv/{key}": map[string]any{
+			"get": operation("kv_get", "Read a key", kvTags,
+				[]any{keyParam}, nil,
+				map[string]any{
+					"200": jsonResponse("Key value, or every match for a wildcard key", oneOf(schemaRef("KeyValue"), arrayOf(schemaRef("KeyValue")))),
+					"404": jsonResponse("Key not found", schemaRef("KeyValue")),
+				},
+			),
+			"put": operation("kv_put", "Write a key", kvTags,
+				[]any{keyParam, map[string]any{
+					"name": "ttl", "in": "query",
+					"description": "Expiration in seconds",
+					"schema":      map[string]any{"type": "integer", "minimum": 0},
+				}},
+				map[string]any{
+					"required": true,
+					"content":  map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}},
+				},
+				map[string]any{
+					"204": emptyResponse("Key stored"),
+					"400": emptyResponse("Key could not be stored"),
+				},
+			),
+			"delete": operation("kv_delete", "Delete a key", kvTags,
+				[]any{keyParam}, nil,
+				map[string]any{"204": emptyResponse("Key deleted")},
+			),
+		},
+		"/kv/mget": map[string]any{
+			"get": operation("kv_mget", "Read several keys", kvTags,
+				[]any{map[string]any{
+					"name": "keys", "in": "query", "required": true,
+					"description": "Comma separated list of keys",
+					"schema":      map[string]any{"type": "string"},
+				}},
+				nil,
+				map[string]any{"200": jsonResponse("Values for each key", arrayOf(schemaRef("KeyValue")))},
+			),
+		},
+		"/save": map[string]any{
+			"post": operation("save", "Flush data to disk", kvTags, nil, nil,
+				map[string]any{"204": emptyResponse("Data saved")},
+			),
+		},
+		"/reset": map[string]any{
+			"post": operation("reset", "Remove every key", kvTags, nil, nil,
+				map[string]any{"200": emptyResponse("Store reset")},
+			),
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+func schemaName(entity string) string {
+	var b strings.Builder
+	for _, r := range entity {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func entityFields(schemaData map[string]any) map[string]schema.Field {
+	if schemaData == nil {
+		return nil
+	}
+
+	raw, ok := schemaData["fields"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	return schema.MapToFields(raw)
+}
+
+func entitySchema(schemaData map[string]any) map[string]any {
+	out := objectSchema(entityFields(schemaData))
+	out["properties"].(map[string]any)["id"] = map[string]any{"type": "string"}
+	out["required"] = append([]string{"id"}, requiredNames(entityFields(schemaData))...)
+
+	return out
+}
+
+func entityInputSchema(schemaData map[string]any) map[string]any {
+	out := objectSchema(entityFields(schemaData))
+	out["properties"].(map[string]any)["id"] = map[string]any{
+		"type":        "string",
+		"description": "Generated when omitted",
+	}
+
+	return out
+}
+
+func objectSchema(fields map[string]schema.Field) map[string]any {
+	properties := make(map[string]any, len(fields))
+	for name, f := range fields {
+		properties[name] = fieldSchema(f)
+	}
+
+	out := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if required := requiredNames(fields); len(required) > 0 {
+		out["required"] = required
+	}
+
+	return out
+}
+
+func fieldSchema(f schema.Field) map[string]any {
+	switch f.Type {
+	case "string", "number", "boolean":
+		return map[string]any{"type": f.Type}
+	case "object":
+		return objectSchema(f.Fields)
+	case "array":
+		items := map[string]any{}
+		if len(f.Fields) > 0 {
+			items = objectSchema(f.Fields)
+		}
+
+		return map[string]any{"type": "array", "items": items}
+	default:
+		return map[string]any{}
+	}
+}
+
+func requiredNames(fields map[string]schema.Field) []string {
+	names := make([]string, 0)
+	for name, f := range fields {
+		if f.Required {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func baseSchemas() map[string]any {
+	return map[string]any{
+		"Error": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"error": map[string]any{"type": "string"}},
+			"required":   []string{"error"},
+		},
+		"ValidationError": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"Field":   map[string]any{"type": "string"},
+					"Message": map[string]any{"type": "string"},
+				},
+			},
+		},
+		"Count": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"count": map[string]any{"type": "integer"}},
+			"required":   []string{"count"},
+		},
+		"Exists": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"exists": map[string]any{"type": "boolean"}},
+			"required":   []string{"exists"},
+		},
+		"Query": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"entity":    map[string]any{"type": "string"},
+				"offset":    map[string]any{"type": "integer"},
+				"limit":     map[string]any{"type": "integer"},
+				"filters":   map[string]any{"type": "object", "additionalProperties": true},
+				"sorts":     map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string", "enum": []string{"asc", "desc"}}},
+				"countOnly": map[string]any{"type": "boolean"},
+				"fields":    map[string]any{"type": "string"},
+			},
+			"required": []string{"entity"},
+		},
+		"Transaction": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"transaction_id": map[string]any{"type": "string"}},
+			"required":   []string{"transaction_id"},
+		},
+		"KeyValue": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"key":   map[string]any{"type": "string"},
+				"value": map[string]any{"type": "string", "nullable": true},
+			},
+			"required": []string{"key", "value"},
+		},
+	}
+}
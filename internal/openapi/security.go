@@ -0,0 +1,38 @@
+package openapi
+
+import (
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+const securitySchemeName = "elysiandb"
+
+func securityScheme(mode string) (map[string]any, bool) {
+	switch mode {
+	case "basic":
+		return map[string]any{
+			"type":   "http",
+			"scheme": "basic",
+		}, true
+	case "token":
+		return map[string]any{
+			"type":        "http",
+			"scheme":      "bearer",
+			"description": "Static token configured in security.authentication.token",
+		}, true
+	case "jwt":
+		return map[string]any{
+			"type":         "http",
+			"scheme":       "bearer",
+			"bearerFormat": "JWT",
+		}, true
+	case "user":
+		return map[string]any{
+			"type":        "apiKey",
+			"in":          "cookie",
+			"name":        security.SessionCookieName,
+			"description": "Session cookie returned by POST /api/security/login. Unsafe methods also require the " + security.CSRFHeaderName + " header.",
+		}, true
+	default:
+		return nil, false
+	}
+}
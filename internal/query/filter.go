@@ -0,0 +1,212 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const UserPlaceholderPrefix = "$user."
+
+func (n FilterNode) IsEmpty() bool {
+	return len(n.Leaf) == 0 && len(n.And) == 0 && len(n.Or) == 0
+}
+
+func (n FilterNode) Fields() []string {
+	fields := LeafFields(n.Leaf)
+	for _, child := range n.And {
+		fields = append(fields, child.Fields()...)
+	}
+
+	for _, child := range n.Or {
+		fields = append(fields, child.Fields()...)
+	}
+
+	return fields
+}
+
+func LeafFields(leaf map[string]map[string]string) []string {
+	fields := make([]string, 0, len(leaf))
+	for field := range leaf {
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func MatchAll() FilterNode {
+	return FilterNode{Leaf: map[string]map[string]string{}}
+}
+
+func And(nodes ...FilterNode) FilterNode {
+	kept := make([]FilterNode, 0, len(nodes))
+	for _, n := range nodes {
+		if !n.IsEmpty() {
+			kept = append(kept, n)
+		}
+	}
+
+	switch len(kept) {
+	case 0:
+		return MatchAll()
+	case 1:
+		return kept[0]
+	}
+
+	return FilterNode{And: kept}
+}
+
+func ParseFilterNode(raw map[string]any) (FilterNode, error) {
+	if raw == nil {
+		return FilterNode{}, nil
+	}
+
+	if orRaw, ok := raw["or"]; ok {
+		nodes, err := parseFilterNodes("or", orRaw)
+		if err != nil {
+			return FilterNode{}, err
+		}
+
+		return FilterNode{Or: nodes}, nil
+	}
+
+	if andRaw, ok := raw["and"]; ok {
+		nodes, err := parseFilterNodes("and", andRaw)
+		if err != nil {
+			return FilterNode{}, err
+		}
+
+		return FilterNode{And: nodes}, nil
+	}
+
+	leaf := make(map[string]map[string]string)
+	for field, v := range raw {
+		opsRaw, ok := v.(map[string]any)
+		if !ok {
+			return FilterNode{}, fmt.Errorf("invalid filter for field %s", field)
+		}
+
+		ops := make(map[string]string)
+		for op, val := range opsRaw {
+			s, ok := val.(string)
+			if !ok {
+				return FilterNode{}, fmt.Errorf("invalid value for %s.%s", field, op)
+			}
+
+			ops[op] = s
+		}
+
+		leaf[field] = ops
+	}
+
+	return FilterNode{Leaf: leaf}, nil
+}
+
+func parseFilterNodes(kind string, raw any) ([]FilterNode, error) {
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", kind)
+	}
+
+	nodes := make([]FilterNode, 0, len(arr))
+	for _, item := range arr {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s item must be an object", kind)
+		}
+
+		n, err := ParseFilterNode(m)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
+}
+
+func ResolveUserPlaceholders(node FilterNode, user map[string]any) (FilterNode, bool) {
+	if node.Leaf != nil {
+		leaf := make(map[string]map[string]string, len(node.Leaf))
+		for field, ops := range node.Leaf {
+			resolved := make(map[string]string, len(ops))
+			for op, val := range ops {
+				if !strings.HasPrefix(val, UserPlaceholderPrefix) {
+					resolved[op] = val
+					continue
+				}
+
+				s, ok := placeholderValue(user, strings.TrimPrefix(val, UserPlaceholderPrefix))
+				if !ok {
+					return FilterNode{}, false
+				}
+
+				resolved[op] = s
+			}
+
+			leaf[field] = resolved
+		}
+
+		return FilterNode{Leaf: leaf}, true
+	}
+
+	out := FilterNode{}
+	for _, n := range node.And {
+		resolved, ok := ResolveUserPlaceholders(n, user)
+		if !ok {
+			return FilterNode{}, false
+		}
+
+		out.And = append(out.And, resolved)
+	}
+
+	for _, n := range node.Or {
+		if resolved, ok := ResolveUserPlaceholders(n, user); ok {
+			out.Or = append(out.Or, resolved)
+		}
+	}
+
+	if len(node.Or) > 0 && len(out.Or) == 0 {
+		return FilterNode{}, false
+	}
+
+	return out, true
+}
+
+func placeholderValue(user map[string]any, path string) (string, bool) {
+	var current any = user
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return "", false
+		}
+
+		current, ok = m[part]
+		if !ok {
+			return "", false
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case []string:
+		return strings.Join(v, ","), len(v) > 0
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := placeholderValue(map[string]any{"v": item}, "v"); ok {
+				values = append(values, s)
+			}
+		}
+
+		return strings.Join(values, ","), len(values) > 0
+	}
+
+	return "", false
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+)
+
+var (
+	ErrDailyWriteQuotaExceeded      = errors.New("daily write quota exceeded")
+	ErrDailyWriteBytesQuotaExceeded = errors.New("daily storage quota exceeded")
+)
+
+type Usage struct {
+	Day        string `json:"day"`
+	Writes     int64  `json:"writes"`
+	WriteBytes int64  `json:"write_bytes"`
+}
+
+type quotaTracker struct {
+	mu    sync.Mutex
+	day   string
+	usage map[string]*Usage
+}
+
+var Quotas = &quotaTracker{usage: make(map[string]*Usage)}
+
+func QuotaIsEnabled() bool {
+	return globals.GetConfig().Security.Quota.Enabled
+}
+
+func quotaLimits(cfg configuration.QuotaConfig, identity string) configuration.QuotaLimitConfig {
+	if limits, ok := cfg.Users[identity]; ok {
+		return limits
+	}
+
+	if username, ok := usernameFromIdentity(identity); ok {
+		if limits, ok := cfg.Users[username]; ok {
+			return limits
+		}
+	}
+
+	return cfg.QuotaLimitConfig
+}
+
+func (q *quotaTracker) Consume(identity string, bytes int64, now time.Time) error {
+	limits := quotaLimits(globals.GetConfig().Security.Quota, identity)
+	day := now.UTC().Format("2006-01-02")
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.day != day {
+		q.day = day
+		q.usage = make(map[string]*Usage)
+	}
+
+	usage, ok := q.usage[identity]
+	if !ok {
+		usage = &Usage{Day: day}
+		q.usage[identity] = usage
+	}
+
+	if limits.DailyWrites > 0 && usage.Writes+1 > limits.DailyWrites {
+		return ErrDailyWriteQuotaExceeded
+	}
+
+	if limits.DailyWriteBytes > 0 && usage.WriteBytes+bytes > limits.DailyWriteBytes {
+		return ErrDailyWriteBytesQuotaExceeded
+	}
+
+	usage.Writes++
+	usage.WriteBytes += bytes
+
+	return nil
+}
+
+func (q *quotaTracker) Usage(identity string, now time.Time) Usage {
+	day := now.UTC().Format("2006-01-02")
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage, ok := q.usage[identity]
+	if !ok || usage.Day != day {
+		return Usage{Day: day}
+	}
+
+	return *usage
+}
+
+func (q *quotaTracker) Reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.usage = make(map[string]*Usage)
+}
+
+func secondsUntilNextDay(now time.Time) int {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	return int(next.Sub(now).Seconds()) + 1
+}
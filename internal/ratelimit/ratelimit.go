@@ -0,0 +1,303 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	GroupRead   = "read"
+	GroupWrite  = "write"
+	GroupQuery  = "query"
+	GroupImport = "import"
+
+	pruneInterval = time.Minute
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  float64
+}
+
+type limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      int
+	RetryAfter int
+}
+
+var Limiter = &limiter{buckets: make(map[string]*bucket)}
+
+func Read(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return limit(GroupRead, next)
+}
+
+func Write(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return limit(GroupWrite, next)
+}
+
+func Query(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return limit(GroupQuery, next)
+}
+
+func Import(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return limit(GroupImport, next)
+}
+
+func IP(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if cfg := globals.GetConfig(); cfg == nil || !cfg.Security.RateLimit.Enabled {
+			next(ctx)
+			return
+		}
+
+		decision := Limiter.AllowIP(ctx.RemoteIP().String(), time.Now())
+		if !decision.Allowed {
+			reject(ctx, decision)
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func RateLimitIsEnabled() bool {
+	return globals.GetConfig().Security.RateLimit.Enabled
+}
+
+func limit(group string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !RateLimitIsEnabled() && !QuotaIsEnabled() {
+			next(ctx)
+			return
+		}
+
+		identity := Identity(ctx)
+
+		if RateLimitIsEnabled() {
+			decision := Limiter.Allow(identity, group, time.Now())
+			if decision.Limit > 0 {
+				ctx.Response.Header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+				ctx.Response.Header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+				ctx.Response.Header.Set("RateLimit-Reset", strconv.Itoa(decision.Reset))
+			}
+
+			if !decision.Allowed {
+				reject(ctx, decision)
+				return
+			}
+		}
+
+		if QuotaIsEnabled() && (group == GroupWrite || group == GroupImport) {
+			if err := Quotas.Consume(identity, int64(len(ctx.PostBody())), time.Now()); err != nil {
+				ctx.Response.Header.Set("Retry-After", strconv.Itoa(secondsUntilNextDay(time.Now())))
+				ctx.Response.Header.Set("Content-Type", "application/json")
+				ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+				ctx.SetBody([]byte(`{"error":"` + err.Error() + `"}`))
+				return
+			}
+		}
+
+		next(ctx)
+	}
+}
+
+func reject(ctx *fasthttp.RequestCtx, decision Decision) {
+	ctx.Response.Header.Set("Retry-After", strconv.Itoa(decision.RetryAfter))
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+	ctx.SetBody([]byte(`{"error":"rate limit exceeded"}`))
+}
+
+func Identity(ctx *fasthttp.RequestCtx) string {
+	if username, ok := ctx.UserValue("username").(string); ok && username != "" {
+		return "user:" + username
+	}
+
+	if auth := ctx.Request.Header.Peek("Authorization"); len(auth) > 0 {
+		return "key:" + KeyHash(strings.TrimPrefix(string(auth), "Bearer "))
+	}
+
+	return "ip:" + ctx.RemoteIP().String()
+}
+
+func KeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+func clientRate(cfg configuration.RateLimitConfig, identity string) configuration.RateConfig {
+	if rate, ok := cfg.Clients[identity]; ok {
+		return rate
+	}
+
+	if username, ok := usernameFromIdentity(identity); ok {
+		if rate, ok := cfg.Clients[username]; ok {
+			return rate
+		}
+	}
+
+	return cfg.PerClient
+}
+
+func usernameFromIdentity(identity string) (string, bool) {
+	const prefix = "user:"
+	if len(identity) > len(prefix) && identity[:len(prefix)] == prefix {
+		return identity[len(prefix):], true
+	}
+
+	return "", false
+}
+
+func (l *limiter) Allow(identity, group string, now time.Time) Decision {
+	cfg := globals.GetConfig().Security.RateLimit
+
+	rates := map[string]configuration.RateConfig{
+		"global":                          cfg.Global,
+		"client:" + identity:              clientRate(cfg, identity),
+		"group:" + group + ":" + identity: cfg.Groups[group],
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruneIfNeeded(now)
+
+	var applied []*bucket
+	for key, rate := range rates {
+		if rate.RequestsPerSecond <= 0 {
+			continue
+		}
+
+		b := l.bucket(key, rate, now)
+		b.refill(now)
+		applied = append(applied, b)
+	}
+
+	if len(applied) == 0 {
+		return Decision{Allowed: true}
+	}
+
+	for _, b := range applied {
+		if b.tokens < 1 {
+			return Decision{
+				Allowed:    false,
+				Limit:      int(b.burst),
+				Remaining:  0,
+				Reset:      b.secondsUntilFull(),
+				RetryAfter: int(math.Ceil((1 - b.tokens) / b.rate)),
+			}
+		}
+	}
+
+	var tightest *bucket
+	for _, b := range applied {
+		b.tokens--
+		if tightest == nil || b.tokens < tightest.tokens {
+			tightest = b
+		}
+	}
+
+	return Decision{
+		Allowed:   true,
+		Limit:     int(tightest.burst),
+		Remaining: int(tightest.tokens),
+		Reset:     tightest.secondsUntilFull(),
+	}
+}
+
+func (l *limiter) AllowIP(ip string, now time.Time) Decision {
+	rate := globals.GetConfig().Security.RateLimit.PerIP
+	if rate.RequestsPerSecond <= 0 {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruneIfNeeded(now)
+
+	b := l.bucket("ip:"+ip, rate, now)
+	b.refill(now)
+	if b.tokens < 1 {
+		return Decision{Allowed: false, RetryAfter: int(math.Ceil((1 - b.tokens) / b.rate))}
+	}
+
+	b.tokens--
+
+	return Decision{Allowed: true}
+}
+
+func (l *limiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buckets = make(map[string]*bucket)
+}
+
+func (l *limiter) bucket(key string, rate configuration.RateConfig, now time.Time) *bucket {
+	burst := float64(rate.Burst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(rate.RequestsPerSecond))
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.rate = rate.RequestsPerSecond
+	b.burst = burst
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+
+	return b
+}
+
+func (l *limiter) pruneIfNeeded(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	b.last = now
+}
+
+func (b *bucket) secondsUntilFull() int {
+	return int(math.Ceil((b.burst - b.tokens) / b.rate))
+}
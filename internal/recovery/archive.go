@@ -0,0 +1,353 @@
+package recovery
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+)
+
+const maxRecordSize = 64 << 20
+
+var ErrNoArchive = errors.New("wal archiving is not configured")
+
+type Segment struct {
+	Store string
+	First uint64
+	Last  uint64
+	Path  string
+}
+
+type WalRecord struct {
+	Seq       uint64
+	Time      time.Time
+	Store     string
+	Op        string
+	Key       string
+	Document  map[string]any
+	Value     []byte
+	ExpiresAt int64
+}
+
+type Target struct {
+	Seq  uint64
+	Time time.Time
+}
+
+type SeqRange struct {
+	From uint64
+	To   uint64
+}
+
+type ReplayResult struct {
+	Records  int
+	LastSeq  uint64
+	LastTime time.Time
+	Gaps     []SeqRange
+}
+
+type segmentRange struct {
+	first uint64
+	last  uint64
+}
+
+func (r *segmentRange) add(seq uint64) {
+	if seq == 0 {
+		return
+	}
+
+	if r.first == 0 {
+		r.first = seq
+	}
+
+	r.last = seq
+}
+
+var sequence atomic.Uint64
+
+func Sequence() uint64 {
+	return sequence.Load()
+}
+
+func stamp() (uint64, int64) {
+	return sequence.Add(1), time.Now().UnixMilli()
+}
+
+func observeSequence(seq uint64) {
+	for {
+		current := sequence.Load()
+		if seq <= current || sequence.CompareAndSwap(current, seq) {
+			return
+		}
+	}
+}
+
+func ArchivingEnabled() bool {
+	cfg := globals.GetConfig().Store.CrashRecovery
+
+	return cfg.Enabled && cfg.Archive.Enabled
+}
+
+func ArchiveFolder() string {
+	return globals.GetConfig().Store.CrashRecovery.Archive.Folder
+}
+
+func InitSequence() {
+	if !ArchivingEnabled() {
+		return
+	}
+
+	segments, err := ListSegments(ArchiveFolder())
+	if err != nil {
+		log.Error("Error listing archived recovery logs:", err)
+		return
+	}
+
+	for _, s := range segments {
+		observeSequence(s.Last)
+	}
+}
+
+func closeSegment(store, path string, r *segmentRange) {
+	if !ArchivingEnabled() || r.first == 0 {
+		*r = segmentRange{}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Error("Error clearing recovery log:", err)
+		}
+
+		return
+	}
+
+	folder := ArchiveFolder()
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		log.Error("Error creating recovery log archive folder:", err)
+		return
+	}
+
+	dest := filepath.Join(folder, segmentName(store, r.first, r.last))
+	if err := moveFile(path, dest); err != nil && !os.IsNotExist(err) {
+		log.Error("Error archiving recovery log, keeping it in place:", err)
+		return
+	}
+
+	*r = segmentRange{}
+}
+
+func segmentName(store string, first, last uint64) string {
+	return fmt.Sprintf("%s-%020d-%020d.log", store, first, last)
+}
+
+func moveFile(src, dest string) error {
+	if err := os.Rename(src, dest); err == nil || os.IsNotExist(err) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+func ListSegments(folder string) ([]Segment, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	segments := make([]Segment, 0, len(entries))
+	for _, e := range entries {
+		var s Segment
+		name := strings.TrimSuffix(e.Name(), ".log")
+		if e.IsDir() || name == e.Name() {
+			continue
+		}
+
+		store, bounds, ok := strings.Cut(name, "-")
+		if !ok || (store != StreamStoreJson && store != StreamStoreKeyValue) {
+			continue
+		}
+
+		if _, err := fmt.Sscanf(bounds, "%d-%d", &s.First, &s.Last); err != nil {
+			continue
+		}
+
+		s.Store = store
+		s.Path = filepath.Join(folder, e.Name())
+		segments = append(segments, s)
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].First < segments[j].First
+	})
+
+	return segments, nil
+}
+
+func ReplayArchive(folder string, after uint64, target Target, apply func(WalRecord)) (ReplayResult, error) {
+	var result ReplayResult
+
+	segments, err := ListSegments(folder)
+	if err != nil {
+		return result, err
+	}
+
+	result.Gaps = findGaps(segments, after, target.Seq)
+
+	for _, store := range []string{StreamStoreKeyValue, StreamStoreJson} {
+		for _, s := range segments {
+			if s.Store != store || s.Last <= after {
+				continue
+			}
+
+			done, err := replaySegment(s, after, target, apply, &result)
+			if err != nil {
+				return result, err
+			}
+
+			if done {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func replaySegment(s Segment, after uint64, target Target, apply func(WalRecord), result *ReplayResult) (bool, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), maxRecordSize)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		r, err := decodeRecord(s.Store, line)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", filepath.Base(s.Path), err)
+		}
+
+		if r.Seq <= after {
+			continue
+		}
+
+		if (target.Seq > 0 && r.Seq > target.Seq) || (!target.Time.IsZero() && r.Time.After(target.Time)) {
+			return true, nil
+		}
+
+		apply(r)
+
+		result.Records++
+		if r.Seq > result.LastSeq {
+			result.LastSeq = r.Seq
+		}
+		if r.Time.After(result.LastTime) {
+			result.LastTime = r.Time
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+func decodeRecord(store string, line []byte) (WalRecord, error) {
+	if store == StreamStoreJson {
+		var op recoveryOp
+		if err := json.Unmarshal(line, &op); err != nil {
+			return WalRecord{}, err
+		}
+
+		return WalRecord{
+			Seq:      op.Seq,
+			Time:     time.UnixMilli(op.Time),
+			Store:    store,
+			Op:       op.Op,
+			Key:      op.Key,
+			Document: op.Value,
+		}, nil
+	}
+
+	var op storeRecoveryOp
+	if err := json.Unmarshal(line, &op); err != nil {
+		return WalRecord{}, err
+	}
+
+	return WalRecord{
+		Seq:       op.Seq,
+		Time:      time.UnixMilli(op.Time),
+		Store:     store,
+		Op:        op.Op,
+		Key:       op.Key,
+		Value:     op.Value,
+		ExpiresAt: int64(op.TTL),
+	}, nil
+}
+
+func findGaps(segments []Segment, after, until uint64) []SeqRange {
+	var gaps []SeqRange
+
+	covered := after
+	for _, s := range segments {
+		if until > 0 && covered >= until {
+			break
+		}
+
+		if s.Last <= covered {
+			continue
+		}
+
+		if s.First > covered+1 {
+			gaps = append(gaps, SeqRange{From: covered + 1, To: s.First - 1})
+		}
+
+		covered = s.Last
+	}
+
+	if until > covered {
+		gaps = append(gaps, SeqRange{From: covered + 1, To: until})
+	}
+
+	return gaps
+}
@@ -16,11 +16,14 @@ const RecoveryFile = "elysiandb.json.recovery.log"
 var (
 	recoveryMu        sync.Mutex
 	recoveryLogActive = false
+	jsonSegment       segmentRange
 )
 
 var SaveJsonDBFunc func()
 
 type recoveryOp struct {
+	Seq   uint64                 `json:"seq,omitempty"`
+	Time  int64                  `json:"ts,omitempty"`
 	Op    string                 `json:"op"`
 	Key   string                 `json:"key"`
 	Value map[string]interface{} `json:"value,omitempty"`
@@ -32,6 +35,12 @@ func ActivateJsonRecoveryLog(saveDBFunc func()) {
 }
 
 func appendJsonRecoveryOp(op recoveryOp) {
+	if path, ok := writeJsonRecoveryOp(op); ok {
+		checkJsonLogSize(path)
+	}
+}
+
+func writeJsonRecoveryOp(op recoveryOp) (string, bool) {
 	recoveryMu.Lock()
 	defer recoveryMu.Unlock()
 
@@ -40,17 +49,21 @@ func appendJsonRecoveryOp(op recoveryOp) {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
 		log.Error("Error opening recovery log:", err)
-		return
+		return path, false
 	}
 
 	defer f.Close()
 
+	op.Seq, op.Time = stamp()
+
 	enc := json.NewEncoder(f)
 	if err := enc.Encode(op); err != nil {
 		log.Error("Error writing to recovery log:", err)
 	}
 
-	checkJsonLogSize(path)
+	jsonSegment.add(op.Seq)
+
+	return path, true
 }
 
 func LogJsonPut(key string, value map[string]any) {
@@ -68,6 +81,14 @@ func LogJsonDelete(key string) {
 	appendJsonRecoveryOp(recoveryOp{Op: "del", Key: key})
 }
 
+func LogJsonReset() {
+	if !recoveryLogActive {
+		return
+	}
+
+	appendJsonRecoveryOp(recoveryOp{Op: "reset"})
+}
+
 func ReplayJsonRecoveryLog(
 	putFunc func(key string, value map[string]any) error,
 	deleteFunc func(key string),
@@ -100,6 +121,11 @@ func ReplayJsonRecoveryLog(
 			continue
 		}
 
+		observeSequence(op.Seq)
+		recoveryMu.Lock()
+		jsonSegment.add(op.Seq)
+		recoveryMu.Unlock()
+
 		switch op.Op {
 		case "put":
 			_ = putFunc(op.Key, op.Value)
@@ -117,11 +143,11 @@ func ReplayJsonRecoveryLog(
 }
 
 func ClearJsonRecoveryLog() {
+	recoveryMu.Lock()
+	defer recoveryMu.Unlock()
+
 	cfg := globals.GetConfig()
-	path := cfg.Store.Folder + "/" + RecoveryFile
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		log.Error("Error clearing recovery log:", err)
-	}
+	closeSegment(StreamStoreJson, cfg.Store.Folder+"/"+RecoveryFile, &jsonSegment)
 }
 
 func checkJsonLogSize(path string) {
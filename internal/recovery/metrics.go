@@ -0,0 +1,37 @@
+package recovery
+
+import (
+	"os"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/metrics"
+)
+
+var _ = metrics.NewGaugeVecFunc(
+	"elysiandb_wal_size_bytes",
+	"Size of the crash recovery logs on disk.",
+	walSizes,
+	"log",
+)
+
+func walSizes() []metrics.Sample {
+	cfg := globals.GetConfig()
+	if cfg == nil || !cfg.Store.CrashRecovery.Enabled {
+		return nil
+	}
+
+	samples := make([]metrics.Sample, 0, 2)
+	for _, wal := range []struct{ name, file string }{
+		{"json", RecoveryFile},
+		{"store", StoreRecoveryFile},
+	} {
+		var size int64
+		if info, err := os.Stat(cfg.Store.Folder + "/" + wal.file); err == nil {
+			size = info.Size()
+		}
+
+		samples = append(samples, metrics.Sample{LabelValues: []string{wal.name}, Value: float64(size)})
+	}
+
+	return samples
+}
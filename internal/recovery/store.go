@@ -17,11 +17,14 @@ const StoreRecoveryFile = "elysiandb.store.recovery.log"
 var (
 	storeRecoveryMu     sync.Mutex
 	storeRecoveryActive = false
+	storeSegment        segmentRange
 )
 
 var SaveDBFunc func()
 
 type storeRecoveryOp struct {
+	Seq   uint64 `json:"seq,omitempty"`
+	Time  int64  `json:"ts,omitempty"`
 	Op    string `json:"op"`
 	Key   string `json:"key"`
 	Value []byte `json:"value,omitempty"`
@@ -34,6 +37,12 @@ func ActivateStoreRecoveryLog(saveDBFunc func()) {
 }
 
 func appendStoreRecoveryOp(op storeRecoveryOp) {
+	if path, ok := writeStoreRecoveryOp(op); ok {
+		checkStoreLogSize(path)
+	}
+}
+
+func writeStoreRecoveryOp(op storeRecoveryOp) (string, bool) {
 	storeRecoveryMu.Lock()
 	defer storeRecoveryMu.Unlock()
 
@@ -42,17 +51,21 @@ func appendStoreRecoveryOp(op storeRecoveryOp) {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
 		log.Error("Error opening store recovery log:", err)
-		return
+		return path, false
 	}
 
 	defer f.Close()
 
+	op.Seq, op.Time = stamp()
+
 	enc := json.NewEncoder(f)
 	if err := enc.Encode(op); err != nil {
 		log.Error("Error writing to store recovery log:", err)
 	}
 
-	checkStoreLogSize(path)
+	storeSegment.add(op.Seq)
+
+	return path, true
 }
 
 func LogStorePut(key string, value []byte) {
@@ -84,6 +97,14 @@ func LogStoreDelete(key string) {
 	appendStoreRecoveryOp(storeRecoveryOp{Op: "del", Key: key})
 }
 
+func LogStoreReset() {
+	if !storeRecoveryActive {
+		return
+	}
+
+	appendStoreRecoveryOp(storeRecoveryOp{Op: "reset"})
+}
+
 func ReplayStoreRecoveryLog(
 	putFunc func(key string, value []byte, ttl int) error,
 	deleteFunc func(key string),
@@ -117,12 +138,22 @@ func ReplayStoreRecoveryLog(
 			continue
 		}
 
+		observeSequence(op.Seq)
+		storeRecoveryMu.Lock()
+		storeSegment.add(op.Seq)
+		storeRecoveryMu.Unlock()
+
 		switch op.Op {
 		case "put":
 			if op.TTL > 0 && now >= op.TTL {
 				continue
 			}
-			_ = putFunc(op.Key, op.Value, op.TTL)
+
+			ttl := -1
+			if op.TTL > 0 {
+				ttl = op.TTL - now
+			}
+			_ = putFunc(op.Key, op.Value, ttl)
 		case "del":
 			deleteFunc(op.Key)
 		}
@@ -137,11 +168,11 @@ func ReplayStoreRecoveryLog(
 }
 
 func ClearStoreRecoveryLog() {
+	storeRecoveryMu.Lock()
+	defer storeRecoveryMu.Unlock()
+
 	cfg := globals.GetConfig()
-	path := cfg.Store.Folder + "/" + StoreRecoveryFile
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		log.Error("Error clearing store recovery log:", err)
-	}
+	closeSegment(StreamStoreKeyValue, cfg.Store.Folder+"/"+StoreRecoveryFile, &storeSegment)
 }
 
 func checkStoreLogSize(path string) {
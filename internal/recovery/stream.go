@@ -0,0 +1,154 @@
+package recovery
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/log"
+)
+
+const (
+	StreamStoreJson     = "json"
+	StreamStoreKeyValue = "kv"
+)
+
+const (
+	StreamOpPut    = "put"
+	StreamOpDelete = "del"
+	StreamOpReset  = "reset"
+)
+
+type StreamOp struct {
+	Store string          `json:"store"`
+	Op    string          `json:"op"`
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	TTL   int             `json:"ttl,omitempty"`
+}
+
+type streamSink struct {
+	fn func(StreamOp)
+}
+
+var (
+	streamMu    sync.Mutex
+	streamSinks atomic.Pointer[[]*streamSink]
+)
+
+func AddStreamSink(fn func(StreamOp)) func() {
+	sink := &streamSink{fn: fn}
+
+	streamMu.Lock()
+	defer streamMu.Unlock()
+
+	sinks := append(currentSinks(), sink)
+	streamSinks.Store(&sinks)
+
+	return func() {
+		removeStreamSink(sink)
+	}
+}
+
+func removeStreamSink(sink *streamSink) {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+
+	current := currentSinks()
+	sinks := make([]*streamSink, 0, len(current))
+	for _, s := range current {
+		if s != sink {
+			sinks = append(sinks, s)
+		}
+	}
+
+	streamSinks.Store(&sinks)
+}
+
+func currentSinks() []*streamSink {
+	if sinks := streamSinks.Load(); sinks != nil {
+		return slices.Clone(*sinks)
+	}
+
+	return nil
+}
+
+func Streaming() bool {
+	sinks := streamSinks.Load()
+	return sinks != nil && len(*sinks) > 0
+}
+
+func publish(op StreamOp) {
+	sinks := streamSinks.Load()
+	if sinks == nil {
+		return
+	}
+
+	for _, sink := range *sinks {
+		sink.fn(op)
+	}
+}
+
+func encodeStreamValue(value any) (json.RawMessage, bool) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		log.Error("Error encoding replication stream value:", err)
+		return nil, false
+	}
+
+	return raw, true
+}
+
+func StreamJsonPut(key string, value map[string]any) {
+	if !Streaming() {
+		return
+	}
+
+	if raw, ok := encodeStreamValue(value); ok {
+		publish(StreamOp{Store: StreamStoreJson, Op: StreamOpPut, Key: key, Value: raw})
+	}
+}
+
+func StreamJsonDelete(key string) {
+	if !Streaming() {
+		return
+	}
+
+	publish(StreamOp{Store: StreamStoreJson, Op: StreamOpDelete, Key: key})
+}
+
+func StreamStorePut(key string, value []byte, ttl int) {
+	if !Streaming() {
+		return
+	}
+
+	raw, ok := encodeStreamValue(value)
+	if !ok {
+		return
+	}
+
+	var exp int
+	if ttl > 0 {
+		exp = int(time.Now().Unix()) + ttl
+	}
+
+	publish(StreamOp{Store: StreamStoreKeyValue, Op: StreamOpPut, Key: key, Value: raw, TTL: exp})
+}
+
+func StreamStoreDelete(key string) {
+	if !Streaming() {
+		return
+	}
+
+	publish(StreamOp{Store: StreamStoreKeyValue, Op: StreamOpDelete, Key: key})
+}
+
+func StreamStoreReset() {
+	if !Streaming() {
+		return
+	}
+
+	publish(StreamOp{Store: StreamStoreKeyValue, Op: StreamOpReset})
+}
@@ -0,0 +1,98 @@
+package replication
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/recovery"
+)
+
+const (
+	OpSnapshot = "snapshot"
+	OpReady    = "ready"
+	OpPing     = "ping"
+)
+
+type Record struct {
+	Seq   uint64          `json:"seq,omitempty"`
+	Epoch string          `json:"epoch,omitempty"`
+	Op    string          `json:"op"`
+	Store string          `json:"store,omitempty"`
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	TTL   int             `json:"ttl,omitempty"`
+	Time  int64           `json:"time,omitempty"`
+}
+
+type backlog struct {
+	mu      sync.Mutex
+	entries []Record
+	start   int
+	size    int
+	last    uint64
+	wake    chan struct{}
+}
+
+func newBacklog(capacity int) *backlog {
+	return &backlog{
+		entries: make([]Record, capacity),
+		wake:    make(chan struct{}),
+	}
+}
+
+func (b *backlog) append(op recovery.StreamOp) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.last++
+	r := Record{
+		Seq:   b.last,
+		Op:    op.Op,
+		Store: op.Store,
+		Key:   op.Key,
+		Value: op.Value,
+		TTL:   op.TTL,
+		Time:  time.Now().UnixMilli(),
+	}
+
+	if b.size < len(b.entries) {
+		b.entries[(b.start+b.size)%len(b.entries)] = r
+		b.size++
+	} else {
+		b.entries[b.start] = r
+		b.start = (b.start + 1) % len(b.entries)
+	}
+
+	close(b.wake)
+	b.wake = make(chan struct{})
+}
+
+func (b *backlog) head() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.last
+}
+
+func (b *backlog) since(after uint64, max int) ([]Record, <-chan struct{}, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if after > b.last || b.last-after > uint64(b.size) {
+		return nil, nil, false
+	}
+
+	n := int(b.last - after)
+	if n > max {
+		n = max
+	}
+
+	out := make([]Record, n)
+	first := b.size - int(b.last-after)
+	for i := range n {
+		out[i] = b.entries[(b.start+first+i)%len(b.entries)]
+	}
+
+	return out, b.wake, true
+}
@@ -0,0 +1,297 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+var (
+	RetryInterval = time.Second
+	IdleTimeout   = 10 * time.Second
+
+	streamClient = &http.Client{}
+)
+
+type follower struct {
+	leader string
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu          sync.Mutex
+	connected   bool
+	syncing     bool
+	epoch       string
+	applied     uint64
+	leaderSeq   uint64
+	lastContact time.Time
+	caughtUpAt  time.Time
+}
+
+var currentFollower atomic.Pointer[follower]
+
+func startFollower(leaderURL string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &follower{
+		leader: strings.TrimRight(leaderURL, "/"),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	currentFollower.Store(f)
+	role.Store(RoleFollower)
+
+	go f.run(ctx)
+
+	log.Info("Replication: following ", f.leader)
+}
+
+func stopFollower() {
+	f := currentFollower.Swap(nil)
+	if f == nil {
+		return
+	}
+
+	f.cancel()
+	<-f.done
+}
+
+func (f *follower) run(ctx context.Context) {
+	defer close(f.done)
+
+	for {
+		err := f.follow(ctx)
+
+		f.mu.Lock()
+		f.connected = false
+		f.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warn("Replication: stream from leader interrupted: ", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(RetryInterval):
+		}
+	}
+}
+
+func (f *follower) follow(parent context.Context) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	f.mu.Lock()
+	params := url.Values{}
+	params.Set("after", strconv.FormatUint(f.applied, 10))
+	params.Set("epoch", f.epoch)
+	if f.syncing {
+		params.Set("after", "0")
+	}
+	f.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.leader+"/replication/stream?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(TokenHeader, globals.GetConfig().Replication.Token)
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("leader answered %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	f.mu.Lock()
+	f.connected = true
+	f.lastContact = time.Now()
+	f.mu.Unlock()
+
+	idle := time.AfterFunc(IdleTimeout, cancel)
+	defer idle.Stop()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var r Record
+		if err := dec.Decode(&r); err != nil {
+			if ctx.Err() != nil && parent.Err() == nil {
+				return fmt.Errorf("no data from leader for %s", IdleTimeout)
+			}
+
+			return err
+		}
+
+		idle.Reset(IdleTimeout)
+		f.apply(r)
+	}
+}
+
+func (f *follower) apply(r Record) {
+	switch r.Op {
+	case OpSnapshot:
+		log.Info("Replication: loading snapshot at seq ", r.Seq)
+		f.update(func() {
+			f.syncing = true
+			f.epoch = r.Epoch
+			f.applied = 0
+			f.leaderSeq = r.Seq
+		})
+		storage.ResetStore()
+		storage.ResetJsonStore()
+	case OpReady:
+		api_storage.ReconcileIndexes()
+		purgeCache("")
+		f.update(func() {
+			f.syncing = false
+			f.applied = r.Seq
+		})
+		log.Info("Replication: snapshot loaded, streaming from seq ", r.Seq)
+	case OpPing:
+		f.update(func() {
+			f.leaderSeq = max(f.leaderSeq, r.Seq)
+		})
+	default:
+		applyRecord(r, !f.syncing)
+		f.update(func() {
+			if r.Seq > 0 {
+				f.applied = r.Seq
+				f.leaderSeq = max(f.leaderSeq, r.Seq)
+			}
+		})
+	}
+}
+
+func (f *follower) update(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn()
+
+	now := time.Now()
+	f.lastContact = now
+	if !f.syncing && f.applied >= f.leaderSeq {
+		f.caughtUpAt = now
+	}
+}
+
+func applyRecord(r Record, maintainIndexes bool) {
+	switch r.Store {
+	case recovery.StreamStoreKeyValue:
+		applyKeyValue(r)
+	case recovery.StreamStoreJson:
+		applyJson(r, maintainIndexes)
+	}
+}
+
+func applyKeyValue(r Record) {
+	switch r.Op {
+	case recovery.StreamOpPut:
+		var value []byte
+		if err := json.Unmarshal(r.Value, &value); err != nil {
+			log.Error("Replication: invalid key/value record for ", r.Key, ": ", err)
+			return
+		}
+
+		ttl := -1
+		if r.TTL > 0 {
+			ttl = r.TTL - int(time.Now().Unix())
+			if ttl <= 0 {
+				storage.DeleteByKey(r.Key)
+				return
+			}
+		}
+
+		_ = storage.PutKeyValueWithTTL(r.Key, value, ttl)
+	case recovery.StreamOpDelete:
+		storage.DeleteByKey(r.Key)
+	case recovery.StreamOpReset:
+		storage.ResetStore()
+	}
+}
+
+func applyJson(r Record, maintainIndexes bool) {
+	entity, id, isEntity := entityKey(r.Key)
+	maintainIndexes = maintainIndexes && isEntity
+
+	switch r.Op {
+	case recovery.StreamOpPut:
+		var value map[string]any
+		if err := json.Unmarshal(r.Value, &value); err != nil {
+			log.Error("Replication: invalid json record for ", r.Key, ": ", err)
+			return
+		}
+
+		var previous map[string]any
+		if maintainIndexes {
+			previous, _ = storage.GetJsonByKeyNoCopy(r.Key)
+		}
+
+		_ = storage.PutJsonValue(r.Key, value)
+
+		if maintainIndexes {
+			api_storage.AddIdToindexes(entity, id)
+			api_storage.UpdateIndexesForEntity(entity, id, previous, value)
+			purgeCache(entity)
+		}
+	case recovery.StreamOpDelete:
+		storage.DeleteJsonByKey(r.Key)
+
+		if maintainIndexes {
+			api_storage.RemoveIdFromIndexes(entity, id)
+			purgeCache(entity)
+		}
+	}
+}
+
+func entityKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, globals.ApiEntityKey(""))
+	if !ok {
+		return "", "", false
+	}
+
+	entity, id, ok := strings.Cut(rest, ":id:")
+	if !ok || entity == "" || id == "" {
+		return "", "", false
+	}
+
+	return entity, id, true
+}
+
+func purgeCache(entity string) {
+	if !globals.GetConfig().Api.Cache.Enabled || cache.CacheStore == nil {
+		return
+	}
+
+	if entity != "" {
+		cache.CacheStore.Purge(entity)
+		return
+	}
+
+	for _, e := range api_storage.ListEntityTypes() {
+		cache.CacheStore.Purge(e)
+	}
+}
@@ -0,0 +1,189 @@
+package replication
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+const streamBatchSize = 512
+
+var (
+	HeartbeatInterval = time.Second
+
+	errBacklogOverrun = errors.New("follower fell behind the replication backlog")
+)
+
+type leader struct {
+	epoch       string
+	log         *backlog
+	done        chan struct{}
+	followers   sync.Map
+	unsubscribe func()
+}
+
+type followerConn struct {
+	address     string
+	connectedAt time.Time
+	seq         atomic.Uint64
+}
+
+var currentLeader atomic.Pointer[leader]
+
+func startLeader() {
+	size := globals.GetConfig().Replication.BacklogSize
+	if size <= 0 {
+		size = DefaultBacklogSize
+	}
+
+	l := &leader{
+		epoch: uuid.NewString(),
+		log:   newBacklog(size),
+		done:  make(chan struct{}),
+	}
+
+	l.unsubscribe = recovery.AddStreamSink(l.log.append)
+	currentLeader.Store(l)
+	role.Store(RoleLeader)
+
+	log.Info("Replication: leading epoch ", l.epoch)
+}
+
+func stopLeader() {
+	l := currentLeader.Swap(nil)
+	if l == nil {
+		return
+	}
+
+	l.unsubscribe()
+	close(l.done)
+}
+
+func Stream(w *bufio.Writer, after uint64, epoch, address string) error {
+	l := currentLeader.Load()
+	if l == nil {
+		return ErrNotLeader
+	}
+
+	conn := &followerConn{address: address, connectedAt: time.Now()}
+	l.followers.Store(conn, struct{}{})
+	defer l.followers.Delete(conn)
+
+	enc := json.NewEncoder(w)
+
+	if after == 0 || epoch != l.epoch {
+		after = l.log.head()
+		if err := writeSnapshot(w, enc, l.epoch, after); err != nil {
+			return err
+		}
+	} else if err := writePing(w, enc, l); err != nil {
+		return err
+	}
+
+	conn.seq.Store(after)
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		records, wake, ok := l.log.since(after, streamBatchSize)
+		if !ok {
+			return errBacklogOverrun
+		}
+
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+
+			after = r.Seq
+		}
+
+		if len(records) > 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			conn.seq.Store(after)
+		}
+
+		if len(records) == streamBatchSize {
+			continue
+		}
+
+		select {
+		case <-wake:
+		case <-ticker.C:
+			if err := writePing(w, enc, l); err != nil {
+				return err
+			}
+		case <-l.done:
+			return nil
+		}
+	}
+}
+
+func writePing(w *bufio.Writer, enc *json.Encoder, l *leader) error {
+	ping := Record{Op: OpPing, Epoch: l.epoch, Seq: l.log.head(), Time: time.Now().UnixMilli()}
+	if err := enc.Encode(ping); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func writeSnapshot(w *bufio.Writer, enc *json.Encoder, epoch string, seq uint64) error {
+	start := Record{Op: OpSnapshot, Epoch: epoch, Seq: seq, Time: time.Now().UnixMilli()}
+	if err := enc.Encode(start); err != nil {
+		return err
+	}
+
+	var err error
+	storage.IterateKeyValues(func(key string, value []byte, expiresAt int64) {
+		if err != nil {
+			return
+		}
+
+		raw, _ := json.Marshal(value)
+		err = enc.Encode(Record{
+			Op:    recovery.StreamOpPut,
+			Store: recovery.StreamStoreKeyValue,
+			Key:   key,
+			Value: raw,
+			TTL:   int(expiresAt),
+		})
+	})
+
+	storage.IterateJsonValues(func(key string, value map[string]any) {
+		if err != nil {
+			return
+		}
+
+		raw, encErr := json.Marshal(value)
+		if encErr != nil {
+			log.Error("Replication: skipping unencodable value for ", key, ": ", encErr)
+			return
+		}
+
+		err = enc.Encode(Record{Op: recovery.StreamOpPut, Store: recovery.StreamStoreJson, Key: key, Value: raw})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Encode(Record{Op: OpReady, Epoch: epoch, Seq: seq}); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
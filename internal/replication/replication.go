@@ -0,0 +1,96 @@
+package replication
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+)
+
+const (
+	RoleStandalone = "standalone"
+	RoleLeader     = "leader"
+	RoleFollower   = "follower"
+)
+
+const (
+	TokenHeader        = "X-Elysian-Replication-Token"
+	DefaultBacklogSize = 100000
+)
+
+var (
+	ErrNotLeader   = errors.New("this node is not a replication leader")
+	ErrNotFollower = errors.New("this node is not a replication follower")
+)
+
+var (
+	lifecycleMu sync.Mutex
+	role        atomic.Value
+)
+
+func Role() string {
+	if r, ok := role.Load().(string); ok {
+		return r
+	}
+
+	return RoleStandalone
+}
+
+func IsLeader() bool {
+	return Role() == RoleLeader
+}
+
+func IsFollower() bool {
+	return Role() == RoleFollower
+}
+
+func Authorized(token string) bool {
+	expected := globals.GetConfig().Replication.Token
+	if expected == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+func Start() {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+
+	cfg := globals.GetConfig().Replication
+
+	switch cfg.Role {
+	case RoleLeader:
+		startLeader()
+	case RoleFollower:
+		startFollower(cfg.Leader)
+	}
+}
+
+func Stop() {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+
+	stopFollower()
+	stopLeader()
+	role.Store(RoleStandalone)
+}
+
+func Promote() error {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+
+	if !IsFollower() {
+		return ErrNotFollower
+	}
+
+	stopFollower()
+	startLeader()
+
+	log.Info("Replication: promoted to leader")
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package replication
+
+import (
+	"sort"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/metrics"
+)
+
+type FollowerStatus struct {
+	Address     string    `json:"address"`
+	Seq         uint64    `json:"seq"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+type Status struct {
+	Role        string           `json:"role"`
+	Epoch       string           `json:"epoch,omitempty"`
+	Seq         uint64           `json:"seq"`
+	Leader      string           `json:"leader,omitempty"`
+	Connected   bool             `json:"connected"`
+	Syncing     bool             `json:"syncing"`
+	LeaderSeq   uint64           `json:"leaderSeq"`
+	LagRecords  uint64           `json:"lagRecords"`
+	LagSeconds  float64          `json:"lagSeconds"`
+	LastContact *time.Time       `json:"lastContact,omitempty"`
+	Followers   []FollowerStatus `json:"followers,omitempty"`
+}
+
+var (
+	_ = metrics.NewGaugeFunc(
+		"elysiandb_replication_lag_records",
+		"Records received by the leader and not yet applied by this follower.",
+		func() float64 { return float64(CurrentStatus().LagRecords) },
+	)
+	_ = metrics.NewGaugeFunc(
+		"elysiandb_replication_lag_seconds",
+		"Seconds since this follower was last caught up with its leader.",
+		func() float64 { return CurrentStatus().LagSeconds },
+	)
+	_ = metrics.NewGaugeFunc(
+		"elysiandb_replication_followers",
+		"Followers currently streaming from this leader.",
+		func() float64 { return float64(len(CurrentStatus().Followers)) },
+	)
+)
+
+func CurrentStatus() Status {
+	if l := currentLeader.Load(); l != nil {
+		return l.status()
+	}
+
+	if f := currentFollower.Load(); f != nil {
+		return f.status()
+	}
+
+	return Status{Role: RoleStandalone}
+}
+
+func (l *leader) status() Status {
+	head := l.log.head()
+	s := Status{Role: RoleLeader, Epoch: l.epoch, Seq: head, LeaderSeq: head, Connected: true}
+
+	l.followers.Range(func(k, _ any) bool {
+		conn := k.(*followerConn)
+		s.Followers = append(s.Followers, FollowerStatus{
+			Address:     conn.address,
+			Seq:         conn.seq.Load(),
+			ConnectedAt: conn.connectedAt,
+		})
+		return true
+	})
+
+	sort.Slice(s.Followers, func(i, j int) bool {
+		return s.Followers[i].ConnectedAt.Before(s.Followers[j].ConnectedAt)
+	})
+
+	return s
+}
+
+func (f *follower) status() Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := Status{
+		Role:      RoleFollower,
+		Epoch:     f.epoch,
+		Seq:       f.applied,
+		Leader:    f.leader,
+		Connected: f.connected,
+		Syncing:   f.syncing,
+		LeaderSeq: f.leaderSeq,
+	}
+
+	if f.leaderSeq > f.applied {
+		s.LagRecords = f.leaderSeq - f.applied
+	}
+
+	if !f.lastContact.IsZero() {
+		lastContact := f.lastContact
+		s.LastContact = &lastContact
+	}
+
+	switch {
+	case f.caughtUpAt.IsZero():
+		s.LagSeconds = -1
+	case f.connected && s.LagRecords == 0 && !f.syncing:
+		s.LagSeconds = 0
+	default:
+		s.LagSeconds = time.Since(f.caughtUpAt).Seconds()
+	}
+
+	return s
+}
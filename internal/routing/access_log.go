@@ -0,0 +1,38 @@
+package routing
+
+import (
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/tracing"
+	"github.com/valyala/fasthttp"
+)
+
+func AccessLog(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		log.EnsureRequestID(ctx)
+
+		if cfg := globals.GetConfig(); cfg == nil || !cfg.Log.AccessLog {
+			next(ctx)
+			return
+		}
+
+		start := time.Now()
+		next(ctx)
+
+		logger := log.ForRequest(ctx)
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			logger = logger.With("trace_id", traceID)
+		}
+
+		logger.WithFields(log.Fields{
+			"method":     string(ctx.Method()),
+			"path":       string(ctx.Path()),
+			"status":     ctx.Response.StatusCode(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":      len(ctx.Response.Body()),
+			"ip":         ctx.RemoteIP().String(),
+		}).Info("http request")
+	}
+}
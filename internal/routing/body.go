@@ -0,0 +1,54 @@
+package routing
+
+import (
+	"io"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+const MaxBufferedBodySize = fasthttp.DefaultMaxRequestBodySize
+
+var streamedBodyPaths = map[string]bool{
+	"/api/import": true,
+}
+
+func StreamsRequestBody(path string) bool {
+	if streamedBodyPaths[path] {
+		return true
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	return len(parts) == 3 && parts[0] == "api" && parts[2] == "import"
+}
+
+func BufferBody(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !ctx.Request.IsBodyStream() || StreamsRequestBody(string(ctx.Path())) {
+			next(ctx)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.RequestBodyStream(), MaxBufferedBodySize+1))
+		if err != nil {
+			rejectBody(ctx, fasthttp.StatusBadRequest, `{"error":"failed to read request body"}`)
+			return
+		}
+
+		if len(body) > MaxBufferedBodySize {
+			rejectBody(ctx, fasthttp.StatusRequestEntityTooLarge, `{"error":"request body too large"}`)
+			return
+		}
+
+		ctx.Request.SetBody(body)
+		next(ctx)
+	}
+}
+
+func rejectBody(ctx *fasthttp.RequestCtx, status int, body string) {
+	ctx.SetConnectionClose()
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(status)
+	ctx.SetBodyString(body)
+}
@@ -0,0 +1,47 @@
+package routing
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/taymour/elysiandb/internal/metrics"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec(
+		"elysiandb_http_requests_total",
+		"HTTP requests per route and status.",
+		"method", "route", "status",
+	)
+	httpRequestDuration = metrics.NewHistogramVec(
+		"elysiandb_http_request_duration_seconds",
+		"HTTP request latency per route and status.",
+		metrics.DefaultBuckets,
+		"method", "route", "status",
+	)
+)
+
+func Instrument(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !metrics.Enabled() {
+			next(ctx)
+			return
+		}
+
+		start := time.Now()
+		next(ctx)
+
+		route, _ := ctx.UserValue(router.MatchedRoutePathParam).(string)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		method := string(ctx.Method())
+		status := strconv.Itoa(ctx.Response.StatusCode())
+
+		httpRequests.Inc(method, route, status)
+		httpRequestDuration.ObserveSince(start, method, route, status)
+	}
+}
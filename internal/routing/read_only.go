@@ -0,0 +1,43 @@
+package routing
+
+import (
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/replication"
+	"github.com/valyala/fasthttp"
+)
+
+var followerWritablePaths = map[string]bool{
+	"/save":                    true,
+	"/api/query":               true,
+	"/api/admin/backup":        true,
+	"/graphql":                 true,
+	"/api/security/login":      true,
+	"/api/security/logout":     true,
+	"/api/security/logout/all": true,
+}
+
+func ReadOnly(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !replication.IsFollower() || !isWriteRequest(ctx) {
+			next(ctx)
+			return
+		}
+
+		ctx.Response.Header.Set("X-Elysian-Leader", globals.GetConfig().Replication.Leader)
+		ctx.SetContentType("application/json")
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		ctx.SetBodyString(`{"error":"read-only follower, send writes to the leader"}`)
+	}
+}
+
+func isWriteRequest(ctx *fasthttp.RequestCtx) bool {
+	if ctx.IsGet() || ctx.IsHead() || ctx.IsOptions() {
+		return false
+	}
+
+	path := string(ctx.Path())
+
+	return !followerWritablePaths[path] && !strings.HasPrefix(path, "/replication/")
+}
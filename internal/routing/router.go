@@ -3,11 +3,15 @@ package routing
 import (
 	"github.com/fasthttp/router"
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/ratelimit"
 	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/tracing"
 	http_adminui "github.com/taymour/elysiandb/internal/transport/http/adminui"
 	"github.com/taymour/elysiandb/internal/transport/http/api"
 	http_acl "github.com/taymour/elysiandb/internal/transport/http/api/acl"
+	http_audit "github.com/taymour/elysiandb/internal/transport/http/api/audit"
 	http_hook "github.com/taymour/elysiandb/internal/transport/http/api/hook"
+	http_replication "github.com/taymour/elysiandb/internal/transport/http/api/replication"
 	http_security "github.com/taymour/elysiandb/internal/transport/http/api/security"
 	api_transaction "github.com/taymour/elysiandb/internal/transport/http/api/transactions"
 	"github.com/taymour/elysiandb/internal/transport/http/controller"
@@ -15,52 +19,89 @@ import (
 )
 
 func RegisterRoutes(r *router.Router) {
+	r.SaveMatchedRoutePath = true
+
 	r.GET("/health", Version(security.Authenticate(controller.HealthController)))
 
-	r.GET("/kv/mget", Version(security.Authenticate(controller.MultiGetController)))
-	r.GET("/kv/{key}", Version(security.Authenticate(controller.GetKeyController)))
-	r.PUT("/kv/{key}", Version(security.Authenticate(controller.PutKeyController)))
-	r.DELETE("/kv/{key}", Version(security.Authenticate(controller.DeleteKeyController)))
+	r.GET("/kv/mget", Version(security.Authenticate(ratelimit.Read(controller.MultiGetController))))
+	r.GET("/kv/{key}", Version(security.Authenticate(ratelimit.Read(controller.GetKeyController))))
+	r.PUT("/kv/{key}", Version(security.Authenticate(ratelimit.Write(controller.PutKeyController))))
+	r.DELETE("/kv/{key}", Version(security.Authenticate(ratelimit.Write(controller.DeleteKeyController))))
 
-	r.POST("/save", Version(security.Authenticate(controller.SaveController)))
+	r.POST("/save", Version(security.Authenticate(ratelimit.Write(controller.SaveController))))
 
-	r.POST("/reset", Version(security.Authenticate(controller.ResetController)))
+	r.POST("/reset", Version(security.Authenticate(ratelimit.Write(controller.ResetController))))
 
 	if globals.GetConfig().Stats.Enabled {
-		r.GET("/stats", Version(security.Authenticate(controller.StatsController)))
+		r.GET("/stats", Version(security.Authenticate(ratelimit.Read(controller.StatsController))))
+	}
+
+	if globals.GetConfig().Metrics.Enabled {
+		r.GET("/metrics", Version(security.Authenticate(controller.MetricsController)))
+	}
+
+	if globals.GetConfig().Security.Audit.Enabled {
+		r.GET("/api/audit", Version(security.Authenticate(ratelimit.Read(http_audit.ListAuditController))))
+		r.GET("/api/audit/export", Version(security.Authenticate(ratelimit.Read(http_audit.ExportAuditController))))
+	}
+
+	if globals.GetConfig().Api.OpenAPI.Enabled {
+		r.GET("/api/openapi.json", Version(security.Authenticate(ratelimit.Read(api.OpenAPIController))))
 	}
 
-	r.GET("/api/export", Version(security.Authenticate(api.ExportController)))
-	r.POST("/api/import", Version(security.Authenticate(api.ImportController)))
-	r.GET("/api/{entity}", Version(security.Authenticate(api.ListController)))
-	r.POST("/api/query", Version(security.Authenticate(api.QueryController)))
-	r.POST("/api/{entity}", Version(security.Authenticate(api.CreateController)))
-	r.GET("/api/{entity}/{id}", Version(security.Authenticate(api.GetByIdController)))
-	r.PUT("/api/{entity}/{id}", Version(security.Authenticate(api.UpdateByIdController)))
-	r.PUT("/api/{entity}", Version(security.Authenticate(api.UpdateListController)))
-	r.DELETE("/api/{entity}/{id}", Version(security.Authenticate(api.DeleteByIdController)))
-	r.DELETE("/api/{entity}", Version(security.Authenticate(api.DestroyController)))
-	r.GET("/api/{entity}/count", Version(security.Authenticate(api.CountController)))
-	r.GET("/api/{entity}/{id}/exists", Version(security.Authenticate(api.ExistsController)))
-	r.POST("/api/{entity}/migrate", Version(security.Authenticate(api.MigrateController)))
-
-	r.GET("/api/entity/types", Version(security.Authenticate(api.GetEntityTypesController)))
-	r.GET("/api/entity/types/name", Version(security.Authenticate(api.GetEntityTypesNamesController)))
+	if globals.GetConfig().Api.GraphQL.Enabled {
+		r.POST("/graphql", Version(security.Authenticate(ratelimit.Query(api.GraphQLController))))
+	}
+
+	r.POST("/api/admin/backup", Version(security.Authenticate(ratelimit.Read(api.BackupController))))
+	r.POST("/api/admin/seed/restore", Version(security.Authenticate(ratelimit.Write(api.RestoreSeedController))))
+	r.GET("/api/export", Version(security.Authenticate(ratelimit.Read(api.ExportController))))
+	r.POST("/api/import", Version(security.Authenticate(ratelimit.Import(api.ImportController))))
+	r.GET("/api/{entity}", Version(security.Authenticate(ratelimit.Read(api.ListController))))
+	r.POST("/api/query", Version(security.Authenticate(ratelimit.Query(api.QueryController))))
+	r.POST("/api/{entity}", Version(security.Authenticate(ratelimit.Write(api.CreateController))))
+	r.GET("/api/{entity}/{id}", Version(security.Authenticate(ratelimit.Read(api.GetByIdController))))
+	r.PUT("/api/{entity}/{id}", Version(security.Authenticate(ratelimit.Write(api.UpdateByIdController))))
+	r.PUT("/api/{entity}", Version(security.Authenticate(ratelimit.Write(api.UpdateListController))))
+	r.DELETE("/api/{entity}/{id}", Version(security.Authenticate(ratelimit.Write(api.DeleteByIdController))))
+	r.DELETE("/api/{entity}", Version(security.Authenticate(ratelimit.Write(api.DestroyController))))
+	r.GET("/api/{entity}/export", Version(security.Authenticate(ratelimit.Read(api.EntityExportController))))
+	r.POST("/api/{entity}/import", Version(security.Authenticate(ratelimit.Import(api.EntityImportController))))
+	r.GET("/api/{entity}/count", Version(security.Authenticate(ratelimit.Read(api.CountController))))
+	r.GET("/api/{entity}/{id}/exists", Version(security.Authenticate(ratelimit.Read(api.ExistsController))))
+	r.GET("/api/{entity}/{id}/share", Version(security.Authenticate(ratelimit.Read(api.GetSharesController))))
+	r.POST("/api/{entity}/{id}/share", Version(security.Authenticate(ratelimit.Write(api.ShareController))))
+	r.DELETE("/api/{entity}/{id}/share", Version(security.Authenticate(ratelimit.Write(api.UnshareController))))
+	r.PUT("/api/{entity}/{id}/owner", Version(security.Authenticate(ratelimit.Write(api.TransferOwnershipController))))
+	r.POST("/api/{entity}/migrate", Version(security.Authenticate(ratelimit.Write(api.MigrateController))))
+
+	r.GET("/api/entity/types", Version(security.Authenticate(ratelimit.Read(api.GetEntityTypesController))))
+	r.GET("/api/entity/types/name", Version(security.Authenticate(ratelimit.Read(api.GetEntityTypesNamesController))))
 
 	if globals.GetConfig().Api.Schema.Enabled {
-		r.POST("/api/{entity}/create", Version(security.Authenticate(api.CreateTypeController)))
-		r.GET("/api/{entity}/schema", Version(security.Authenticate(api.GetSchemaController)))
-		r.PUT("/api/{entity}/schema", Version(security.Authenticate(api.PutSchemaController)))
+		r.POST("/api/{entity}/create", Version(security.Authenticate(ratelimit.Write(api.CreateTypeController))))
+		r.GET("/api/{entity}/schema", Version(security.Authenticate(ratelimit.Read(api.GetSchemaController))))
+		r.PUT("/api/{entity}/schema", Version(security.Authenticate(ratelimit.Write(api.PutSchemaController))))
+	}
+
+	r.POST("/api/tx/begin", Version(security.Authenticate(ratelimit.Write(api_transaction.BeginTransactionController))))
+	r.POST("/api/tx/{txId}/rollback", Version(security.Authenticate(ratelimit.Write(api_transaction.RollbackTransactionController))))
+	r.POST("/api/tx/{txId}/entity/{entity}", Version(security.Authenticate(ratelimit.Write(api_transaction.WriteTransactionController))))
+	r.PUT("/api/tx/{txId}/entity/{entity}/{id}", Version(security.Authenticate(ratelimit.Write(api_transaction.UpdateTransactionController))))
+	r.DELETE("/api/tx/{txId}/entity/{entity}/{id}", Version(security.Authenticate(ratelimit.Write(api_transaction.DeleteTransactionController))))
+	r.POST("/api/tx/{txId}/commit", Version(security.Authenticate(ratelimit.Write(api_transaction.CommitTransactionController))))
+
+	if globals.GetConfig().Replication.Role != "" {
+		r.GET("/replication/stream", Version(http_replication.StreamController))
+		r.POST("/replication/promote", Version(http_replication.PromoteController))
+		r.GET("/replication/status", Version(security.Authenticate(ratelimit.Read(http_replication.StatusController))))
 	}
 
-	r.POST("/api/tx/begin", Version(security.Authenticate(api_transaction.BeginTransactionController)))
-	r.POST("/api/tx/{txId}/rollback", Version(security.Authenticate(api_transaction.RollbackTransactionController)))
-	r.POST("/api/tx/{txId}/entity/{entity}", Version(security.Authenticate(api_transaction.WriteTransactionController)))
-	r.PUT("/api/tx/{txId}/entity/{entity}/{id}", Version(security.Authenticate(api_transaction.UpdateTransactionController)))
-	r.DELETE("/api/tx/{txId}/entity/{entity}/{id}", Version(security.Authenticate(api_transaction.DeleteTransactionController)))
-	r.POST("/api/tx/{txId}/commit", Version(security.Authenticate(api_transaction.CommitTransactionController)))
+	r.GET("/config", Version(security.Authenticate(ratelimit.Read(controller.GetConfigController))))
 
-	r.GET("/config", Version(security.Authenticate(controller.GetConfigController)))
+	if security.BasicAuthenticationIsEnabled() {
+		r.PUT("/api/security/user/{user_name}/password", Version(security.Authenticate(ratelimit.Write(http_security.ChangeUserPasswordController))))
+	}
 
 	if security.AuthenticationIsEnabled() && security.UserAuthenticationIsEnabled() {
 		// Security - User Management
@@ -69,16 +110,40 @@ func RegisterRoutes(r *router.Router) {
 		r.GET("/api/security/user/{user_name}", Version(http_adminui.AdminAuth(http_security.GetUserByUsernameController)))
 		r.DELETE("/api/security/user/{user_name}", Version(http_adminui.AdminAuth(http_security.DeleteUserByUsernameController)))
 		r.PUT("/api/security/user/{user_name}/password", Version(http_adminui.AdminAuth(http_security.ChangeUserPasswordController)))
+		r.POST("/api/security/user/{user_name}/unlock", Version(http_adminui.AdminAuth(http_security.UnlockUserController)))
 		r.PUT("/api/security/user/{user_name}/role", Version(http_adminui.AdminAuth(http_security.ChangeUserRoleController)))
+		r.GET("/api/security/user/{user_name}/attributes", Version(http_adminui.AdminAuth(http_security.GetUserAttributesController)))
+		r.PUT("/api/security/user/{user_name}/attributes", Version(http_adminui.AdminAuth(http_security.ChangeUserAttributesController)))
+		r.GET("/api/security/user/{user_name}/sessions", Version(http_adminui.AdminAuth(http_security.GetUserSessionsController)))
+		r.DELETE("/api/security/user/{user_name}/sessions", Version(http_adminui.AdminAuth(http_security.DeleteUserSessionsController)))
 		r.POST("/api/security/login", Version(http_adminui.LoginController))
 		r.POST("/api/security/logout", Version(http_adminui.AdminAuth(http_adminui.LogoutController)))
+		r.POST("/api/security/logout/all", Version(http_adminui.AdminAuth(http_adminui.LogoutEverywhereController)))
 		r.GET("/api/security/me", Version(http_adminui.AdminAuth(http_adminui.MeController)))
 
+		// Security - Roles & Groups
+		r.GET("/api/security/roles", Version(http_adminui.AdminAuth(http_security.GetRolesController)))
+		r.POST("/api/security/roles", Version(http_adminui.AdminAuth(http_security.CreateRoleController)))
+		r.GET("/api/security/roles/{role_name}", Version(http_adminui.AdminAuth(http_security.GetRoleController)))
+		r.DELETE("/api/security/roles/{role_name}", Version(http_adminui.AdminAuth(http_security.DeleteRoleController)))
+		r.GET("/api/security/roles/{role_name}/acl/{entity}", Version(http_adminui.AdminAuth(http_acl.GetRoleACLController)))
+		r.PUT("/api/security/roles/{role_name}/acl/{entity}", Version(http_adminui.AdminAuth(http_acl.UpdateRoleACLController)))
+		r.GET("/api/security/groups", Version(http_adminui.AdminAuth(http_security.GetGroupsController)))
+		r.POST("/api/security/groups", Version(http_adminui.AdminAuth(http_security.CreateGroupController)))
+		r.GET("/api/security/groups/{group}", Version(http_adminui.AdminAuth(http_security.GetGroupController)))
+		r.DELETE("/api/security/groups/{group}", Version(http_adminui.AdminAuth(http_security.DeleteGroupController)))
+		r.PUT("/api/security/groups/{group}/members/{user_name}", Version(http_adminui.AdminAuth(http_security.AddGroupMemberController)))
+		r.DELETE("/api/security/groups/{group}/members/{user_name}", Version(http_adminui.AdminAuth(http_security.RemoveGroupMemberController)))
+		r.GET("/api/security/groups/{group}/acl/{entity}", Version(http_adminui.AdminAuth(http_acl.GetGroupACLController)))
+		r.PUT("/api/security/groups/{group}/acl/{entity}", Version(http_adminui.AdminAuth(http_acl.UpdateGroupACLController)))
+
 		// ACL
 		r.GET("/api/acl/{user_name}/{entity}", Version(http_adminui.AdminAuth(http_acl.GetACLForUsernameAndEntityController)))
 		r.GET("/api/acl/{user_name}", Version(http_adminui.AdminAuth(http_acl.GetAllACLForUsername)))
 		r.PUT("/api/acl/{user_name}/{entity}", Version(http_adminui.AdminAuth(http_acl.UpdateACLForUsernameAndEntityController)))
 		r.PUT("/api/acl/{user_name}/{entity}/default", Version(http_adminui.AdminAuth(http_acl.SetDefaultACLForUsernameAndEntityController)))
+		r.GET("/api/acl/role/{role_name}/{entity}/fields", Version(http_adminui.AdminAuth(http_acl.GetRoleFieldRulesController)))
+		r.PUT("/api/acl/role/{role_name}/{entity}/fields", Version(http_adminui.AdminAuth(http_acl.UpdateRoleFieldRulesController)))
 	}
 
 	// Hooks
@@ -92,13 +157,15 @@ func RegisterRoutes(r *router.Router) {
 
 	// Admin UI
 	if globals.GetConfig().AdminUI.Enabled {
-		r.GET("/admin/{filepath:*}", http_adminui.AdminUIHandler)
+		r.GET("/admin/{filepath:*}", tracing.Middleware(AccessLog(Instrument(http_adminui.AdminUIHandler))))
 	}
 }
 
 var Version func(requestHandler fasthttp.RequestHandler) fasthttp.RequestHandler = func(requestHandler fasthttp.RequestHandler) fasthttp.RequestHandler {
-	return func(ctx *fasthttp.RequestCtx) {
-		requestHandler(ctx)
+	guarded := ReadOnly(ratelimit.IP(requestHandler))
+
+	return tracing.Middleware(AccessLog(Instrument(func(ctx *fasthttp.RequestCtx) {
+		guarded(ctx)
 		ctx.Response.Header.Add("X-Elysian-Version", globals.VERSION)
-	}
+	})))
 }
@@ -18,6 +18,7 @@ type Field struct {
 	Type     string
 	Required bool
 	Fields   map[string]Field
+	Entity   string
 }
 
 type Entity struct {
@@ -58,11 +59,13 @@ func analyzeFields(data map[string]interface{}, isRoot, required bool) map[strin
 		case "object":
 			sub, _ := v.(map[string]interface{})
 			f.Fields = analyzeFields(sub, false, required)
+			f.Entity = linkedEntity(sub)
 		case "array":
 			arr := v.([]interface{})
 			if len(arr) > 0 {
 				if firstObj, ok := arr[0].(map[string]interface{}); ok {
 					f.Fields = analyzeFields(firstObj, false, required)
+					f.Entity = linkedEntity(firstObj)
 				}
 			}
 		}
@@ -73,6 +76,12 @@ func analyzeFields(data map[string]interface{}, isRoot, required bool) map[strin
 	return fields
 }
 
+func linkedEntity(data map[string]any) string {
+	entity, _ := data["@entity"].(string)
+
+	return entity
+}
+
 func DetectJSONType(v any) string {
 	switch val := v.(type) {
 	case string:
@@ -253,6 +262,10 @@ func FieldsToMap(fields map[string]Field) map[string]any {
 			fieldMap["fields"] = FieldsToMap(v.Fields)
 		}
 
+		if v.Entity != "" {
+			fieldMap["entity"] = v.Entity
+		}
+
 		out[v.Name] = fieldMap
 	}
 
@@ -276,6 +289,10 @@ func MapToFields(m map[string]any) map[string]Field {
 				f.Fields = MapToFields(subFields)
 			}
 
+			if entity, ok := fieldMap["entity"].(string); ok {
+				f.Entity = entity
+			}
+
 			if name, ok := fieldMap["name"].(string); ok {
 				f.Name = name
 				fields[name] = f
@@ -0,0 +1,86 @@
+package security
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taymour/elysiandb/internal/engine"
+)
+
+const UserAttributesEntity = "_elysiandb_core_user_attributes"
+
+func GetUserAttributes(username string) map[string]string {
+	attributes := map[string]string{}
+
+	data := engine.ReadEntityById(UserAttributesEntity, username)
+	if data == nil {
+		return attributes
+	}
+
+	switch raw := data["attributes"].(type) {
+	case map[string]string:
+		for k, v := range raw {
+			attributes[k] = v
+		}
+	case map[string]any:
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				attributes[k] = s
+			}
+		}
+	}
+
+	return attributes
+}
+
+func SetUserAttributes(username string, attributes map[string]string) error {
+	if engine.ReadEntityById(UserEntity, username) == nil {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+
+	values := make(map[string]any, len(attributes))
+	for k, v := range attributes {
+		values[k] = v
+	}
+
+	errs := engine.WriteEntity(UserAttributesEntity, map[string]any{
+		"id":         username,
+		"username":   username,
+		"attributes": values,
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to save attributes of user '%s'", username)
+	}
+
+	PurgeCachedReads()
+
+	return nil
+}
+
+func DeleteUserAttributes(username string) {
+	if engine.EntityExists(UserAttributesEntity, username) {
+		engine.DeleteEntityById(UserAttributesEntity, username)
+	}
+}
+
+func CurrentUserAttributes(ctx context.Context) map[string]any {
+	current := CurrentPrincipal(ctx)
+	username := current.Username
+	attributes := map[string]any{}
+
+	if JWTAuthenticationIsEnabled() {
+		for k, v := range current.Claims {
+			attributes[k] = v
+		}
+	} else if username != "" {
+		for k, v := range GetUserAttributes(username) {
+			attributes[k] = v
+		}
+	}
+
+	attributes["username"] = username
+	attributes["role"] = string(current.Role)
+	attributes["groups"] = GroupsForUser(username)
+
+	return attributes
+}
@@ -1,6 +1,7 @@
 package security
 
 import (
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/valyala/fasthttp"
 )
@@ -25,6 +26,15 @@ func UserAuthenticationIsEnabled() bool {
 	return cfg.Security.Authentication.Enabled && cfg.Security.Authentication.Mode == "user"
 }
 
+func JWTAuthenticationIsEnabled() bool {
+	cfg := globals.GetConfig()
+	return cfg.Security.Authentication.Enabled && cfg.Security.Authentication.Mode == "jwt"
+}
+
+func IdentityAuthenticationIsEnabled() bool {
+	return UserAuthenticationIsEnabled() || JWTAuthenticationIsEnabled()
+}
+
 func Authenticate(requestHandler fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
 		if !AuthenticationIsEnabled() {
@@ -33,11 +43,18 @@ func Authenticate(requestHandler fasthttp.RequestHandler) fasthttp.RequestHandle
 		}
 
 		if BasicAuthenticationIsEnabled() && !CheckBasicAuthentication(ctx) {
+			audit.RecordFailure(ctx, audit.CategoryAuth, "authenticate", basicAuthUsername(ctx), "invalid credentials")
 			ctx.Response.SetStatusCode(fasthttp.StatusUnauthorized)
 			return
 		}
 
+		if username, _ := ctx.UserValue("username").(string); BasicAuthenticationIsEnabled() && username != "" && PasswordChangeRequired(ctx, username) {
+			SendPasswordChangeRequired(ctx)
+			return
+		}
+
 		if TokenAuthenticationIsEnabled() && !CheckTokenAuthentication(ctx) {
+			audit.RecordFailure(ctx, audit.CategoryAuth, "authenticate", "", "invalid token")
 			ctx.Response.SetStatusCode(fasthttp.StatusUnauthorized)
 			return
 		}
@@ -47,6 +64,11 @@ func Authenticate(requestHandler fasthttp.RequestHandler) fasthttp.RequestHandle
 			return
 		}
 
+		if JWTAuthenticationIsEnabled() {
+			JWTAuth(requestHandler)(ctx)
+			return
+		}
+
 		requestHandler(ctx)
 	}
 }
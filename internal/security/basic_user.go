@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
@@ -32,9 +34,10 @@ const (
 )
 
 type BasicUser struct {
-	Username string
-	Password string
-	Role     Role
+	Username           string
+	Password           string
+	Role               Role
+	MustChangePassword bool
 }
 
 func (u *BasicUser) ToHasedUser() (*BasicHashedUser, error) {
@@ -55,16 +58,18 @@ func (u *BasicUser) ToHasedUser() (*BasicHashedUser, error) {
 	}
 
 	return &BasicHashedUser{
-		Username: u.Username,
-		Password: string(hashedPassword),
-		Role:     role,
+		Username:           u.Username,
+		Password:           string(hashedPassword),
+		Role:               role,
+		MustChangePassword: u.MustChangePassword,
 	}, nil
 }
 
 type BasicHashedUser struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Role     Role   `json:"role"`
+	Username           string `json:"username"`
+	Password           string `json:"password"`
+	Role               Role   `json:"role"`
+	MustChangePassword bool   `json:"must_change_password"`
 }
 
 type UsersFile struct {
@@ -73,10 +78,11 @@ type UsersFile struct {
 
 func (u *BasicHashedUser) ToDataMap() map[string]any {
 	return map[string]any{
-		"id":       u.Username,
-		"username": u.Username,
-		"password": u.Password,
-		"role":     string(u.Role),
+		"id":                   u.Username,
+		"username":             u.Username,
+		"password":             u.Password,
+		"role":                 string(u.Role),
+		"must_change_password": u.MustChangePassword,
 	}
 }
 
@@ -99,6 +105,7 @@ func (u *BasicHashedUser) FromDataMap(data map[string]any) error {
 	u.Username = username
 	u.Password = password
 	u.Role = Role(roleStr)
+	u.MustChangePassword, _ = data["must_change_password"].(bool)
 
 	return nil
 }
@@ -118,18 +125,40 @@ func (u *BasicHashedUser) Save() error {
 }
 
 func InitAdminUserIfNotExists() error {
+	if err := InitBasicUsersStorage(); err != nil {
+		return err
+	}
+
+	engine.UpdateEntitySchema(UserEntity, UserEntitySchema())
+
 	adminUser := engine.ReadEntityById(UserEntity, DefaultAdminUsername)
 	if adminUser != nil {
-		return nil
+		return flagDefaultAdminPassword(adminUser)
 	}
 
 	user := &BasicUser{
-		Username: DefaultAdminUsername,
-		Password: DefaultAdminPassword,
-		Role:     RoleAdmin,
+		Username:           DefaultAdminUsername,
+		Password:           DefaultAdminPassword,
+		Role:               RoleAdmin,
+		MustChangePassword: true,
 	}
 
-	return CreateBasicUser(user)
+	return createBasicUser(user)
+}
+
+func flagDefaultAdminPassword(data map[string]any) error {
+	user := &BasicHashedUser{}
+	if err := user.FromDataMap(data); err != nil {
+		return err
+	}
+
+	if user.MustChangePassword || !passwordMatches(user, DefaultAdminPassword) {
+		return nil
+	}
+
+	user.MustChangePassword = true
+
+	return user.Save()
 }
 
 func UserEntitySchema() map[string]any {
@@ -150,6 +179,10 @@ func UserEntitySchema() map[string]any {
 			"type":     "string",
 			"required": true,
 		},
+		"must_change_password": map[string]any{
+			"type":     "boolean",
+			"required": false,
+		},
 	}
 }
 
@@ -212,6 +245,14 @@ func ListBasicUsers() ([]map[string]any, error) {
 }
 
 func CreateBasicUser(user *BasicUser) error {
+	if err := ValidatePassword(user.Password); err != nil {
+		return err
+	}
+
+	return createBasicUser(user)
+}
+
+func createBasicUser(user *BasicUser) error {
 	err := InitBasicUsersStorage()
 	if err != nil {
 		return err
@@ -222,6 +263,10 @@ func CreateBasicUser(user *BasicUser) error {
 		return err
 	}
 
+	if !RoleExists(hashedUser.Role) {
+		return fmt.Errorf("role '%s' does not exist", hashedUser.Role)
+	}
+
 	err = hashedUser.Save()
 	if err != nil {
 		return err
@@ -236,6 +281,10 @@ func ChangeUserPassword(username, newPassword string) error {
 		return fmt.Errorf("user '%s' not found", username)
 	}
 
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
 	user := &BasicHashedUser{}
 	err := user.FromDataMap(u)
 	if err != nil {
@@ -254,13 +303,18 @@ func ChangeUserPassword(username, newPassword string) error {
 	}
 
 	user.Password = string(hashedPassword)
+	user.MustChangePassword = false
 
 	err = user.Save()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	Lockouts.Clear(username)
+
+	_, err = DeleteUserSessions(username)
+
+	return err
 }
 
 func ChangeUserRole(username, newRole string) error {
@@ -275,6 +329,10 @@ func ChangeUserRole(username, newRole string) error {
 		return err
 	}
 
+	if !RoleExists(Role(newRole)) {
+		return fmt.Errorf("role '%s' does not exist", newRole)
+	}
+
 	user.Role = Role(newRole)
 
 	err = user.Save()
@@ -282,7 +340,11 @@ func ChangeUserRole(username, newRole string) error {
 		return err
 	}
 
-	return nil
+	PurgeCachedReads()
+
+	_, err = DeleteUserSessions(username)
+
+	return err
 }
 
 func DeleteBasicUser(username string) {
@@ -291,6 +353,9 @@ func DeleteBasicUser(username string) {
 	}
 
 	engine.DeleteEntityById(UserEntity, username)
+	RemoveUserFromAllGroups(username)
+	DeleteUserAttributes(username)
+	_, _ = DeleteUserSessions(username)
 }
 
 func GenerateKey() (string, error) {
@@ -303,8 +368,35 @@ func GenerateKey() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
+var (
+	ephemeralKeyMu sync.Mutex
+	ephemeralKey   string
+)
+
+func ephemeralUsersKey() (string, error) {
+	ephemeralKeyMu.Lock()
+	defer ephemeralKeyMu.Unlock()
+
+	if ephemeralKey != "" {
+		return ephemeralKey, nil
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+
+	ephemeralKey = key
+
+	return key, nil
+}
+
 func CreateKeyFileOrGetKey() (string, error) {
 	cfg := globals.GetConfig()
+	if cfg.Store.IsEphemeral() {
+		return ephemeralUsersKey()
+	}
+
 	path := fmt.Sprintf("%s/%s", cfg.Store.Folder, KeyFilename)
 
 	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
@@ -345,52 +437,108 @@ func CreateKeyFileOrGetKey() (string, error) {
 }
 
 func AuthenticateUser(username, password string) (*BasicHashedUser, bool) {
+	user, err := LoginUser(username, password)
+	if err != nil {
+		return nil, false
+	}
+
+	return user, true
+}
+
+func LoginUser(username, password string) (*BasicHashedUser, error) {
 	u := engine.ReadEntityById(UserEntity, username)
 	if u == nil {
-		return nil, false
+		return nil, ErrInvalidCredentials
 	}
 
 	user := &BasicHashedUser{}
 	err := user.FromDataMap(u)
 	if err != nil {
-		return nil, false
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if err := Lockouts.Check(username, now); err != nil {
+		return nil, err
 	}
 
+	if passwordMatches(user, password) {
+		Lockouts.Clear(username)
+		return user, nil
+	}
+
+	if err := Lockouts.Fail(username, now); err != nil {
+		return nil, err
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+func passwordMatches(user *BasicHashedUser, password string) bool {
 	key, err := CreateKeyFileOrGetKey()
 	if err != nil {
-		return nil, false
+		return false
 	}
 
 	sum := sha256.Sum256([]byte(password + key))
-	pass := sum[:]
 
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), pass) == nil {
-		return user, true
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), sum[:]) == nil
+}
+
+var CheckBasicAuthentication = func(ctx *fasthttp.RequestCtx) bool {
+	username, password, ok := basicAuthCredentials(ctx)
+	if !ok {
+		return false
+	}
+
+	_, ok = AuthenticateUser(username, password)
+	if ok {
+		ctx.SetUserValue("username", username)
 	}
 
-	return nil, false
+	return ok
 }
 
-var CheckBasicAuthentication = func(ctx *fasthttp.RequestCtx) bool {
+func basicUserCanManageUser(ctx *fasthttp.RequestCtx, username string) bool {
+	current, _ := ctx.UserValue("username").(string)
+	if current == "" {
+		return false
+	}
+
+	if current == username {
+		return true
+	}
+
+	user, err := GetBasicUserByUsername(current)
+	if err != nil {
+		return false
+	}
+
+	role, _ := user["role"].(string)
+
+	return Role(role) == RoleAdmin
+}
+
+func basicAuthCredentials(ctx *fasthttp.RequestCtx) (string, string, bool) {
 	header := string(ctx.Request.Header.Peek("Authorization"))
 	if header == "" || !strings.HasPrefix(header, "Basic ") {
-		return false
+		return "", "", false
 	}
 
 	payload, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
 	if err != nil {
-		return false
+		return "", "", false
 	}
 
 	parts := strings.SplitN(string(payload), ":", 2)
 	if len(parts) != 2 {
-		return false
+		return "", "", false
 	}
 
-	username := parts[0]
-	password := parts[1]
-
-	_, ok := AuthenticateUser(username, password)
+	return parts[0], parts[1], true
+}
 
-	return ok
+func basicAuthUsername(ctx *fasthttp.RequestCtx) string {
+	username, _, _ := basicAuthCredentials(ctx)
+	return username
 }
@@ -1,24 +1,163 @@
 package security
 
-import "github.com/valyala/fasthttp"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	DefaultCORSAllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	DefaultCORSAllowedHeaders = []string{"Content-Type", "Authorization", CSRFHeaderName}
+	DefaultCORSExposedHeaders = []string{"X-Elysian-Version", "X-Elysian-Cache"}
+)
 
 func CORS(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
 		origin := string(ctx.Request.Header.Peek("Origin"))
+		preflight := string(ctx.Method()) == fasthttp.MethodOptions
 
-		if origin != "" {
-			ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
-			ctx.Response.Header.Set("Vary", "Origin")
-			ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
-			ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		if origin == "" {
+			if preflight {
+				ctx.SetStatusCode(fasthttp.StatusNoContent)
+				return
+			}
+
+			next(ctx)
+			return
 		}
 
-		if string(ctx.Method()) == fasthttp.MethodOptions {
-			ctx.SetStatusCode(204)
+		policy := CORSPolicyForPath(string(ctx.Path()))
+		ctx.Response.Header.Add("Vary", "Origin")
+
+		allowed := OriginIsAllowed(policy.AllowedOrigins, origin)
+
+		if preflight {
+			requestedMethod := string(ctx.Request.Header.Peek("Access-Control-Request-Method"))
+			if !allowed || (requestedMethod != "" && !containsFold(corsMethods(policy), requestedMethod)) {
+				ctx.SetStatusCode(fasthttp.StatusForbidden)
+				return
+			}
+
+			setAllowOrigin(ctx, policy, origin)
+			ctx.Response.Header.Set("Access-Control-Allow-Methods", strings.Join(corsMethods(policy), ", "))
+			ctx.Response.Header.Set("Access-Control-Allow-Headers", strings.Join(corsHeaders(policy), ", "))
+			if policy.MaxAgeSeconds > 0 {
+				ctx.Response.Header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAgeSeconds))
+			}
+
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
 			return
 		}
 
+		if allowed {
+			setAllowOrigin(ctx, policy, origin)
+			ctx.Response.Header.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders(policy), ", "))
+		}
+
 		next(ctx)
 	}
 }
+
+func CORSPolicyForPath(path string) configuration.CORSPolicyConfig {
+	cfg := globals.GetConfig().Server.HTTP.CORS
+
+	policy := cfg.CORSPolicyConfig
+	matched := -1
+	for _, route := range cfg.Routes {
+		if len(route.Path) > matched && pathHasPrefix(path, route.Path) {
+			policy = route.CORSPolicyConfig
+			matched = len(route.Path)
+		}
+	}
+
+	return policy
+}
+
+func OriginIsAllowed(allowedOrigins []string, origin string) bool {
+	for _, pattern := range allowedOrigins {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+
+		star := strings.Index(pattern, "*")
+		if star < 0 {
+			continue
+		}
+
+		prefix := strings.ToLower(pattern[:star])
+		suffix := strings.ToLower(pattern[star+1:])
+		candidate := strings.ToLower(origin)
+
+		if len(candidate) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(candidate, prefix) ||
+			!strings.HasSuffix(candidate, suffix) {
+			continue
+		}
+
+		subdomain := candidate[len(prefix) : len(candidate)-len(suffix)]
+		if !strings.ContainsAny(subdomain, "/:@") && !strings.HasPrefix(subdomain, ".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func setAllowOrigin(ctx *fasthttp.RequestCtx, policy configuration.CORSPolicyConfig, origin string) {
+	if !policy.AllowCredentials && containsFold(policy.AllowedOrigins, "*") {
+		ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
+	if policy.AllowCredentials {
+		ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func pathHasPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func corsMethods(policy configuration.CORSPolicyConfig) []string {
+	if len(policy.AllowedMethods) == 0 {
+		return DefaultCORSAllowedMethods
+	}
+
+	return policy.AllowedMethods
+}
+
+func corsHeaders(policy configuration.CORSPolicyConfig) []string {
+	if len(policy.AllowedHeaders) == 0 {
+		return DefaultCORSAllowedHeaders
+	}
+
+	return policy.AllowedHeaders
+}
+
+func corsExposedHeaders(policy configuration.CORSPolicyConfig) []string {
+	if len(policy.ExposedHeaders) == 0 {
+		return DefaultCORSExposedHeaders
+	}
+
+	return policy.ExposedHeaders
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
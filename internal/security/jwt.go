@@ -0,0 +1,529 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+
+	DefaultJWTUsernameClaim      = "sub"
+	DefaultJWTRoleClaim          = "role"
+	DefaultJWTJWKSRefreshSeconds = 300
+)
+
+type Principal struct {
+	Username string
+	Role     Role
+	Claims   map[string]any
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwksCache struct {
+	mu       sync.Mutex
+	fetchMu  sync.Mutex
+	source   string
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+var jwks = &jwksCache{}
+
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+const jwksMinRefreshInterval = 10 * time.Second
+
+var CheckJWTAuthentication = func(ctx *fasthttp.RequestCtx) (*Principal, bool) {
+	header := string(ctx.Request.Header.Peek("Authorization"))
+
+	const bearerPrefix = "Bearer "
+	if len(header) <= len(bearerPrefix) || header[:len(bearerPrefix)] != bearerPrefix {
+		return nil, false
+	}
+
+	principal, err := ParseJWT(header[len(bearerPrefix):])
+	if err != nil {
+		return nil, false
+	}
+
+	return principal, true
+}
+
+var JWTAuth func(next fasthttp.RequestHandler) fasthttp.RequestHandler = func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		principal, ok := CheckJWTAuthentication(ctx)
+		if !ok {
+			audit.RecordFailure(ctx, audit.CategoryAuth, "authenticate", "", "invalid token")
+			ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+			return
+		}
+
+		SetCurrentPrincipal(ctx, principal)
+
+		next(ctx)
+	}
+}
+
+func ParseJWT(token string) (*Principal, error) {
+	claims, err := VerifyJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return principalFromClaims(claims)
+}
+
+func VerifyJWT(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	if err := verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	if err := validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+func verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	cfg := globals.GetConfig().Security.Authentication.JWT
+
+	switch header.Alg {
+	case JWTAlgorithmHS256:
+		if cfg.Secret == "" {
+			return errors.New("HS256 tokens are not accepted without a configured secret")
+		}
+
+		mac := hmac.New(sha256.New, []byte(cfg.Secret))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid token signature")
+		}
+
+		return nil
+	case JWTAlgorithmRS256, JWTAlgorithmES256:
+		key, err := jwks.key(header.Kid)
+		if err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256([]byte(signingInput))
+
+		if header.Alg == JWTAlgorithmRS256 {
+			pub, ok := key.(*rsa.PublicKey)
+			if !ok {
+				return errors.New("token key is not an RSA key")
+			}
+
+			if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+				return errors.New("invalid token signature")
+			}
+
+			return nil
+		}
+
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("token key is not an EC key")
+		}
+
+		if len(signature) != 64 {
+			return errors.New("invalid token signature")
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errors.New("invalid token signature")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported token algorithm '%s'", header.Alg)
+	}
+}
+
+func validateClaims(claims map[string]any) error {
+	cfg := globals.GetConfig().Security.Authentication.JWT
+	now := time.Now().Unix()
+	leeway := int64(cfg.LeewaySeconds)
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no expiration")
+	}
+
+	if now > int64(exp)+leeway {
+		return errors.New("token has expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now+leeway < int64(nbf) {
+		return errors.New("token is not valid yet")
+	}
+
+	if cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != cfg.Issuer {
+			return errors.New("invalid token issuer")
+		}
+	}
+
+	if cfg.Audience != "" && !audienceMatches(claims["aud"], cfg.Audience) {
+		return errors.New("invalid token audience")
+	}
+
+	return nil
+}
+
+func audienceMatches(aud any, expected string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == expected
+	case []any:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == expected {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func principalFromClaims(claims map[string]any) (*Principal, error) {
+	cfg := globals.GetConfig().Security.Authentication.JWT
+
+	usernameClaim := cfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = DefaultJWTUsernameClaim
+	}
+
+	roleClaim := cfg.RoleClaim
+	if roleClaim == "" {
+		roleClaim = DefaultJWTRoleClaim
+	}
+
+	username, _ := claimValue(claims, usernameClaim).(string)
+	if username == "" {
+		return nil, fmt.Errorf("claim '%s' is missing from token", usernameClaim)
+	}
+
+	adminRoles := cfg.AdminRoles
+	if len(adminRoles) == 0 {
+		adminRoles = []string{string(RoleAdmin)}
+	}
+
+	values := claimStrings(claimValue(claims, roleClaim))
+
+	return &Principal{
+		Username: username,
+		Role:     roleFromClaimValues(values, adminRoles),
+		Claims:   claims,
+	}, nil
+}
+
+func roleFromClaimValues(values, adminRoles []string) Role {
+	for _, value := range values {
+		for _, adminRole := range adminRoles {
+			if value == adminRole {
+				return RoleAdmin
+			}
+		}
+	}
+
+	for _, value := range values {
+		if role := Role(value); !IsBuiltinRole(role) && RoleExists(role) {
+			return role
+		}
+	}
+
+	return RoleUser
+}
+
+func claimValue(claims map[string]any, path string) any {
+	var current any = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		current = m[part]
+	}
+
+	return current
+}
+
+func claimStrings(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return strings.Fields(val)
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+
+		return out
+	}
+
+	return nil
+}
+
+func (c *jwksCache) key(kid string) (crypto.PublicKey, error) {
+	cfg := globals.GetConfig().Security.Authentication.JWT
+
+	source := cfg.JWKSURL
+	if source == "" {
+		source = cfg.JWKSFile
+	}
+
+	if source == "" {
+		return nil, errors.New("no JWKS source is configured")
+	}
+
+	refresh := time.Duration(cfg.JWKSRefreshSeconds) * time.Second
+	if refresh <= 0 {
+		refresh = DefaultJWTJWKSRefreshSeconds * time.Second
+	}
+
+	key, ok, loadedAt := c.cached(source, kid, refresh)
+	if ok {
+		return key, nil
+	}
+
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+
+	key, ok, current := c.cached(source, kid, refresh)
+	if ok {
+		return key, nil
+	}
+
+	if current.After(loadedAt) || (!current.IsZero() && time.Since(current) < jwksMinRefreshInterval) {
+		return nil, fmt.Errorf("no JWKS key found for kid '%s'", kid)
+	}
+
+	keys, err := loadJWKS(cfg.JWKSURL, cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.source = source
+	c.keys = keys
+	c.loadedAt = time.Now()
+
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("no JWKS key found for kid '%s'", kid)
+}
+
+func (c *jwksCache) cached(source, kid string, refresh time.Duration) (crypto.PublicKey, bool, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.source != source || time.Since(c.loadedAt) > refresh {
+		return nil, false, time.Time{}
+	}
+
+	key, ok := c.lookup(kid)
+
+	return key, ok, c.loadedAt
+}
+
+func (c *jwksCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid != "" {
+		key, ok := c.keys[kid]
+		return key, ok
+	}
+
+	if len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+func loadJWKS(url, path string) (map[string]crypto.PublicKey, error) {
+	var raw []byte
+	var err error
+
+	if url != "" {
+		raw, err = fetchJWKS(url)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+
+	return ParseJWKS(raw)
+}
+
+func fetchJWKS(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func ParseJWKS(raw []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, err
+	}
+
+	var unsupported error
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			unsupported = err
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 && unsupported != nil {
+		return nil, unsupported
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported JWKS curve '%s'", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWKS key type '%s'", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
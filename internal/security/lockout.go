@@ -0,0 +1,149 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+)
+
+const (
+	DefaultLockoutMaxAttempts      = 5
+	DefaultLockoutBaseDelaySeconds = 30
+	DefaultLockoutMaxDelaySeconds  = 3600
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked until %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *AccountLockedError) RetryAfter(now time.Time) int {
+	return int(math.Max(1, math.Ceil(e.Until.Sub(now).Seconds())))
+}
+
+type loginAttempts struct {
+	failures    int
+	lockouts    int
+	lockedUntil time.Time
+}
+
+type lockoutTracker struct {
+	mu       sync.Mutex
+	attempts map[string]*loginAttempts
+}
+
+var Lockouts = &lockoutTracker{attempts: make(map[string]*loginAttempts)}
+
+func LockoutIsEnabled() bool {
+	return globals.GetConfig().Security.Lockout.Enabled
+}
+
+func lockoutSettings() (maxAttempts int, base, max time.Duration) {
+	cfg := globals.GetConfig().Security.Lockout
+
+	maxAttempts = cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultLockoutMaxAttempts
+	}
+
+	baseSeconds := cfg.BaseDelaySeconds
+	if baseSeconds <= 0 {
+		baseSeconds = DefaultLockoutBaseDelaySeconds
+	}
+
+	maxSeconds := cfg.MaxDelaySeconds
+	if maxSeconds <= 0 {
+		maxSeconds = DefaultLockoutMaxDelaySeconds
+	}
+
+	return maxAttempts, time.Duration(baseSeconds) * time.Second, time.Duration(maxSeconds) * time.Second
+}
+
+func (l *lockoutTracker) Check(username string, now time.Time) error {
+	if !LockoutIsEnabled() {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if a, ok := l.attempts[username]; ok && now.Before(a.lockedUntil) {
+		return &AccountLockedError{Until: a.lockedUntil}
+	}
+
+	return nil
+}
+
+func (l *lockoutTracker) Fail(username string, now time.Time) error {
+	if !LockoutIsEnabled() {
+		return nil
+	}
+
+	maxAttempts, base, max := lockoutSettings()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[username]
+	if !ok {
+		a = &loginAttempts{}
+		l.attempts[username] = a
+	}
+
+	a.failures++
+	if a.failures < maxAttempts {
+		return nil
+	}
+
+	delay := max
+	if a.lockouts < 32 {
+		delay = base << a.lockouts
+	}
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+
+	a.failures = 0
+	a.lockouts++
+	a.lockedUntil = now.Add(delay)
+
+	return &AccountLockedError{Until: a.lockedUntil}
+}
+
+func (l *lockoutTracker) Clear(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, username)
+}
+
+func (l *lockoutTracker) IsLocked(username string, now time.Time) bool {
+	return l.Check(username, now) != nil
+}
+
+func (l *lockoutTracker) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.attempts = make(map[string]*loginAttempts)
+}
+
+func UnlockUser(username string) error {
+	if engine.ReadEntityById(UserEntity, username) == nil {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+
+	Lockouts.Clear(username)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/valyala/fasthttp"
+)
+
+func ValidatePassword(password string) error {
+	policy := globals.GetConfig().Security.Password
+
+	if policy.MinLength > 0 && len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", policy.MinLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	if policy.RequireUppercase && !upper {
+		return fmt.Errorf("password must contain an uppercase letter")
+	}
+
+	if policy.RequireLowercase && !lower {
+		return fmt.Errorf("password must contain a lowercase letter")
+	}
+
+	if policy.RequireDigit && !digit {
+		return fmt.Errorf("password must contain a digit")
+	}
+
+	if policy.RequireSymbol && !symbol {
+		return fmt.Errorf("password must contain a symbol")
+	}
+
+	return nil
+}
+
+func UserMustChangePassword(username string) bool {
+	data := engine.ReadEntityById(UserEntity, username)
+	if data == nil {
+		return false
+	}
+
+	mustChange, _ := data["must_change_password"].(bool)
+
+	return mustChange
+}
+
+func PasswordChangeRequired(ctx *fasthttp.RequestCtx, username string) bool {
+	if !UserMustChangePassword(username) {
+		return false
+	}
+
+	path := strings.TrimSuffix(string(ctx.Path()), "/")
+	method := string(ctx.Method())
+
+	switch {
+	case path == "/api/security/me" && method == fasthttp.MethodGet:
+		return false
+	case path == "/api/security/logout" && method == fasthttp.MethodPost:
+		return false
+	case path == "/api/security/user/"+username+"/password" && method == fasthttp.MethodPut:
+		return false
+	}
+
+	return true
+}
+
+func SendPasswordChangeRequired(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	ctx.SetStatusCode(fasthttp.StatusForbidden)
+	ctx.SetBodyString(`{"error":"password change required"}`)
+}
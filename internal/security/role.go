@@ -0,0 +1,349 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+)
+
+const (
+	RoleEntity            = "_elysiandb_core_role"
+	GroupEntity           = "_elysiandb_core_group"
+	GroupMembershipEntity = "_elysiandb_core_group_membership"
+)
+
+func BuiltinRoles() []Role {
+	return []Role{RoleAdmin, RoleUser}
+}
+
+func IsBuiltinRole(role Role) bool {
+	for _, r := range BuiltinRoles() {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RoleExists(role Role) bool {
+	if IsBuiltinRole(role) {
+		return true
+	}
+
+	return role != "" && engine.ReadEntityById(RoleEntity, string(role)) != nil
+}
+
+func CreateRole(name, description string) error {
+	if name == "" {
+		return fmt.Errorf("role name is required")
+	}
+
+	if RoleExists(Role(name)) {
+		return fmt.Errorf("role '%s' already exists", name)
+	}
+
+	errs := engine.WriteEntity(RoleEntity, map[string]any{
+		"id":          name,
+		"name":        name,
+		"description": description,
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to create role '%s'", name)
+	}
+
+	return nil
+}
+
+func GetRole(name string) (map[string]any, error) {
+	if IsBuiltinRole(Role(name)) {
+		return builtinRoleDataMap(Role(name)), nil
+	}
+
+	data := engine.ReadEntityById(RoleEntity, name)
+	if data == nil {
+		return nil, fmt.Errorf("role '%s' not found", name)
+	}
+
+	return roleDataMap(data), nil
+}
+
+func ListRoles() []map[string]any {
+	roles := make([]map[string]any, 0)
+	for _, role := range BuiltinRoles() {
+		roles = append(roles, builtinRoleDataMap(role))
+	}
+
+	custom := engine.ListEntities(RoleEntity, 0, 0, "name", true, nil, "", "")
+	for _, data := range custom {
+		roles = append(roles, roleDataMap(data))
+	}
+
+	return roles
+}
+
+func DeleteRole(name string) error {
+	if IsBuiltinRole(Role(name)) {
+		return fmt.Errorf("built-in role '%s' cannot be deleted", name)
+	}
+
+	if engine.ReadEntityById(RoleEntity, name) == nil {
+		return fmt.Errorf("role '%s' not found", name)
+	}
+
+	users, err := ListBasicUsers()
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if role, _ := user["role"].(string); role == name {
+			return fmt.Errorf("role '%s' is still assigned to users", name)
+		}
+	}
+
+	engine.DeleteEntityById(RoleEntity, name)
+
+	return nil
+}
+
+func builtinRoleDataMap(role Role) map[string]any {
+	return map[string]any{
+		"name":        string(role),
+		"description": "",
+		"builtin":     true,
+	}
+}
+
+func roleDataMap(data map[string]any) map[string]any {
+	name, _ := data["name"].(string)
+	description, _ := data["description"].(string)
+
+	return map[string]any{
+		"name":        name,
+		"description": description,
+		"builtin":     false,
+	}
+}
+
+func CreateGroup(name, description string) error {
+	if name == "" {
+		return fmt.Errorf("group name is required")
+	}
+
+	if engine.ReadEntityById(GroupEntity, name) != nil {
+		return fmt.Errorf("group '%s' already exists", name)
+	}
+
+	return saveGroup(name, description, []string{})
+}
+
+func GetGroup(name string) (map[string]any, error) {
+	data := engine.ReadEntityById(GroupEntity, name)
+	if data == nil {
+		return nil, fmt.Errorf("group '%s' not found", name)
+	}
+
+	return groupDataMap(data), nil
+}
+
+func ListGroups() []map[string]any {
+	groups := make([]map[string]any, 0)
+	for _, data := range engine.ListEntities(GroupEntity, 0, 0, "name", true, nil, "", "") {
+		groups = append(groups, groupDataMap(data))
+	}
+
+	return groups
+}
+
+func DeleteGroup(name string) error {
+	data := engine.ReadEntityById(GroupEntity, name)
+	if data == nil {
+		return fmt.Errorf("group '%s' not found", name)
+	}
+
+	engine.DeleteEntityById(GroupEntity, name)
+	updateGroupMemberships(name, groupMembers(data), nil)
+	PurgeCachedReads()
+
+	return nil
+}
+
+func AddUserToGroup(group, username string) error {
+	data := engine.ReadEntityById(GroupEntity, group)
+	if data == nil {
+		return fmt.Errorf("group '%s' not found", group)
+	}
+
+	members := groupMembers(data)
+	for _, member := range members {
+		if member == username {
+			return nil
+		}
+	}
+
+	description, _ := data["description"].(string)
+
+	return saveGroup(group, description, append(members, username))
+}
+
+func RemoveUserFromGroup(group, username string) error {
+	data := engine.ReadEntityById(GroupEntity, group)
+	if data == nil {
+		return fmt.Errorf("group '%s' not found", group)
+	}
+
+	members := make([]string, 0)
+	for _, member := range groupMembers(data) {
+		if member != username {
+			members = append(members, member)
+		}
+	}
+
+	description, _ := data["description"].(string)
+
+	return saveGroup(group, description, members)
+}
+
+func RemoveUserFromAllGroups(username string) {
+	for _, group := range GroupsForUser(username) {
+		_ = RemoveUserFromGroup(group, username)
+	}
+}
+
+func GroupsForUser(username string) []string {
+	groups := make([]string, 0)
+	if username == "" {
+		return groups
+	}
+
+	data := engine.ReadEntityById(GroupMembershipEntity, username)
+	if data == nil {
+		return groups
+	}
+
+	return append(groups, stringList(data["groups"])...)
+}
+
+func PurgeCachedReads() {
+	if globals.GetConfig().Api.Cache.Enabled && cache.CacheStore != nil {
+		cache.CacheStore.PurgeAll()
+	}
+}
+
+func saveGroup(name, description string, members []string) error {
+	sort.Strings(members)
+
+	previous := make([]string, 0)
+	if data := engine.ReadEntityById(GroupEntity, name); data != nil {
+		previous = groupMembers(data)
+	}
+
+	list := make([]any, 0, len(members))
+	for _, member := range members {
+		list = append(list, member)
+	}
+
+	errs := engine.WriteEntity(GroupEntity, map[string]any{
+		"id":          name,
+		"name":        name,
+		"description": description,
+		"members":     list,
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to save group '%s'", name)
+	}
+
+	updateGroupMemberships(name, previous, members)
+	PurgeCachedReads()
+
+	return nil
+}
+
+func updateGroupMemberships(group string, previous, current []string) {
+	before := make(map[string]bool, len(previous))
+	for _, member := range previous {
+		before[member] = true
+	}
+
+	after := make(map[string]bool, len(current))
+	for _, member := range current {
+		after[member] = true
+		if !before[member] {
+			setGroupMembership(member, group, true)
+		}
+	}
+
+	for _, member := range previous {
+		if !after[member] {
+			setGroupMembership(member, group, false)
+		}
+	}
+}
+
+func setGroupMembership(username, group string, member bool) {
+	groups := make([]string, 0)
+	if data := engine.ReadEntityById(GroupMembershipEntity, username); data != nil {
+		for _, name := range stringList(data["groups"]) {
+			if name != group {
+				groups = append(groups, name)
+			}
+		}
+	}
+
+	if member {
+		groups = append(groups, group)
+	}
+
+	if len(groups) == 0 {
+		engine.DeleteEntityById(GroupMembershipEntity, username)
+		return
+	}
+
+	sort.Strings(groups)
+
+	list := make([]any, 0, len(groups))
+	for _, name := range groups {
+		list = append(list, name)
+	}
+
+	engine.WriteEntity(GroupMembershipEntity, map[string]any{
+		"id":     username,
+		"groups": list,
+	})
+}
+
+func groupMembers(data map[string]any) []string {
+	return stringList(data["members"])
+}
+
+func stringList(value any) []string {
+	items := make([]string, 0)
+
+	switch list := value.(type) {
+	case []string:
+		items = append(items, list...)
+	case []any:
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	}
+
+	return items
+}
+
+func groupDataMap(data map[string]any) map[string]any {
+	name, _ := data["name"].(string)
+	description, _ := data["description"].(string)
+
+	return map[string]any{
+		"name":        name,
+		"description": description,
+		"members":     groupMembers(data),
+	}
+}
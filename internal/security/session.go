@@ -1,39 +1,89 @@
 package security
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/valyala/fasthttp"
 )
 
-var CurrentUsername string
+const (
+	CSRFCookieName                       = "edb_csrf"
+	CSRFHeaderName                       = "X-CSRF-Token"
+	DefaultSessionTTLSeconds             = 24 * 60 * 60
+	DefaultSessionPersistIntervalSeconds = 1
+	sessionSlidePersistSeconds           = 60
+)
 
 type Session struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Role      Role   `json:"role"`
-	ExpiresAt int64  `json:"expires_at"`
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	Role       Role   `json:"role"`
+	ExpiresAt  int64  `json:"expires_at"`
+	CreatedAt  int64  `json:"created_at,omitempty"`
+	LastSeenAt int64  `json:"last_seen_at,omitempty"`
+	TTL        int64  `json:"ttl,omitempty"`
+	CSRFToken  string `json:"csrf_token,omitempty"`
 }
 
 type SessionsFile struct {
 	Sessions []Session `json:"sessions"`
 }
 
-func SetCurrentUsername(username string) {
-	CurrentUsername = username
+type sessionStore struct {
+	mu        sync.Mutex
+	flushMu   sync.Mutex
+	path      string
+	sessions  map[string]*Session
+	persisted map[string]int64
+	stamp     fileStamp
+	flushing  bool
+	dirty     bool
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
 }
 
-func GetCurrentUsername() string {
-	return CurrentUsername
+var sessions = &sessionStore{}
+
+type principalKey struct{}
+
+func SetCurrentPrincipal(ctx *fasthttp.RequestCtx, principal *Principal) {
+	ctx.SetUserValue("username", principal.Username)
+	ctx.SetUserValue("role", principal.Role)
+	ctx.SetUserValue(principalKey{}, principal)
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func CurrentPrincipal(ctx context.Context) *Principal {
+	if ctx != nil {
+		if principal, ok := ctx.Value(principalKey{}).(*Principal); ok && principal != nil {
+			return principal
+		}
+	}
+
+	return &Principal{}
 }
 
 func CurrentUserCanManageUser(ctx *fasthttp.RequestCtx, username string) (bool, error) {
+	if BasicAuthenticationIsEnabled() {
+		return basicUserCanManageUser(ctx, username), nil
+	}
+
 	currentSession, err := CurrentSession(ctx)
 	if err != nil {
 		return false, err
@@ -52,6 +102,11 @@ func CurrentSession(ctx *fasthttp.RequestCtx) (*Session, error) {
 }
 
 func CurrentUserIsAdmin(ctx *fasthttp.RequestCtx) bool {
+	if JWTAuthenticationIsEnabled() {
+		role, ok := ctx.UserValue("role").(Role)
+		return ok && role == RoleAdmin
+	}
+
 	currentSession, err := CurrentSession(ctx)
 	if err != nil || currentSession == nil {
 		return false
@@ -60,6 +115,37 @@ func CurrentUserIsAdmin(ctx *fasthttp.RequestCtx) bool {
 	return currentSession.Role == RoleAdmin
 }
 
+func SessionTTL() time.Duration {
+	ttl := globals.GetConfig().Security.Session.TTLSeconds
+	if ttl <= 0 {
+		ttl = DefaultSessionTTLSeconds
+	}
+
+	return time.Duration(ttl) * time.Second
+}
+
+func CSRFIsEnabled() bool {
+	return globals.GetConfig().Security.Session.CSRF.Enabled
+}
+
+func CheckCSRF(ctx *fasthttp.RequestCtx, session *Session) bool {
+	if !CSRFIsEnabled() {
+		return true
+	}
+
+	switch string(ctx.Method()) {
+	case fasthttp.MethodGet, fasthttp.MethodHead, fasthttp.MethodOptions:
+		return true
+	}
+
+	token := ctx.Request.Header.Peek(CSRFHeaderName)
+	if len(token) == 0 || session.CSRFToken == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(token, []byte(session.CSRFToken)) == 1
+}
+
 func generateSessionID() (string, error) {
 	b := make([]byte, 32)
 	_, err := rand.Read(b)
@@ -70,9 +156,15 @@ func generateSessionID() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
-func loadSessions() (*SessionsFile, error) {
+func sessionsPath() string {
 	cfg := globals.GetConfig()
-	path := fmt.Sprintf("%s/%s", cfg.Store.Folder, SessionsFilename)
+	return fmt.Sprintf("%s/%s", cfg.Store.Folder, SessionsFilename)
+}
+
+func loadSessions(path string) (*SessionsFile, error) {
+	if globals.GetConfig().Store.IsEphemeral() {
+		return &SessionsFile{Sessions: []Session{}}, nil
+	}
 
 	file, err := os.Open(path)
 	if err != nil {
@@ -94,105 +186,339 @@ func loadSessions() (*SessionsFile, error) {
 	return &sf, nil
 }
 
-func saveSessions(sf *SessionsFile) error {
-	cfg := globals.GetConfig()
-	path := fmt.Sprintf("%s/%s", cfg.Store.Folder, SessionsFilename)
+func saveSessions(path string, sf *SessionsFile) error {
+	if globals.GetConfig().Store.IsEphemeral() {
+		return nil
+	}
 
-	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return err
 	}
 
-	defer file.Close()
-
 	enc := json.NewEncoder(file)
+	if err := enc.Encode(sf); err != nil {
+		file.Close()
+		return err
+	}
 
-	return enc.Encode(sf)
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
 }
 
-func CreateSession(username string, role Role, ttl time.Duration) (*Session, error) {
-	sf, err := loadSessions()
+func statSessions(path string) fileStamp {
+	if globals.GetConfig().Store.IsEphemeral() {
+		return fileStamp{}
+	}
+
+	info, err := os.Stat(path)
 	if err != nil {
-		return nil, err
+		return fileStamp{}
 	}
 
-	now := time.Now().Unix()
-	var active []Session
-	for _, s := range sf.Sessions {
-		if s.ExpiresAt > now {
-			active = append(active, s)
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+func persistedExpirations(list []Session) map[string]int64 {
+	persisted := make(map[string]int64, len(list))
+	for _, session := range list {
+		persisted[session.ID] = session.ExpiresAt
+	}
+
+	return persisted
+}
+
+func (s *sessionStore) ensureLoaded() error {
+	path := sessionsPath()
+	if s.sessions != nil && s.path == path {
+		return s.reloadIfChanged()
+	}
+
+	if s.sessions != nil && s.dirty {
+		_ = saveSessions(s.path, s.snapshot())
+	}
+
+	stamp := statSessions(path)
+	sf, err := loadSessions(path)
+	if err != nil {
+		return err
+	}
+
+	s.path = path
+	s.dirty = false
+	s.stamp = stamp
+	s.persisted = persistedExpirations(sf.Sessions)
+	s.sessions = make(map[string]*Session, len(sf.Sessions))
+	for i := range sf.Sessions {
+		session := sf.Sessions[i]
+		s.sessions[session.ID] = &session
+	}
+
+	return nil
+}
+
+func (s *sessionStore) reloadIfChanged() error {
+	if s.flushing {
+		return nil
+	}
+
+	stamp := statSessions(s.path)
+	if stamp == s.stamp {
+		return nil
+	}
+
+	sf, err := loadSessions(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.merge(sf.Sessions)
+	s.stamp = stamp
+
+	return nil
+}
+
+func (s *sessionStore) merge(stored []Session) {
+	onDisk := make(map[string]bool, len(stored))
+	for i := range stored {
+		session := stored[i]
+		onDisk[session.ID] = true
+
+		if current, ok := s.sessions[session.ID]; ok {
+			if session.ExpiresAt > current.ExpiresAt {
+				current.ExpiresAt = session.ExpiresAt
+			}
+
+			continue
+		}
+
+		if _, known := s.persisted[session.ID]; !known {
+			s.sessions[session.ID] = &session
+		}
+	}
+
+	for id := range s.sessions {
+		if _, known := s.persisted[id]; known && !onDisk[id] {
+			delete(s.sessions, id)
 		}
 	}
 
-	sf.Sessions = active
+	s.persisted = persistedExpirations(stored)
+}
+
+func (s *sessionStore) snapshot() *SessionsFile {
+	sf := &SessionsFile{Sessions: make([]Session, 0, len(s.sessions))}
+	for _, session := range s.sessions {
+		sf.Sessions = append(sf.Sessions, *session)
+	}
+
+	sort.Slice(sf.Sessions, func(i, j int) bool {
+		return sf.Sessions[i].CreatedAt < sf.Sessions[j].CreatedAt
+	})
+
+	return sf
+}
 
+func (s *sessionStore) purgeExpired(now int64) {
+	for id, session := range s.sessions {
+		if session.ExpiresAt <= now {
+			delete(s.sessions, id)
+			s.dirty = true
+		}
+	}
+}
+
+func slideExpiration(session *Session, now int64) {
+	session.LastSeenAt = now
+	if session.TTL <= 0 {
+		return
+	}
+
+	expiresAt := now + session.TTL
+
+	maxLifetime := int64(globals.GetConfig().Security.Session.MaxLifetimeSeconds)
+	if maxLifetime > 0 && session.CreatedAt > 0 && expiresAt > session.CreatedAt+maxLifetime {
+		expiresAt = session.CreatedAt + maxLifetime
+	}
+
+	if expiresAt > session.ExpiresAt {
+		session.ExpiresAt = expiresAt
+	}
+}
+
+func CreateSession(username string, role Role, ttl time.Duration) (*Session, error) {
 	id, err := generateSessionID()
 	if err != nil {
 		return nil, err
 	}
 
-	session := Session{
-		ID:        id,
-		Username:  username,
-		Role:      role,
-		ExpiresAt: now + int64(ttl.Seconds()),
+	csrfToken, err := generateSessionID()
+	if err != nil {
+		return nil, err
 	}
 
-	sf.Sessions = append(sf.Sessions, session)
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
 
-	if err := saveSessions(sf); err != nil {
+	if err := sessions.ensureLoaded(); err != nil {
 		return nil, err
 	}
 
+	now := time.Now().Unix()
+	sessions.purgeExpired(now)
+
+	session := Session{
+		ID:         id,
+		Username:   username,
+		Role:       role,
+		ExpiresAt:  now + int64(ttl.Seconds()),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		TTL:        int64(ttl.Seconds()),
+		CSRFToken:  csrfToken,
+	}
+
+	stored := session
+	sessions.sessions[id] = &stored
+	sessions.dirty = true
+
 	return &session, nil
 }
 
 func GetSession(id string) (*Session, error) {
-	sf, err := loadSessions()
-	if err != nil {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	if err := sessions.ensureLoaded(); err != nil {
 		return nil, err
 	}
 
-	now := time.Now().Unix()
-	var active []Session
-	var found *Session
-
-	for _, s := range sf.Sessions {
-		if s.ExpiresAt > now {
-			active = append(active, s)
-			if s.ID == id {
-				tmp := s
-				found = &tmp
-			}
-		}
+	session, ok := sessions.sessions[id]
+	if !ok {
+		return nil, nil
 	}
 
-	if len(active) != len(sf.Sessions) {
-		sf.Sessions = active
-		_ = saveSessions(sf)
+	now := time.Now().Unix()
+	if session.ExpiresAt <= now {
+		delete(sessions.sessions, id)
+		sessions.dirty = true
+		return nil, nil
 	}
 
-	if found == nil {
-		return nil, nil
+	slideExpiration(session, now)
+	if session.ExpiresAt-sessions.persisted[id] >= min(sessionSlidePersistSeconds, session.TTL/2) {
+		sessions.dirty = true
 	}
 
-	return found, nil
+	found := *session
+
+	return &found, nil
 }
 
 func DeleteSession(id string) error {
-	sf, err := loadSessions()
-	if err != nil {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	if err := sessions.ensureLoaded(); err != nil {
 		return err
 	}
 
-	var updated []Session
-	for _, s := range sf.Sessions {
-		if s.ID != id {
-			updated = append(updated, s)
+	if _, ok := sessions.sessions[id]; ok {
+		delete(sessions.sessions, id)
+		sessions.dirty = true
+	}
+
+	return nil
+}
+
+func ListUserSessions(username string) ([]Session, error) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	if err := sessions.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	sessions.purgeExpired(time.Now().Unix())
+
+	result := []Session{}
+	for _, session := range sessions.snapshot().Sessions {
+		if session.Username == username {
+			result = append(result, session)
+		}
+	}
+
+	return result, nil
+}
+
+func DeleteUserSessions(username string) (int, error) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	if err := sessions.ensureLoaded(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for id, session := range sessions.sessions {
+		if session.Username == username {
+			delete(sessions.sessions, id)
+			count++
 		}
 	}
 
-	sf.Sessions = updated
+	if count > 0 {
+		sessions.dirty = true
+	}
+
+	return count, nil
+}
+
+func FlushSessions() error {
+	sessions.flushMu.Lock()
+	defer sessions.flushMu.Unlock()
+
+	sessions.mu.Lock()
+	if sessions.sessions == nil {
+		sessions.mu.Unlock()
+		return nil
+	}
+
+	if err := sessions.reloadIfChanged(); err != nil {
+		sessions.mu.Unlock()
+		return err
+	}
+
+	if !sessions.dirty {
+		sessions.mu.Unlock()
+		return nil
+	}
+
+	sessions.purgeExpired(time.Now().Unix())
+	path := sessions.path
+	sf := sessions.snapshot()
+	sessions.dirty = false
+	sessions.flushing = true
+	sessions.mu.Unlock()
+
+	err := saveSessions(path, sf)
+
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	sessions.flushing = false
+	if err != nil {
+		sessions.dirty = true
+		return err
+	}
+
+	sessions.persisted = persistedExpirations(sf.Sessions)
+	sessions.stamp = statSessions(path)
 
-	return saveSessions(sf)
+	return nil
 }
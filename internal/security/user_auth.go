@@ -20,9 +20,17 @@ var UserAuth func(next fasthttp.RequestHandler) fasthttp.RequestHandler = func(n
 			return
 		}
 
-		ctx.SetUserValue("username", session.Username)
-		ctx.SetUserValue("role", session.Role)
-		SetCurrentUsername(session.Username)
+		if !CheckCSRF(ctx, session) {
+			ctx.SetStatusCode(fasthttp.StatusForbidden)
+			return
+		}
+
+		if PasswordChangeRequired(ctx, session.Username) {
+			SendPasswordChangeRequired(ctx)
+			return
+		}
+
+		SetCurrentPrincipal(ctx, &Principal{Username: session.Username, Role: session.Role})
 
 		next(ctx)
 	}
@@ -0,0 +1,207 @@
+package seed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/hook"
+)
+
+const (
+	KindEntity = "entity"
+	KindUser   = "user"
+	KindACL    = "acl"
+	KindHook   = "hook"
+	KindSchema = "schema"
+
+	maxLineSize = 64 << 20
+)
+
+type User struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type ACL struct {
+	Entity      string            `json:"entity"`
+	Username    string            `json:"username,omitempty"`
+	Role        string            `json:"role,omitempty"`
+	Group       string            `json:"group,omitempty"`
+	Permissions map[string]bool   `json:"permissions,omitempty"`
+	Fields      map[string]string `json:"fields,omitempty"`
+	Filter      map[string]any    `json:"filter,omitempty"`
+}
+
+type Schema struct {
+	Entity string         `json:"entity"`
+	Fields map[string]any `json:"fields"`
+}
+
+type Fixture struct {
+	Schemas  []Schema                    `json:"schemas"`
+	Entities map[string][]map[string]any `json:"entities"`
+	Users    []User                      `json:"users"`
+	ACLs     []ACL                       `json:"acls"`
+	Hooks    []hook.Hook                 `json:"hooks"`
+}
+
+type record struct {
+	Kind   string          `json:"kind"`
+	Entity string          `json:"entity"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func ReadFiles(paths []string) (*Fixture, error) {
+	fixture := &Fixture{Entities: make(map[string][]map[string]any)}
+
+	for _, path := range paths {
+		f, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fixture.merge(f)
+	}
+
+	return fixture, nil
+}
+
+func ReadFile(path string) (*Fixture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var fixture *Fixture
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		fixture, err = readNDJSON(file)
+	default:
+		fixture, err = readJSON(file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+func readJSON(r io.Reader) (*Fixture, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	fixture := &Fixture{}
+	if err := dec.Decode(fixture); err != nil {
+		return nil, err
+	}
+
+	return fixture, nil
+}
+
+func readNDJSON(r io.Reader) (*Fixture, error) {
+	fixture := &Fixture{Entities: make(map[string][]map[string]any)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := fixture.add(rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return fixture, scanner.Err()
+}
+
+func (f *Fixture) add(rec record) error {
+	switch rec.Kind {
+	case "", KindEntity:
+		if rec.Entity == "" {
+			return fmt.Errorf("entity record without entity")
+		}
+
+		var doc map[string]any
+		if err := json.Unmarshal(rec.Data, &doc); err != nil {
+			return err
+		}
+
+		f.Entities[rec.Entity] = append(f.Entities[rec.Entity], doc)
+	case KindUser:
+		var u User
+		if err := json.Unmarshal(rec.Data, &u); err != nil {
+			return err
+		}
+
+		f.Users = append(f.Users, u)
+	case KindACL:
+		var a ACL
+		if err := json.Unmarshal(rec.Data, &a); err != nil {
+			return err
+		}
+
+		if a.Entity == "" {
+			a.Entity = rec.Entity
+		}
+
+		f.ACLs = append(f.ACLs, a)
+	case KindHook:
+		var h hook.Hook
+		if err := json.Unmarshal(rec.Data, &h); err != nil {
+			return err
+		}
+
+		if h.Entity == "" {
+			h.Entity = rec.Entity
+		}
+
+		f.Hooks = append(f.Hooks, h)
+	case KindSchema:
+		var s Schema
+		if err := json.Unmarshal(rec.Data, &s); err != nil {
+			return err
+		}
+
+		if s.Entity == "" {
+			s.Entity = rec.Entity
+		}
+
+		f.Schemas = append(f.Schemas, s)
+	default:
+		return fmt.Errorf("unknown record kind %q", rec.Kind)
+	}
+
+	return nil
+}
+
+func (f *Fixture) merge(other *Fixture) {
+	f.Schemas = append(f.Schemas, other.Schemas...)
+	f.Users = append(f.Users, other.Users...)
+	f.ACLs = append(f.ACLs, other.ACLs...)
+	f.Hooks = append(f.Hooks, other.Hooks...)
+
+	for entity, docs := range other.Entities {
+		f.Entities[entity] = append(f.Entities[entity], docs...)
+	}
+}
@@ -0,0 +1,271 @@
+package seed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+var ErrNotSeeded = errors.New("no seed fixtures were loaded")
+
+var (
+	mu       sync.Mutex
+	fixtures *Fixture
+	seeded   []byte
+)
+
+func Load(paths []string) error {
+	fixture, err := ReadFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := apply(fixture); err != nil {
+		return err
+	}
+
+	fixtures = fixture
+	seeded = nil
+
+	if !engine.CurrentCapabilities().ExternalStorage {
+		seeded, err = json.Marshal(storage.TakeSnapshot())
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info("Seed fixtures loaded from ", len(paths), " files")
+
+	return nil
+}
+
+func Restore() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if fixtures == nil {
+		return ErrNotSeeded
+	}
+
+	before := engine.ListEntityTypes()
+
+	if seeded != nil {
+		var snapshot storage.Snapshot
+		if err := json.Unmarshal(seeded, &snapshot); err != nil {
+			return err
+		}
+
+		storage.RestoreSnapshot(&snapshot)
+		api_storage.ReconcileIndexes()
+	} else {
+		engine.DeleteAll()
+		storage.ResetStore()
+
+		if err := apply(fixtures); err != nil {
+			return err
+		}
+	}
+
+	purgeCache(append(before, engine.ListEntityTypes()...))
+
+	return nil
+}
+
+func apply(f *Fixture) error {
+	for _, s := range f.Schemas {
+		if err := ApplySchema(s); err != nil {
+			return err
+		}
+	}
+
+	entities := make([]string, 0, len(f.Entities))
+	for entity := range f.Entities {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	for _, entity := range entities {
+		for i, doc := range f.Entities[entity] {
+			if errs := engine.WriteEntity(entity, doc); len(errs) > 0 {
+				return fmt.Errorf("%s #%d: %s", entity, i+1, errs[0].ToError())
+			}
+		}
+	}
+
+	for _, u := range f.Users {
+		if err := applyUser(u); err != nil {
+			return err
+		}
+	}
+
+	acl.InitACL()
+
+	for _, a := range f.ACLs {
+		if err := ApplyACL(a); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range f.Hooks {
+		if err := ApplyHook(h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ApplySchema(s Schema) error {
+	if s.Entity == "" || s.Fields == nil {
+		return fmt.Errorf("schema fixtures need an entity and fields")
+	}
+
+	if !engine.EntityTypeExists(s.Entity) {
+		if err := engine.CreateEntityType(s.Entity); err != nil {
+			return fmt.Errorf("schema %s: %w", s.Entity, err)
+		}
+	}
+
+	engine.UpdateEntitySchema(s.Entity, s.Fields)
+
+	return nil
+}
+
+func applyUser(u User) error {
+	if u.Username == "" || u.Password == "" {
+		return fmt.Errorf("user fixtures need a username and a password")
+	}
+
+	user := &security.BasicUser{Username: u.Username, Password: u.Password, Role: security.Role(u.Role)}
+	if err := security.CreateBasicUser(user); err != nil {
+		return fmt.Errorf("user %s: %w", u.Username, err)
+	}
+
+	return nil
+}
+
+func ApplyACL(a ACL) error {
+	principals := 0
+	for _, p := range []string{a.Username, a.Role, a.Group} {
+		if p != "" {
+			principals++
+		}
+	}
+
+	if a.Entity == "" || principals != 1 {
+		return fmt.Errorf("acl fixtures need an entity and exactly one of username, role or group")
+	}
+
+	permissions, err := parsePermissions(a.Permissions)
+	if err != nil {
+		return err
+	}
+
+	rules := make(map[string]acl.FieldRule, len(a.Fields))
+	for field, value := range a.Fields {
+		rule, ok := acl.StringToFieldRule(value)
+		if !ok || field == "" {
+			return fmt.Errorf("acl %s: invalid field rule %q for %q", a.Entity, value, field)
+		}
+
+		rules[field] = rule
+	}
+
+	if a.Username != "" {
+		if len(a.Filter) > 0 {
+			return fmt.Errorf("acl %s: filters are only supported for roles and groups", a.Entity)
+		}
+
+		if permissions != nil {
+			if err := acl.UpdateACLEntityForUsername(a.Entity, a.Username, permissions); err != nil {
+				return err
+			}
+		}
+
+		if a.Fields != nil {
+			return acl.UpdateACLFieldsForUsername(a.Entity, a.Username, rules)
+		}
+
+		return nil
+	}
+
+	if _, err := query.ParseFilterNode(a.Filter); err != nil {
+		return fmt.Errorf("acl %s: invalid filter: %w", a.Entity, err)
+	}
+
+	principal := &acl.PrincipalACL{Entity: a.Entity, Permissions: permissions, Fields: rules, Filter: a.Filter}
+
+	if a.Role != "" {
+		if !security.RoleExists(security.Role(a.Role)) {
+			return fmt.Errorf("acl %s: role '%s' does not exist", a.Entity, a.Role)
+		}
+
+		principal.Principal = a.Role
+		acl.SetRoleACL(principal)
+
+		return nil
+	}
+
+	principal.Principal = a.Group
+	acl.SetGroupACL(principal)
+
+	return nil
+}
+
+func parsePermissions(raw map[string]bool) (map[acl.Permission]bool, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	permissions := acl.NewPermissions()
+	for name, allowed := range raw {
+		p, ok := acl.StringToPermission(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown acl permission %q", name)
+		}
+
+		permissions[p] = allowed
+	}
+
+	return permissions, nil
+}
+
+func ApplyHook(h hook.Hook) error {
+	if h.Entity == "" {
+		return fmt.Errorf("hook fixtures need an entity")
+	}
+
+	if h.ID != "" && engine.EntityExists(hook.HookEntity, h.ID) {
+		return hook.UpdateHook(&h)
+	}
+
+	return hook.CreateHook(h)
+}
+
+func purgeCache(entities []string) {
+	if !globals.GetConfig().Api.Cache.Enabled || cache.CacheStore == nil {
+		return
+	}
+
+	slices.Sort(entities)
+	for _, entity := range slices.Compact(entities) {
+		cache.CacheStore.Purge(entity)
+	}
+}
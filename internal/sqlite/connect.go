@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"database/sql"
+	"net/url"
+	"path/filepath"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	_ "modernc.org/sqlite"
+)
+
+const DefaultFileName = "elysiandb.sqlite"
+
+var createdIndexes sync.Map
+
+var schemaStatements = []string{
+	`CREATE TABLE IF NOT EXISTS documents (
+		entity TEXT NOT NULL,
+		id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (entity, id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS entity_types (
+		name TEXT PRIMARY KEY
+	)`,
+}
+
+func Path() string {
+	cfg := globals.GetConfig()
+	if cfg.Engine.URI != "" {
+		return cfg.Engine.URI
+	}
+
+	return filepath.Join(cfg.Store.Folder, DefaultFileName)
+}
+
+func Connect() error {
+	if err := registerFunctions(); err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Add("_pragma", "analysis_limit(1000)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+Path()+"?"+params.Encode())
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range schemaStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return err
+		}
+	}
+
+	globals.SQLiteDB = db
+
+	return nil
+}
+
+func Disconnect() error {
+	if globals.SQLiteDB == nil {
+		return nil
+	}
+
+	err := globals.SQLiteDB.Close()
+	globals.SQLiteDB = nil
+	createdIndexes.Clear()
+
+	return err
+}
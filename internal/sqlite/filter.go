@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/query"
+)
+
+const arrayElementText = "CASE je.type WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(je.value AS TEXT) END"
+
+var comparisonOperators = map[string]string{
+	"eq":  "=",
+	"neq": "!=",
+	"lt":  "<",
+	"lte": "<=",
+	"gt":  ">",
+	"gte": ">=",
+}
+
+type field struct {
+	kind  string
+	value string
+	each  string
+	plain bool
+}
+
+type builder struct {
+	args []any
+}
+
+func (b *builder) bind(v any) string {
+	b.args = append(b.args, v)
+
+	return "?"
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func jsonPath(segments []string) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, s := range segments {
+		sb.WriteString(`."`)
+		sb.WriteString(s)
+		sb.WriteString(`"`)
+	}
+
+	return sb.String()
+}
+
+func fieldAt(column, name string) field {
+	parts := strings.Split(name, ".")
+	path := quote(jsonPath(parts))
+
+	direct := field{
+		kind:  fmt.Sprintf("json_type(%s, %s)", column, path),
+		value: fmt.Sprintf("json_extract(%s, %s)", column, path),
+		each:  fmt.Sprintf("%s, %s", column, path),
+		plain: true,
+	}
+
+	if len(parts) == 1 {
+		return direct
+	}
+
+	entityPath := quote(jsonPath([]string{parts[0], "@entity"}))
+	idPath := quote(jsonPath([]string{parts[0], "id"}))
+	rest := quote(jsonPath(parts[1:]))
+
+	linked := func(fn string) string {
+		return fmt.Sprintf(
+			"(SELECT %s(l.data, %s) FROM documents l WHERE l.entity = json_extract(%s, %s) AND l.id = json_extract(%s, %s))",
+			fn, rest, column, entityPath, column, idPath,
+		)
+	}
+
+	isLink := fmt.Sprintf("json_type(%s, %s) = 'text'", column, entityPath)
+	value := fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", isLink, linked("json_extract"), direct.value)
+
+	return field{
+		kind:  fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", isLink, linked("json_type"), direct.kind),
+		value: value,
+		each:  value,
+	}
+}
+
+func fieldOf(name string) field {
+	return fieldAt("e.data", name)
+}
+
+func all(conds []string) string {
+	if len(conds) == 0 {
+		return "1"
+	}
+
+	return "(" + strings.Join(conds, " AND ") + ")"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func (b *builder) filters(filters map[string]map[string]string, lenient bool) string {
+	conds := make([]string, 0, len(filters))
+	for _, name := range sortedKeys(filters) {
+		conds = append(conds, b.fieldCondition(name, filters[name], lenient))
+	}
+
+	return all(conds)
+}
+
+func (b *builder) fieldCondition(name string, ops map[string]string, lenient bool) string {
+	f := fieldOf(name)
+
+	text := b.textCondition(f, ops)
+	number := b.numberCondition(f, ops)
+	array := b.arrayCondition(f, ops)
+	boolean := b.boolCondition(f, ops)
+
+	typed := fmt.Sprintf(
+		"(%s = 'text' AND %s) OR (%s IN ('integer', 'real') AND %s) OR (%s = 'array' AND %s) OR (%s IN ('true', 'false') AND %s)",
+		f.kind, text, f.kind, number, f.kind, array, f.kind, boolean,
+	)
+
+	if lenient {
+		return fmt.Sprintf("(%s IS NULL OR %s IN ('null', 'object') OR %s)", f.kind, f.kind, typed)
+	}
+
+	return "(" + typed + ")"
+}
+
+func (b *builder) textCondition(f field, ops map[string]string) string {
+	dates := len(ops) > 0
+	for _, cmp := range ops {
+		if _, _, ok := parseDate(cmp); !ok {
+			dates = false
+			break
+		}
+	}
+
+	str := b.stringCondition(f, ops)
+	if !dates {
+		return str
+	}
+
+	date := b.dateCondition(f, ops)
+
+	return fmt.Sprintf("CASE WHEN ely_time(%s) IS NULL THEN %s ELSE %s END", f.value, str, date)
+}
+
+func (b *builder) stringCondition(f field, ops map[string]string) string {
+	conds := []string{}
+	for _, op := range sortedKeys(ops) {
+		switch op {
+		case "eq":
+			conds = append(conds, fmt.Sprintf("ely_glob(%s, %s) = 1", b.bind(ops[op]), f.value))
+		case "neq":
+			conds = append(conds, fmt.Sprintf("ely_glob(%s, %s) = 0", b.bind(ops[op]), f.value))
+		}
+	}
+
+	return all(conds)
+}
+
+func (b *builder) dateCondition(f field, ops map[string]string) string {
+	conds := []string{}
+	for _, op := range sortedKeys(ops) {
+		sqlOp, ok := comparisonOperators[op]
+		if !ok {
+			continue
+		}
+
+		t, dateOnly, _ := parseDate(ops[op])
+		day := t.Truncate(24 * time.Hour).UnixNano()
+
+		if dateOnly {
+			conds = append(conds, fmt.Sprintf("ely_day(%s) %s %s", f.value, sqlOp, b.bind(day)))
+			continue
+		}
+
+		conds = append(conds, fmt.Sprintf(
+			"CASE WHEN ely_is_day(%s) = 1 THEN ely_day(%s) %s %s ELSE ely_time(%s) %s %s END",
+			f.value, f.value, sqlOp, b.bind(day), f.value, sqlOp, b.bind(t.UnixNano()),
+		))
+	}
+
+	return all(conds)
+}
+
+func (b *builder) numberCondition(f field, ops map[string]string) string {
+	conds := []string{}
+	for _, op := range sortedKeys(ops) {
+		num, err := strconv.ParseFloat(ops[op], 64)
+		if err != nil {
+			return "0"
+		}
+
+		if sqlOp, ok := comparisonOperators[op]; ok {
+			conds = append(conds, fmt.Sprintf("%s %s %s", f.value, sqlOp, b.bind(num)))
+		}
+	}
+
+	return all(conds)
+}
+
+func (b *builder) boolCondition(f field, ops map[string]string) string {
+	conds := []string{}
+	for _, op := range sortedKeys(ops) {
+		value := int64(0)
+		if ops[op] == "true" || ops[op] == "1" {
+			value = 1
+		}
+
+		switch op {
+		case "eq":
+			conds = append(conds, fmt.Sprintf("%s = %s", f.value, b.bind(value)))
+		case "neq":
+			conds = append(conds, fmt.Sprintf("%s != %s", f.value, b.bind(value)))
+		}
+	}
+
+	return all(conds)
+}
+
+func (b *builder) arrayCondition(f field, ops map[string]string) string {
+	contains := func(values []string) string {
+		placeholders := make([]string, 0, len(values))
+		for _, v := range values {
+			placeholders = append(placeholders, b.bind(v))
+		}
+
+		return fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM json_each(%s) je WHERE %s IN (%s))",
+			f.each, arrayElementText, strings.Join(placeholders, ", "),
+		)
+	}
+
+	conds := []string{}
+	for _, op := range sortedKeys(ops) {
+		values := strings.Split(ops[op], ",")
+
+		switch op {
+		case "contains":
+			conds = append(conds, contains(values[:1]))
+		case "not_contains":
+			conds = append(conds, "NOT "+contains(values[:1]))
+		case "any":
+			conds = append(conds, contains(values))
+		case "none":
+			conds = append(conds, "NOT "+contains(values))
+		case "all":
+			for _, v := range values {
+				conds = append(conds, contains([]string{v}))
+			}
+		case "eq":
+			conds = append(conds, fmt.Sprintf("json_array_length(%s) = %d", f.each, len(values)))
+			for _, v := range values {
+				conds = append(conds, contains([]string{v}))
+			}
+		}
+	}
+
+	return all(conds)
+}
+
+func (b *builder) node(n query.FilterNode) string {
+	if n.Leaf != nil {
+		return b.filters(n.Leaf, false)
+	}
+
+	if len(n.And) > 0 {
+		conds := make([]string, 0, len(n.And))
+		for _, child := range n.And {
+			conds = append(conds, b.node(child))
+		}
+
+		return "(" + strings.Join(conds, " AND ") + ")"
+	}
+
+	if len(n.Or) > 0 {
+		conds := make([]string, 0, len(n.Or))
+		for _, child := range n.Or {
+			conds = append(conds, b.node(child))
+		}
+
+		return "(" + strings.Join(conds, " OR ") + ")"
+	}
+
+	return "0"
+}
+
+func (b *builder) search(pattern string) string {
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM json_tree(e.data) jt WHERE jt.type = 'text' AND ely_glob(%s, jt.atom) = 1)",
+		b.bind(pattern),
+	)
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/storage"
+	driverlib "modernc.org/sqlite"
+)
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+var scalarFunctions = map[string]struct {
+	args int32
+	fn   func(args []driver.Value) driver.Value
+}{
+	"ely_glob":   {2, globFunction},
+	"ely_time":   {1, timeFunction},
+	"ely_day":    {1, dayFunction},
+	"ely_is_day": {1, isDayFunction},
+}
+
+func registerFunctions() error {
+	registerOnce.Do(func() {
+		for name, f := range scalarFunctions {
+			fn := f.fn
+			err := driverlib.RegisterDeterministicScalarFunction(name, f.args, func(_ *driverlib.FunctionContext, args []driver.Value) (driver.Value, error) {
+				return fn(args), nil
+			})
+			if err != nil {
+				registerErr = err
+				return
+			}
+		}
+	})
+
+	return registerErr
+}
+
+func globFunction(args []driver.Value) driver.Value {
+	pattern, ok1 := args[0].(string)
+	value, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return int64(0)
+	}
+
+	if storage.MatchGlob(pattern, value) {
+		return int64(1)
+	}
+
+	return int64(0)
+}
+
+func timeFunction(args []driver.Value) driver.Value {
+	t, _, ok := parseDate(args[0])
+	if !ok {
+		return nil
+	}
+
+	return t.UnixNano()
+}
+
+func dayFunction(args []driver.Value) driver.Value {
+	t, _, ok := parseDate(args[0])
+	if !ok {
+		return nil
+	}
+
+	return t.Truncate(24 * time.Hour).UnixNano()
+}
+
+func isDayFunction(args []driver.Value) driver.Value {
+	_, dateOnly, ok := parseDate(args[0])
+	if ok && dateOnly {
+		return int64(1)
+	}
+
+	return int64(0)
+}
+
+func parseDate(v any) (time.Time, bool, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false, false
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, true
+	}
+
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true, true
+	}
+
+	return time.Time{}, false, false
+}
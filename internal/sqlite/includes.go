@@ -0,0 +1,177 @@
+package sqlite
+
+import "strings"
+
+const maxIncludeDepth = 8
+
+type includeTree map[string]includeTree
+
+type includeRef struct {
+	parentMap map[string]any
+	parentArr []any
+	key       string
+	idx       int
+	entity    string
+	id        string
+}
+
+func (r includeRef) set(v map[string]any) {
+	if r.parentMap != nil {
+		r.parentMap[r.key] = v
+		return
+	}
+
+	r.parentArr[r.idx] = v
+}
+
+func ApplyIncludes(data []map[string]any, includesParam string) []map[string]any {
+	return db().ApplyIncludes(data, includesParam)
+}
+
+func (s Session) ApplyIncludes(data []map[string]any, includesParam string) []map[string]any {
+	includesParam = strings.TrimSpace(includesParam)
+	if includesParam == "" || len(data) == 0 {
+		return data
+	}
+
+	if includesParam == "all" {
+		s.includeAll(data, maxIncludeDepth)
+		return data
+	}
+
+	s.includeTree(data, parseIncludeTree(includesParam))
+
+	return data
+}
+
+func parseIncludeTree(includesParam string) includeTree {
+	tree := includeTree{}
+	for _, inc := range strings.Split(includesParam, ",") {
+		node := tree
+		for _, part := range strings.Split(strings.TrimSpace(inc), ".") {
+			if part == "" {
+				continue
+			}
+
+			child, ok := node[part]
+			if !ok {
+				child = includeTree{}
+				node[part] = child
+			}
+
+			node = child
+		}
+	}
+
+	return tree
+}
+
+func (s Session) includeTree(items []map[string]any, tree includeTree) {
+	for _, field := range sortedKeys(tree) {
+		refs := []includeRef{}
+		children := []map[string]any{}
+
+		for _, item := range items {
+			switch v := item[field].(type) {
+			case map[string]any:
+				if ref, ok := refOf(v); ok {
+					ref.parentMap, ref.key = item, field
+					refs = append(refs, ref)
+				} else {
+					children = append(children, v)
+				}
+			case []any:
+				for i, el := range v {
+					m, ok := el.(map[string]any)
+					if !ok {
+						continue
+					}
+
+					if ref, ok := refOf(m); ok {
+						ref.parentArr, ref.idx = v, i
+						refs = append(refs, ref)
+					} else {
+						children = append(children, m)
+					}
+				}
+			}
+		}
+
+		children = append(children, s.resolve(refs)...)
+
+		if len(tree[field]) > 0 && len(children) > 0 {
+			s.includeTree(children, tree[field])
+		}
+	}
+}
+
+func (s Session) includeAll(items []map[string]any, depth int) {
+	if depth <= 0 || len(items) == 0 {
+		return
+	}
+
+	refs := []includeRef{}
+	for _, item := range items {
+		for key, value := range item {
+			switch v := value.(type) {
+			case map[string]any:
+				if ref, ok := refOf(v); ok {
+					ref.parentMap, ref.key = item, key
+					refs = append(refs, ref)
+				}
+			case []any:
+				for i, el := range v {
+					if m, ok := el.(map[string]any); ok {
+						if ref, ok := refOf(m); ok {
+							ref.parentArr, ref.idx = v, i
+							refs = append(refs, ref)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	s.includeAll(s.resolve(refs), depth-1)
+}
+
+func refOf(m map[string]any) (includeRef, bool) {
+	entity, _ := m["@entity"].(string)
+	id, _ := m["id"].(string)
+	if entity == "" || id == "" {
+		return includeRef{}, false
+	}
+
+	return includeRef{entity: entity, id: id}, true
+}
+
+func (s Session) resolve(refs []includeRef) []map[string]any {
+	byEntity := map[string][]string{}
+	for _, r := range refs {
+		byEntity[r.entity] = append(byEntity[r.entity], r.id)
+	}
+
+	loaded := map[string]map[string]string{}
+	for entity, ids := range byEntity {
+		loaded[entity] = s.readRaw(entity, ids)
+	}
+
+	resolved := make([]map[string]any, 0, len(refs))
+	for _, r := range refs {
+		raw, ok := loaded[r.entity][r.id]
+		if !ok {
+			continue
+		}
+
+		doc := decodeDocument(raw)
+		if doc == nil {
+			continue
+		}
+
+		doc["@entity"] = r.entity
+		r.set(doc)
+		resolved = append(resolved, doc)
+	}
+
+	return resolved
+}
@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/metrics"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+var emptyNode = query.FilterNode{}
+
+var selectDuration = metrics.NewHistogramVec(
+	"elysiandb_sqlite_select_duration_seconds",
+	"Latency of document selects issued by the SQLite engine.",
+	metrics.DefaultBuckets,
+	"outcome",
+)
+
+func ListEntitiesWithFilterNodeContext(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	return db().ListEntities(ctx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam, node)
+}
+
+func GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	return db().GetListOfIds(entity, sortField, sortAscending)
+}
+
+func ExecuteQuery(q query.Query) ([]map[string]any, error) {
+	return db().ExecuteQuery(q)
+}
+
+func MatchFilterNode(node query.FilterNode, entity map[string]any) bool {
+	return api_storage.MatchFilterNode(node, entity)
+}
+
+func (s Session) ListEntities(
+	ctx context.Context,
+	entity string,
+	limit int,
+	offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search string,
+	includesParam string,
+	node query.FilterNode,
+) []map[string]any {
+	b := &builder{}
+	where := []string{"e.entity = " + b.bind(entity)}
+
+	if !node.IsEmpty() {
+		where = append(where, b.node(node))
+	}
+
+	if search != "" {
+		where = append(where, b.search(search))
+	} else if len(filters) > 0 {
+		where = append(where, b.filters(filters, true))
+	}
+
+	s.ensureSortIndex(entity, sortField)
+
+	out, err := s.selectDocuments(ctx, where, b.args, orderBy(sortField, sortAscending), limit, offset)
+	if err != nil {
+		return []map[string]any{}
+	}
+
+	includesParam = api_storage.MergeIncludes(includesParam, api_storage.ExtractAutoIncludes(filters))
+	if includesParam != "" {
+		_, span := tracing.Start(ctx, "ApplyIncludes", attribute.String("elysiandb.includes", includesParam))
+		out = s.ApplyIncludes(out, includesParam)
+		span.End()
+	}
+
+	return out
+}
+
+func (s Session) ExecuteQuery(q query.Query) ([]map[string]any, error) {
+	sortField := ""
+	sortAsc := true
+
+	for field, dir := range q.Sorts {
+		sortField = field
+		sortAsc = strings.ToLower(dir) != "desc"
+		break
+	}
+
+	b := &builder{}
+	where := []string{"e.entity = " + b.bind(q.Entity)}
+	where = append(where, b.node(q.Filter))
+
+	s.ensureSortIndex(q.Entity, sortField)
+
+	return s.selectDocuments(context.Background(), where, b.args, orderBy(sortField, sortAsc), q.Limit, q.Offset)
+}
+
+func (s Session) GetListOfIds(entity, sortField string, sortAscending bool) []string {
+	s.ensureSortIndex(entity, sortField)
+
+	rows, err := s.q.QueryContext(
+		context.Background(),
+		"SELECT e.id FROM documents e WHERE e.entity = ? ORDER BY "+orderBy(sortField, sortAscending),
+		entity,
+	)
+	if err != nil {
+		return []string{}
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var id string
+		if rows.Scan(&id) == nil {
+			out = append(out, id)
+		}
+	}
+
+	return out
+}
+
+func (s Session) selectDocuments(
+	ctx context.Context,
+	where []string,
+	args []any,
+	order string,
+	limit int,
+	offset int,
+) ([]map[string]any, error) {
+	stmt := "SELECT e.data FROM documents e WHERE " + strings.Join(where, " AND ") + " ORDER BY " + order
+
+	if limit > 0 || offset > 0 {
+		if limit <= 0 {
+			limit = -1
+		}
+
+		stmt += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+
+	ctx, span := tracing.Start(ctx, "sqlite.select", semconv.DBSystemNameSQLite, semconv.DBOperationName("SELECT"))
+	start := time.Now()
+
+	out, err := s.scanDocuments(ctx, stmt, args)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+
+	selectDuration.Observe(time.Since(start).Seconds(), outcome)
+	tracing.EndWithError(span, err)
+
+	return out, err
+}
+
+func (s Session) scanDocuments(ctx context.Context, stmt string, args []any) ([]map[string]any, error) {
+	rows, err := s.q.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []map[string]any{}
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+
+		if data := decodeDocument(raw); data != nil {
+			out = append(out, data)
+		}
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/taymour/elysiandb/internal/globals"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type Session struct {
+	q  querier
+	tx bool
+}
+
+func db() Session {
+	return Session{q: globals.SQLiteDB}
+}
+
+func InTransaction(fn func(s Session) error) error {
+	if globals.SQLiteDB == nil {
+		return errors.New("sqlite engine is not open")
+	}
+
+	tx, err := globals.SQLiteDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(Session{q: tx, tx: true}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+func sortKind(f field) string {
+	return fmt.Sprintf(
+		"CASE %s WHEN 'true' THEN 1 WHEN 'false' THEN 1 WHEN 'integer' THEN 2 WHEN 'real' THEN 2 "+
+			"WHEN 'text' THEN CASE WHEN ely_time(%s) IS NULL THEN 4 ELSE 3 END "+
+			"WHEN 'object' THEN 5 WHEN 'array' THEN 5 ELSE 0 END",
+		f.kind, f.value,
+	)
+}
+
+func sortKey(f field) string {
+	return fmt.Sprintf("COALESCE(ely_time(%s), %s)", f.value, f.value)
+}
+
+func orderBy(sortField string, ascending bool) string {
+	if sortField == "" {
+		return "e.rowid"
+	}
+
+	dir := "ASC"
+	if !ascending {
+		dir = "DESC"
+	}
+
+	f := fieldOf(sortField)
+
+	return fmt.Sprintf("%s %s, %s %s, e.id %s", sortKind(f), dir, sortKey(f), dir, dir)
+}
+
+func indexName(prefix, field string) string {
+	h := fnv.New64a()
+	h.Write([]byte(field))
+
+	return fmt.Sprintf("%s_%x", prefix, h.Sum64())
+}
+
+func (s Session) sortIndexAllowed(entity, sortField string) bool {
+	if slices.Contains(globals.GetConfig().Engine.SortIndexes[entity], sortField) {
+		return true
+	}
+
+	schemaData := s.GetEntitySchema(entity)
+	if !schema.IsManualSchema(entity, schemaData) {
+		return false
+	}
+
+	declared, _ := schemaData["fields"].(map[string]any)
+	_, ok := declared[sortField]
+
+	return ok
+}
+
+func (s Session) ensureSortIndex(entity, sortField string) {
+	if s.tx || sortField == "" {
+		return
+	}
+
+	f := fieldAt("data", sortField)
+	if !f.plain {
+		return
+	}
+
+	name := indexName("ely_sort", sortField)
+	if _, ok := createdIndexes.Load(name); ok || !s.sortIndexAllowed(entity, sortField) {
+		return
+	}
+
+	ctx := context.Background()
+
+	var exists int
+	if err := s.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?", name).Scan(&exists); err != nil {
+		return
+	}
+
+	if exists == 0 {
+		stmt := fmt.Sprintf(
+			"CREATE INDEX %s ON documents (entity, %s, %s, id)",
+			name, sortKind(f), sortKey(f),
+		)
+
+		if _, err := s.q.ExecContext(ctx, stmt); err != nil {
+			return
+		}
+
+		_, _ = s.q.ExecContext(ctx, "ANALYZE documents")
+	}
+
+	createdIndexes.Store(name, struct{}{})
+}
@@ -0,0 +1,447 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/google/uuid"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+const readBatchSize = 500
+
+func WriteEntity(entity string, data map[string]any) []schema.ValidationError {
+	return db().WriteEntity(entity, data)
+}
+
+func WriteListOfEntities(entity string, list []map[string]any) [][]schema.ValidationError {
+	return db().WriteListOfEntities(entity, list)
+}
+
+func UpdateEntitySchema(entity string, fieldsRaw map[string]any) map[string]any {
+	return db().UpdateEntitySchema(entity, fieldsRaw)
+}
+
+func CreateEntityType(entity string) error {
+	return db().CreateEntityType(entity)
+}
+
+func DeleteEntityType(entity string) error {
+	return db().DeleteEntityType(entity)
+}
+
+func AddEntityType(entity string) {
+	db().AddEntityType(entity)
+}
+
+func GetEntitySchema(entity string) map[string]any {
+	return db().GetEntitySchema(entity)
+}
+
+func EntityTypeExists(entity string) bool {
+	return db().EntityTypeExists(entity)
+}
+
+func ListEntityTypes() []string {
+	return db().ListEntityTypes()
+}
+
+func ListPublicEntityTypes() []string {
+	return db().ListPublicEntityTypes()
+}
+
+func ReadEntityById(entity, id string) map[string]any {
+	return db().ReadEntityById(entity, id)
+}
+
+func ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	return db().ReadEntitiesByIds(entity, ids)
+}
+
+func EntityExists(entity, id string) bool {
+	return db().EntityExists(entity, id)
+}
+
+func UpdateEntityById(entity, id string, updated map[string]any) map[string]any {
+	return db().UpdateEntityById(entity, id, updated)
+}
+
+func UpdateListOfEntities(entity string, updates []map[string]any) []map[string]any {
+	return db().UpdateListOfEntities(entity, updates)
+}
+
+func DeleteEntityById(entity, id string) {
+	db().DeleteEntityById(entity, id)
+}
+
+func DeleteAllEntities(entity string) {
+	db().DeleteAllEntities(entity)
+}
+
+func DeleteAll() {
+	db().DeleteAll()
+}
+
+func DumpAll() map[string]any {
+	return db().DumpAll()
+}
+
+func ImportAll(data map[string][]map[string]any) {
+	db().ImportAll(data)
+}
+
+func CountAllEntities() int {
+	return db().CountAllEntities()
+}
+
+func FilterFields(data map[string]any, fields []string) map[string]any {
+	return api_storage.FilterFields(data, fields)
+}
+
+func ApplyFiltersToList(entities []map[string]any, filters map[string]map[string]string) []map[string]any {
+	return api_storage.ApplyFiltersToList(entities, filters)
+}
+
+func (s Session) WriteEntity(entity string, data map[string]any) []schema.ValidationError {
+	schemaData := s.GetEntitySchema(entity)
+	if globals.GetConfig().Api.Schema.Enabled && entity != schema.SchemaEntity {
+		errors := schema.ValidateEntity(entity, data, schemaData)
+		if len(errors) > 0 {
+			return errors
+		}
+	}
+
+	if _, ok := data["id"].(string); !ok || data["id"] == "" {
+		data["id"] = uuid.New().String()
+	}
+
+	subs := api_storage.ExtractSubEntities(entity, data)
+	for _, sub := range subs {
+		subEntity := sub["@entity"].(string)
+		delete(sub, "@entity")
+		s.WriteEntity(subEntity, sub)
+	}
+
+	if err := s.persist(entity, data); err != nil {
+		return []schema.ValidationError{{Message: err.Error()}}
+	}
+
+	if !schema.IsManualSchema(entity, schemaData) || !globals.GetConfig().Api.Schema.Strict {
+		s.updateSchemaIfNeeded(entity, data)
+	}
+
+	return []schema.ValidationError{}
+}
+
+func (s Session) persist(entity string, data map[string]any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	id, _ := data["id"].(string)
+
+	_, err = s.q.ExecContext(
+		context.Background(),
+		`INSERT INTO documents (entity, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (entity, id) DO UPDATE SET data = excluded.data`,
+		entity, id, string(raw),
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.addEntityType(entity)
+}
+
+func (s Session) updateSchemaIfNeeded(entity string, data map[string]any) {
+	if globals.GetConfig().Api.Schema.Enabled && entity != schema.SchemaEntity {
+		analyzed := schema.AnalyzeEntitySchema(entity, data)
+		s.WriteEntity(schema.SchemaEntity, analyzed)
+	}
+}
+
+func (s Session) WriteListOfEntities(entity string, list []map[string]any) [][]schema.ValidationError {
+	out := make([][]schema.ValidationError, 0, len(list))
+	for _, data := range list {
+		out = append(out, s.WriteEntity(entity, data))
+	}
+
+	return out
+}
+
+func (s Session) UpdateEntitySchema(entity string, fieldsRaw map[string]any) map[string]any {
+	entitySchema := schema.Entity{
+		ID:     entity,
+		Fields: schema.MapToFields(fieldsRaw),
+	}
+
+	storable := schema.SchemaEntityToStorableStructure(entitySchema)
+	storable["_manual"] = true
+
+	s.WriteEntity(schema.SchemaEntity, storable)
+
+	return storable
+}
+
+func (s Session) CreateEntityType(entity string) error {
+	if s.EntityTypeExists(entity) {
+		return fmt.Errorf("entity type '%s' already exists", entity)
+	}
+
+	return s.addEntityType(entity)
+}
+
+func (s Session) DeleteEntityType(entity string) error {
+	if !s.EntityTypeExists(entity) {
+		return fmt.Errorf("entity type '%s' does not exist", entity)
+	}
+
+	return s.deleteEntities(entity)
+}
+
+func (s Session) AddEntityType(entity string) {
+	_ = s.addEntityType(entity)
+}
+
+func (s Session) addEntityType(entity string) error {
+	_, err := s.q.ExecContext(context.Background(), "INSERT OR IGNORE INTO entity_types (name) VALUES (?)", entity)
+
+	return err
+}
+
+func (s Session) deleteEntities(entity string) error {
+	ctx := context.Background()
+
+	if _, err := s.q.ExecContext(ctx, "DELETE FROM documents WHERE entity = ?", entity); err != nil {
+		return err
+	}
+
+	_, err := s.q.ExecContext(ctx, "DELETE FROM entity_types WHERE name = ?", entity)
+
+	return err
+}
+
+func (s Session) GetEntitySchema(entity string) map[string]any {
+	return s.ReadEntityById(schema.SchemaEntity, entity)
+}
+
+func (s Session) EntityTypeExists(entity string) bool {
+	var n int
+	err := s.q.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM entity_types WHERE name = ?", entity).Scan(&n)
+
+	return err == nil && n > 0
+}
+
+func (s Session) ListEntityTypes() []string {
+	rows, err := s.q.QueryContext(context.Background(), "SELECT name FROM entity_types ORDER BY rowid")
+	if err != nil {
+		return []string{}
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			out = append(out, name)
+		}
+	}
+
+	return out
+}
+
+func (s Session) ListPublicEntityTypes() []string {
+	out := []string{}
+	for _, entity := range s.ListEntityTypes() {
+		if !strings.HasPrefix(entity, api_storage.CoreEntityTypePrefix) {
+			out = append(out, entity)
+		}
+	}
+
+	return out
+}
+
+func (s Session) ReadEntityById(entity, id string) map[string]any {
+	var raw string
+	err := s.q.QueryRowContext(
+		context.Background(),
+		"SELECT data FROM documents WHERE entity = ? AND id = ?",
+		entity, id,
+	).Scan(&raw)
+	if err != nil {
+		return nil
+	}
+
+	return decodeDocument(raw)
+}
+
+func (s Session) ReadEntitiesByIds(entity string, ids []string) map[string]map[string]any {
+	out := make(map[string]map[string]any, len(ids))
+	for id, raw := range s.readRaw(entity, ids) {
+		if data := decodeDocument(raw); data != nil {
+			out[id] = data
+		}
+	}
+
+	return out
+}
+
+func (s Session) readRaw(entity string, ids []string) map[string]string {
+	out := make(map[string]string, len(ids))
+
+	for start := 0; start < len(ids); start += readBatchSize {
+		batch := ids[start:min(start+readBatchSize, len(ids))]
+
+		args := make([]any, 0, len(batch)+1)
+		args = append(args, entity)
+		for _, id := range batch {
+			args = append(args, id)
+		}
+
+		rows, err := s.q.QueryContext(
+			context.Background(),
+			"SELECT id, data FROM documents WHERE entity = ? AND id IN (?"+strings.Repeat(", ?", len(batch)-1)+")",
+			args...,
+		)
+		if err != nil {
+			return out
+		}
+
+		for rows.Next() {
+			var id, raw string
+			if rows.Scan(&id, &raw) == nil {
+				out[id] = raw
+			}
+		}
+
+		rows.Close()
+	}
+
+	return out
+}
+
+func decodeDocument(raw string) map[string]any {
+	var data map[string]any
+	if json.Unmarshal([]byte(raw), &data) != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (s Session) EntityExists(entity, id string) bool {
+	var n int
+	err := s.q.QueryRowContext(
+		context.Background(),
+		"SELECT COUNT(*) FROM documents WHERE entity = ? AND id = ?",
+		entity, id,
+	).Scan(&n)
+
+	return err == nil && n > 0
+}
+
+func (s Session) UpdateEntityById(entity, id string, updated map[string]any) map[string]any {
+	existing := s.ReadEntityById(entity, id)
+	if existing == nil {
+		return nil
+	}
+
+	maps.Copy(existing, updated)
+
+	if _, ok := existing["id"].(string); !ok || existing["id"] == "" {
+		existing["id"] = id
+	}
+
+	subs := api_storage.ExtractSubEntities(entity, existing)
+	for _, sub := range subs {
+		subEntity := sub["@entity"].(string)
+		delete(sub, "@entity")
+		s.WriteEntity(subEntity, sub)
+	}
+
+	if s.persist(entity, existing) != nil {
+		return nil
+	}
+
+	s.updateSchemaIfNeeded(entity, existing)
+
+	return existing
+}
+
+func (s Session) UpdateListOfEntities(entity string, updates []map[string]any) []map[string]any {
+	out := make([]map[string]any, 0, len(updates))
+	for _, upd := range updates {
+		id, ok := upd["id"].(string)
+		if !ok || id == "" {
+			continue
+		}
+
+		if res := s.UpdateEntityById(entity, id, upd); res != nil {
+			out = append(out, res)
+		}
+	}
+
+	return out
+}
+
+func (s Session) DeleteEntityById(entity, id string) {
+	_, _ = s.q.ExecContext(context.Background(), "DELETE FROM documents WHERE entity = ? AND id = ?", entity, id)
+}
+
+func (s Session) DeleteAllEntities(entity string) {
+	_ = s.deleteEntities(entity)
+}
+
+func (s Session) DeleteAll() {
+	for _, entity := range s.ListEntityTypes() {
+		s.DeleteAllEntities(entity)
+	}
+}
+
+func (s Session) DumpAll() map[string]any {
+	out := map[string]any{}
+	for _, entity := range s.ListEntityTypes() {
+		if entity == schema.SchemaEntity {
+			continue
+		}
+
+		out[entity] = s.ListEntities(context.Background(), entity, 0, 0, "", true, nil, "", "", emptyNode)
+	}
+
+	return out
+}
+
+func (s Session) ImportAll(data map[string][]map[string]any) {
+	for entity, items := range data {
+		for _, item := range items {
+			if errs := s.WriteEntity(entity, item); len(errs) > 0 {
+				log.WithFields(log.Fields{"entity": entity, "errors": fmt.Sprintf("%+v", errs)}).Error("Error importing entity")
+			}
+		}
+
+		log.Info(fmt.Sprintf("The entity '%s' has been imported", entity))
+	}
+
+	log.Info("All entities have been imported")
+}
+
+func (s Session) CountAllEntities() int {
+	var n int
+	err := s.q.QueryRowContext(
+		context.Background(),
+		"SELECT COUNT(*) FROM documents WHERE entity != ?",
+		schema.SchemaEntity,
+	).Scan(&n)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
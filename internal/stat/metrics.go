@@ -0,0 +1,30 @@
+package stat
+
+import (
+	"time"
+
+	"github.com/taymour/elysiandb/internal/metrics"
+)
+
+var startedAt = time.Now()
+
+var (
+	_ = metrics.NewGaugeFunc("elysiandb_keys", "Keys currently held by the internal engine.", func() float64 {
+		return float64(Stats.keysCount.Load())
+	})
+	_ = metrics.NewGaugeFunc("elysiandb_expiration_keys", "Keys with a pending expiration.", func() float64 {
+		return float64(Stats.expirationKeysCount.Load())
+	})
+	_ = metrics.NewGaugeFunc("elysiandb_uptime_seconds", "Seconds since the process started.", func() float64 {
+		return time.Since(startedAt).Seconds()
+	})
+	_ = metrics.NewCounterFunc("elysiandb_kv_requests_total", "KV requests served over HTTP and TCP.", func() float64 {
+		return float64(Stats.totalRequests.Load())
+	})
+	_ = metrics.NewCounterFunc("elysiandb_kv_hits_total", "KV reads that found a key.", func() float64 {
+		return float64(Stats.hits.Load())
+	})
+	_ = metrics.NewCounterFunc("elysiandb_kv_misses_total", "KV reads that did not find a key.", func() float64 {
+		return float64(Stats.misses.Load())
+	})
+)
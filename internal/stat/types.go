@@ -14,8 +14,10 @@ type StatsContainer struct {
 	totalRequests       atomic.Uint64
 	hits                atomic.Uint64
 	misses              atomic.Uint64
+	evictedKeys         atomic.Uint64
 	version             string
 	EntitiesCount       int
+	MemoryUsedBytes     int64
 }
 
 func NewStatsContainer() *StatsContainer {
@@ -28,6 +30,7 @@ func (s *StatsContainer) IncrementTotalRequests()             { s.totalRequests.
 func (s *StatsContainer) IncrementUptimeSeconds()             { s.uptimeSeconds.Add(1) }
 func (s *StatsContainer) IncrementHits()                      { s.hits.Add(1) }
 func (s *StatsContainer) IncrementMisses()                    { s.misses.Add(1) }
+func (s *StatsContainer) IncrementEvictedKeys()               { s.evictedKeys.Add(1) }
 func (s *StatsContainer) SetKeysCount(count uint64)           { s.keysCount.Store(count) }
 func (s *StatsContainer) SetExpirationKeysCount(count uint64) { s.expirationKeysCount.Store(count) }
 
@@ -64,6 +67,7 @@ func (s *StatsContainer) Reset() {
 	s.totalRequests.Store(0)
 	s.hits.Store(0)
 	s.misses.Store(0)
+	s.evictedKeys.Store(0)
 }
 
 type statsDTO struct {
@@ -73,6 +77,8 @@ type statsDTO struct {
 	TotalRequests       uint64 `json:"total_requests,string"`
 	Hits                uint64 `json:"hits,string"`
 	Misses              uint64 `json:"misses,string"`
+	EvictedKeys         uint64 `json:"evicted_keys,string"`
+	MemoryUsedBytes     int64  `json:"memory_used_bytes,string"`
 	Version             string `json:"version"`
 	EntitiesCount       int    `json:"entities_count,string"`
 }
@@ -85,6 +91,8 @@ func (s *StatsContainer) ToJson() string {
 		TotalRequests:       s.totalRequests.Load(),
 		Hits:                s.hits.Load(),
 		Misses:              s.misses.Load(),
+		EvictedKeys:         s.evictedKeys.Load(),
+		MemoryUsedBytes:     s.MemoryUsedBytes,
 		Version:             s.version,
 		EntitiesCount:       s.EntitiesCount,
 	}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/metrics"
+	"github.com/taymour/elysiandb/internal/stat"
+)
+
+const (
+	EvictionNone        = "noeviction"
+	EvictionAllKeysLRU  = "allkeys-lru"
+	EvictionAllKeysLFU  = "allkeys-lfu"
+	EvictionVolatileTTL = "volatile-ttl"
+
+	evictionSamples = 16
+	samplesPerShard = 4
+)
+
+var ErrMemoryLimit = errors.New("store memory limit reached")
+
+var (
+	evictions = metrics.NewCounterVec(
+		"elysiandb_kv_evicted_keys_total",
+		"Keys evicted to keep the key/value store under store.maxMemoryMB.",
+		"policy",
+	)
+	_ = metrics.NewGaugeFunc("elysiandb_kv_memory_bytes", "Estimated memory used by the key/value store.", func() float64 {
+		return float64(MemoryUsage())
+	})
+)
+
+type candidate struct {
+	key   string
+	score int64
+}
+
+func MemoryUsage() int64 {
+	rootMu.RLock()
+	ms := mainStore
+	rootMu.RUnlock()
+
+	if ms == nil {
+		return 0
+	}
+
+	return ms.MemoryUsage()
+}
+
+func memoryLimit() int64 {
+	return int64(globals.GetConfig().Store.MaxMemoryMB) * 1024 * 1024
+}
+
+func evictionPolicy() string {
+	if p := globals.GetConfig().Store.EvictionPolicy; p != "" {
+		return p
+	}
+
+	return EvictionNone
+}
+
+func isProtectedKey(key string) bool {
+	return strings.HasPrefix(key, globals.ApiEntityKey("")) || strings.HasPrefix(key, globals.CoreFieldsPrefix)
+}
+
+func makeRoom(key string, value []byte) error {
+	limit := memoryLimit()
+	if limit <= 0 {
+		return nil
+	}
+
+	needed := mainStore.sizeDelta(key, value)
+	if needed <= 0 {
+		return nil
+	}
+
+	protected := isProtectedKey(key)
+	if !protected && entrySize(key, value) > limit {
+		return ErrMemoryLimit
+	}
+
+	policy := evictionPolicy()
+	for mainStore.MemoryUsage()+needed > limit {
+		victim, ok := pickVictim(policy, key)
+		if !ok {
+			if protected {
+				return nil
+			}
+
+			return ErrMemoryLimit
+		}
+
+		evict(victim, policy)
+	}
+
+	return nil
+}
+
+func enforceMemoryLimit() {
+	limit := memoryLimit()
+	if limit <= 0 || mainStore.MemoryUsage() <= limit {
+		return
+	}
+
+	policy := evictionPolicy()
+	evicted := 0
+	for mainStore.MemoryUsage() > limit {
+		victim, ok := pickVictim(policy, "")
+		if !ok {
+			log.Warn("Store uses ", mainStore.MemoryUsage(), " bytes, above store.maxMemoryMB, and policy ", policy, " cannot free more")
+			break
+		}
+
+		evict(victim, policy)
+		evicted++
+	}
+
+	if evicted > 0 {
+		log.Info("Evicted ", evicted, " keys to fit store.maxMemoryMB")
+	}
+}
+
+func evict(key, policy string) {
+	DeleteByKey(key)
+	evictions.Inc(policy)
+
+	if globals.GetConfig().Stats.Enabled {
+		stat.Stats.IncrementEvictedKeys()
+	}
+}
+
+func pickVictim(policy, exclude string) (string, bool) {
+	var candidates []candidate
+
+	switch policy {
+	case EvictionAllKeysLRU, EvictionAllKeysLFU:
+		candidates = mainStore.sample(policy, exclude)
+	case EvictionVolatileTTL:
+		candidates = expirationContainer.sample(exclude)
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.score < best.score {
+			best = c
+		}
+	}
+
+	return best.key, true
+}
+
+func (s *Store) sample(policy, exclude string) []candidate {
+	now := time.Now().UnixNano()
+	candidates := make([]candidate, 0, evictionSamples)
+
+	start := rand.IntN(s.shardCount)
+	for i := 0; i < s.shardCount && len(candidates) < evictionSamples; i++ {
+		sh := s.shards[(start+i)%s.shardCount]
+		taken := 0
+
+		sh.mu.RLock()
+		for k, e := range sh.m {
+			if taken == samplesPerShard || len(candidates) == evictionSamples {
+				break
+			}
+
+			if k == exclude || isProtectedKey(k) {
+				continue
+			}
+
+			score := e.accessed.Load()
+			if policy == EvictionAllKeysLFU {
+				score = int64(e.frequency(now))
+			}
+
+			candidates = append(candidates, candidate{key: k, score: score})
+			taken++
+		}
+		sh.mu.RUnlock()
+	}
+
+	return candidates
+}
+
+func (c *ExpirationContainer) sample(exclude string) []candidate {
+	candidates := make([]candidate, 0, evictionSamples)
+
+	c.mu.RLock()
+	for k, ts := range c.index {
+		if len(candidates) == evictionSamples {
+			break
+		}
+
+		if k == exclude || isProtectedKey(k) {
+			continue
+		}
+
+		candidates = append(candidates, candidate{key: k, score: ts})
+	}
+	c.mu.RUnlock()
+
+	return candidates
+}
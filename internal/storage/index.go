@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/btree"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+)
+
+const indexBTreeDegree = 32
+
+const (
+	SortKindMissing uint8 = iota
+	SortKindBool
+	SortKindNumber
+	SortKindTime
+	SortKindString
+	SortKindOther
+)
+
+type SortKey struct {
+	Kind uint8   `json:"k"`
+	Num  float64 `json:"n,omitempty"`
+	Int  int64   `json:"i,omitempty"`
+	Str  string  `json:"s,omitempty"`
+}
+
+func (a SortKey) Compare(b SortKey) int {
+	if a.Kind != b.Kind {
+		return compareOrdered(a.Kind, b.Kind)
+	}
+
+	if c := compareOrdered(a.Num, b.Num); c != 0 {
+		return c
+	}
+
+	if c := compareOrdered(a.Int, b.Int); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.Str, b.Str)
+}
+
+func compareOrdered[T float64 | int64 | uint8](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type indexEntry struct {
+	key SortKey
+	id  string
+}
+
+func lessIndexEntry(a, b indexEntry) bool {
+	if c := a.key.Compare(b.key); c != 0 {
+		return c < 0
+	}
+
+	return a.id < b.id
+}
+
+type OrderedIndex struct {
+	mu    sync.RWMutex
+	tree  *btree.BTreeG[indexEntry]
+	keys  map[string]SortKey
+	seq   int64
+	saved atomic.Bool
+}
+
+func NewOrderedIndex() *OrderedIndex {
+	idx := &OrderedIndex{
+		tree: btree.NewG(indexBTreeDegree, lessIndexEntry),
+		keys: map[string]SortKey{},
+	}
+
+	idx.saved.Store(true)
+
+	return idx
+}
+
+func (idx *OrderedIndex) Set(id string, key SortKey) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	return idx.set(id, key)
+}
+
+func (idx *OrderedIndex) set(id string, key SortKey) bool {
+	if old, ok := idx.keys[id]; ok {
+		if old.Compare(key) == 0 {
+			return false
+		}
+
+		idx.tree.Delete(indexEntry{key: old, id: id})
+	}
+
+	idx.tree.ReplaceOrInsert(indexEntry{key: key, id: id})
+	idx.keys[id] = key
+
+	if key.Kind == SortKindNumber && int64(key.Num) > idx.seq {
+		idx.seq = int64(key.Num)
+	}
+
+	idx.saved.Store(false)
+
+	return true
+}
+
+func (idx *OrderedIndex) Append(id string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if _, ok := idx.keys[id]; ok {
+		return false
+	}
+
+	return idx.set(id, SortKey{Kind: SortKindNumber, Num: float64(idx.seq + 1)})
+}
+
+func (idx *OrderedIndex) Delete(id string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	key, ok := idx.keys[id]
+	if !ok {
+		return false
+	}
+
+	idx.tree.Delete(indexEntry{key: key, id: id})
+	delete(idx.keys, id)
+	idx.saved.Store(false)
+
+	return true
+}
+
+func (idx *OrderedIndex) Key(id string) (SortKey, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	key, ok := idx.keys[id]
+
+	return key, ok
+}
+
+func (idx *OrderedIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.tree.Len()
+}
+
+func (idx *OrderedIndex) Ids(ascending bool, offset, limit int) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	size := idx.tree.Len() - offset
+	if limit > 0 && limit < size {
+		size = limit
+	}
+
+	if size <= 0 {
+		return []string{}
+	}
+
+	out := make([]string, 0, size)
+	skipped := 0
+	visit := func(e indexEntry) bool {
+		if skipped < offset {
+			skipped++
+			return true
+		}
+
+		out = append(out, e.id)
+
+		return len(out) < size
+	}
+
+	if ascending {
+		idx.tree.Ascend(visit)
+	} else {
+		idx.tree.Descend(visit)
+	}
+
+	return out
+}
+
+type persistedIndex struct {
+	Seq     int64            `json:"seq"`
+	Entries []persistedEntry `json:"entries"`
+}
+
+type persistedEntry struct {
+	ID  string  `json:"id"`
+	Key SortKey `json:"key"`
+}
+
+func (idx *OrderedIndex) toPersisted() persistedIndex {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	p := persistedIndex{Seq: idx.seq, Entries: make([]persistedEntry, 0, idx.tree.Len())}
+	idx.tree.Ascend(func(e indexEntry) bool {
+		p.Entries = append(p.Entries, persistedEntry{ID: e.id, Key: e.key})
+		return true
+	})
+
+	return p
+}
+
+func orderedIndexFromPersisted(p persistedIndex) *OrderedIndex {
+	idx := NewOrderedIndex()
+	for _, e := range p.Entries {
+		idx.set(e.ID, e.Key)
+	}
+
+	if p.Seq > idx.seq {
+		idx.seq = p.Seq
+	}
+
+	idx.saved.Store(true)
+
+	return idx
+}
+
+type IndexSet struct {
+	indexes sync.Map
+	dropped atomic.Bool
+}
+
+func (s *IndexSet) get(name string) (*OrderedIndex, bool) {
+	v, ok := s.indexes.Load(name)
+	if !ok {
+		return nil, false
+	}
+
+	return v.(*OrderedIndex), true
+}
+
+func (s *IndexSet) unsaved() bool {
+	if s.dropped.Load() {
+		return true
+	}
+
+	unsaved := false
+	s.indexes.Range(func(_, v any) bool {
+		unsaved = !v.(*OrderedIndex).saved.Load()
+		return !unsaved
+	})
+
+	return unsaved
+}
+
+func (s *IndexSet) clear() {
+	s.indexes.Range(func(k, _ any) bool {
+		s.indexes.Delete(k)
+		return true
+	})
+
+	s.dropped.Store(true)
+}
+
+func currentIndexSet() *IndexSet {
+	js := mainJsonStore.Load()
+	if js == nil {
+		return nil
+	}
+
+	return js.indexes
+}
+
+func GetIndex(name string) (*OrderedIndex, bool) {
+	s := currentIndexSet()
+	if s == nil {
+		return nil, false
+	}
+
+	return s.get(name)
+}
+
+func GetOrCreateIndex(name string) *OrderedIndex {
+	s := currentIndexSet()
+	if s == nil {
+		return NewOrderedIndex()
+	}
+
+	if idx, ok := s.get(name); ok {
+		return idx
+	}
+
+	v, _ := s.indexes.LoadOrStore(name, NewOrderedIndex())
+
+	return v.(*OrderedIndex)
+}
+
+func PutIndex(name string, idx *OrderedIndex) {
+	s := currentIndexSet()
+	if s == nil {
+		return
+	}
+
+	idx.saved.Store(false)
+	s.indexes.Store(name, idx)
+}
+
+func DeleteIndex(name string) {
+	s := currentIndexSet()
+	if s == nil {
+		return
+	}
+
+	if _, loaded := s.indexes.LoadAndDelete(name); loaded {
+		s.dropped.Store(true)
+	}
+}
+
+func DeleteIndexesByPattern(pattern string) int {
+	s := currentIndexSet()
+	if s == nil {
+		return 0
+	}
+
+	deleted := 0
+	s.indexes.Range(func(k, _ any) bool {
+		if MatchGlob(pattern, k.(string)) {
+			s.indexes.Delete(k)
+			deleted++
+		}
+		return true
+	})
+
+	if deleted > 0 {
+		s.dropped.Store(true)
+	}
+
+	return deleted
+}
+
+func ListIndexNames() []string {
+	s := currentIndexSet()
+	if s == nil {
+		return nil
+	}
+
+	names := []string{}
+	s.indexes.Range(func(k, _ any) bool {
+		names = append(names, k.(string))
+		return true
+	})
+
+	return names
+}
+
+func ListJsonKeysByPrefix(prefix string) []string {
+	js := mainJsonStore.Load()
+	if js == nil {
+		return nil
+	}
+
+	keys := []string{}
+	for _, sh := range js.shards {
+		sh.m.Range(func(k, _ any) bool {
+			if strings.HasPrefix(k.(string), prefix) {
+				keys = append(keys, k.(string))
+			}
+			return true
+		})
+	}
+
+	return keys
+}
+
+func readIndexesFromDB(fileName string) *IndexSet {
+	s := &IndexSet{}
+
+	file, err := os.Open(globals.GetConfig().Store.Folder + "/" + fileName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Error("Error opening index snapshot:", err)
+		}
+
+		return s
+	}
+
+	defer file.Close()
+
+	raw, _ := io.ReadAll(file)
+	if len(raw) == 0 {
+		return s
+	}
+
+	data := map[string]persistedIndex{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		log.Error("Error decoding index snapshot, indexes will be rebuilt:", err)
+		return s
+	}
+
+	for name, p := range data {
+		s.indexes.Store(name, orderedIndexFromPersisted(p))
+	}
+
+	return s
+}
+
+func writeIndexesToFile(cfg *configuration.Config, fileName string, s *IndexSet) error {
+	if !s.unsaved() {
+		return nil
+	}
+
+	s.dropped.Store(false)
+
+	indexes := map[string]*OrderedIndex{}
+	s.indexes.Range(func(k, v any) bool {
+		idx := v.(*OrderedIndex)
+		idx.saved.Store(true)
+		indexes[k.(string)] = idx
+		return true
+	})
+
+	data := make(map[string]persistedIndex, len(indexes))
+	for name, idx := range indexes {
+		data[name] = idx.toPersisted()
+	}
+
+	path := cfg.Store.Folder + "/" + fileName
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		s.dropped.Store(true)
+		return err
+	}
+
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(data); err != nil {
+		s.dropped.Store(true)
+		return err
+	}
+
+	return nil
+}
@@ -22,12 +22,17 @@ var (
 func LoadDB() {
 	cfg := globals.GetConfig()
 
-	createFolder(cfg.Store.Folder)
-	createFile(cfg.Store.Folder, DataFile)
-	createFile(cfg.Store.Folder, ExpirationDataFile)
+	ms := NewStore()
+	ec := newExpirationContainer()
 
-	ms := createStore(DataFile)
-	ec := createExpirationContainer(ExpirationDataFile)
+	if !cfg.Store.IsEphemeral() {
+		createFolder(cfg.Store.Folder)
+		createFile(cfg.Store.Folder, DataFile)
+		createFile(cfg.Store.Folder, ExpirationDataFile)
+
+		ms = createStore(DataFile)
+		ec = createExpirationContainer(ExpirationDataFile)
+	}
 
 	rootMu.Lock()
 	mainStore = ms
@@ -35,6 +40,7 @@ func LoadDB() {
 	rootMu.Unlock()
 
 	CleanAllPastKeys()
+	enforceMemoryLimit()
 
 	if cfg.Stats.Enabled {
 		stat.Stats.SetKeysCount(mainStore.CountTotalKeys())
@@ -177,7 +183,15 @@ func PutKeyValue(key string, value []byte) error {
 
 func PutKeyValueWithTTL(key string, value []byte, ttl int) error {
 	cfg := globals.GetConfig()
-	_, existed := mainStore.get(key)
+
+	if err := makeRoom(key, value); err != nil {
+		return err
+	}
+
+	unlock := mainStore.lockWrites(key)
+	defer unlock()
+
+	existed := mainStore.has(key)
 	hadTTL := hasTTL(key)
 
 	mainStore.put(key, value)
@@ -198,6 +212,8 @@ func PutKeyValueWithTTL(key string, value []byte, ttl int) error {
 		}
 	}
 
+	recovery.StreamStorePut(key, value, ttl)
+
 	if cfg.Stats.Enabled && !existed {
 		stat.Stats.IncrementKeysCount()
 	}
@@ -208,7 +224,7 @@ func PutKeyValueWithTTL(key string, value []byte, ttl int) error {
 func DeleteByKey(key string) {
 	cfg := globals.GetConfig()
 
-	_, existed := mainStore.get(key)
+	existed := mainStore.has(key)
 	hadTTL := hasTTL(key)
 
 	mainStore.del(key)
@@ -229,6 +245,7 @@ func ResetStore() {
 
 	mainStore.reset()
 	expirationContainer.reset()
+	recovery.StreamStoreReset()
 	log.Info("Store has been reset")
 
 	if cfg.Stats.Enabled {
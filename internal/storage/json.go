@@ -23,6 +23,13 @@ var (
 
 func LoadJsonDB() {
 	cfg := globals.GetConfig()
+
+	if cfg.Store.IsEphemeral() {
+		GetJsonByKey = GetJsonByKeyImpl
+		mainJsonStore.Store(NewJsonStore())
+		return
+	}
+
 	createFolder(cfg.Store.Folder)
 	createFile(cfg.Store.Folder, JsonDataFile)
 	js := createJsonStore(JsonDataFile)
@@ -38,6 +45,7 @@ func createJsonStore(file string) *JsonStore {
 	newStore := NewJsonStore()
 	newStore.FromMap(data)
 	newStore.saved.Store(true)
+	newStore.indexes = readIndexesFromDB(IndexDataFile)
 
 	GetJsonByKey = GetJsonByKeyImpl
 
@@ -115,7 +123,13 @@ func DeleteJsonByPrefix(prefix string) int {
 	for _, sh := range js.shards {
 		sh.m.Range(func(k, _ any) bool {
 			if strings.HasPrefix(k.(string), pre) {
+				sh.write.Lock()
 				sh.m.Delete(k)
+				if cfg.Store.CrashRecovery.Enabled {
+					recovery.LogJsonDelete(k.(string))
+				}
+				recovery.StreamJsonDelete(k.(string))
+				sh.write.Unlock()
 				deleted++
 			}
 			return true
@@ -133,11 +147,13 @@ func DeleteJsonByPrefix(prefix string) int {
 }
 
 type jsonShard struct {
-	m sync.Map
+	m     sync.Map
+	write sync.Mutex
 }
 
 type JsonStore struct {
 	shards     []*jsonShard
+	indexes    *IndexSet
 	saved      atomic.Bool
 	shardMask  uint64
 	shardCount int
@@ -147,6 +163,7 @@ func NewJsonStore() *JsonStore {
 	n := globals.GetConfig().Store.Shards
 	s := &JsonStore{
 		shards:     make([]*jsonShard, n),
+		indexes:    &IndexSet{},
 		shardMask:  uint64(n - 1),
 		shardCount: n,
 	}
@@ -180,9 +197,12 @@ func (s *JsonStore) reset() {
 		s.shards[i].m = sync.Map{}
 	}
 
+	s.indexes.clear()
+
 	s.saved.Store(false)
 	if globals.GetConfig().Store.CrashRecovery.Enabled {
 		recovery.ClearJsonRecoveryLog()
+		recovery.LogJsonReset()
 	}
 }
 
@@ -203,20 +223,30 @@ func (s *JsonStore) get(key string) (map[string]any, bool) {
 
 func (s *JsonStore) put(key string, value map[string]any) {
 	sh := s.shards[s.shardIndex(key)]
+	sh.write.Lock()
+	defer sh.write.Unlock()
+
 	sh.m.Store(key, value)
 	s.saved.Store(false)
 	if globals.GetConfig().Store.CrashRecovery.Enabled {
 		recovery.LogJsonPut(key, value)
 	}
+
+	recovery.StreamJsonPut(key, value)
 }
 
 func (s *JsonStore) del(key string) {
 	sh := s.shards[s.shardIndex(key)]
+	sh.write.Lock()
+	defer sh.write.Unlock()
+
 	sh.m.Delete(key)
 	s.saved.Store(false)
 	if globals.GetConfig().Store.CrashRecovery.Enabled {
 		recovery.LogJsonDelete(key)
 	}
+
+	recovery.StreamJsonDelete(key)
 }
 
 func (s *JsonStore) Iterate(fn func(k string, v map[string]any)) {
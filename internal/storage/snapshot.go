@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/stat"
+)
+
+type Snapshot struct {
+	KeyValues   map[string][]byte
+	Expirations map[string]int64
+	Json        map[string]map[string]any
+}
+
+func IterateJsonValues(fn func(key string, value map[string]any)) {
+	js := mainJsonStore.Load()
+	if js == nil {
+		return
+	}
+
+	js.Iterate(fn)
+}
+
+func IterateKeyValues(fn func(key string, value []byte, expiresAt int64)) {
+	rootMu.RLock()
+	ms := mainStore
+	ec := expirationContainer
+	rootMu.RUnlock()
+
+	for k, v := range ms.ToMap() {
+		ec.mu.RLock()
+		exp := ec.index[k]
+		ec.mu.RUnlock()
+
+		fn(k, v, exp)
+	}
+}
+
+func ResetJsonStore() {
+	js := mainJsonStore.Load()
+	if js == nil {
+		return
+	}
+
+	js.reset()
+}
+
+func TakeSnapshot() *Snapshot {
+	var (
+		mu      sync.Mutex
+		pending []recovery.StreamOp
+		closed  bool
+	)
+
+	unsubscribe := recovery.AddStreamSink(func(op recovery.StreamOp) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !closed {
+			pending = append(pending, op)
+		}
+	})
+
+	rootMu.RLock()
+	ms := mainStore
+	ec := expirationContainer
+	rootMu.RUnlock()
+
+	s := &Snapshot{
+		KeyValues:   ms.ToMap(),
+		Expirations: ec.expirations(),
+		Json:        map[string]map[string]any{},
+	}
+
+	if js := mainJsonStore.Load(); js != nil {
+		s.Json = js.ToMap()
+	}
+
+	unsubscribe()
+
+	mu.Lock()
+	closed = true
+	ops := pending
+	mu.Unlock()
+
+	for _, op := range ops {
+		s.apply(op)
+	}
+
+	for key := range s.Expirations {
+		if _, ok := s.KeyValues[key]; !ok {
+			delete(s.Expirations, key)
+		}
+	}
+
+	return s
+}
+
+func (s *Snapshot) apply(op recovery.StreamOp) {
+	switch op.Store {
+	case recovery.StreamStoreKeyValue:
+		switch op.Op {
+		case recovery.StreamOpPut:
+			var value []byte
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				log.Error("Snapshot: skipping invalid key/value write for ", op.Key, ": ", err)
+				return
+			}
+
+			s.putKeyValue(op.Key, value, int64(op.TTL))
+		case recovery.StreamOpDelete:
+			s.deleteKeyValue(op.Key)
+		case recovery.StreamOpReset:
+			s.resetKeyValues()
+		}
+	case recovery.StreamStoreJson:
+		switch op.Op {
+		case recovery.StreamOpPut:
+			var value map[string]any
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				log.Error("Snapshot: skipping invalid json write for ", op.Key, ": ", err)
+				return
+			}
+
+			s.Json[op.Key] = value
+		case recovery.StreamOpDelete:
+			delete(s.Json, op.Key)
+		}
+	}
+}
+
+func (s *Snapshot) ApplyWal(r recovery.WalRecord) {
+	switch r.Store {
+	case recovery.StreamStoreKeyValue:
+		switch r.Op {
+		case recovery.StreamOpPut:
+			s.putKeyValue(r.Key, r.Value, r.ExpiresAt)
+		case recovery.StreamOpDelete:
+			s.deleteKeyValue(r.Key)
+		case recovery.StreamOpReset:
+			s.resetKeyValues()
+		}
+	case recovery.StreamStoreJson:
+		switch r.Op {
+		case recovery.StreamOpPut:
+			s.Json[r.Key] = r.Document
+		case recovery.StreamOpDelete:
+			delete(s.Json, r.Key)
+		case recovery.StreamOpReset:
+			s.Json = map[string]map[string]any{}
+		}
+	}
+}
+
+func (s *Snapshot) putKeyValue(key string, value []byte, expiresAt int64) {
+	s.KeyValues[key] = value
+	if expiresAt > 0 {
+		s.Expirations[key] = expiresAt
+	}
+}
+
+func (s *Snapshot) deleteKeyValue(key string) {
+	delete(s.KeyValues, key)
+	delete(s.Expirations, key)
+}
+
+func (s *Snapshot) resetKeyValues() {
+	s.KeyValues = map[string][]byte{}
+	s.Expirations = map[string]int64{}
+}
+
+func RestoreSnapshot(s *Snapshot) {
+	ms := NewStore()
+	ms.FromMap(s.KeyValues)
+	ms.saved.Store(false)
+
+	ec := newExpirationContainer()
+	for key, ts := range s.Expirations {
+		if _, ok := s.KeyValues[key]; ok {
+			ec.put(ts, []string{key})
+		}
+	}
+	ec.saved.Store(false)
+
+	rootMu.Lock()
+	mainStore = ms
+	expirationContainer = ec
+	rootMu.Unlock()
+
+	if s.Json != nil {
+		js := NewJsonStore()
+		js.FromMap(s.Json)
+		js.saved.Store(false)
+		mainJsonStore.Store(js)
+	}
+
+	enforceMemoryLimit()
+
+	if globals.GetConfig().Stats.Enabled {
+		stat.Stats.SetKeysCount(ms.CountTotalKeys())
+		stat.Stats.SetExpirationKeysCount(ec.CountTotalKeys())
+	}
+}
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	xxhash "github.com/cespare/xxhash/v2"
 	"github.com/taymour/elysiandb/internal/globals"
@@ -13,6 +15,7 @@ const (
 	DataFile           = "elysiandb.json"
 	JsonDataFile       = "elysiandbjson.json"
 	ExpirationDataFile = "elysiandb.expiration.json"
+	IndexDataFile      = "elysiandb.indexes.json"
 )
 
 type ExpirationContainer struct {
@@ -98,6 +101,18 @@ func (c *ExpirationContainer) ToMap() map[int64][]string {
 	return result
 }
 
+func (c *ExpirationContainer) expirations() map[string]int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make(map[string]int64, len(c.index))
+	for k, ts := range c.index {
+		result[k] = ts
+	}
+
+	return result
+}
+
 func (c *ExpirationContainer) FromMap(data map[int64][]string) {
 	for k, v := range data {
 		c.put(k, v)
@@ -149,14 +164,54 @@ func (c *ExpirationContainer) reset() {
 	c.mu.Unlock()
 }
 
+const entryOverhead = 64
+
+type entry struct {
+	value    []byte
+	accessed atomic.Int64
+	hits     atomic.Uint32
+}
+
+func newEntry(value []byte) *entry {
+	e := &entry{value: value}
+	e.accessed.Store(time.Now().UnixNano())
+	e.hits.Store(1)
+
+	return e
+}
+
+func (e *entry) touch() {
+	e.accessed.Store(time.Now().UnixNano())
+
+	if h := e.hits.Load(); h < math.MaxUint32 {
+		e.hits.CompareAndSwap(h, h+1)
+	}
+}
+
+func (e *entry) frequency(now int64) uint32 {
+	idle := (now - e.accessed.Load()) / int64(time.Minute)
+	if idle >= 32 {
+		return 0
+	}
+
+	return e.hits.Load() >> max(idle, 0)
+}
+
+func entrySize(key string, value []byte) int64 {
+	return int64(len(key)+len(value)) + entryOverhead
+}
+
 type shard struct {
-	mu sync.RWMutex
-	m  map[string][]byte
+	mu    sync.RWMutex
+	write sync.Mutex
+	m     map[string]*entry
+	used  int64
 }
 
 type Store struct {
 	shards     []*shard
 	saved      atomic.Bool
+	used       atomic.Int64
 	shardMask  uint64
 	shardCount int
 }
@@ -171,7 +226,7 @@ func NewStore() *Store {
 	}
 
 	for i := range n {
-		s.shards[i] = &shard{m: make(map[string][]byte)}
+		s.shards[i] = &shard{m: make(map[string]*entry)}
 	}
 
 	s.saved.Store(true)
@@ -191,11 +246,29 @@ func (s *Store) CountTotalKeys() uint64 {
 	return total
 }
 
+func (s *Store) MemoryUsage() int64 {
+	return s.used.Load()
+}
+
+func (s *Store) ShardMemoryUsage() []int64 {
+	usage := make([]int64, s.shardCount)
+	for i := 0; i < s.shardCount; i++ {
+		sh := s.shards[i]
+		sh.mu.RLock()
+		usage[i] = sh.used
+		sh.mu.RUnlock()
+	}
+
+	return usage
+}
+
 func (s *Store) reset() {
 	for i := 0; i < s.shardCount; i++ {
 		sh := s.shards[i]
 		sh.mu.Lock()
-		sh.m = make(map[string][]byte)
+		sh.m = make(map[string]*entry)
+		s.used.Add(-sh.used)
+		sh.used = 0
 		sh.mu.Unlock()
 	}
 
@@ -203,6 +276,7 @@ func (s *Store) reset() {
 
 	if globals.GetConfig().Store.CrashRecovery.Enabled {
 		recovery.ClearStoreRecoveryLog()
+		recovery.LogStoreReset()
 	}
 }
 
@@ -214,13 +288,37 @@ func (s *Store) shardIndex(key string) int {
 func (s *Store) get(key string) ([]byte, bool) {
 	sh := s.shards[s.shardIndex(key)]
 	sh.mu.RLock()
-	v, ok := sh.m[key]
+	e, ok := sh.m[key]
 	sh.mu.RUnlock()
 	if !ok {
 		return nil, false
 	}
 
-	return v, true
+	e.touch()
+
+	return e.value, true
+}
+
+func (s *Store) has(key string) bool {
+	sh := s.shards[s.shardIndex(key)]
+	sh.mu.RLock()
+	_, ok := sh.m[key]
+	sh.mu.RUnlock()
+
+	return ok
+}
+
+func (s *Store) sizeDelta(key string, value []byte) int64 {
+	delta := entrySize(key, value)
+
+	sh := s.shards[s.shardIndex(key)]
+	sh.mu.RLock()
+	if e, ok := sh.m[key]; ok {
+		delta -= entrySize(key, e.value)
+	}
+	sh.mu.RUnlock()
+
+	return delta
 }
 
 func (s *Store) put(key string, value []byte) {
@@ -229,30 +327,58 @@ func (s *Store) put(key string, value []byte) {
 
 	sh := s.shards[s.shardIndex(key)]
 	sh.mu.Lock()
-	sh.m[key] = buf
+	s.store(sh, key, buf)
 	sh.mu.Unlock()
 	s.saved.Store(false)
 }
 
+func (s *Store) store(sh *shard, key string, value []byte) {
+	delta := entrySize(key, value)
+	if e, ok := sh.m[key]; ok {
+		delta -= entrySize(key, e.value)
+	}
+
+	sh.m[key] = newEntry(value)
+	sh.used += delta
+	s.used.Add(delta)
+}
+
+func (s *Store) lockWrites(key string) func() {
+	sh := s.shards[s.shardIndex(key)]
+	sh.write.Lock()
+
+	return sh.write.Unlock
+}
+
 func (s *Store) del(key string) {
 	sh := s.shards[s.shardIndex(key)]
+	sh.write.Lock()
+	defer sh.write.Unlock()
+
 	sh.mu.Lock()
-	delete(sh.m, key)
+	if e, ok := sh.m[key]; ok {
+		size := entrySize(key, e.value)
+		delete(sh.m, key)
+		sh.used -= size
+		s.used.Add(-size)
+	}
 	sh.mu.Unlock()
 	s.saved.Store(false)
 
 	if globals.GetConfig().Store.CrashRecovery.Enabled {
 		recovery.LogStoreDelete(key)
 	}
+
+	recovery.StreamStoreDelete(key)
 }
 
 func (s *Store) Iterate(fn func(k string, v []byte)) {
 	for i := 0; i < s.shardCount; i++ {
 		sh := s.shards[i]
 		sh.mu.RLock()
-		for k, v := range sh.m {
-			c := make([]byte, len(v))
-			copy(c, v)
+		for k, e := range sh.m {
+			c := make([]byte, len(e.value))
+			copy(c, e.value)
 			fn(k, c)
 		}
 
@@ -266,7 +392,7 @@ func (s *Store) FromMap(src map[string][]byte) {
 		sh.mu.Lock()
 		buf := make([]byte, len(v))
 		copy(buf, v)
-		sh.m[k] = buf
+		s.store(sh, k, buf)
 		sh.mu.Unlock()
 	}
 
@@ -3,10 +3,12 @@ package storage
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/metrics"
 	"github.com/taymour/elysiandb/internal/recovery"
 )
 
@@ -15,8 +17,20 @@ func WriteToDB() {
 	WriteJsonDB()
 }
 
+var saveDuration = metrics.NewHistogramVec(
+	"elysiandb_save_duration_seconds",
+	"Time spent persisting a store to disk.",
+	metrics.DefaultBuckets,
+	"store",
+)
+
 func WriteStoreDB() {
 	cfg := globals.GetConfig()
+	if cfg.Store.IsEphemeral() {
+		return
+	}
+
+	defer saveDuration.ObserveSince(time.Now(), "kv")
 
 	rootMu.RLock()
 	ms := mainStore
@@ -42,6 +56,11 @@ func WriteStoreDB() {
 
 func WriteJsonDB() {
 	cfg := globals.GetConfig()
+	if cfg.Store.IsEphemeral() {
+		return
+	}
+
+	defer saveDuration.ObserveSince(time.Now(), "json")
 
 	rootMu.RLock()
 	js := mainJsonStore.Load()
@@ -57,6 +76,10 @@ func WriteJsonDB() {
 		return
 	}
 
+	if err := writeIndexesToFile(cfg, IndexDataFile, js.indexes); err != nil {
+		log.Error("Error writing indexes to database:", err)
+	}
+
 	if cfg.Store.CrashRecovery.Enabled {
 		recovery.ClearJsonRecoveryLog()
 	}
@@ -0,0 +1,454 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+type ReadOptions struct {
+	Entity  string
+	Mapping map[string]string
+}
+
+type Emit func(n int, doc map[string]any, err error)
+
+type columns struct {
+	paths []string
+	types []string
+}
+
+func Read(r io.Reader, format string, opts ReadOptions, emit Emit) error {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, opts, emit)
+	case FormatParquet:
+		return readParquet(r, opts, emit)
+	default:
+		return fmt.Errorf("unsupported format '%s', expected csv or parquet", format)
+	}
+}
+
+func newColumns(entity string, names []string, mapping map[string]string) (*columns, error) {
+	cols := &columns{paths: make([]string, len(names)), types: make([]string, len(names))}
+
+	var fields map[string]schema.Field
+	if s := schema.LoadSchemaForEntity(entity, engine.SchemaData(entity)); s != nil {
+		fields = s.Fields
+	}
+
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		path := name
+		if target, ok := mapping[name]; ok {
+			path = strings.TrimSpace(target)
+		}
+
+		if path == "-" {
+			path = ""
+		}
+
+		cols.paths[i] = path
+		cols.types[i] = fieldType(fields, path)
+	}
+
+	if err := checkPaths(cols.paths); err != nil {
+		return nil, err
+	}
+
+	return cols, nil
+}
+
+func fieldType(fields map[string]schema.Field, path string) string {
+	if path == "id" {
+		return "string"
+	}
+
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		f, ok := fields[part]
+		if !ok {
+			return ""
+		}
+
+		if i == len(parts)-1 {
+			return f.Type
+		}
+
+		fields = f.Fields
+	}
+
+	return ""
+}
+
+func (c *columns) inferFrom(records [][]string) {
+	for i, path := range c.paths {
+		if path == "" || c.types[i] != "" {
+			continue
+		}
+
+		detected := ""
+		for _, record := range records {
+			if i >= len(record) || record[i] == "" {
+				continue
+			}
+
+			t := schema.DetectJSONType(guess(record[i]))
+			if detected == "" {
+				detected = t
+			} else if detected != t {
+				detected = "string"
+				break
+			}
+		}
+
+		c.types[i] = detected
+	}
+}
+
+func (c *columns) document(record []string) (map[string]any, error) {
+	doc := make(map[string]any, len(record))
+	for i, raw := range record {
+		if i >= len(c.paths) || c.paths[i] == "" || raw == "" {
+			continue
+		}
+
+		value, err := convert(c.paths[i], c.types[i], raw)
+		if err != nil {
+			return doc, err
+		}
+
+		api_storage.SetNestedField(doc, c.paths[i], value)
+	}
+
+	return doc, nil
+}
+
+func convert(path, typ, raw string) (any, error) {
+	switch typ {
+	case "string":
+		return raw, nil
+	case "number":
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("Field '%s': expected a number but got '%s'", path, raw)
+		}
+
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("Field '%s': expected a boolean but got '%s'", path, raw)
+		}
+
+		return b, nil
+	case "object", "array":
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil || schema.DetectJSONType(v) != typ {
+			return nil, fmt.Errorf("Field '%s': expected a JSON %s but got '%s'", path, typ, raw)
+		}
+
+		return v, nil
+	default:
+		return guess(raw), nil
+	}
+}
+
+func guess(raw string) any {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if looksNumeric(raw) {
+		if f, err := strconv.ParseFloat(raw, 64); err == nil && !math.IsInf(f, 0) {
+			return f
+		}
+	}
+
+	if raw[0] == '{' || raw[0] == '[' {
+		var v any
+		if json.Unmarshal([]byte(raw), &v) == nil {
+			return v
+		}
+	}
+
+	return raw
+}
+
+func looksNumeric(raw string) bool {
+	digits := strings.TrimPrefix(raw, "-")
+	if digits == "" || digits[0] < '0' || digits[0] > '9' {
+		return false
+	}
+
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return false
+	}
+
+	for _, c := range digits {
+		if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' && c != '-' && c != '+' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readCSV(r io.Reader, opts ReadOptions, emit Emit) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("missing header row")
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	cols, err := newColumns(opts.Entity, header, opts.Mapping)
+	if err != nil {
+		return err
+	}
+
+	var sample [][]string
+	var lines []int
+
+	flush := func() {
+		cols.inferFrom(sample)
+		for i, record := range sample {
+			emitRecord(cols, lines[i], record, len(header), emit)
+		}
+
+		sample = nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if sample != nil {
+				flush()
+			}
+
+			return err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if sample == nil && lines != nil {
+			emitRecord(cols, line, record, len(header), emit)
+			continue
+		}
+
+		sample = append(sample, record)
+		lines = append(lines, line)
+		if len(sample) == sampleSize {
+			flush()
+		}
+	}
+
+	if sample != nil {
+		flush()
+	}
+
+	return nil
+}
+
+func emitRecord(cols *columns, line int, record []string, width int, emit Emit) {
+	if len(record) != width {
+		emit(line, nil, fmt.Errorf("expected %d columns but got %d", width, len(record)))
+		return
+	}
+
+	doc, err := cols.document(record)
+	emit(line, doc, err)
+}
+
+func readParquet(r io.Reader, opts ReadOptions, emit Emit) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("invalid parquet file: %v", recovered)
+		}
+	}()
+
+	input, size, cleanup, err := readerAt(r)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	file, err := parquet.OpenFile(input, size)
+	if err != nil {
+		return fmt.Errorf("invalid parquet file: %w", err)
+	}
+
+	sch := file.Schema()
+	names := make([]string, 0, len(sch.Fields()))
+	for _, f := range sch.Fields() {
+		names = append(names, f.Name())
+	}
+
+	cols, err := newColumns(opts.Entity, names, opts.Mapping)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+
+	rows := make([]parquet.Row, 64)
+	n := 0
+	for {
+		count, err := reader.ReadRows(rows)
+		for _, row := range rows[:count] {
+			n++
+
+			values := map[string]any{}
+			if err := sch.Reconstruct(&values, row); err != nil {
+				emit(n, nil, err)
+				continue
+			}
+
+			doc, err := cols.values(index, values)
+			emit(n, doc, err)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", n+1, err)
+		}
+	}
+}
+
+func (c *columns) values(index map[string]int, values map[string]any) (map[string]any, error) {
+	doc := make(map[string]any, len(values))
+	for name, value := range values {
+		i := index[name]
+		if c.paths[i] == "" {
+			continue
+		}
+
+		value = normalize(value)
+		if value == nil {
+			continue
+		}
+
+		if raw, ok := value.(string); ok && c.types[i] != "string" {
+			converted, err := convertString(c.paths[i], c.types[i], raw)
+			if err != nil {
+				return doc, err
+			}
+
+			value = converted
+		}
+
+		api_storage.SetNestedField(doc, c.paths[i], value)
+	}
+
+	return doc, nil
+}
+
+func convertString(path, typ, raw string) (any, error) {
+	if typ != "" {
+		return convert(path, typ, raw)
+	}
+
+	if raw != "" && (raw[0] == '{' || raw[0] == '[') {
+		var v any
+		if json.Unmarshal([]byte(raw), &v) == nil {
+			return v, nil
+		}
+	}
+
+	return raw, nil
+}
+
+func normalize(value any) any {
+	switch v := value.(type) {
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if item = normalize(item); item != nil {
+				out[key] = item
+			}
+		}
+
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+
+		return out
+	default:
+		return v
+	}
+}
+
+func readerAt(r io.Reader) (io.ReaderAt, int64, func(), error) {
+	if br, ok := r.(*bytes.Reader); ok {
+		return br, br.Size(), func() {}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "elysiandb-import-*.parquet")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	cleanup := func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+
+	return tmp, size, cleanup, nil
+}
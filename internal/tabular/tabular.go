@@ -0,0 +1,128 @@
+package tabular
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+
+	sampleSize = 100
+)
+
+func IsFormat(format string) bool {
+	return format == FormatCSV || format == FormatParquet
+}
+
+func ContentType(format string) string {
+	if format == FormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+func FileName(entity, format string, at time.Time) string {
+	return entity + "-" + at.UTC().Format("20060102T150405Z") + "." + format
+}
+
+func Flatten(doc map[string]any) map[string]any {
+	out := make(map[string]any, len(doc))
+	flattenInto(out, "", doc)
+
+	return out
+}
+
+func flattenInto(out map[string]any, prefix string, doc map[string]any) {
+	for key, value := range doc {
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenInto(out, prefix+key+".", nested)
+			continue
+		}
+
+		out[prefix+key] = value
+	}
+}
+
+func Columns(rows []map[string]any) []string {
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for column := range row {
+			seen[column] = true
+		}
+	}
+
+	columns := make([]string, 0, len(seen))
+	for column := range seen {
+		if column != "id" {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	if seen["id"] {
+		columns = append([]string{"id"}, columns...)
+	}
+
+	return columns
+}
+
+func cell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(b)
+	}
+}
+
+func checkPaths(paths []string) error {
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		if seen[path] {
+			return fmt.Errorf("duplicate column '%s'", path)
+		}
+
+		seen[path] = true
+	}
+
+	for _, path := range paths {
+		for i := strings.IndexByte(path, '.'); i > 0; i = nextDot(path, i) {
+			if seen[path[:i]] {
+				return fmt.Errorf("column '%s' conflicts with column '%s'", path, path[:i])
+			}
+		}
+	}
+
+	return nil
+}
+
+func nextDot(path string, i int) int {
+	j := strings.IndexByte(path[i+1:], '.')
+	if j < 0 {
+		return -1
+	}
+
+	return i + 1 + j
+}
@@ -0,0 +1,148 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/taymour/elysiandb/internal/schema"
+)
+
+func Write(w io.Writer, format string, docs []map[string]any) error {
+	rows := make([]map[string]any, len(docs))
+	for i, doc := range docs {
+		rows[i] = Flatten(doc)
+	}
+
+	columns := Columns(rows)
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, columns, rows)
+	case FormatParquet:
+		return writeParquet(w, columns, rows)
+	default:
+		return fmt.Errorf("unsupported format '%s', expected csv or parquet", format)
+	}
+}
+
+func writeCSV(w io.Writer, columns []string, rows []map[string]any) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = cell(row[column])
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func writeParquet(w io.Writer, columns []string, rows []map[string]any) error {
+	types := make(map[string]string, len(columns))
+	group := make(parquet.Group, len(columns))
+	for _, column := range columns {
+		types[column] = columnType(rows, column)
+
+		switch types[column] {
+		case "number":
+			group[column] = parquet.Optional(parquet.Leaf(parquet.DoubleType))
+		case "boolean":
+			group[column] = parquet.Optional(parquet.Leaf(parquet.BooleanType))
+		default:
+			group[column] = parquet.Optional(parquet.String())
+		}
+	}
+
+	sch := parquet.NewSchema("elysiandb", group)
+	leaves := make([]string, 0, len(columns))
+	for _, path := range sch.Columns() {
+		leaves = append(leaves, path[0])
+	}
+
+	pw := parquet.NewWriter(w, sch)
+	batch := make([]parquet.Row, 0, 256)
+	for _, row := range rows {
+		values := make(parquet.Row, len(leaves))
+		for i, column := range leaves {
+			value := row[column]
+			switch {
+			case value == nil:
+				values[i] = parquet.NullValue().Level(0, 0, i)
+			case types[column] == "number":
+				values[i] = parquet.ValueOf(toFloat(value)).Level(0, 1, i)
+			case types[column] == "boolean":
+				values[i] = parquet.ValueOf(value).Level(0, 1, i)
+			default:
+				values[i] = parquet.ValueOf(cell(value)).Level(0, 1, i)
+			}
+		}
+
+		batch = append(batch, values)
+		if len(batch) == cap(batch) {
+			if _, err := pw.WriteRows(batch); err != nil {
+				return err
+			}
+
+			batch = batch[:0]
+		}
+	}
+
+	if _, err := pw.WriteRows(batch); err != nil {
+		return err
+	}
+
+	return pw.Close()
+}
+
+func columnType(rows []map[string]any, column string) string {
+	detected := ""
+	for _, row := range rows {
+		value := row[column]
+		if value == nil {
+			continue
+		}
+
+		t := schema.DetectJSONType(value)
+		if detected == "" {
+			detected = t
+		} else if detected != t {
+			return "string"
+		}
+	}
+
+	if detected == "number" || detected == "boolean" {
+		return detected
+	}
+
+	return "string"
+}
+
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		f, _ := v.(float64)
+		return f
+	}
+}
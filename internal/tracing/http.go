@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const contextKey = "trace_context"
+
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string {
+	return string(c.header.Peek(key))
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c.header.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range c.header.All() {
+		keys = append(keys, string(key))
+	}
+
+	return keys
+}
+
+func Middleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !Enabled() {
+			next(ctx)
+			return
+		}
+
+		method := string(ctx.Method())
+		parent := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier{&ctx.Request.Header})
+
+		spanCtx, span := Tracer().Start(parent, method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLPath(string(ctx.Path())),
+			semconv.ClientAddress(ctx.RemoteIP().String()),
+		))
+		defer span.End()
+
+		ctx.SetUserValue(contextKey, spanCtx)
+
+		next(ctx)
+
+		if route, ok := ctx.UserValue(router.MatchedRoutePathParam).(string); ok && route != "" {
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
+		status := ctx.Response.StatusCode()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fasthttp.StatusInternalServerError {
+			span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+		}
+	}
+}
+
+func FromRequest(ctx *fasthttp.RequestCtx) context.Context {
+	if spanCtx, ok := ctx.UserValue(contextKey).(context.Context); ok {
+		return spanCtx
+	}
+
+	return context.Background()
+}
+
+func TraceID(ctx *fasthttp.RequestCtx) string {
+	sc := trace.SpanContextFromContext(FromRequest(ctx))
+	if !sc.HasTraceID() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
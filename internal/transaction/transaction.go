@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/metrics"
 	"github.com/taymour/elysiandb/internal/schema"
 )
 
@@ -36,8 +37,18 @@ type Storage interface {
 	DeleteEntityById(entity, id string)
 }
 
+type Atomic interface {
+	Atomically(fn func(s Storage) error) error
+}
+
 type realStorage struct{}
 
+func (realStorage) Atomically(fn func(s Storage) error) error {
+	return engine.RunInTransaction(func(w engine.Writer) error {
+		return fn(w)
+	})
+}
+
 func (realStorage) WriteEntity(e string, d map[string]any) []schema.ValidationError {
 	return engine.WriteEntity(e, d)
 }
@@ -57,6 +68,17 @@ var TxManager = struct {
 	txs map[string]*Transaction
 }{txs: map[string]*Transaction{}}
 
+var _ = metrics.NewGaugeFunc("elysiandb_transactions_open", "Transactions begun but not yet committed or rolled back.", func() float64 {
+	return float64(OpenTransactions())
+})
+
+func OpenTransactions() int {
+	TxManager.mu.Lock()
+	defer TxManager.mu.Unlock()
+
+	return len(TxManager.txs)
+}
+
 func BeginTransaction() *Transaction {
 	TxManager.mu.Lock()
 	defer TxManager.mu.Unlock()
@@ -115,20 +137,30 @@ func CommitTransaction(txID string) error {
 	delete(TxManager.txs, txID)
 	TxManager.mu.Unlock()
 
-	for _, op := range tx.Ops {
+	if a, ok := storageImpl.(Atomic); ok {
+		return a.Atomically(func(s Storage) error {
+			return applyOperations(s, tx.Ops)
+		})
+	}
+
+	return applyOperations(storageImpl, tx.Ops)
+}
+
+func applyOperations(s Storage, ops []TxOperation) error {
+	for _, op := range ops {
 		switch op.Kind {
 		case "write":
-			errs := storageImpl.WriteEntity(op.Entity, op.Data)
+			errs := s.WriteEntity(op.Entity, op.Data)
 			if len(errs) > 0 {
 				return errors.New("validation error")
 			}
 		case "update":
-			res := storageImpl.UpdateEntityById(op.Entity, op.ID, op.Data)
+			res := s.UpdateEntityById(op.Entity, op.ID, op.Data)
 			if res == nil {
 				return errors.New("update failed")
 			}
 		case "delete":
-			storageImpl.DeleteEntityById(op.Entity, op.ID)
+			s.DeleteEntityById(op.Entity, op.ID)
 		}
 	}
 
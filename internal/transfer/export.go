@@ -0,0 +1,223 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/seed"
+)
+
+type ExportOptions struct {
+	Entities []string
+	Schemas  bool
+	Hooks    bool
+	ACLs     bool
+	Filter   func(entity string, list []map[string]any) []map[string]any
+}
+
+func (o *ExportOptions) Include(values []string) error {
+	for _, v := range values {
+		switch v {
+		case IncludeSchemas:
+			o.Schemas = true
+		case IncludeHooks:
+			o.Hooks = true
+		case IncludeACLs:
+			o.ACLs = true
+		default:
+			return fmt.Errorf("unknown include '%s', expected schemas, hooks or acls", v)
+		}
+	}
+
+	return nil
+}
+
+func (o *ExportOptions) IncludesCoreRecords() bool {
+	return o.Schemas || o.Hooks || o.ACLs
+}
+
+func Export(w io.Writer, opts ExportOptions) (map[string]int, error) {
+	entities, err := ResolveEntities(opts.Entities)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	counts := make(map[string]int, len(entities))
+	for _, entity := range entities {
+		n, err := exportEntity(enc, entity, opts.Filter)
+		if err != nil {
+			return counts, err
+		}
+
+		counts[entity] = n
+	}
+
+	if opts.Schemas {
+		if err := exportSchemas(enc, entities); err != nil {
+			return counts, err
+		}
+	}
+
+	if opts.ACLs {
+		if err := exportACLs(enc, entities); err != nil {
+			return counts, err
+		}
+	}
+
+	if opts.Hooks {
+		if err := exportHooks(enc, entities); err != nil {
+			return counts, err
+		}
+	}
+
+	return counts, nil
+}
+
+func exportSchemas(enc *json.Encoder, entities []string) error {
+	for _, entity := range entities {
+		data := engine.GetEntitySchema(entity)
+		if !schema.IsManualSchema(entity, data) {
+			continue
+		}
+
+		fields, _ := data["fields"].(map[string]any)
+		if err := enc.Encode(Record{Kind: seed.KindSchema, Entity: entity, Data: seed.Schema{Entity: entity, Fields: fields}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportEntity(enc *json.Encoder, entity string, filter func(string, []map[string]any) []map[string]any) (int, error) {
+	ids := engine.GetListOfIds(entity, "", true)
+	written := 0
+
+	for start := 0; start < len(ids); start += pageSize {
+		pageIds := ids[start:min(start+pageSize, len(ids))]
+		docs := engine.ReadEntitiesByIds(entity, pageIds)
+
+		page := make([]map[string]any, 0, len(pageIds))
+		for _, id := range pageIds {
+			if doc := docs[id]; doc != nil {
+				page = append(page, doc)
+			}
+		}
+
+		if filter != nil {
+			page = filter(entity, page)
+		}
+
+		for _, doc := range page {
+			if err := enc.Encode(Record{Kind: seed.KindEntity, Entity: entity, Data: doc}); err != nil {
+				return written, err
+			}
+
+			written++
+		}
+	}
+
+	return written, nil
+}
+
+func exportACLs(enc *json.Encoder, entities []string) error {
+	selected := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		selected[entity] = true
+	}
+
+	for _, core := range []string{acl.RoleACLEntity, acl.GroupACLEntity, acl.ACLEntity} {
+		if !engine.EntityTypeExists(core) {
+			continue
+		}
+
+		for _, doc := range engine.ListEntities(core, 0, 0, "id", true, nil, "", "") {
+			entity, _ := doc["entity"].(string)
+			if !selected[entity] {
+				continue
+			}
+
+			if inherited, _ := doc["inherited"].(bool); core == acl.ACLEntity && inherited {
+				continue
+			}
+
+			raw, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+
+			var a seed.ACL
+			if err := json.Unmarshal(raw, &a); err != nil {
+				return err
+			}
+
+			if err := enc.Encode(Record{Kind: seed.KindACL, Entity: entity, Data: a}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func exportHooks(enc *json.Encoder, entities []string) error {
+	if !engine.EntityTypeExists(hook.HookEntity) {
+		return nil
+	}
+
+	for _, entity := range entities {
+		hooks, err := hook.GetHooksForEntity(entity)
+		if err != nil {
+			return err
+		}
+
+		for _, h := range hooks {
+			if err := enc.Encode(Record{Kind: seed.KindHook, Entity: entity, Data: h}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func ExportFile(path string, opts ExportOptions) (map[string]int, error) {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriterSize(file, 64<<10)
+	counts, err := Export(w, opts)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+
+	return counts, nil
+}
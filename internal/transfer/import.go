@@ -0,0 +1,358 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/seed"
+)
+
+type ImportOptions struct {
+	Mode        string
+	CoreRecords bool
+	DryRun      bool
+	Prepare     func(entity string, doc, existing map[string]any) error
+}
+
+type RecordError struct {
+	Line   int    `json:"line"`
+	Kind   string `json:"kind,omitempty"`
+	Entity string `json:"entity,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error"`
+}
+
+type Report struct {
+	Mode     string         `json:"mode"`
+	DryRun   bool           `json:"dry_run,omitempty"`
+	Imported map[string]int `json:"imported"`
+	Skipped  map[string]int `json:"skipped"`
+	Schemas  int            `json:"schemas"`
+	ACLs     int            `json:"acls"`
+	Hooks    int            `json:"hooks"`
+	Failed   int            `json:"failed"`
+	Errors   []RecordError  `json:"errors"`
+}
+
+type line struct {
+	Kind   string          `json:"kind"`
+	Entity string          `json:"entity"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type Importer struct {
+	opts     ImportOptions
+	report   *Report
+	cleared  map[string]bool
+	aclReady bool
+}
+
+func ImportNDJSON(r io.Reader, opts ImportOptions) (*Report, error) {
+	im, err := NewImporter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+
+	n := 0
+	for scanner.Scan() {
+		n++
+
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var rec line
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			im.Fail(n, "", "", "", err)
+			continue
+		}
+
+		im.add(n, rec)
+	}
+
+	report := im.Finish()
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("line %d: %w", n+1, err)
+	}
+
+	return report, nil
+}
+
+func ImportDump(dump map[string][]map[string]any, opts ImportOptions) (*Report, error) {
+	im, err := NewImporter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]string, 0, len(dump))
+	for entity := range dump {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	for _, entity := range entities {
+		for i, doc := range dump[entity] {
+			im.WriteEntity(i+1, entity, doc)
+		}
+	}
+
+	return im.Finish(), nil
+}
+
+func NewImporter(opts ImportOptions) (*Importer, error) {
+	if opts.Mode == "" {
+		opts.Mode = ModeUpsert
+	}
+
+	if !IsMode(opts.Mode) {
+		return nil, fmt.Errorf("unknown import mode '%s', expected replace, upsert or skip-existing", opts.Mode)
+	}
+
+	return &Importer{
+		opts: opts,
+		report: &Report{
+			Mode:     opts.Mode,
+			DryRun:   opts.DryRun,
+			Imported: map[string]int{},
+			Skipped:  map[string]int{},
+			Errors:   []RecordError{},
+		},
+		cleared: map[string]bool{},
+	}, nil
+}
+
+func (im *Importer) add(n int, rec line) {
+	kind := rec.Kind
+	if kind == "" {
+		kind = seed.KindEntity
+	}
+
+	if kind != seed.KindEntity && !im.opts.CoreRecords {
+		im.Fail(n, kind, rec.Entity, "", fmt.Errorf("only admins can import %s records", kind))
+		return
+	}
+
+	switch kind {
+	case seed.KindEntity:
+		var doc map[string]any
+		if err := json.Unmarshal(rec.Data, &doc); err != nil || doc == nil {
+			im.Fail(n, kind, rec.Entity, "", fmt.Errorf("entity records need an object in data"))
+			return
+		}
+
+		im.WriteEntity(n, rec.Entity, doc)
+	case seed.KindSchema:
+		var s seed.Schema
+		if err := json.Unmarshal(rec.Data, &s); err != nil {
+			im.Fail(n, kind, rec.Entity, "", err)
+			return
+		}
+
+		if s.Entity == "" {
+			s.Entity = rec.Entity
+		}
+
+		im.applySchema(n, s)
+	case seed.KindACL:
+		var a seed.ACL
+		if err := json.Unmarshal(rec.Data, &a); err != nil {
+			im.Fail(n, kind, rec.Entity, "", err)
+			return
+		}
+
+		if a.Entity == "" {
+			a.Entity = rec.Entity
+		}
+
+		im.applyACL(n, a)
+	case seed.KindHook:
+		var h hook.Hook
+		if err := json.Unmarshal(rec.Data, &h); err != nil {
+			im.Fail(n, kind, rec.Entity, "", err)
+			return
+		}
+
+		if h.Entity == "" {
+			h.Entity = rec.Entity
+		}
+
+		im.applyHook(n, h)
+	case seed.KindUser:
+		im.Fail(n, kind, rec.Entity, "", fmt.Errorf("user records are only supported in seed fixtures"))
+	default:
+		im.Fail(n, kind, rec.Entity, "", fmt.Errorf("unknown record kind '%s'", kind))
+	}
+}
+
+func (im *Importer) WriteEntity(n int, entity string, doc map[string]any) {
+	id, _ := doc["id"].(string)
+
+	if entity == "" {
+		im.Fail(n, seed.KindEntity, entity, id, fmt.Errorf("entity records need an entity"))
+		return
+	}
+
+	if isReserved(entity) && !im.opts.CoreRecords {
+		im.Fail(n, seed.KindEntity, entity, id, fmt.Errorf("only admins can import %s", entity))
+		return
+	}
+
+	if im.opts.Mode == ModeReplace && !im.cleared[entity] && !im.opts.DryRun {
+		for _, existing := range engine.GetListOfIds(entity, "", true) {
+			engine.DeleteEntityById(entity, existing)
+		}
+
+		im.cleared[entity] = true
+	}
+
+	exists := id != "" && !im.cleared[entity] && engine.EntityExists(entity, id)
+	if im.opts.Mode == ModeSkipExisting && exists {
+		im.report.Skipped[entity]++
+		return
+	}
+
+	if im.opts.Prepare != nil {
+		var existing map[string]any
+		if exists && im.opts.Mode != ModeReplace {
+			existing = engine.ReadEntityById(entity, id)
+		}
+
+		if err := im.opts.Prepare(entity, doc, existing); err != nil {
+			im.Fail(n, seed.KindEntity, entity, id, err)
+			return
+		}
+	}
+
+	if im.opts.DryRun {
+		if globals.GetConfig().Api.Schema.Enabled {
+			if errs := schema.ValidateEntity(entity, doc, engine.SchemaData(entity)); len(errs) > 0 {
+				im.Fail(n, seed.KindEntity, entity, id, errs[0].ToError())
+				return
+			}
+		}
+
+		im.report.Imported[entity]++
+		return
+	}
+
+	if errs := engine.WriteEntity(entity, doc); len(errs) > 0 {
+		im.Fail(n, seed.KindEntity, entity, id, errs[0].ToError())
+		return
+	}
+
+	im.report.Imported[entity]++
+}
+
+func (im *Importer) applySchema(n int, s seed.Schema) {
+	if im.opts.Mode == ModeSkipExisting && schema.IsManualSchema(s.Entity, engine.GetEntitySchema(s.Entity)) {
+		return
+	}
+
+	if err := seed.ApplySchema(s); err != nil {
+		im.Fail(n, seed.KindSchema, s.Entity, "", err)
+		return
+	}
+
+	im.report.Schemas++
+}
+
+func (im *Importer) applyACL(n int, a seed.ACL) {
+	if !im.aclReady {
+		acl.InitACL()
+		im.aclReady = true
+	}
+
+	if err := seed.ApplyACL(a); err != nil {
+		im.Fail(n, seed.KindACL, a.Entity, "", err)
+		return
+	}
+
+	im.report.ACLs++
+}
+
+func (im *Importer) applyHook(n int, h hook.Hook) {
+	if im.opts.Mode == ModeSkipExisting && h.ID != "" && engine.EntityExists(hook.HookEntity, h.ID) {
+		return
+	}
+
+	if err := seed.ApplyHook(h); err != nil {
+		im.Fail(n, seed.KindHook, h.Entity, h.ID, err)
+		return
+	}
+
+	im.report.Hooks++
+}
+
+func (im *Importer) Fail(n int, kind, entity, id string, err error) {
+	im.report.Failed++
+
+	if len(im.report.Errors) < maxReportedErrors {
+		im.report.Errors = append(im.report.Errors, RecordError{Line: n, Kind: kind, Entity: entity, ID: id, Error: err.Error()})
+	}
+}
+
+func (im *Importer) Finish() *Report {
+	if im.opts.DryRun {
+		return im.report
+	}
+
+	acl.InitACL()
+
+	entities := make([]string, 0, len(im.report.Imported))
+	for entity := range im.report.Imported {
+		entities = append(entities, entity)
+	}
+
+	for entity := range im.cleared {
+		entities = append(entities, entity)
+	}
+
+	if globals.GetConfig().Api.Cache.Enabled && cache.CacheStore != nil {
+		slices.Sort(entities)
+		for _, entity := range slices.Compact(entities) {
+			cache.CacheStore.Purge(entity)
+		}
+	}
+
+	log.Info("Import finished (", im.opts.Mode, "): ", len(im.report.Imported), " entity types written, ", im.report.Failed, " records failed")
+
+	return im.report
+}
+
+func ImportFile(path string, opts ImportOptions) (*Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) != ".json" {
+		return ImportNDJSON(file, opts)
+	}
+
+	var dump map[string][]map[string]any
+	if err := json.NewDecoder(file).Decode(&dump); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON dump: %w", path, err)
+	}
+
+	return ImportDump(dump, opts)
+}
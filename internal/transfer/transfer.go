@@ -0,0 +1,81 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/engine"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+
+	ModeReplace      = "replace"
+	ModeUpsert       = "upsert"
+	ModeSkipExisting = "skip-existing"
+
+	IncludeSchemas = "schemas"
+	IncludeHooks   = "hooks"
+	IncludeACLs    = "acls"
+
+	ContentType = "application/x-ndjson"
+
+	pageSize          = 500
+	maxLineSize       = 64 << 20
+	maxReportedErrors = 100
+)
+
+type Record struct {
+	Kind   string `json:"kind"`
+	Entity string `json:"entity,omitempty"`
+	Data   any    `json:"data"`
+}
+
+func FileName(at time.Time) string {
+	return "elysiandb-export-" + at.UTC().Format("20060102T150405Z") + ".ndjson"
+}
+
+func ParseList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+
+	return out
+}
+
+func IsMode(mode string) bool {
+	return mode == ModeReplace || mode == ModeUpsert || mode == ModeSkipExisting
+}
+
+func ResolveEntities(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return engine.ListPublicEntityTypes(), nil
+	}
+
+	seen := make(map[string]bool, len(requested))
+	entities := make([]string, 0, len(requested))
+	for _, entity := range requested {
+		if seen[entity] {
+			continue
+		}
+
+		if isReserved(entity) || !engine.EntityTypeExists(entity) {
+			return nil, fmt.Errorf("unknown entity '%s'", entity)
+		}
+
+		seen[entity] = true
+		entities = append(entities, entity)
+	}
+
+	return entities, nil
+}
+
+func isReserved(entity string) bool {
+	return strings.HasPrefix(entity, api_storage.CoreEntityTypePrefix)
+}
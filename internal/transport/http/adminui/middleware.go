@@ -24,6 +24,16 @@ func AdminAuth(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 			return
 		}
 
+		if !security.CheckCSRF(ctx, session) {
+			ctx.SetStatusCode(fasthttp.StatusForbidden)
+			return
+		}
+
+		if security.PasswordChangeRequired(ctx, session.Username) {
+			security.SendPasswordChangeRequired(ctx)
+			return
+		}
+
 		ctx.SetUserValue("username", session.Username)
 		ctx.SetUserValue("role", session.Role)
 
@@ -2,8 +2,11 @@ package adminui
 
 import (
 	"encoding/json"
+	"errors"
+	"strconv"
 	"time"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
@@ -14,8 +17,10 @@ type loginRequest struct {
 }
 
 type userResponse struct {
-	Username string        `json:"username"`
-	Role     security.Role `json:"role"`
+	Username           string        `json:"username"`
+	Role               security.Role `json:"role"`
+	CSRFToken          string        `json:"csrf_token,omitempty"`
+	MustChangePassword bool          `json:"must_change_password"`
 }
 
 func LoginController(ctx *fasthttp.RequestCtx) {
@@ -25,31 +30,48 @@ func LoginController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	user, ok := security.AuthenticateUser(req.Username, req.Password)
-	if !ok || user == nil || user.Role != security.RoleAdmin {
+	user, err := security.LoginUser(req.Username, req.Password)
+
+	var locked *security.AccountLockedError
+	if errors.As(err, &locked) {
+		audit.RecordFailure(ctx, audit.CategoryAuth, "login", req.Username, "account locked")
+		ctx.Response.Header.Set("Retry-After", strconv.Itoa(locked.RetryAfter(time.Now())))
+		ctx.SetContentType("application/json")
+		ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+		ctx.SetBodyString(`{"error":"account locked"}`)
+		return
+	}
+
+	if err != nil || user == nil {
+		audit.RecordFailure(ctx, audit.CategoryAuth, "login", req.Username, "invalid credentials")
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+		return
+	}
+
+	if user.Role != security.RoleAdmin {
+		audit.RecordFailure(ctx, audit.CategoryAuth, "login", req.Username, "role not allowed")
 		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
 		return
 	}
 
-	session, err := security.CreateSession(user.Username, user.Role, 24*time.Hour)
+	session, err := security.CreateSession(user.Username, user.Role, security.SessionTTL())
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
 		return
 	}
 
-	cookie := fasthttp.AcquireCookie()
-	cookie.SetKey(security.SessionCookieName)
-	cookie.SetValue(session.ID)
-	cookie.SetHTTPOnly(true)
-	cookie.SetSameSite(fasthttp.CookieSameSiteStrictMode)
-	cookie.SetPath("/")
-	cookie.SetSecure(true)
-	ctx.Response.Header.SetCookie(cookie)
-	fasthttp.ReleaseCookie(cookie)
+	ctx.SetUserValue("username", user.Username)
+	ctx.SetUserValue("role", user.Role)
+	audit.Record(ctx, audit.CategoryAuth, "login", user.Username, nil, nil)
+
+	setCookie(ctx, security.SessionCookieName, session.ID, true)
+	setCookie(ctx, security.CSRFCookieName, session.CSRFToken, false)
 
 	resp := userResponse{
-		Username: user.Username,
-		Role:     user.Role,
+		Username:           user.Username,
+		Role:               user.Role,
+		CSRFToken:          session.CSRFToken,
+		MustChangePassword: user.MustChangePassword,
 	}
 
 	b, _ := json.Marshal(resp)
@@ -63,16 +85,30 @@ func LogoutController(ctx *fasthttp.RequestCtx) {
 		_ = security.DeleteSession(id)
 	}
 
-	cookie := fasthttp.AcquireCookie()
-	cookie.SetKey(security.SessionCookieName)
-	cookie.SetValue("")
-	cookie.SetPath("/")
-	cookie.SetExpire(time.Unix(0, 0))
-	cookie.SetHTTPOnly(true)
-	cookie.SetSameSite(fasthttp.CookieSameSiteStrictMode)
-	cookie.SetSecure(true)
-	ctx.Response.Header.SetCookie(cookie)
-	fasthttp.ReleaseCookie(cookie)
+	username, _ := ctx.UserValue("username").(string)
+	audit.Record(ctx, audit.CategoryAuth, "logout", username, nil, nil)
+
+	clearSessionCookies(ctx)
+
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+func LogoutEverywhereController(ctx *fasthttp.RequestCtx) {
+	session, err := security.CurrentSession(ctx)
+	if err != nil || session == nil {
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+		return
+	}
+
+	count, err := security.DeleteUserSessions(session.Username)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryAuth, "logout_all", session.Username, nil, map[string]any{"revoked": count})
+
+	clearSessionCookies(ctx)
 
 	ctx.SetStatusCode(fasthttp.StatusNoContent)
 }
@@ -91,11 +127,40 @@ func MeController(ctx *fasthttp.RequestCtx) {
 	}
 
 	resp := userResponse{
-		Username: session.Username,
-		Role:     session.Role,
+		Username:           session.Username,
+		Role:               session.Role,
+		CSRFToken:          session.CSRFToken,
+		MustChangePassword: security.UserMustChangePassword(session.Username),
 	}
 
 	b, _ := json.Marshal(resp)
 	ctx.SetContentType("application/json")
 	ctx.SetBody(b)
 }
+
+func setCookie(ctx *fasthttp.RequestCtx, key, value string, httpOnly bool) {
+	cookie := fasthttp.AcquireCookie()
+	cookie.SetKey(key)
+	cookie.SetValue(value)
+	cookie.SetHTTPOnly(httpOnly)
+	cookie.SetSameSite(fasthttp.CookieSameSiteStrictMode)
+	cookie.SetPath("/")
+	cookie.SetSecure(true)
+	ctx.Response.Header.SetCookie(cookie)
+	fasthttp.ReleaseCookie(cookie)
+}
+
+func clearSessionCookies(ctx *fasthttp.RequestCtx) {
+	for _, key := range []string{security.SessionCookieName, security.CSRFCookieName} {
+		cookie := fasthttp.AcquireCookie()
+		cookie.SetKey(key)
+		cookie.SetValue("")
+		cookie.SetPath("/")
+		cookie.SetExpire(time.Unix(0, 0))
+		cookie.SetHTTPOnly(key == security.SessionCookieName)
+		cookie.SetSameSite(fasthttp.CookieSameSiteStrictMode)
+		cookie.SetSecure(true)
+		ctx.Response.Header.SetCookie(cookie)
+		fasthttp.ReleaseCookie(cookie)
+	}
+}
@@ -0,0 +1,30 @@
+package http_acl
+
+import (
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+func userACLSnapshot(entity, username string) map[string]any {
+	if stored := acl.GetStoredACLForUsername(entity, username); stored != nil {
+		return stored.ToDataMap()
+	}
+
+	return nil
+}
+
+func roleACLSnapshot(entity string, role security.Role) map[string]any {
+	if stored := acl.GetRoleACL(entity, role); stored != nil {
+		return stored.ToRoleDataMap()
+	}
+
+	return nil
+}
+
+func groupACLSnapshot(entity, group string) map[string]any {
+	if stored := acl.GetGroupACL(entity, group); stored != nil {
+		return stored.ToGroupDataMap()
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package http_acl
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func GetRoleACLController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	entity := ctx.UserValue("entity").(string)
+	role := security.Role(ctx.UserValue("role_name").(string))
+
+	if !security.RoleExists(role) {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"role not found"}`)
+		return
+	}
+
+	principal := acl.GetRoleACL(entity, role)
+	if principal == nil {
+		principal = &acl.PrincipalACL{Principal: string(role), Entity: entity}
+	}
+
+	if principal.Permissions == nil {
+		principal.Permissions = acl.RolePermissions(entity, role)
+	}
+
+	sendPrincipalACL(ctx, principal.ToRoleDataMap())
+}
+
+func UpdateRoleACLController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	entity := ctx.UserValue("entity").(string)
+	role := security.Role(ctx.UserValue("role_name").(string))
+
+	if !security.RoleExists(role) {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"role not found"}`)
+		return
+	}
+
+	principal, ok := parsePrincipalACLPayload(ctx, string(role), entity)
+	if !ok {
+		return
+	}
+
+	before := roleACLSnapshot(entity, role)
+
+	acl.SetRoleACL(principal)
+
+	audit.Record(ctx, audit.CategoryACL, "update", entity+"/role:"+string(role), before, roleACLSnapshot(entity, role))
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(`{"status":"ACL updated successfully"}`)
+}
+
+func GetGroupACLController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	entity := ctx.UserValue("entity").(string)
+	group := ctx.UserValue("group").(string)
+
+	if _, err := security.GetGroup(group); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"group not found"}`)
+		return
+	}
+
+	principal := acl.GetGroupACL(entity, group)
+	if principal == nil {
+		principal = &acl.PrincipalACL{Principal: group, Entity: entity}
+	}
+
+	if principal.Permissions == nil {
+		principal.Permissions = acl.NewPermissions()
+	}
+
+	sendPrincipalACL(ctx, principal.ToGroupDataMap())
+}
+
+func UpdateGroupACLController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	entity := ctx.UserValue("entity").(string)
+	group := ctx.UserValue("group").(string)
+
+	if _, err := security.GetGroup(group); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"group not found"}`)
+		return
+	}
+
+	principal, ok := parsePrincipalACLPayload(ctx, group, entity)
+	if !ok {
+		return
+	}
+
+	before := groupACLSnapshot(entity, group)
+
+	acl.SetGroupACL(principal)
+
+	audit.Record(ctx, audit.CategoryACL, "update", entity+"/group:"+group, before, groupACLSnapshot(entity, group))
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(`{"status":"ACL updated successfully"}`)
+}
+
+func parsePrincipalACLPayload(ctx *fasthttp.RequestCtx, principal, entity string) (*acl.PrincipalACL, bool) {
+	var payload struct {
+		Permissions map[string]bool   `json:"permissions"`
+		Fields      map[string]string `json:"fields"`
+		Filter      map[string]any    `json:"filter"`
+	}
+
+	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid json body"}`)
+		return nil, false
+	}
+
+	rules, ok := parseFieldRulesPayload(payload.Fields)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid field rule"}`)
+		return nil, false
+	}
+
+	if _, err := query.ParseFilterNode(payload.Filter); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid filter"}`)
+		return nil, false
+	}
+
+	result := &acl.PrincipalACL{
+		Principal: principal,
+		Entity:    entity,
+		Fields:    rules,
+		Filter:    payload.Filter,
+	}
+
+	if payload.Permissions != nil {
+		result.Permissions = acl.NewPermissions()
+		for k, v := range payload.Permissions {
+			if p, ok := acl.StringToPermission(k); ok {
+				result.Permissions[p] = v
+			}
+		}
+	}
+
+	return result, true
+}
+
+func sendPrincipalACL(ctx *fasthttp.RequestCtx, data map[string]any) {
+	response, err := json.Marshal(data)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"internal server error"}`)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
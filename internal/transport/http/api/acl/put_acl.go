@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
@@ -21,7 +22,8 @@ func UpdateACLForUsernameAndEntityController(ctx *fasthttp.RequestCtx) {
 	username := ctx.UserValue("user_name").(string)
 
 	var payload struct {
-		Permissions map[string]bool `json:"permissions"`
+		Permissions map[string]bool   `json:"permissions"`
+		Fields      map[string]string `json:"fields"`
 	}
 
 	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
@@ -37,12 +39,45 @@ func UpdateACLForUsernameAndEntityController(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
+	rules, ok := parseFieldRulesPayload(payload.Fields)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid field rule"}`)
+		return
+	}
+
+	before := userACLSnapshot(entity, username)
+
 	if err := acl.UpdateACLEntityForUsername(entity, username, perms); err != nil {
 		ctx.SetStatusCode(fasthttp.StatusBadRequest)
 		ctx.SetBodyString(`{"error":"unable to update acl"}`)
 		return
 	}
 
+	if payload.Fields != nil {
+		if err := acl.UpdateACLFieldsForUsername(entity, username, rules); err != nil {
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			ctx.SetBodyString(`{"error":"unable to update acl"}`)
+			return
+		}
+	}
+
+	audit.Record(ctx, audit.CategoryACL, "update", entity+"/user:"+username, before, userACLSnapshot(entity, username))
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 	ctx.SetBodyString(`{"status":"ACL updated successfully"}`)
 }
+
+func parseFieldRulesPayload(fields map[string]string) (map[string]acl.FieldRule, bool) {
+	rules := make(map[string]acl.FieldRule, len(fields))
+	for field, value := range fields {
+		rule, ok := acl.StringToFieldRule(value)
+		if !ok || field == "" {
+			return nil, false
+		}
+
+		rules[field] = rule
+	}
+
+	return rules, true
+}
@@ -2,6 +2,7 @@ package http_acl
 
 import (
 	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
@@ -18,12 +19,16 @@ func SetDefaultACLForUsernameAndEntityController(ctx *fasthttp.RequestCtx) {
 	entity := ctx.UserValue("entity").(string)
 	username := ctx.UserValue("user_name").(string)
 
+	before := userACLSnapshot(entity, username)
+
 	if err := acl.ResetACLEntityToDefault(entity, username); err != nil {
 		ctx.SetStatusCode(fasthttp.StatusBadRequest)
 		ctx.SetBodyString(`{"error":"unable to reset acl"}`)
 		return
 	}
 
+	audit.Record(ctx, audit.CategoryACL, "reset", entity+"/user:"+username, before, userACLSnapshot(entity, username))
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 	ctx.SetBodyString(`{"status":"ACL reset to default"}`)
 }
@@ -0,0 +1,76 @@
+package http_acl
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func GetRoleFieldRulesController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	entity := ctx.UserValue("entity").(string)
+	role := security.Role(ctx.UserValue("role_name").(string))
+
+	response, err := json.Marshal(map[string]any{
+		"role":   role,
+		"entity": entity,
+		"fields": acl.GetFieldRulesForRole(entity, role),
+	})
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"internal server error"}`)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
+
+func UpdateRoleFieldRulesController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	entity := ctx.UserValue("entity").(string)
+	role := security.Role(ctx.UserValue("role_name").(string))
+
+	var payload struct {
+		Fields map[string]string `json:"fields"`
+	}
+
+	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid json body"}`)
+		return
+	}
+
+	rules, ok := parseFieldRulesPayload(payload.Fields)
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid field rule"}`)
+		return
+	}
+
+	before := acl.GetFieldRulesForRole(entity, role)
+
+	acl.SetFieldRulesForRole(entity, role, rules)
+
+	audit.Record(ctx, audit.CategoryACL, "field_rules_update", entity+"/role:"+string(role), before, rules)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(`{"status":"field rules updated successfully"}`)
+}
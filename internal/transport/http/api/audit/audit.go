@@ -0,0 +1,130 @@
+package http_audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func ListAuditController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	filter, ok := authorizeAndParseFilter(ctx)
+	if !ok {
+		return
+	}
+
+	events, err := audit.Query(filter)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"unable to read audit log"}`)
+		return
+	}
+
+	body, err := json.Marshal(map[string]any{"events": events})
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"internal server error"}`)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(body)
+}
+
+func ExportAuditController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	filter, ok := authorizeAndParseFilter(ctx)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := audit.Export(&buf, filter); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"unable to read audit log"}`)
+		return
+	}
+
+	ctx.Response.Header.Set("Content-Type", "application/x-ndjson")
+	ctx.Response.Header.Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(buf.Bytes())
+}
+
+func authorizeAndParseFilter(ctx *fasthttp.RequestCtx) (audit.Filter, bool) {
+	if security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return audit.Filter{}, false
+	}
+
+	args := ctx.QueryArgs()
+
+	filter := audit.Filter{
+		Actor:    string(args.Peek("actor")),
+		Category: string(args.Peek("category")),
+		Action:   string(args.Peek("action")),
+		Target:   string(args.Peek("target")),
+		Outcome:  string(args.Peek("outcome")),
+		SourceIP: string(args.Peek("source_ip")),
+	}
+
+	var err error
+	if filter.From, err = parseTime(args.Peek("from")); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid from parameter"}`)
+		return filter, false
+	}
+
+	if filter.To, err = parseTime(args.Peek("to")); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid to parameter"}`)
+		return filter, false
+	}
+
+	if filter.Limit, err = parseInt(args.Peek("limit")); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid limit parameter"}`)
+		return filter, false
+	}
+
+	if filter.Offset, err = parseInt(args.Peek("offset")); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid offset parameter"}`)
+		return filter, false
+	}
+
+	return filter, true
+}
+
+func parseTime(raw []byte) (time.Time, error) {
+	if len(raw) == 0 {
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Parse(time.RFC3339, string(raw))
+}
+
+func parseInt(raw []byte) (int, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(string(raw))
+	if err == nil && n < 0 {
+		return 0, strconv.ErrRange
+	}
+
+	return n, err
+}
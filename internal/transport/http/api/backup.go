@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/backup"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	backupChunkSize    = 64 << 10
+	backupWriteTimeout = 30 * time.Second
+)
+
+func BackupController(ctx *fasthttp.RequestCtx) {
+	if security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetContentType("application/json")
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	var buf bytes.Buffer
+	manifest, err := backup.Write(&buf)
+	if err != nil {
+		log.Error("Backup failed: ", err)
+		audit.RecordFailure(ctx, audit.CategoryBackup, "backup", "", err.Error())
+		ctx.SetContentType("application/json")
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"backup failed"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryBackup, "backup", "", nil, manifest)
+
+	data := buf.Bytes()
+	conn := ctx.Conn()
+
+	ctx.SetContentType(backup.ContentType)
+	ctx.Response.Header.Set("Content-Disposition", `attachment; filename="`+backup.FileName(manifest.CreatedAt)+`"`)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		for len(data) > 0 {
+			n := min(len(data), backupChunkSize)
+
+			_ = conn.SetWriteDeadline(time.Now().Add(backupWriteTimeout))
+			if _, err := w.Write(data[:n]); err != nil {
+				log.Warn("Backup: download interrupted: ", err)
+				return
+			}
+
+			if err := w.Flush(); err != nil {
+				log.Warn("Backup: download interrupted: ", err)
+				return
+			}
+
+			data = data[n:]
+		}
+	})
+}
@@ -12,10 +12,10 @@ func CountController(ctx *fasthttp.RequestCtx) {
 	entity := ctx.UserValue("entity").(string)
 	ctx.Response.Header.Set("Content-Type", "application/json")
 
-	data := engine.ListEntities(entity, 0, 0, "", true, nil, "", "")
-	data = acl.FilterListOfEntities(entity, data)
-
-	count := int64(len(data))
+	count := int64(0)
+	if rowFilter, allowed := acl.ReadFilterForCurrentUser(ctx, entity); allowed {
+		count = int64(len(engine.ListEntitiesWithFilterNode(entity, 0, 0, "", true, nil, "", "", rowFilter)))
+	}
 
 	ctx.SetStatusCode(fasthttp.StatusOK)
 	ctx.SetBody([]byte(`{"count":` + fmt.Sprintf("%d", count) + `}`))
@@ -8,7 +8,6 @@ import (
 	"github.com/taymour/elysiandb/internal/cache"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
-	"github.com/taymour/elysiandb/internal/mongodb"
 	"github.com/taymour/elysiandb/internal/schema"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
@@ -18,13 +17,7 @@ func CreateController(ctx *fasthttp.RequestCtx) {
 	entity := ctx.UserValue("entity").(string)
 	body := ctx.PostBody()
 
-	var schemaData map[string]any
-
-	if engine.IsEngineMongoDB() {
-		schemaData = mongodb.GetEntitySchema(entity)
-	} else {
-		schemaData = nil
-	}
+	schemaData := engine.SchemaData(entity)
 
 	if globals.GetConfig().Api.Schema.Strict && schema.IsManualSchema(entity, schemaData) {
 		var tmp any
@@ -82,8 +75,14 @@ func handleSingleEntity(ctx *fasthttp.RequestCtx, entity string, body []byte) bo
 		data["id"] = uuid.New().String()
 	}
 
-	if security.UserAuthenticationIsEnabled() {
-		data[acl.UsernameField] = security.GetCurrentUsername()
+	if forbidden := acl.ForbiddenFieldsForCreate(ctx, entity, data); len(forbidden) > 0 {
+		sendForbiddenFields(ctx, forbidden)
+		return true
+	}
+
+	acl.StripProtectedFields(data)
+	if security.IdentityAuthenticationIsEnabled() {
+		data[acl.UsernameField] = security.CurrentPrincipal(ctx).Username
 	}
 
 	errors := engine.WriteEntity(entity, data)
@@ -110,13 +109,19 @@ func handleEntityList(ctx *fasthttp.RequestCtx, entity string, body []byte) bool
 	}
 
 	for i := range list {
+		if forbidden := acl.ForbiddenFieldsForCreate(ctx, entity, list[i]); len(forbidden) > 0 {
+			sendForbiddenFields(ctx, forbidden)
+			return true
+		}
+
 		id, hasId := list[i]["id"].(string)
 		if !hasId || id == "" {
 			list[i]["id"] = uuid.New().String()
 		}
 
-		if security.UserAuthenticationIsEnabled() {
-			list[i][acl.UsernameField] = security.GetCurrentUsername()
+		acl.StripProtectedFields(list[i])
+		if security.IdentityAuthenticationIsEnabled() {
+			list[i][acl.UsernameField] = security.CurrentPrincipal(ctx).Username
 		}
 	}
 
@@ -146,6 +151,17 @@ func handleEntityList(ctx *fasthttp.RequestCtx, entity string, body []byte) bool
 	return true
 }
 
+func sendForbiddenFields(ctx *fasthttp.RequestCtx, fields []string) {
+	response, _ := json.Marshal(map[string]any{
+		"error":  "forbidden fields",
+		"fields": fields,
+	})
+
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	ctx.SetStatusCode(fasthttp.StatusForbidden)
+	ctx.SetBody(response)
+}
+
 func finalizeCreate(entity string) {
 	if globals.GetConfig().Api.Cache.Enabled {
 		cache.CacheStore.Purge(entity)
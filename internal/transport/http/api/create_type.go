@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/valyala/fasthttp"
 )
@@ -40,6 +41,8 @@ func CreateTypeController(ctx *fasthttp.RequestCtx) {
 
 	acl.InitACL()
 
+	audit.Record(ctx, audit.CategorySchema, "create", entity, nil, storable)
+
 	out, _ := json.Marshal(storable)
 	ctx.SetStatusCode(fasthttp.StatusOK)
 	ctx.SetBody(out)
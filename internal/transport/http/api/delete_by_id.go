@@ -18,7 +18,7 @@ func DeleteByIdController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	if !acl.CanDeleteEntity(entity, data) {
+	if !acl.CanDeleteEntity(ctx, entity, data) {
 		ctx.SetStatusCode(fasthttp.StatusForbidden)
 		return
 	}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/cache"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
@@ -12,7 +13,8 @@ import (
 func DestroyController(ctx *fasthttp.RequestCtx) {
 	entity := ctx.UserValue("entity").(string)
 
-	if security.UserAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+	if security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		audit.RecordFailure(ctx, audit.CategoryDestructive, "destroy", entity, "forbidden")
 		ctx.SetStatusCode(fasthttp.StatusForbidden)
 		ctx.SetBody([]byte(`{"error":"only admin users can destroy entities"}`))
 		return
@@ -21,6 +23,8 @@ func DestroyController(ctx *fasthttp.RequestCtx) {
 	engine.DeleteAllEntities(entity)
 	acl.DeleteACLForEntityType(entity)
 
+	audit.Record(ctx, audit.CategoryDestructive, "destroy", entity, nil, nil)
+
 	ctx.SetStatusCode(fasthttp.StatusNoContent)
 
 	if globals.GetConfig().Api.Cache.Enabled {
@@ -1,22 +1,123 @@
 package api
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"time"
 
+	"github.com/taymour/elysiandb/internal/acl"
 	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/transfer"
 	"github.com/valyala/fasthttp"
 )
 
 func ExportController(ctx *fasthttp.RequestCtx) {
 	ctx.Response.Header.Set("Content-Type", "application/json")
-	ctx.SetStatusCode(fasthttp.StatusOK)
 
-	response, err := json.Marshal(engine.DumpAll())
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = transfer.FormatJSON
+	}
+
+	if format != transfer.FormatJSON && format != transfer.FormatNDJSON {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBody([]byte(`{"error":"unsupported format, expected json or ndjson"}`))
+		return
+	}
+
+	principal := security.WithPrincipal(context.Background(), security.CurrentPrincipal(ctx))
+	opts := transfer.ExportOptions{
+		Entities: transfer.ParseList(string(ctx.QueryArgs().Peek("entities"))),
+		Filter: func(entity string, list []map[string]any) []map[string]any {
+			return acl.FilterListOfEntities(principal, entity, list)
+		},
+	}
+
+	if err := opts.Include(transfer.ParseList(string(ctx.QueryArgs().Peek("include")))); err != nil {
+		sendTransferError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	if opts.IncludesCoreRecords() && format != transfer.FormatNDJSON {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBody([]byte(`{"error":"include needs format=ndjson"}`))
+		return
+	}
+
+	if opts.IncludesCoreRecords() && security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBody([]byte(`{"error":"forbidden"}`))
+		return
+	}
+
+	if len(opts.Entities) > 0 {
+		entities, err := transfer.ResolveEntities(opts.Entities)
+		if err != nil {
+			sendTransferError(ctx, fasthttp.StatusNotFound, err.Error())
+			return
+		}
+
+		opts.Entities = entities
+	}
+
+	if format == transfer.FormatNDJSON {
+		streamExport(ctx, opts)
+		return
+	}
+
+	var dump map[string]any
+	if len(opts.Entities) == 0 {
+		dump = engine.DumpAll()
+	} else {
+		dump = make(map[string]any, len(opts.Entities))
+		for _, entity := range opts.Entities {
+			dump[entity] = engine.ListEntities(entity, 0, 0, "", true, nil, "", "")
+		}
+	}
+
+	for entity, data := range dump {
+		if list, ok := data.([]map[string]any); ok {
+			dump[entity] = acl.FilterListOfEntities(ctx, entity, list)
+		}
+	}
+
+	response, err := json.Marshal(dump)
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
 		ctx.SetBody([]byte(`{"error":"failed to marshal response"}`))
 		return
 	}
 
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
+
+func streamExport(ctx *fasthttp.RequestCtx, opts transfer.ExportOptions) {
+	conn := ctx.Conn()
+
+	ctx.SetContentType(transfer.ContentType)
+	ctx.Response.Header.Set("Content-Disposition", `attachment; filename="`+transfer.FileName(time.Now())+`"`)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := transfer.Export(deadlineWriter{w: w, conn: conn}, opts); err != nil {
+			log.Warn("Export: download interrupted: ", err)
+			return
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(streamTimeout))
+		if err := w.Flush(); err != nil {
+			log.Warn("Export: download interrupted: ", err)
+		}
+	})
+}
+
+func sendTransferError(ctx *fasthttp.RequestCtx, status int, message string) {
+	response, _ := json.Marshal(map[string]string{"error": message})
+
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	ctx.SetStatusCode(status)
 	ctx.SetBody(response)
 }
@@ -19,7 +19,12 @@ func GetByIdController(ctx *fasthttp.RequestCtx) {
 	fields := api_storage.ParseFieldsParam(fieldsParam)
 	includesParam := string(ctx.QueryArgs().Peek("includes"))
 
-	if !hook.EntityHasHooks(entity) && len(fields) == 0 && globals.GetConfig().Api.Cache.Enabled {
+	rowFilter, canReadAll := acl.ReadFilterForCurrentUser(ctx, entity)
+	canReadAll = canReadAll && rowFilter.IsEmpty()
+
+	useCache := !hook.EntityHasHooks(entity) && len(fields) == 0 && globals.GetConfig().Api.Cache.Enabled && !acl.HasFieldRules(ctx, entity) && canReadAll
+
+	if useCache {
 		if v := cache.CacheStore.GetById(entity, id); v != nil {
 			ctx.Response.Header.Set("Content-Type", "application/json")
 			ctx.Response.Header.Set("X-Elysian-Cache", "HIT")
@@ -38,7 +43,7 @@ func GetByIdController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	if !acl.CanReadEntity(entity, data) {
+	if !acl.CanReadEntity(ctx, entity, data) {
 		ctx.SetStatusCode(fasthttp.StatusForbidden)
 		ctx.Response.Header.Set("X-Elysian-Cache", "MISS")
 		ctx.SetBodyString("Access denied")
@@ -51,12 +56,14 @@ func GetByIdController(ctx *fasthttp.RequestCtx) {
 		data = engine.ApplyIncludes(list, includesParam)[0]
 	}
 
+	data = acl.HideFields(ctx, entity, data)
+
 	if len(fields) > 0 {
 		data = engine.FilterFields(data, fields)
 	}
 
 	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPostReadHooks(entity) {
-		data = hook.ApplyPostReadHooksForEntity(entity, data)
+		data = hook.ApplyPostReadHooksForEntity(ctx, entity, data)
 	}
 
 	response, err := json.Marshal(data)
@@ -67,7 +74,7 @@ func GetByIdController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	if globals.GetConfig().Api.Cache.Enabled {
+	if useCache {
 		cache.CacheStore.SetById(entity, id, response)
 	}
 
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/graphql"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/tracing"
+	"github.com/valyala/fasthttp"
+)
+
+func GraphQLController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	var req graphql.Request
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid json"}`)
+
+		return
+	}
+
+	if req.Query == "" {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"missing query"}`)
+
+		return
+	}
+
+	result := graphql.Execute(security.WithPrincipal(tracing.FromRequest(ctx), security.CurrentPrincipal(ctx)), req)
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"failed to marshal response"}`)
+
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/hook"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
@@ -41,6 +42,8 @@ func CreateHookForEntityController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	audit.Record(ctx, audit.CategoryHook, "create", entity, nil, hookData.ToDataMap())
+
 	response, err := json.Marshal(hookData)
 	if err != nil {
 		fmt.Printf("Error %v\n", err)
@@ -1,6 +1,7 @@
 package http_hook
 
 import (
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/hook"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
@@ -18,6 +19,11 @@ func DeleteHookByIdController(ctx *fasthttp.RequestCtx) {
 
 	id := ctx.UserValue("id").(string)
 
+	var before map[string]any
+	if existing, err := hook.GetHookById(id); err == nil && existing != nil {
+		before = existing.ToDataMap()
+	}
+
 	err := hook.DeleteHook(id)
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusNotFound)
@@ -26,5 +32,7 @@ func DeleteHookByIdController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	audit.Record(ctx, audit.CategoryHook, "delete", id, before, nil)
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
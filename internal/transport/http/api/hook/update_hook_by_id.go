@@ -3,6 +3,7 @@ package http_hook
 import (
 	"encoding/json"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/hook"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
@@ -34,8 +35,15 @@ func UpdateHookByIdController(ctx *fasthttp.RequestCtx) {
 	hookEntity := &hook.Hook{}
 	hookEntity.FromDataMap(hookData)
 
+	var before map[string]any
+	if existing, err := hook.GetHookById(id); err == nil && existing != nil {
+		before = existing.ToDataMap()
+	}
+
 	hook.UpdateHook(hookEntity)
 
+	audit.Record(ctx, audit.CategoryHook, "update", hookEntity.Entity, before, hookEntity.ToDataMap())
+
 	response, err := json.Marshal(hookEntity)
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
@@ -1,24 +1,100 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"strings"
 
-	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/transfer"
 	"github.com/valyala/fasthttp"
 )
 
+type importResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	*transfer.Report
+}
+
 func ImportController(ctx *fasthttp.RequestCtx) {
 	ctx.Response.Header.Set("Content-Type", "application/json")
 
-	var dump map[string][]map[string]any
-	if err := json.Unmarshal(ctx.PostBody(), &dump); err != nil {
+	opts := transfer.ImportOptions{
+		Mode:        string(ctx.QueryArgs().Peek("mode")),
+		CoreRecords: !security.IdentityAuthenticationIsEnabled() || security.CurrentUserIsAdmin(ctx),
+		Prepare:     prepareImportedEntity(ctx),
+	}
+
+	if opts.Mode != "" && !transfer.IsMode(opts.Mode) {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBody([]byte(`{"error":"unsupported mode, expected replace, upsert or skip-existing"}`))
+		return
+	}
+
+	if opts.Mode == transfer.ModeReplace && security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		sendTransferError(ctx, fasthttp.StatusForbidden, "replace mode needs an admin")
+		return
+	}
+
+	var report *transfer.Report
+	var err error
+
+	switch importFormat(ctx) {
+	case transfer.FormatNDJSON:
+		var body io.Reader = bytes.NewReader(ctx.PostBody())
+		if ctx.Request.IsBodyStream() {
+			body = deadlineReader{r: ctx.RequestBodyStream(), conn: ctx.Conn()}
+		}
+
+		report, err = transfer.ImportNDJSON(body, opts)
+	case transfer.FormatJSON:
+		var dump map[string][]map[string]any
+		if err := json.Unmarshal(ctx.PostBody(), &dump); err != nil {
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			ctx.SetBody([]byte(`{"error":"invalid JSON dump"}`))
+			return
+		}
+
+		report, err = transfer.ImportDump(dump, opts)
+	default:
 		ctx.SetStatusCode(fasthttp.StatusBadRequest)
-		ctx.SetBody([]byte(`{"error":"invalid JSON dump"}`))
+		ctx.SetBody([]byte(`{"error":"unsupported format, expected json or ndjson"}`))
 		return
 	}
 
-	engine.ImportAll(dump)
+	audit.Record(ctx, audit.CategoryDestructive, "import", "", nil, map[string]any{
+		"mode":     report.Mode,
+		"imported": report.Imported,
+		"failed":   report.Failed,
+	})
+
+	response := importResponse{Status: "import completed", Report: report}
+	status := fasthttp.StatusOK
+
+	if err != nil {
+		response.Status = "import aborted"
+		response.Error = err.Error()
+		status = fasthttp.StatusBadRequest
+	} else if report.Failed > 0 {
+		response.Status = "import completed with errors"
+	}
+
+	body, _ := json.Marshal(response)
+
+	ctx.SetStatusCode(status)
+	ctx.SetBody(body)
+}
+
+func importFormat(ctx *fasthttp.RequestCtx) string {
+	if format := string(ctx.QueryArgs().Peek("format")); format != "" {
+		return format
+	}
+
+	if strings.HasPrefix(string(ctx.Request.Header.ContentType()), transfer.ContentType) {
+		return transfer.FormatNDJSON
+	}
 
-	ctx.SetStatusCode(fasthttp.StatusOK)
-	ctx.SetBody([]byte(`{"status":"import completed"}`))
+	return transfer.FormatJSON
 }
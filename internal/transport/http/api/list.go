@@ -11,8 +11,11 @@ import (
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/query"
 	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/tracing"
 	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func ListController(ctx *fasthttp.RequestCtx) {
@@ -26,9 +29,14 @@ func ListController(ctx *fasthttp.RequestCtx) {
 	includesParam := string(ctx.QueryArgs().Peek("includes"))
 	countOnlyParam := ctx.QueryArgs().GetBool("countOnly")
 
+	if hidden := acl.HiddenFieldsInQuery(ctx, entity, append(query.LeafFields(filters), sortField)...); len(hidden) > 0 {
+		sendForbiddenFields(ctx, hidden)
+		return
+	}
+
 	currentUser := ""
-	if security.UserAuthenticationIsEnabled() {
-		currentUser = security.GetCurrentUsername()
+	if security.IdentityAuthenticationIsEnabled() {
+		currentUser = security.CurrentPrincipal(ctx).Username
 	}
 
 	var hash []byte
@@ -57,23 +65,7 @@ func ListController(ctx *fasthttp.RequestCtx) {
 		}
 	}
 
-	data := engine.ListEntities(entity, limit, offset, sortField, sortAscending, filters, search, includesParam)
-
-	data = acl.FilterListOfEntities(entity, data)
-
-	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPreReadHooks(entity) {
-		for i, item := range data {
-			data[i] = hook.ApplyPreReadHooksForEntity(entity, item)
-		}
-
-		data = engine.ApplyFiltersToList(data, filters)
-	}
-
-	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPostReadHooks(entity) {
-		for i, item := range data {
-			data[i] = hook.ApplyPostReadHooksForEntity(entity, item)
-		}
-	}
+	data := readList(ctx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam)
 
 	if countOnlyParam {
 		countResult := int64(len(data))
@@ -101,7 +93,10 @@ func ListController(ctx *fasthttp.RequestCtx) {
 		data = filteredData
 	}
 
+	_, span := tracing.Start(tracing.FromRequest(ctx), "json.Marshal", attribute.String("elysiandb.entity", entity))
 	response, err := json.Marshal(data)
+	span.End()
+
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
 		ctx.Response.Header.Set("X-Elysian-Cache", "MISS")
@@ -119,6 +114,65 @@ func ListController(ctx *fasthttp.RequestCtx) {
 	}
 }
 
+func readList(
+	ctx *fasthttp.RequestCtx,
+	entity string,
+	limit, offset int,
+	sortField string,
+	sortAscending bool,
+	filters map[string]map[string]string,
+	search, includesParam string,
+) []map[string]any {
+	traceCtx := tracing.FromRequest(ctx)
+	entityAttr := attribute.String("elysiandb.entity", entity)
+
+	data := []map[string]any{}
+	_, span := tracing.Start(traceCtx, "acl.ReadFilter", entityAttr)
+	rowFilter, allowed := acl.ReadFilterForCurrentUser(ctx, entity)
+	span.End()
+
+	if allowed {
+		searchVisible := search != "" && acl.HasHiddenFields(ctx, entity)
+
+		listCtx, span := tracing.Start(traceCtx, "ListEntities", entityAttr)
+		if searchVisible {
+			data = engine.ListEntitiesWithFilterNodeContext(listCtx, entity, 0, 0, sortField, sortAscending, nil, "", includesParam, rowFilter)
+		} else {
+			data = engine.ListEntitiesWithFilterNodeContext(listCtx, entity, limit, offset, sortField, sortAscending, filters, search, includesParam, rowFilter)
+		}
+		span.SetAttributes(attribute.Int("elysiandb.results", len(data)))
+		span.End()
+
+		_, span = tracing.Start(traceCtx, "acl.HideFields", entityAttr)
+		data = acl.HideFieldsInList(ctx, entity, data)
+		span.End()
+
+		if searchVisible {
+			data = api_storage.SearchList(data, search, offset, limit)
+		}
+	}
+
+	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPreReadHooks(entity) {
+		_, span := tracing.Start(traceCtx, "hooks.PreRead", entityAttr)
+		for i, item := range data {
+			data[i] = hook.ApplyPreReadHooksForEntity(ctx, entity, item)
+		}
+
+		data = engine.ApplyFiltersToList(data, filters)
+		span.End()
+	}
+
+	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPostReadHooks(entity) {
+		_, span := tracing.Start(traceCtx, "hooks.PostRead", entityAttr)
+		for i, item := range data {
+			data[i] = hook.ApplyPostReadHooksForEntity(ctx, entity, item)
+		}
+		span.End()
+	}
+
+	return data
+}
+
 func ParseSortParam(params *fasthttp.Args) (field string, ascending bool) {
 	ascending = true
 
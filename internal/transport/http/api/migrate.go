@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/valyala/fasthttp"
 )
@@ -11,7 +12,7 @@ import (
 func MigrateController(ctx *fasthttp.RequestCtx) {
 	ctx.Response.Header.Set("Content-Type", "application/json")
 
-	if !engine.IsEngineInternal() {
+	if !engine.CurrentCapabilities().Migrations {
 		ctx.SetStatusCode(fasthttp.StatusNotImplemented)
 		ctx.SetBodyString(`{"error" : "Only the ElysianDB engine is supported for migrations."}`)
 		return
@@ -40,6 +41,8 @@ func MigrateController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	audit.Record(ctx, audit.CategoryDestructive, "migrate", entity, nil, map[string]any{"query": string(ctx.PostBody())})
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 	ctx.SetBodyString(fmt.Sprintf(`{"message" : "Entity '%s' migrated successfully."}`, entity))
 }
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/taymour/elysiandb/internal/openapi"
+	"github.com/valyala/fasthttp"
+)
+
+func OpenAPIController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	data, err := openapi.Spec()
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"failed to generate openapi spec"}`)
+
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(data)
+}
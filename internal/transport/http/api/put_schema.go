@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/valyala/fasthttp"
 )
@@ -32,8 +33,11 @@ func PutSchemaController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	before := engine.GetEntitySchema(entity)
 	storable := engine.UpdateEntitySchema(entity, fieldsRaw)
 
+	audit.Record(ctx, audit.CategorySchema, "update", entity, before, storable)
+
 	out, _ := json.Marshal(storable)
 	ctx.SetStatusCode(fasthttp.StatusOK)
 	ctx.SetBody(out)
@@ -105,8 +105,8 @@ func QueryController(ctx *fasthttp.RequestCtx) {
 	}
 
 	currentUser := ""
-	if security.UserAuthenticationIsEnabled() {
-		currentUser = security.GetCurrentUsername()
+	if security.IdentityAuthenticationIsEnabled() {
+		currentUser = security.CurrentPrincipal(ctx).Username
 	}
 
 	var hash []byte
@@ -134,25 +134,38 @@ func QueryController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	query := query.Query{
-		Entity: payload.Entity,
-		Offset: payload.Offset,
-		Limit:  payload.Limit,
-		Filter: filter,
-		Sorts:  payload.Sorts,
+	sortFields := make([]string, 0, len(payload.Sorts))
+	for field := range payload.Sorts {
+		sortFields = append(sortFields, field)
 	}
 
-	data, err := engine.ExecuteQuery(query)
-	if err != nil {
-		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+	if hidden := acl.HiddenFieldsInQuery(ctx, payload.Entity, append(filter.Fields(), sortFields...)...); len(hidden) > 0 {
+		sendForbiddenFields(ctx, hidden)
 		return
 	}
 
-	data = acl.FilterListOfEntities(payload.Entity, data)
+	data := []map[string]any{}
+	if rowFilter, allowed := acl.ReadFilterForCurrentUser(ctx, payload.Entity); allowed {
+		query := query.Query{
+			Entity: payload.Entity,
+			Offset: payload.Offset,
+			Limit:  payload.Limit,
+			Filter: query.And(filter, rowFilter),
+			Sorts:  payload.Sorts,
+		}
+
+		data, err = engine.ExecuteQuery(query)
+		if err != nil {
+			ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+			return
+		}
+
+		data = acl.HideFieldsInList(ctx, payload.Entity, data)
+	}
 
 	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPreReadHooks(payload.Entity) {
 		for i, item := range data {
-			data[i] = hook.ApplyPreReadHooksForEntity(payload.Entity, item)
+			data[i] = hook.ApplyPreReadHooksForEntity(ctx, payload.Entity, item)
 		}
 
 		data = api_storage.ApplyQueryFilter(data, filter)
@@ -160,7 +173,7 @@ func QueryController(ctx *fasthttp.RequestCtx) {
 
 	if globals.GetConfig().Api.Hooks.Enabled && hook.EntityHasPostReadHooks(payload.Entity) {
 		for i, item := range data {
-			data[i] = hook.ApplyPostReadHooksForEntity(payload.Entity, item)
+			data[i] = hook.ApplyPostReadHooksForEntity(ctx, payload.Entity, item)
 		}
 	}
 
@@ -205,77 +218,5 @@ func QueryController(ctx *fasthttp.RequestCtx) {
 }
 
 func ParseFilterNode(raw map[string]any) (query.FilterNode, error) {
-	if raw == nil {
-		return query.FilterNode{}, nil
-	}
-
-	if orRaw, ok := raw["or"]; ok {
-		arr, ok := orRaw.([]any)
-		if !ok {
-			return query.FilterNode{}, fmt.Errorf("or must be an array")
-		}
-
-		nodes := make([]query.FilterNode, 0, len(arr))
-		for _, item := range arr {
-			m, ok := item.(map[string]any)
-			if !ok {
-				return query.FilterNode{}, fmt.Errorf("or item must be an object")
-			}
-
-			n, err := ParseFilterNode(m)
-			if err != nil {
-				return query.FilterNode{}, err
-			}
-
-			nodes = append(nodes, n)
-		}
-
-		return query.FilterNode{Or: nodes}, nil
-	}
-
-	if andRaw, ok := raw["and"]; ok {
-		arr, ok := andRaw.([]any)
-		if !ok {
-			return query.FilterNode{}, fmt.Errorf("and must be an array")
-		}
-
-		nodes := make([]query.FilterNode, 0, len(arr))
-		for _, item := range arr {
-			m, ok := item.(map[string]any)
-			if !ok {
-				return query.FilterNode{}, fmt.Errorf("and item must be an object")
-			}
-
-			n, err := ParseFilterNode(m)
-			if err != nil {
-				return query.FilterNode{}, err
-			}
-
-			nodes = append(nodes, n)
-		}
-
-		return query.FilterNode{And: nodes}, nil
-	}
-
-	leaf := make(map[string]map[string]string)
-	for field, v := range raw {
-		opsRaw, ok := v.(map[string]any)
-		if !ok {
-			return query.FilterNode{}, fmt.Errorf("invalid filter for field %s", field)
-		}
-
-		ops := make(map[string]string)
-		for op, val := range opsRaw {
-			s, ok := val.(string)
-			if !ok {
-				return query.FilterNode{}, fmt.Errorf("invalid value for %s.%s", field, op)
-			}
-
-			ops[op] = s
-		}
-
-		leaf[field] = ops
-	}
-
-	return query.FilterNode{Leaf: leaf}, nil
+	return query.ParseFilterNode(raw)
 }
@@ -0,0 +1,122 @@
+package http_replication
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/replication"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+const streamWriteTimeout = 10 * time.Second
+
+func StreamController(ctx *fasthttp.RequestCtx) {
+	if !authorizeReplication(ctx) {
+		return
+	}
+
+	if !replication.IsLeader() {
+		ctx.SetContentType("application/json")
+		ctx.SetStatusCode(fasthttp.StatusConflict)
+		ctx.SetBodyString(`{"error":"` + replication.ErrNotLeader.Error() + `"}`)
+		return
+	}
+
+	args := ctx.QueryArgs()
+	after, err := strconv.ParseUint(string(args.Peek("after")), 10, 64)
+	if err != nil && len(args.Peek("after")) > 0 {
+		ctx.SetContentType("application/json")
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid after parameter"}`)
+		return
+	}
+
+	epoch := string(args.Peek("epoch"))
+	address := ctx.RemoteAddr().String()
+	conn := ctx.Conn()
+
+	ctx.SetContentType("application/x-ndjson")
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		out := bufio.NewWriter(deadlineWriter{conn: conn, w: w})
+		if err := replication.Stream(out, after, epoch, address); err != nil {
+			log.Warn("Replication: stream to ", address, " closed: ", err)
+		}
+	})
+}
+
+type deadlineWriter struct {
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+func (d deadlineWriter) Write(p []byte) (int, error) {
+	_ = d.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+	n, err := d.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	return n, d.w.Flush()
+}
+
+func PromoteController(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+
+	if !authorizeReplication(ctx) {
+		return
+	}
+
+	if err := replication.Promote(); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusConflict)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryReplication, "promote", "", nil, nil)
+
+	writeStatus(ctx)
+}
+
+func StatusController(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+
+	if security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	writeStatus(ctx)
+}
+
+func writeStatus(ctx *fasthttp.RequestCtx) {
+	body, err := json.Marshal(replication.CurrentStatus())
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"internal server error"}`)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(body)
+}
+
+func authorizeReplication(ctx *fasthttp.RequestCtx) bool {
+	if replication.Authorized(string(ctx.Request.Header.Peek(replication.TokenHeader))) {
+		return true
+	}
+
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+	ctx.SetBodyString(`{"error":"invalid replication token"}`)
+
+	return false
+}
@@ -3,6 +3,7 @@ package http_security
 import (
 	"encoding/json"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
@@ -28,6 +29,12 @@ func ChangeUserPasswordController(ctx *fasthttp.RequestCtx) {
 
 	password := req.Password
 
+	if err := security.ValidatePassword(password); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
 	err := security.ChangeUserPassword(username, password)
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusNotFound)
@@ -35,5 +42,7 @@ func ChangeUserPasswordController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	audit.Record(ctx, audit.CategoryUser, "password_change", username, nil, nil)
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
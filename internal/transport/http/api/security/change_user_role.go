@@ -3,6 +3,7 @@ package http_security
 import (
 	"encoding/json"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
@@ -27,6 +28,7 @@ func ChangeUserRoleController(ctx *fasthttp.RequestCtx) {
 	}
 
 	role := userDto.Role
+	before, _ := security.GetBasicUserByUsername(username)
 
 	err := security.ChangeUserRole(username, role)
 	if err != nil {
@@ -35,5 +37,7 @@ func ChangeUserRoleController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	audit.Record(ctx, audit.CategoryUser, "role_change", username, map[string]any{"role": before["role"]}, map[string]any{"role": role})
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
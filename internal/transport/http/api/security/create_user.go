@@ -4,21 +4,24 @@ import (
 	"encoding/json"
 
 	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
 
 type UserDto struct {
-	User     string `json:"username"`
-	Password string `json:"password"`
-	Role     string `json:"role"`
+	User               string `json:"username"`
+	Password           string `json:"password"`
+	Role               string `json:"role"`
+	MustChangePassword bool   `json:"must_change_password"`
 }
 
 func (u *UserDto) ToBasicUser() *security.BasicUser {
 	return &security.BasicUser{
-		Username: u.User,
-		Password: u.Password,
-		Role:     security.Role(u.Role),
+		Username:           u.User,
+		Password:           u.Password,
+		Role:               security.Role(u.Role),
+		MustChangePassword: u.MustChangePassword,
 	}
 }
 
@@ -38,6 +41,13 @@ func CreateUserController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if err := security.ValidatePassword(user.Password); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+
+		return
+	}
+
 	err := security.CreateBasicUser(user.ToBasicUser())
 	if err != nil {
 		ctx.SetStatusCode(fasthttp.StatusNotFound)
@@ -48,5 +58,11 @@ func CreateUserController(ctx *fasthttp.RequestCtx) {
 
 	acl.InitACL()
 
+	audit.Record(ctx, audit.CategoryUser, "create", user.User, nil, map[string]any{
+		"username":             user.User,
+		"role":                 user.Role,
+		"must_change_password": user.MustChangePassword,
+	})
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
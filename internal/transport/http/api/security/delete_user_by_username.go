@@ -2,6 +2,7 @@ package http_security
 
 import (
 	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
@@ -15,7 +16,12 @@ func DeleteUserByUsernameController(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	before, _ := security.GetBasicUserByUsername(username)
+
 	security.DeleteBasicUser(username)
 	acl.DeleteUserACls(username)
+
+	audit.Record(ctx, audit.CategoryUser, "delete", username, before, nil)
+
 	ctx.SetStatusCode(fasthttp.StatusOK)
 }
@@ -0,0 +1,34 @@
+package http_security
+
+import (
+	"fmt"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func DeleteUserSessionsController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	username := ctx.UserValue("user_name").(string)
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+
+		return
+	}
+
+	count, err := security.DeleteUserSessions(username)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"internal server error"}`)
+
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryUser, "sessions_revoke", username, nil, map[string]any{"revoked": count})
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(fmt.Sprintf(`{"revoked":%d}`, count))
+}
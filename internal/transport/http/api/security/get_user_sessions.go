@@ -0,0 +1,52 @@
+package http_security
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+type sessionDto struct {
+	Username   string        `json:"username"`
+	Role       security.Role `json:"role"`
+	CreatedAt  int64         `json:"created_at"`
+	LastSeenAt int64         `json:"last_seen_at"`
+	ExpiresAt  int64         `json:"expires_at"`
+}
+
+func GetUserSessionsController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	username := ctx.UserValue("user_name").(string)
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+
+		return
+	}
+
+	sessions, err := security.ListUserSessions(username)
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"internal server error"}`)
+
+		return
+	}
+
+	result := make([]sessionDto, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, sessionDto{
+			Username:   s.Username,
+			Role:       s.Role,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+		})
+	}
+
+	response, _ := json.Marshal(map[string]any{"sessions": result})
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
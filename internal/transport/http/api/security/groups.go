@@ -0,0 +1,166 @@
+package http_security
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+type GroupDto struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"`
+}
+
+func GetGroupsController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	response, _ := json.Marshal(map[string]any{"groups": security.ListGroups()})
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
+
+func GetGroupController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	group, err := security.GetGroup(ctx.UserValue("group").(string))
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	response, _ := json.Marshal(group)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
+
+func CreateGroupController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	var group GroupDto
+	if err := json.Unmarshal(ctx.PostBody(), &group); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid request body"}`)
+		return
+	}
+
+	if err := security.CreateGroup(group.Name, group.Description); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	for _, member := range group.Members {
+		if err := security.AddUserToGroup(group.Name, member); err != nil {
+			ctx.SetStatusCode(fasthttp.StatusBadRequest)
+			ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+			return
+		}
+	}
+
+	after, _ := security.GetGroup(group.Name)
+	audit.Record(ctx, audit.CategoryGroup, "create", group.Name, nil, after)
+
+	ctx.SetStatusCode(fasthttp.StatusCreated)
+}
+
+func DeleteGroupController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	name := ctx.UserValue("group").(string)
+	before, _ := security.GetGroup(name)
+
+	if err := security.DeleteGroup(name); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	acl.DeleteGroupACLs(name)
+
+	audit.Record(ctx, audit.CategoryGroup, "delete", name, before, nil)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+func AddGroupMemberController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	group := ctx.UserValue("group").(string)
+	username := ctx.UserValue("user_name").(string)
+
+	if _, err := security.GetBasicUserByUsername(username); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	if err := security.AddUserToGroup(group, username); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryGroup, "member_add", group, nil, map[string]any{"username": username})
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+func RemoveGroupMemberController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	group := ctx.UserValue("group").(string)
+	username := ctx.UserValue("user_name").(string)
+
+	if err := security.RemoveUserFromGroup(group, username); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryGroup, "member_remove", group, map[string]any{"username": username}, nil)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
@@ -0,0 +1,104 @@
+package http_security
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+type RoleDto struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func GetRolesController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	response, _ := json.Marshal(map[string]any{"roles": security.ListRoles()})
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
+
+func GetRoleController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	role, err := security.GetRole(ctx.UserValue("role_name").(string))
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	response, _ := json.Marshal(role)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(response)
+}
+
+func CreateRoleController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	var role RoleDto
+	if err := json.Unmarshal(ctx.PostBody(), &role); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid request body"}`)
+		return
+	}
+
+	if err := security.CreateRole(role.Name, role.Description); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryRole, "create", role.Name, nil, role)
+
+	ctx.SetStatusCode(fasthttp.StatusCreated)
+}
+
+func DeleteRoleController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	name := ctx.UserValue("role_name").(string)
+	before, _ := security.GetRole(name)
+
+	if err := security.DeleteRole(name); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	acl.DeleteRoleACLs(security.Role(name))
+
+	audit.Record(ctx, audit.CategoryRole, "delete", name, before, nil)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
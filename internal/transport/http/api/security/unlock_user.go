@@ -0,0 +1,31 @@
+package http_security
+
+import (
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func UnlockUserController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	username := ctx.UserValue("user_name").(string)
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+
+		return
+	}
+
+	if err := security.UnlockUser(username); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryUser, "unlock", username, nil, nil)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(`{"unlocked":true}`)
+}
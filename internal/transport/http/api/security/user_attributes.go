@@ -0,0 +1,69 @@
+package http_security
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func GetUserAttributesController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	username := ctx.UserValue("user_name").(string)
+
+	if canManage, err := security.CurrentUserCanManageUser(ctx, username); err != nil || !canManage {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		return
+	}
+
+	if _, err := security.GetBasicUserByUsername(username); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"username":   username,
+		"attributes": security.GetUserAttributes(username),
+	})
+	if err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBody(body)
+}
+
+func ChangeUserAttributesController(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	username := ctx.UserValue("user_name").(string)
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		return
+	}
+
+	var payload struct {
+		Attributes map[string]string `json:"attributes"`
+	}
+
+	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusBadRequest)
+		ctx.SetBodyString(`{"error":"invalid request body"}`)
+		return
+	}
+
+	before := security.GetUserAttributes(username)
+
+	if err := security.SetUserAttributes(username, payload.Attributes); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetBodyString(`{"error":"` + err.Error() + `"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryUser, "attributes_change", username, before, payload.Attributes)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/seed"
+	"github.com/valyala/fasthttp"
+)
+
+func RestoreSeedController(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+
+	if security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		ctx.SetBodyString(`{"error":"forbidden"}`)
+		return
+	}
+
+	err := seed.Restore()
+	if errors.Is(err, seed.ErrNotSeeded) {
+		ctx.SetStatusCode(fasthttp.StatusConflict)
+		ctx.SetBodyString(`{"error":"no seed fixtures were loaded"}`)
+		return
+	}
+
+	if err != nil {
+		log.Error("Seed restore failed: ", err)
+		audit.RecordFailure(ctx, audit.CategoryDestructive, "seed-restore", "", err.Error())
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+		ctx.SetBodyString(`{"error":"seed restore failed"}`)
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryDestructive, "seed-restore", "", nil, nil)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyString(`{"status":"seed restored"}`)
+}
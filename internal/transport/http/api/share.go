@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+type SharePayload struct {
+	Users       []string `json:"users"`
+	Groups      []string `json:"groups"`
+	Permissions []string `json:"permissions"`
+}
+
+func (p *SharePayload) ToGrant() acl.ShareGrant {
+	return acl.ShareGrant{
+		Users:       p.Users,
+		Groups:      p.Groups,
+		Permissions: p.Permissions,
+	}
+}
+
+func GetSharesController(ctx *fasthttp.RequestCtx) {
+	entity, id, data, ok := loadSharedEntity(ctx)
+	if !ok {
+		return
+	}
+
+	sendShares(ctx, entity, id, data)
+}
+
+func ShareController(ctx *fasthttp.RequestCtx) {
+	entity, id, existing, ok := loadSharedEntity(ctx)
+	if !ok {
+		return
+	}
+
+	payload, ok := parseSharePayload(ctx, true)
+	if !ok {
+		return
+	}
+
+	data, err := acl.ShareEntity(entity, id, payload.ToGrant())
+	if err != nil {
+		sendShareError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryACL, "share", entity+"/"+id, acl.GetShares(existing), acl.GetShares(data))
+
+	finalizeUpdate(entity)
+	sendShares(ctx, entity, id, data)
+}
+
+func UnshareController(ctx *fasthttp.RequestCtx) {
+	entity, id, existing, ok := loadSharedEntity(ctx)
+	if !ok {
+		return
+	}
+
+	payload, ok := parseSharePayload(ctx, false)
+	if !ok {
+		return
+	}
+
+	data, err := acl.UnshareEntity(entity, id, payload.ToGrant())
+	if err != nil {
+		sendShareError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryACL, "unshare", entity+"/"+id, acl.GetShares(existing), acl.GetShares(data))
+
+	finalizeUpdate(entity)
+	sendShares(ctx, entity, id, data)
+}
+
+func TransferOwnershipController(ctx *fasthttp.RequestCtx) {
+	entity, id, existing, ok := loadSharedEntity(ctx)
+	if !ok {
+		return
+	}
+
+	var payload struct {
+		Username string `json:"username"`
+	}
+
+	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil || payload.Username == "" {
+		sendShareError(ctx, fasthttp.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if security.UserAuthenticationIsEnabled() {
+		if _, err := security.GetBasicUserByUsername(payload.Username); err != nil {
+			sendShareError(ctx, fasthttp.StatusNotFound, err.Error())
+			return
+		}
+	}
+
+	data, err := acl.TransferOwnership(entity, id, payload.Username)
+	if err != nil {
+		sendShareError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	}
+
+	audit.Record(ctx, audit.CategoryACL, "transfer_ownership", entity+"/"+id,
+		map[string]any{"owner": existing[acl.UsernameField]},
+		map[string]any{"owner": data[acl.UsernameField]},
+	)
+
+	finalizeUpdate(entity)
+
+	response, _ := json.Marshal(acl.HideFields(ctx, entity, data))
+	sendJSONResponse(ctx, response)
+}
+
+func loadSharedEntity(ctx *fasthttp.RequestCtx) (string, string, map[string]any, bool) {
+	entity := ctx.UserValue("entity").(string)
+	id := ctx.UserValue("id").(string)
+
+	if !security.IdentityAuthenticationIsEnabled() {
+		sendShareError(ctx, fasthttp.StatusBadRequest, "sharing requires user or jwt authentication")
+		return "", "", nil, false
+	}
+
+	data := engine.ReadEntityById(entity, id)
+	if data == nil {
+		sendShareError(ctx, fasthttp.StatusNotFound, "entity not found")
+		return "", "", nil, false
+	}
+
+	if !acl.CanManageSharing(ctx, data) {
+		sendShareError(ctx, fasthttp.StatusForbidden, "forbidden")
+		return "", "", nil, false
+	}
+
+	return entity, id, data, true
+}
+
+func parseSharePayload(ctx *fasthttp.RequestCtx, requirePermissions bool) (*SharePayload, bool) {
+	var payload SharePayload
+	if err := json.Unmarshal(ctx.PostBody(), &payload); err != nil {
+		sendShareError(ctx, fasthttp.StatusBadRequest, "invalid request body")
+		return nil, false
+	}
+
+	if len(payload.Users) == 0 && len(payload.Groups) == 0 {
+		sendShareError(ctx, fasthttp.StatusBadRequest, "users or groups are required")
+		return nil, false
+	}
+
+	if requirePermissions && len(payload.Permissions) == 0 {
+		sendShareError(ctx, fasthttp.StatusBadRequest, "permissions are required")
+		return nil, false
+	}
+
+	for _, p := range payload.Permissions {
+		if !acl.IsValidSharePermission(p) {
+			sendShareError(ctx, fasthttp.StatusBadRequest, "invalid permission "+p)
+			return nil, false
+		}
+	}
+
+	return &payload, true
+}
+
+func sendShares(ctx *fasthttp.RequestCtx, entity, id string, data map[string]any) {
+	response, _ := json.Marshal(map[string]any{
+		"entity": entity,
+		"id":     id,
+		"owner":  data[acl.UsernameField],
+		"shares": acl.GetShares(data),
+	})
+
+	sendJSONResponse(ctx, response)
+}
+
+func sendShareError(ctx *fasthttp.RequestCtx, status int, message string) {
+	response, _ := json.Marshal(map[string]string{"error": message})
+
+	ctx.Response.Header.Set("Content-Type", "application/json")
+	ctx.SetStatusCode(status)
+	ctx.SetBody(response)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"io"
+	"net"
+	"time"
+)
+
+const streamTimeout = 30 * time.Second
+
+type deadlineWriter struct {
+	w    io.Writer
+	conn net.Conn
+}
+
+func (d deadlineWriter) Write(p []byte) (int, error) {
+	_ = d.conn.SetWriteDeadline(time.Now().Add(streamTimeout))
+	return d.w.Write(p)
+}
+
+type deadlineReader struct {
+	r    io.Reader
+	conn net.Conn
+}
+
+func (d deadlineReader) Read(p []byte) (int, error) {
+	_ = d.conn.SetReadDeadline(time.Now().Add(streamTimeout))
+	return d.r.Read(p)
+}
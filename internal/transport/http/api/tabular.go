@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/seed"
+	"github.com/taymour/elysiandb/internal/tabular"
+	"github.com/taymour/elysiandb/internal/transfer"
+	"github.com/valyala/fasthttp"
+)
+
+func EntityExportController(ctx *fasthttp.RequestCtx) {
+	entity := ctx.UserValue("entity").(string)
+
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = tabular.FormatCSV
+	}
+
+	if !tabular.IsFormat(format) {
+		sendTransferError(ctx, fasthttp.StatusBadRequest, "unsupported format, expected csv or parquet")
+		return
+	}
+
+	if _, err := transfer.ResolveEntities([]string{entity}); err != nil {
+		sendTransferError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	}
+
+	sortField, sortAscending := ParseSortParam(ctx.QueryArgs())
+	filters := ParseFilterParam(ctx.QueryArgs())
+	search := string(ctx.QueryArgs().Peek("search"))
+
+	if hidden := acl.HiddenFieldsInQuery(ctx, entity, append(query.LeafFields(filters), sortField)...); len(hidden) > 0 {
+		sendForbiddenFields(ctx, hidden)
+		return
+	}
+
+	data := readList(
+		ctx,
+		entity,
+		ctx.QueryArgs().GetUintOrZero("limit"),
+		ctx.QueryArgs().GetUintOrZero("offset"),
+		sortField,
+		sortAscending,
+		filters,
+		search,
+		string(ctx.QueryArgs().Peek("includes")),
+	)
+
+	if fields := api_storage.ParseFieldsParam(string(ctx.QueryArgs().Peek("fields"))); len(fields) > 0 {
+		for i, item := range data {
+			data[i] = engine.FilterFields(item, fields)
+		}
+	}
+
+	conn := ctx.Conn()
+
+	ctx.SetContentType(tabular.ContentType(format))
+	ctx.Response.Header.Set("Content-Disposition", `attachment; filename="`+tabular.FileName(entity, format, time.Now())+`"`)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := tabular.Write(deadlineWriter{w: w, conn: conn}, format, data); err != nil {
+			log.Warn("Export: ", entity, " download interrupted: ", err)
+			return
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(streamTimeout))
+		if err := w.Flush(); err != nil {
+			log.Warn("Export: ", entity, " download interrupted: ", err)
+		}
+	})
+}
+
+func EntityImportController(ctx *fasthttp.RequestCtx) {
+	entity := ctx.UserValue("entity").(string)
+	ctx.Response.Header.Set("Content-Type", "application/json")
+
+	format := tabularImportFormat(ctx)
+	if !tabular.IsFormat(format) {
+		sendTransferError(ctx, fasthttp.StatusBadRequest, "unsupported format, expected csv or parquet")
+		return
+	}
+
+	if strings.HasPrefix(entity, api_storage.CoreEntityTypePrefix) {
+		sendTransferError(ctx, fasthttp.StatusForbidden, "forbidden")
+		return
+	}
+
+	opts := transfer.ImportOptions{
+		Mode:    string(ctx.QueryArgs().Peek("mode")),
+		DryRun:  ctx.QueryArgs().GetBool("dryRun"),
+		Prepare: prepareImportedEntity(ctx),
+	}
+
+	if opts.Mode != "" && !transfer.IsMode(opts.Mode) {
+		sendTransferError(ctx, fasthttp.StatusBadRequest, "unsupported mode, expected replace, upsert or skip-existing")
+		return
+	}
+
+	if opts.Mode == transfer.ModeReplace && security.IdentityAuthenticationIsEnabled() && !security.CurrentUserIsAdmin(ctx) {
+		sendTransferError(ctx, fasthttp.StatusForbidden, "replace mode needs an admin")
+		return
+	}
+
+	im, err := transfer.NewImporter(opts)
+	if err != nil {
+		sendTransferError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body io.Reader = bytes.NewReader(ctx.PostBody())
+	if ctx.Request.IsBodyStream() {
+		body = deadlineReader{r: ctx.RequestBodyStream(), conn: ctx.Conn()}
+	}
+
+	readOpts := tabular.ReadOptions{Entity: entity, Mapping: ParseMappingParam(ctx.QueryArgs())}
+	err = tabular.Read(body, format, readOpts, func(n int, doc map[string]any, err error) {
+		if err != nil {
+			id, _ := doc["id"].(string)
+			im.Fail(n, seed.KindEntity, entity, id, err)
+			return
+		}
+
+		im.WriteEntity(n, entity, doc)
+	})
+
+	report := im.Finish()
+
+	if !opts.DryRun {
+		audit.Record(ctx, audit.CategoryDestructive, "import", entity, nil, map[string]any{
+			"format":   format,
+			"mode":     report.Mode,
+			"imported": report.Imported[entity],
+			"failed":   report.Failed,
+		})
+	}
+
+	response := importResponse{Status: "import completed", Report: report}
+	status := fasthttp.StatusOK
+
+	switch {
+	case err != nil:
+		response.Status = "import aborted"
+		response.Error = err.Error()
+		status = fasthttp.StatusBadRequest
+	case opts.DryRun && report.Failed > 0:
+		response.Status = "dry run found errors"
+	case opts.DryRun:
+		response.Status = "dry run completed"
+	case report.Failed > 0:
+		response.Status = "import completed with errors"
+	}
+
+	b, _ := json.Marshal(response)
+
+	ctx.SetStatusCode(status)
+	ctx.SetBody(b)
+}
+
+func prepareImportedEntity(ctx *fasthttp.RequestCtx) func(entity string, doc, existing map[string]any) error {
+	return func(entity string, doc, existing map[string]any) error {
+		acl.StripProtectedFields(doc)
+
+		if existing == nil {
+			if !acl.CanCreateEntity(ctx, entity) {
+				return errors.New("forbidden")
+			}
+
+			if forbidden := acl.ForbiddenFieldsForCreate(ctx, entity, doc); len(forbidden) > 0 {
+				return fmt.Errorf("forbidden fields: %s", strings.Join(forbidden, ", "))
+			}
+
+			if security.IdentityAuthenticationIsEnabled() {
+				doc[acl.UsernameField] = security.CurrentPrincipal(ctx).Username
+			}
+
+			return nil
+		}
+
+		if !acl.CanUpdateEntity(ctx, entity, existing) {
+			return errors.New("forbidden")
+		}
+
+		if forbidden := acl.ForbiddenFieldsForUpdate(ctx, entity, doc, existing); len(forbidden) > 0 {
+			return fmt.Errorf("forbidden fields: %s", strings.Join(forbidden, ", "))
+		}
+
+		if security.IdentityAuthenticationIsEnabled() {
+			for _, field := range []string{acl.UsernameField, acl.SharesField} {
+				if value, ok := existing[field]; ok {
+					doc[field] = value
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+func tabularImportFormat(ctx *fasthttp.RequestCtx) string {
+	if format := string(ctx.QueryArgs().Peek("format")); format != "" {
+		return format
+	}
+
+	if strings.HasPrefix(string(ctx.Request.Header.ContentType()), tabular.ContentType(tabular.FormatParquet)) {
+		return tabular.FormatParquet
+	}
+
+	return tabular.FormatCSV
+}
+
+func ParseMappingParam(params *fasthttp.Args) map[string]string {
+	mapping := make(map[string]string)
+	for k, v := range params.All() {
+		key := string(k)
+		if strings.HasPrefix(key, "map[") && strings.HasSuffix(key, "]") {
+			mapping[key[len("map["):len(key)-1]] = strings.TrimSpace(string(v))
+		}
+	}
+
+	return mapping
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/taymour/elysiandb/internal/cache"
 	"github.com/taymour/elysiandb/internal/engine"
 	"github.com/taymour/elysiandb/internal/globals"
-	"github.com/taymour/elysiandb/internal/mongodb"
 	"github.com/taymour/elysiandb/internal/schema"
 	"github.com/valyala/fasthttp"
 )
@@ -17,13 +16,7 @@ func UpdateByIdController(ctx *fasthttp.RequestCtx) {
 	id := ctx.UserValue("id").(string)
 	body := ctx.PostBody()
 
-	var schemaData map[string]any
-
-	if engine.IsEngineMongoDB() {
-		schemaData = mongodb.GetEntitySchema(entity)
-	} else {
-		schemaData = nil
-	}
+	schemaData := engine.SchemaData(entity)
 
 	if globals.GetConfig().Api.Schema.Strict && schema.IsManualSchema(entity, schemaData) {
 		var obj map[string]any
@@ -51,13 +44,7 @@ func UpdateListController(ctx *fasthttp.RequestCtx) {
 	entity := ctx.UserValue("entity").(string)
 	body := ctx.PostBody()
 
-	var schemaData map[string]any
-
-	if engine.IsEngineMongoDB() {
-		schemaData = mongodb.GetEntitySchema(entity)
-	} else {
-		schemaData = nil
-	}
+	schemaData := engine.SchemaData(entity)
 
 	if globals.GetConfig().Api.Schema.Strict && schema.IsManualSchema(entity, schemaData) {
 		var arr []map[string]any
@@ -89,14 +76,22 @@ func handleSingleUpdate(ctx *fasthttp.RequestCtx, entity, id string, body []byte
 		return false
 	}
 
-	if !acl.CanUpdateEntity(entity, single) {
+	acl.StripProtectedFields(single)
+
+	existing := engine.ReadEntityById(entity, id)
+	if !acl.CanUpdateEntity(ctx, entity, existingOr(existing, single)) {
 		ctx.SetStatusCode(fasthttp.StatusForbidden)
 		ctx.SetBody([]byte(`{"error":"forbidden"}`))
 
-		return false
+		return true
+	}
+
+	if forbidden := acl.ForbiddenFieldsForUpdate(ctx, entity, single, existing); len(forbidden) > 0 {
+		sendForbiddenFields(ctx, forbidden)
+		return true
 	}
 
-	data := engine.UpdateEntityById(entity, id, single)
+	data := acl.HideFields(ctx, entity, engine.UpdateEntityById(entity, id, single))
 
 	response, _ := json.Marshal(data)
 	sendJSONResponse(ctx, response)
@@ -110,20 +105,45 @@ func handleBatchUpdate(ctx *fasthttp.RequestCtx, entity string, body []byte) boo
 		return false
 	}
 
-	if !acl.CanUpdateListOfEntities(entity, list) {
+	existing := make([]map[string]any, len(list))
+	targets := make([]map[string]any, len(list))
+	for i, item := range list {
+		acl.StripProtectedFields(item)
+
+		id, _ := item["id"].(string)
+		existing[i] = engine.ReadEntityById(entity, id)
+		targets[i] = existingOr(existing[i], item)
+	}
+
+	if !acl.CanUpdateListOfEntities(ctx, entity, targets) {
 		ctx.SetStatusCode(fasthttp.StatusForbidden)
 		ctx.SetBody([]byte(`{"error":"forbidden"}`))
 
-		return false
+		return true
+	}
+
+	for i, item := range list {
+		if forbidden := acl.ForbiddenFieldsForUpdate(ctx, entity, item, existing[i]); len(forbidden) > 0 {
+			sendForbiddenFields(ctx, forbidden)
+			return true
+		}
 	}
 
-	data := engine.UpdateListOfEntities(entity, list)
+	data := acl.HideFieldsInList(ctx, entity, engine.UpdateListOfEntities(entity, list))
 	response, _ := json.Marshal(data)
 	sendJSONResponse(ctx, response)
 
 	return true
 }
 
+func existingOr(existing, payload map[string]any) map[string]any {
+	if existing != nil {
+		return existing
+	}
+
+	return payload
+}
+
 func sendJSONResponse(ctx *fasthttp.RequestCtx, response []byte) {
 	ctx.Response.Header.Set("Content-Type", "application/json")
 	ctx.SetStatusCode(fasthttp.StatusOK)
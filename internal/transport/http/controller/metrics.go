@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"github.com/taymour/elysiandb/internal/metrics"
+	"github.com/valyala/fasthttp"
+)
+
+func MetricsController(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("text/plain; version=0.0.4; charset=utf-8")
+
+	if err := metrics.Default.WriteText(ctx); err != nil {
+		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+	}
+}
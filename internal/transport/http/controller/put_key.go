@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/taymour/elysiandb/internal/globals"
@@ -28,6 +29,11 @@ func PutKeyController(ctx *fasthttp.RequestCtx) {
 		err = storage.PutKeyValue(key, buf)
 	}
 
+	if errors.Is(err, storage.ErrMemoryLimit) {
+		ctx.Error(err.Error(), http.StatusInsufficientStorage)
+		return
+	}
+
 	if err != nil {
 		ctx.Error("Failed to store key-value pair", http.StatusBadRequest)
 		return
@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 
+	"github.com/taymour/elysiandb/internal/audit"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/stat"
 	"github.com/taymour/elysiandb/internal/storage"
@@ -18,4 +19,6 @@ func ResetController(ctx *fasthttp.RequestCtx) {
 	ctx.SetStatusCode(http.StatusOK)
 
 	storage.ResetStore()
+
+	audit.Record(ctx, audit.CategoryDestructive, "reset", "", nil, nil)
 }
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/log"
 	"github.com/taymour/elysiandb/internal/stat"
@@ -26,6 +28,10 @@ func HandleSet(query []byte, ttl int) []byte {
 		err = storage.PutKeyValue(key, val)
 	}
 
+	if errors.Is(err, storage.ErrMemoryLimit) {
+		return []byte("ERR OOM")
+	}
+
 	if err != nil {
 		log.Error("Failed to store key-value pair:", err)
 		return []byte("ERR")
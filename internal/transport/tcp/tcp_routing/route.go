@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/replication"
 	"github.com/taymour/elysiandb/internal/transport/tcp/handler"
 	"github.com/taymour/elysiandb/internal/transport/tcp/parsing"
 )
@@ -25,6 +26,9 @@ func RouteLine(line []byte, c net.Conn) []byte {
 	case parsing.EqASCII(cmd, []byte("MGET")):
 		return handler.HandleMultiGet(query)
 
+	case isWriteCommand(cmd) && replication.IsFollower():
+		return []byte("ERR READONLY")
+
 	case parsing.EqASCII(cmd, []byte("SET")):
 		ttl := extractTTLFromQuery(&query)
 		return handler.HandleSet(query, ttl)
@@ -44,6 +48,12 @@ func RouteLine(line []byte, c net.Conn) []byte {
 	return []byte("ERR")
 }
 
+func isWriteCommand(cmd []byte) bool {
+	return parsing.EqASCII(cmd, []byte("SET")) ||
+		parsing.EqASCII(cmd, []byte("DEL")) ||
+		parsing.EqASCII(cmd, []byte("RESET"))
+}
+
 func extractTTLFromQuery(query *[]byte) int {
 	ttlParam, rest := parsing.FirstWordBytes(*query)
 
@@ -1,10 +1,13 @@
 package acl_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/taymour/elysiandb/internal/acl"
 	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
 	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/security"
@@ -33,130 +36,134 @@ func writeACL(username, entity string, perms map[acl.Permission]bool) {
 	api_storage.WriteEntity(acl.ACLEntity, a.ToDataMap())
 }
 
+func asUser(username string, role security.Role) context.Context {
+	return security.WithPrincipal(context.Background(), &security.Principal{Username: username, Role: role})
+}
+
 func TestCanCreateEntity_DefaultDeny(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	writeACL("u", "doc", perms)
 
-	if acl.CanCreateEntity("doc") {
+	if acl.CanCreateEntity(ctx, "doc") {
 		t.Fatalf("expected false")
 	}
 }
 
 func TestCanCreateEntity_WithPermission(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionCreate] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanCreateEntity("doc") {
+	if !acl.CanCreateEntity(ctx, "doc") {
 		t.Fatalf("expected true")
 	}
 }
 
 func TestCanReadEntity_Global(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionRead] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanReadEntity("doc", map[string]any{acl.UsernameField: "x"}) {
+	if !acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "x"}) {
 		t.Fatalf("expected true")
 	}
 }
 
 func TestCanReadEntity_Owning(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionOwningRead] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanReadEntity("doc", map[string]any{acl.UsernameField: "u"}) {
+	if !acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "u"}) {
 		t.Fatalf("expected true")
 	}
 
-	if acl.CanReadEntity("doc", map[string]any{acl.UsernameField: "x"}) {
+	if acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "x"}) {
 		t.Fatalf("expected false")
 	}
 }
 
 func TestCanUpdateEntity_Global(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionUpdate] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanUpdateEntity("doc", map[string]any{acl.UsernameField: "x"}) {
+	if !acl.CanUpdateEntity(ctx, "doc", map[string]any{acl.UsernameField: "x"}) {
 		t.Fatalf("expected true")
 	}
 }
 
 func TestCanUpdateEntity_Owning(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionOwningUpdate] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanUpdateEntity("doc", map[string]any{acl.UsernameField: "u"}) {
+	if !acl.CanUpdateEntity(ctx, "doc", map[string]any{acl.UsernameField: "u"}) {
 		t.Fatalf("expected true")
 	}
 
-	if acl.CanUpdateEntity("doc", map[string]any{acl.UsernameField: "x"}) {
+	if acl.CanUpdateEntity(ctx, "doc", map[string]any{acl.UsernameField: "x"}) {
 		t.Fatalf("expected false")
 	}
 }
 
 func TestCanDeleteEntity_Global(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionDelete] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanDeleteEntity("doc", map[string]any{acl.UsernameField: "x"}) {
+	if !acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "x"}) {
 		t.Fatalf("expected true")
 	}
 }
 
 func TestCanDeleteEntity_Owning(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionOwningDelete] = true
 	writeACL("u", "doc", perms)
 
-	if !acl.CanDeleteEntity("doc", map[string]any{acl.UsernameField: "u"}) {
+	if !acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "u"}) {
 		t.Fatalf("expected true")
 	}
 
-	if acl.CanDeleteEntity("doc", map[string]any{acl.UsernameField: "x"}) {
+	if acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "x"}) {
 		t.Fatalf("expected false")
 	}
 }
 
 func TestCanUpdateListOfEntities_Global(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionUpdate] = true
 	writeACL("u", "doc", perms)
 
-	ok := acl.CanUpdateListOfEntities("doc", []map[string]any{
+	ok := acl.CanUpdateListOfEntities(ctx, "doc", []map[string]any{
 		{acl.UsernameField: "x"},
 		{acl.UsernameField: "y"},
 	})
@@ -167,13 +174,13 @@ func TestCanUpdateListOfEntities_Global(t *testing.T) {
 
 func TestCanUpdateListOfEntities_Owning(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionOwningUpdate] = true
 	writeACL("u", "doc", perms)
 
-	ok := acl.CanUpdateListOfEntities("doc", []map[string]any{
+	ok := acl.CanUpdateListOfEntities(ctx, "doc", []map[string]any{
 		{acl.UsernameField: "u"},
 		{acl.UsernameField: "u"},
 	})
@@ -181,7 +188,7 @@ func TestCanUpdateListOfEntities_Owning(t *testing.T) {
 		t.Fatalf("expected true")
 	}
 
-	ok = acl.CanUpdateListOfEntities("doc", []map[string]any{
+	ok = acl.CanUpdateListOfEntities(ctx, "doc", []map[string]any{
 		{acl.UsernameField: "u"},
 		{acl.UsernameField: "x"},
 	})
@@ -192,7 +199,7 @@ func TestCanUpdateListOfEntities_Owning(t *testing.T) {
 
 func TestFilterListOfEntities_GlobalRead(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionRead] = true
@@ -203,7 +210,7 @@ func TestFilterListOfEntities_GlobalRead(t *testing.T) {
 		{acl.UsernameField: "x"},
 	}
 
-	out := acl.FilterListOfEntities("doc", data)
+	out := acl.FilterListOfEntities(ctx, "doc", data)
 	if len(out) != 2 {
 		t.Fatalf("expected 2")
 	}
@@ -211,7 +218,7 @@ func TestFilterListOfEntities_GlobalRead(t *testing.T) {
 
 func TestFilterListOfEntities_Owning(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	perms[acl.PermissionOwningRead] = true
@@ -222,7 +229,7 @@ func TestFilterListOfEntities_Owning(t *testing.T) {
 		{acl.UsernameField: "x"},
 	}
 
-	out := acl.FilterListOfEntities("doc", data)
+	out := acl.FilterListOfEntities(ctx, "doc", data)
 	if len(out) != 1 {
 		t.Fatalf("expected 1")
 	}
@@ -230,7 +237,7 @@ func TestFilterListOfEntities_Owning(t *testing.T) {
 
 func TestFilterListOfEntities_NoPermission(t *testing.T) {
 	setup(t, true)
-	security.SetCurrentUsername("u")
+	ctx := asUser("u", "")
 
 	perms := acl.NewPermissions()
 	writeACL("u", "doc", perms)
@@ -239,7 +246,7 @@ func TestFilterListOfEntities_NoPermission(t *testing.T) {
 		{acl.UsernameField: "u"},
 	}
 
-	out := acl.FilterListOfEntities("doc", data)
+	out := acl.FilterListOfEntities(ctx, "doc", data)
 	if len(out) != 0 {
 		t.Fatalf("expected empty")
 	}
@@ -247,24 +254,485 @@ func TestFilterListOfEntities_NoPermission(t *testing.T) {
 
 func TestAllAllowedWhenAuthDisabled(t *testing.T) {
 	setup(t, false)
-	security.SetCurrentUsername("any")
+	ctx := asUser("any", "")
 
-	if !acl.CanCreateEntity("x") {
+	if !acl.CanCreateEntity(ctx, "x") {
 		t.Fatalf("expected true")
 	}
-	if !acl.CanReadEntity("x", map[string]any{}) {
+	if !acl.CanReadEntity(ctx, "x", map[string]any{}) {
 		t.Fatalf("expected true")
 	}
-	if !acl.CanUpdateEntity("x", map[string]any{}) {
+	if !acl.CanUpdateEntity(ctx, "x", map[string]any{}) {
 		t.Fatalf("expected true")
 	}
-	if !acl.CanDeleteEntity("x", map[string]any{}) {
+	if !acl.CanDeleteEntity(ctx, "x", map[string]any{}) {
 		t.Fatalf("expected true")
 	}
 
 	data := []map[string]any{{"a": 1}}
-	out := acl.FilterListOfEntities("x", data)
+	out := acl.FilterListOfEntities(ctx, "x", data)
 	if len(out) != 1 {
 		t.Fatalf("expected passthrough")
 	}
 }
+
+func TestJWTPrincipalFallsBackToRoleDefaults(t *testing.T) {
+	setup(t, true)
+	globals.GetConfig().Security.Authentication.Mode = "jwt"
+	ctx := asUser("external", security.RoleUser)
+
+	if acl.CanCreateEntity(ctx, "doc") {
+		t.Fatalf("expected create denied for user role")
+	}
+	if !acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "external"}) {
+		t.Fatalf("expected owning read allowed")
+	}
+	if acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("expected read of foreign document denied")
+	}
+
+	ctx = asUser("external", security.RoleAdmin)
+	if !acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("expected admin delete allowed")
+	}
+
+	perms := acl.NewPermissions()
+	writeACL("external", "doc", perms)
+	if acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("stored ACL must win over role defaults")
+	}
+}
+
+func TestJWTPrincipalUsesCustomRoleACL(t *testing.T) {
+	setup(t, true)
+	globals.GetConfig().Security.Authentication.Mode = "jwt"
+	_ = security.CreateRole("editor", "")
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionRead] = true
+	perms[acl.PermissionUpdate] = true
+	acl.SetRoleACL(&acl.PrincipalACL{Principal: "editor", Entity: "doc", Permissions: perms})
+
+	ctx := asUser("external", "editor")
+	if !acl.CanUpdateEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("expected the editor role ACL to grant update")
+	}
+	if acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("expected delete denied for the editor role")
+	}
+}
+
+func TestFieldRules_HiddenFieldsAreStripped(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", security.RoleUser)
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionRead] = true
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+		Username:    "u",
+		Entity:      "employee",
+		Permissions: perms,
+		Fields:      map[string]acl.FieldRule{"salary": acl.FieldRuleHidden, "address.street": acl.FieldRuleHidden},
+	}).ToDataMap())
+
+	data := []map[string]any{
+		{
+			"id":      "1",
+			"name":    "a",
+			"salary":  100,
+			"address": map[string]any{"street": "x", "city": "y"},
+			"manager": map[string]any{"@entity": "employee", "id": "2", "salary": 200},
+		},
+	}
+
+	out := acl.FilterListOfEntities(ctx, "employee", data)
+	if len(out) != 1 {
+		t.Fatalf("expected 1 item")
+	}
+	if _, ok := out[0]["salary"]; ok {
+		t.Fatalf("salary should be hidden")
+	}
+	if address := out[0]["address"].(map[string]any); address["street"] != nil || address["city"] != "y" {
+		t.Fatalf("nested hidden field not stripped: %v", address)
+	}
+	if _, ok := out[0]["manager"].(map[string]any)["salary"]; ok {
+		t.Fatalf("hidden field of included entity should be stripped")
+	}
+	if _, ok := data[0]["salary"]; !ok {
+		t.Fatalf("source data must not be mutated")
+	}
+	if _, ok := data[0]["address"].(map[string]any)["street"]; !ok {
+		t.Fatalf("nested source data must not be mutated")
+	}
+}
+
+func TestFieldRules_RoleRulesAndUserOverride(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", security.RoleUser)
+
+	acl.SetFieldRulesForRole("employee", security.RoleUser, map[string]acl.FieldRule{
+		"salary": acl.FieldRuleHidden,
+		"status": acl.FieldRuleReadOnly,
+	})
+
+	out := acl.HideFields(ctx, "employee", map[string]any{"salary": 1, "status": "active"})
+	if _, ok := out["salary"]; ok {
+		t.Fatalf("role rule should hide salary")
+	}
+
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+		Username:    "u",
+		Entity:      "employee",
+		Permissions: acl.NewPermissions(),
+		Fields:      map[string]acl.FieldRule{"salary": acl.FieldRuleReadWrite},
+	}).ToDataMap())
+
+	out = acl.HideFields(ctx, "employee", map[string]any{"salary": 1})
+	if _, ok := out["salary"]; !ok {
+		t.Fatalf("user override should reveal salary")
+	}
+
+	forbidden := acl.ForbiddenFieldsForCreate(ctx, "employee", map[string]any{"salary": 1, "status": "x"})
+	if len(forbidden) != 1 || forbidden[0] != "status" {
+		t.Fatalf("expected status to be forbidden, got %v", forbidden)
+	}
+}
+
+func TestFieldRules_WriteOnce(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", "")
+
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+		Username:    "u",
+		Entity:      "doc",
+		Permissions: acl.NewPermissions(),
+		Fields:      map[string]acl.FieldRule{"code": acl.FieldRuleWriteOnce, "secret": acl.FieldRuleHidden},
+	}).ToDataMap())
+
+	if f := acl.ForbiddenFieldsForCreate(ctx, "doc", map[string]any{"code": "A"}); len(f) != 0 {
+		t.Fatalf("write-once field can be set on create, got %v", f)
+	}
+	if f := acl.ForbiddenFieldsForUpdate(ctx, "doc", map[string]any{"code": "A"}, map[string]any{"id": "1"}); len(f) != 0 {
+		t.Fatalf("write-once field can be set when empty, got %v", f)
+	}
+
+	f := acl.ForbiddenFieldsForUpdate(ctx, "doc", map[string]any{"code": "B", "secret": "x"}, map[string]any{"id": "1", "code": "A"})
+	if len(f) != 2 || f[0] != "code" || f[1] != "secret" {
+		t.Fatalf("expected code and secret to be forbidden, got %v", f)
+	}
+}
+
+func TestFieldRules_IgnoredWhenAuthDisabled(t *testing.T) {
+	setup(t, false)
+
+	if f := acl.ForbiddenFieldsForCreate(context.Background(), "doc", map[string]any{"a": 1}); f != nil {
+		t.Fatalf("expected no forbidden fields")
+	}
+	if out := acl.HideFields(context.Background(), "doc", map[string]any{"a": 1}); out["a"] != 1 {
+		t.Fatalf("expected passthrough")
+	}
+}
+
+func TestInheritedACL_RoleAndGroupPermissions(t *testing.T) {
+	setup(t, true)
+	_ = security.CreateRole("editor", "")
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "u", Password: "p", Role: "editor"})
+	api_storage.CreateEntityType("doc")
+	acl.InitACL()
+
+	ctx := asUser("u", "editor")
+
+	stored := acl.GetStoredACLForUsername("doc", "u")
+	if stored == nil || !stored.Inherited {
+		t.Fatalf("generated ACL should be inherited")
+	}
+
+	if acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("custom role without ACL should only get owning permissions")
+	}
+
+	rolePerms := acl.NewPermissions()
+	rolePerms[acl.PermissionRead] = true
+	acl.SetRoleACL(&acl.PrincipalACL{Principal: "editor", Entity: "doc", Permissions: rolePerms})
+
+	if !acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("role ACL should grant read")
+	}
+	if acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("role ACL should not grant delete")
+	}
+
+	_ = security.CreateGroup("cleaners", "")
+	_ = security.AddUserToGroup("cleaners", "u")
+	groupPerms := acl.NewPermissions()
+	groupPerms[acl.PermissionDelete] = true
+	acl.SetGroupACL(&acl.PrincipalACL{Principal: "cleaners", Entity: "doc", Permissions: groupPerms})
+
+	if !acl.CanDeleteEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("group ACL should grant delete")
+	}
+
+	effective := acl.GetACLEntityForUsername("doc", "u")
+	if !effective.Can(acl.PermissionRead) || !effective.Can(acl.PermissionDelete) || effective.Can(acl.PermissionCreate) {
+		t.Fatalf("unexpected effective permissions %v", effective.Permissions)
+	}
+
+	if err := acl.UpdateACLEntityForUsername("doc", "u", acl.NewPermissions()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("user override must win over role and group ACLs")
+	}
+
+	if err := acl.ResetACLEntityToDefault("doc", "u"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !acl.CanReadEntity(ctx, "doc", map[string]any{acl.UsernameField: "other"}) {
+		t.Fatalf("reset should restore inheritance")
+	}
+}
+
+func TestACLChanges_PurgeCachedReads(t *testing.T) {
+	setup(t, true)
+	globals.GetConfig().Api.Cache.Enabled = true
+	cache.InitCache(time.Minute)
+	_ = security.CreateRole("editor", "")
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "u", Password: "p", Role: "editor"})
+	_ = security.CreateGroup("cleaners", "")
+	api_storage.CreateEntityType("doc")
+	acl.InitACL()
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionRead] = true
+	acl.SetRoleACL(&acl.PrincipalACL{Principal: "editor", Entity: "doc", Permissions: perms})
+	acl.SetGroupACL(&acl.PrincipalACL{Principal: "cleaners", Entity: "doc", Permissions: perms})
+
+	changes := map[string]func(){
+		"update user ACL":   func() { _ = acl.UpdateACLEntityForUsername("doc", "u", perms) },
+		"delete user ACLs":  func() { _ = acl.DeleteUserACls("u") },
+		"delete role ACLs":  func() { acl.DeleteRoleACLs("editor") },
+		"delete group ACLs": func() { acl.DeleteGroupACLs("cleaners") },
+		"add to group":      func() { _ = security.AddUserToGroup("cleaners", "u") },
+		"remove from group": func() { _ = security.RemoveUserFromGroup("cleaners", "u") },
+		"change role":       func() { _ = security.ChangeUserRole("u", string(security.RoleUser)) },
+		"set attributes":    func() { _ = security.SetUserAttributes("u", map[string]string{"team": "a"}) },
+		"delete group":      func() { _ = security.DeleteGroup("cleaners") },
+	}
+
+	for name, change := range changes {
+		acl.InitACL()
+		cache.CacheStore.Set("doc", []byte("query"), []byte("[]"))
+		change()
+		if cache.CacheStore.Get("doc", []byte("query")) != nil {
+			t.Fatalf("%s should purge cached reads", name)
+		}
+	}
+}
+
+func TestInheritedACL_GroupFieldRules(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", security.RoleUser)
+
+	_ = security.CreateGroup("interns", "")
+	_ = security.AddUserToGroup("interns", "u")
+	acl.SetGroupACL(&acl.PrincipalACL{Principal: "interns", Entity: "employee", Fields: map[string]acl.FieldRule{"salary": acl.FieldRuleHidden}})
+
+	if out := acl.HideFields(ctx, "employee", map[string]any{"salary": 1, "name": "a"}); out["salary"] != nil || out["name"] != "a" {
+		t.Fatalf("group field rule not applied: %v", out)
+	}
+}
+
+func TestRowFilters_RoleFilterWithUserPlaceholders(t *testing.T) {
+	setup(t, true)
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "u", Password: "p", Role: security.RoleUser})
+	_ = security.SetUserAttributes("u", map[string]string{"team": "red"})
+	ctx := asUser("u", security.RoleUser)
+
+	writeACL("u", "order", acl.NewPermissions())
+	acl.SetRoleACL(&acl.PrincipalACL{
+		Principal: string(security.RoleUser),
+		Entity:    "order",
+		Filter:    map[string]any{"team": map[string]any{"eq": "$user.team"}},
+	})
+
+	if !acl.CanReadEntity(ctx, "order", map[string]any{"team": "red"}) {
+		t.Fatalf("row filter should grant read on team orders")
+	}
+	if acl.CanReadEntity(ctx, "order", map[string]any{"team": "blue"}) {
+		t.Fatalf("row filter should not grant read on other teams")
+	}
+
+	out := acl.FilterListOfEntities(ctx, "order", []map[string]any{
+		{"id": "1", "team": "red"},
+		{"id": "2", "team": "blue"},
+		{"id": "3"},
+	})
+	if len(out) != 1 || out[0]["id"] != "1" {
+		t.Fatalf("unexpected filtered list: %v", out)
+	}
+}
+
+func TestRowFilters_JWTClaimsResolvePerRequest(t *testing.T) {
+	setup(t, true)
+	globals.GetConfig().Security.Authentication.Mode = "jwt"
+
+	acl.SetRoleACL(&acl.PrincipalACL{
+		Principal: string(security.RoleUser),
+		Entity:    "order",
+		Filter:    map[string]any{"team": map[string]any{"eq": "$user.team"}},
+	})
+
+	red := security.WithPrincipal(context.Background(), &security.Principal{Username: "a", Role: security.RoleUser, Claims: map[string]any{"team": "red"}})
+	blue := security.WithPrincipal(context.Background(), &security.Principal{Username: "b", Role: security.RoleUser, Claims: map[string]any{"team": "blue"}})
+
+	order := map[string]any{"team": "red"}
+	if !acl.CanReadEntity(red, "order", order) {
+		t.Fatalf("claims of the red principal should grant read")
+	}
+	if acl.CanReadEntity(blue, "order", order) {
+		t.Fatalf("claims of another principal must not leak into this request")
+	}
+}
+
+func TestRowFilters_GroupFilterAndOwnershipAreCombined(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", security.RoleUser)
+
+	_ = security.CreateGroup("readers", "")
+	_ = security.AddUserToGroup("readers", "u")
+	acl.SetGroupACL(&acl.PrincipalACL{
+		Principal: "readers",
+		Entity:    "article",
+		Filter:    map[string]any{"status": map[string]any{"eq": "published"}},
+	})
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionOwningRead] = true
+	writeACL("u", "article", perms)
+
+	filter, allowed := acl.ReadFilterForCurrentUser(ctx, "article")
+	if !allowed || len(filter.Or) != 3 {
+		t.Fatalf("expected ownership, share and group filters, got %+v", filter)
+	}
+
+	out := acl.FilterListOfEntities(ctx, "article", []map[string]any{
+		{"id": "1", "status": "published"},
+		{"id": "2", "status": "draft", acl.UsernameField: "u"},
+		{"id": "3", "status": "draft", acl.UsernameField: "other"},
+	})
+	if len(out) != 2 {
+		t.Fatalf("expected published and owned articles, got %v", out)
+	}
+}
+
+func TestRowFilters_UnresolvedPlaceholderGrantsNothing(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", security.RoleUser)
+
+	writeACL("u", "order", acl.NewPermissions())
+	acl.SetRoleACL(&acl.PrincipalACL{
+		Principal: string(security.RoleUser),
+		Entity:    "order",
+		Filter:    map[string]any{"team": map[string]any{"eq": "$user.team"}},
+	})
+
+	filter, _ := acl.ReadFilterForCurrentUser(ctx, "order")
+	if _, ok := filter.Leaf["team"]; ok {
+		t.Fatalf("a filter with unresolved placeholders must not grant access, got %+v", filter)
+	}
+	if acl.CanReadEntity(ctx, "order", map[string]any{"team": ""}) {
+		t.Fatalf("expected read to be denied")
+	}
+}
+
+func TestRowFilters_ReadPermissionIsUnrestricted(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("u", security.RoleUser)
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionRead] = true
+	writeACL("u", "order", perms)
+	acl.SetRoleACL(&acl.PrincipalACL{
+		Principal: string(security.RoleUser),
+		Entity:    "order",
+		Filter:    map[string]any{"team": map[string]any{"eq": "red"}},
+	})
+
+	filter, allowed := acl.ReadFilterForCurrentUser(ctx, "order")
+	if !allowed || !filter.IsEmpty() {
+		t.Fatalf("read permission should not be restricted, got %+v", filter)
+	}
+}
+
+func TestShares_GrantAccessToUsersAndGroups(t *testing.T) {
+	setup(t, true)
+	ctx := asUser("bob", security.RoleUser)
+	writeACL("bob", "doc", acl.NewPermissions())
+
+	api_storage.WriteEntity("doc", map[string]any{"id": "d1", acl.UsernameField: "alice"})
+
+	if acl.CanReadEntity(ctx, "doc", api_storage.ReadEntityById("doc", "d1")) {
+		t.Fatalf("unshared document must not be readable")
+	}
+
+	if _, err := acl.ShareEntity("doc", "d1", acl.ShareGrant{Users: []string{"bob"}, Permissions: []string{acl.ShareUpdate}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := api_storage.ReadEntityById("doc", "d1")
+	if !acl.CanReadEntity(ctx, "doc", data) || !acl.CanUpdateEntity(ctx, "doc", data) {
+		t.Fatalf("update share should grant read and update")
+	}
+	if acl.CanDeleteEntity(ctx, "doc", data) {
+		t.Fatalf("update share must not grant delete")
+	}
+
+	_ = security.CreateGroup("ops", "")
+	_ = security.AddUserToGroup("ops", "bob")
+	if _, err := acl.ShareEntity("doc", "d1", acl.ShareGrant{Groups: []string{"ops"}, Permissions: []string{acl.ShareDelete}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !acl.CanDeleteEntity(ctx, "doc", api_storage.ReadEntityById("doc", "d1")) {
+		t.Fatalf("group share should grant delete")
+	}
+
+	out := acl.FilterListOfEntities(ctx, "doc", []map[string]any{api_storage.ReadEntityById("doc", "d1"), {"id": "d2"}})
+	if len(out) != 1 {
+		t.Fatalf("expected only the shared document, got %v", out)
+	}
+
+	if _, err := acl.UnshareEntity("doc", "d1", acl.ShareGrant{Users: []string{"bob"}, Groups: []string{"ops"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shares := acl.GetShares(api_storage.ReadEntityById("doc", "d1")); len(shares) != 0 {
+		t.Fatalf("expected no shares, got %v", shares)
+	}
+}
+
+func TestShares_ManageSharingAndTransferOwnership(t *testing.T) {
+	setup(t, true)
+	api_storage.WriteEntity("doc", map[string]any{"id": "d1", acl.UsernameField: "alice"})
+	data := api_storage.ReadEntityById("doc", "d1")
+
+	ctx := asUser("bob", security.RoleUser)
+	if acl.CanManageSharing(ctx, data) {
+		t.Fatalf("only the owner or an admin can manage sharing")
+	}
+
+	ctx = asUser("alice", security.RoleUser)
+	if !acl.CanManageSharing(ctx, data) {
+		t.Fatalf("owner should manage sharing")
+	}
+
+	out, err := acl.TransferOwnership("doc", "d1", "bob")
+	if err != nil || out[acl.UsernameField] != "bob" {
+		t.Fatalf("unexpected transfer result %v %v", out, err)
+	}
+	if acl.CanManageSharing(ctx, api_storage.ReadEntityById("doc", "d1")) {
+		t.Fatalf("previous owner should lose sharing rights")
+	}
+
+	if _, err := acl.TransferOwnership("doc", "missing", "bob"); err == nil {
+		t.Fatalf("expected error for missing document")
+	}
+}
@@ -1,7 +1,6 @@
 package api_test
 
 import (
-	"bytes"
 	"reflect"
 	"testing"
 
@@ -27,20 +26,6 @@ func initIdxTestStore(t *testing.T) {
 	storage.LoadJsonDB()
 }
 
-func decodeIDs(b []byte) []string {
-	if len(b) == 0 {
-		return nil
-	}
-	parts := bytes.Split(b, []byte{'\n'})
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		if len(p) > 0 {
-			out = append(out, string(p))
-		}
-	}
-	return out
-}
-
 func TestAddIdToindexes_And_RemoveIdFromIndexes(t *testing.T) {
 	initIdxTestStore(t)
 
@@ -48,23 +33,16 @@ func TestAddIdToindexes_And_RemoveIdFromIndexes(t *testing.T) {
 	api_storage.AddIdToindexes(entity, "u1")
 	api_storage.AddIdToindexes(entity, "u2")
 
-	key := globals.ApiEntityIndexIdKey(entity)
-	raw, err := storage.GetByKey(key)
-	if err != nil {
-		t.Fatalf("GetByKey: %v", err)
-	}
-	got := decodeIDs(raw)
+	api_storage.AddIdToindexes(entity, "u1")
+
+	got := api_storage.GetListOfIds(entity, "", true)
 	if !reflect.DeepEqual(got, []string{"u1", "u2"}) {
 		t.Fatalf("ids=%v, want [u1 u2]", got)
 	}
 
 	api_storage.RemoveIdFromIndexes(entity, "u1")
 
-	raw2, err := storage.GetByKey(key)
-	if err != nil {
-		t.Fatalf("GetByKey after remove: %v", err)
-	}
-	got2 := decodeIDs(raw2)
+	got2 := api_storage.GetListOfIds(entity, "", true)
 	if !reflect.DeepEqual(got2, []string{"u2"}) {
 		t.Fatalf("ids=%v, want [u2]", got2)
 	}
@@ -96,20 +74,8 @@ func TestEnsureFieldIndex_And_IndexExists(t *testing.T) {
 		t.Fatalf("index should exist")
 	}
 
-	ascKey := globals.ApiEntityIndexFieldSortAscKey(entity, "score")
-	descKey := globals.ApiEntityIndexFieldSortDescKey(entity, "score")
-
-	ascRaw, err := storage.GetByKey(ascKey)
-	if err != nil {
-		t.Fatalf("asc GetByKey: %v", err)
-	}
-	descRaw, err := storage.GetByKey(descKey)
-	if err != nil {
-		t.Fatalf("desc GetByKey: %v", err)
-	}
-
-	asc := decodeIDs(ascRaw)
-	desc := decodeIDs(descRaw)
+	asc := api_storage.GetListOfIds(entity, "score", true)
+	desc := api_storage.GetListOfIds(entity, "score", false)
 
 	if !reflect.DeepEqual(asc, []string{"b", "a", "c"}) {
 		t.Fatalf("asc=%v", asc)
@@ -152,15 +118,16 @@ func TestRemoveEntityIndexes(t *testing.T) {
 
 	api_storage.RemoveEntityIndexes(entity)
 
-	keys := []string{
-		globals.ApiEntityIndexIdKey(entity),
-		globals.ApiEntityIndexAllFieldsKey(entity),
-		globals.ApiEntityIndexFieldSortAscKey(entity, "age"),
-		globals.ApiEntityIndexFieldSortDescKey(entity, "age"),
+	if v, _ := storage.GetByKey(globals.ApiEntityIndexAllFieldsKey(entity)); v != nil {
+		t.Fatalf("expected indexed fields list to be deleted, got %q", string(v))
 	}
-	for _, k := range keys {
-		if v, _ := storage.GetByKey(k); v != nil {
-			t.Fatalf("expected key %q to be deleted, got %q", k, string(v))
+
+	for _, name := range []string{
+		globals.ApiEntityIndexIdKey(entity),
+		globals.ApiEntityIndexFieldSortKey(entity, "age"),
+	} {
+		if _, ok := storage.GetIndex(name); ok {
+			t.Fatalf("expected index %q to be deleted", name)
 		}
 	}
 }
@@ -176,12 +143,7 @@ func TestIndexesCreatedOnWriteEntity(t *testing.T) {
 		t.Fatalf("expected index after WriteEntity")
 	}
 
-	ascKey := globals.ApiEntityIndexFieldSortAscKey(entity, "rank")
-	ascRaw, err := storage.GetByKey(ascKey)
-	if err != nil {
-		t.Fatalf("asc GetByKey: %v", err)
-	}
-	asc := decodeIDs(ascRaw)
+	asc := api_storage.GetListOfIds(entity, "rank", true)
 	if !reflect.DeepEqual(asc, []string{"p2", "p1"}) {
 		t.Fatalf("asc=%v", asc)
 	}
@@ -241,12 +203,19 @@ func TestUpdateIndexesForEntity_AddField(t *testing.T) {
 func TestUpdateIndexesForEntity_RemoveField(t *testing.T) {
 	initIdxTestStore(t)
 	entity := "idx_update_remove"
+	api_storage.WriteEntity(entity, map[string]any{"id": "1", "country": "FR", "age": 40})
+	api_storage.WriteEntity(entity, map[string]any{"id": "2", "country": "BE", "age": 30})
+
+	if asc := api_storage.GetListOfIds(entity, "country", true); !reflect.DeepEqual(asc, []string{"2", "1"}) {
+		t.Fatalf("asc=%v", asc)
+	}
+
 	oldData := map[string]interface{}{"id": "1", "country": "FR", "age": 40}
 	newData := map[string]interface{}{"id": "1", "age": 40}
 	api_storage.UpdateIndexesForEntity(entity, "1", oldData, newData)
 
-	if _, dirty := api_storage.DirtyFields.Load(entity + "|country"); !dirty {
-		t.Fatalf("expected index for 'country' to be marked dirty or removed")
+	if asc := api_storage.GetListOfIds(entity, "country", true); !reflect.DeepEqual(asc, []string{"1", "2"}) {
+		t.Fatalf("expected entity without country to sort first, asc=%v", asc)
 	}
 }
 
@@ -357,3 +326,98 @@ func TestProcessNextDirtyField(t *testing.T) {
 		t.Fatalf("expected index rebuilt for qty")
 	}
 }
+
+func TestIndexesUpdatedIncrementallyOnWrite(t *testing.T) {
+	initIdxTestStore(t)
+	entity := "idx_incremental"
+	api_storage.WriteEntity(entity, map[string]any{"id": "a", "score": 2})
+	api_storage.WriteEntity(entity, map[string]any{"id": "b", "score": 1})
+
+	if asc := api_storage.GetListOfIds(entity, "score", true); !reflect.DeepEqual(asc, []string{"b", "a"}) {
+		t.Fatalf("asc=%v", asc)
+	}
+
+	api_storage.WriteEntity(entity, map[string]any{"id": "c", "score": 0})
+	api_storage.UpdateEntityById(entity, "b", map[string]any{"score": 5})
+	api_storage.DeleteEntityById(entity, "a")
+
+	if _, dirty := api_storage.DirtyFields.Load(entity + "|score"); dirty {
+		t.Fatalf("writes should not mark the index dirty")
+	}
+
+	if asc := api_storage.GetListOfIds(entity, "score", true); !reflect.DeepEqual(asc, []string{"c", "b"}) {
+		t.Fatalf("asc=%v", asc)
+	}
+	if desc := api_storage.GetListOfIds(entity, "score", false); !reflect.DeepEqual(desc, []string{"b", "c"}) {
+		t.Fatalf("desc=%v", desc)
+	}
+}
+
+func TestIndexSortsByLinkedEntityField(t *testing.T) {
+	initIdxTestStore(t)
+	api_storage.WriteEntity("idx_author", map[string]any{"id": "a1", "name": "Zed"})
+	api_storage.WriteEntity("idx_author", map[string]any{"id": "a2", "name": "Amy"})
+	api_storage.WriteEntity("idx_book", map[string]any{"id": "b1", "author": map[string]any{"@entity": "idx_author", "id": "a1"}})
+	api_storage.WriteEntity("idx_book", map[string]any{"id": "b2", "author": map[string]any{"@entity": "idx_author", "id": "a2"}})
+
+	if asc := api_storage.GetListOfIds("idx_book", "author.name", true); !reflect.DeepEqual(asc, []string{"b2", "b1"}) {
+		t.Fatalf("asc=%v", asc)
+	}
+}
+
+func TestIndexSortsByLinkedEntityFieldAfterLinkedWrites(t *testing.T) {
+	initIdxTestStore(t)
+	api_storage.WriteEntity("idx_author", map[string]any{"id": "a1", "name": "Zed"})
+	api_storage.WriteEntity("idx_author", map[string]any{"id": "a2", "name": "Amy"})
+	api_storage.WriteEntity("idx_book", map[string]any{"id": "b1", "author": map[string]any{"@entity": "idx_author", "id": "a1"}})
+	api_storage.WriteEntity("idx_book", map[string]any{"id": "b2", "author": map[string]any{"@entity": "idx_author", "id": "a2"}})
+	api_storage.WriteEntity("idx_book", map[string]any{"id": "b3", "author": map[string]any{"@entity": "idx_author", "id": "a3"}})
+
+	if asc := api_storage.GetListOfIds("idx_book", "author.name", true); !reflect.DeepEqual(asc, []string{"b3", "b2", "b1"}) {
+		t.Fatalf("asc=%v", asc)
+	}
+
+	api_storage.WriteEntity("idx_author", map[string]any{"id": "a1", "name": "Abe"})
+	if asc := api_storage.GetListOfIds("idx_book", "author.name", true); !reflect.DeepEqual(asc, []string{"b3", "b1", "b2"}) {
+		t.Fatalf("after update asc=%v", asc)
+	}
+
+	api_storage.WriteEntity("idx_author", map[string]any{"id": "a3", "name": "Bob"})
+	if asc := api_storage.GetListOfIds("idx_book", "author.name", true); !reflect.DeepEqual(asc, []string{"b1", "b2", "b3"}) {
+		t.Fatalf("after create asc=%v", asc)
+	}
+
+	api_storage.DeleteEntityById("idx_author", "a2")
+	if asc := api_storage.GetListOfIds("idx_book", "author.name", true); !reflect.DeepEqual(asc, []string{"b2", "b1", "b3"}) {
+		t.Fatalf("after delete asc=%v", asc)
+	}
+}
+
+func TestReconcileIndexesAfterSnapshotReload(t *testing.T) {
+	initIdxTestStore(t)
+	entity := "idx_reconcile"
+	api_storage.WriteEntity(entity, map[string]any{"id": "a", "score": 1})
+	api_storage.WriteEntity(entity, map[string]any{"id": "b", "score": 2})
+	_ = api_storage.GetListOfIds(entity, "score", true)
+
+	storage.WriteJsonDB()
+
+	storage.PutJsonValue(globals.ApiSingleEntityKey(entity, "a"), map[string]any{"id": "a", "score": 3})
+	storage.PutJsonValue(globals.ApiSingleEntityKey(entity, "c"), map[string]any{"id": "c", "score": 0})
+	storage.DeleteJsonByKey(globals.ApiSingleEntityKey(entity, "b"))
+	storage.WriteJsonDB()
+	storage.LoadJsonDB()
+
+	if _, ok := storage.GetIndex(globals.ApiEntityIndexFieldSortKey(entity, "score")); !ok {
+		t.Fatalf("expected score index to be loaded from the snapshot")
+	}
+
+	api_storage.ReconcileIndexes()
+
+	if ids := api_storage.GetListOfIds(entity, "", true); !reflect.DeepEqual(ids, []string{"a", "c"}) {
+		t.Fatalf("ids=%v", ids)
+	}
+	if asc := api_storage.GetListOfIds(entity, "score", true); !reflect.DeepEqual(asc, []string{"c", "a"}) {
+		t.Fatalf("asc=%v", asc)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	api_storage "github.com/taymour/elysiandb/internal/api"
 	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/query"
 	"github.com/taymour/elysiandb/internal/storage"
 )
 
@@ -621,10 +622,7 @@ func TestGetListOfIds_NoSortField(t *testing.T) {
 	api_storage.WriteEntity(entity, map[string]interface{}{"id": "1"})
 	api_storage.WriteEntity(entity, map[string]interface{}{"id": "2"})
 
-	data, err := api_storage.GetListOfIds(entity, "", true)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
+	data := api_storage.GetListOfIds(entity, "", true)
 	if len(data) == 0 {
 		t.Fatalf("expected id list, got empty")
 	}
@@ -823,3 +821,25 @@ func TestWriteEntity_SkipSchemaUpdatesForManualStrict(t *testing.T) {
 		t.Fatalf("manual schema must exist")
 	}
 }
+
+func TestListEntitiesWithFilterNode_PaginatesAfterFiltering(t *testing.T) {
+	initTestStore(t)
+
+	entity := "orders"
+	api_storage.WriteEntity(entity, map[string]any{"id": "o1", "team": "blue", "total": 1.0})
+	api_storage.WriteEntity(entity, map[string]any{"id": "o2", "team": "red", "total": 2.0})
+	api_storage.WriteEntity(entity, map[string]any{"id": "o3", "team": "red", "total": 3.0})
+	api_storage.WriteEntity(entity, map[string]any{"id": "o4", "team": "red", "total": 4.0})
+
+	node := query.FilterNode{Leaf: map[string]map[string]string{"team": {"eq": "red"}}}
+
+	results := api_storage.ListEntitiesWithFilterNode(entity, 2, 1, "total", true, nil, "", "", node)
+	if len(results) != 2 || results[0]["id"] != "o3" || results[1]["id"] != "o4" {
+		t.Fatalf("unexpected page: %v", results)
+	}
+
+	all := api_storage.ListEntitiesWithFilterNode(entity, 0, 0, "", true, nil, "", "", query.FilterNode{})
+	if len(all) != 4 {
+		t.Fatalf("empty node should not restrict, got %d", len(all))
+	}
+}
@@ -0,0 +1,230 @@
+package audit_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/valyala/fasthttp"
+)
+
+func setup(t *testing.T, enabled bool) {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Security.Audit.Enabled = enabled
+	globals.SetConfig(cfg)
+
+	t.Cleanup(func() { _ = audit.Store.Close() })
+}
+
+func newCtx(username string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	if username != "" {
+		ctx.SetUserValue("username", username)
+		ctx.SetUserValue("role", "admin")
+	}
+
+	return ctx
+}
+
+func TestRecord_Disabled(t *testing.T) {
+	setup(t, false)
+
+	audit.Record(newCtx("alice"), audit.CategoryUser, "create", "bob", nil, nil)
+
+	if _, err := os.Stat(audit.Path()); !os.IsNotExist(err) {
+		t.Fatalf("audit file should not exist, got %v", err)
+	}
+}
+
+func TestRecord_AppendsEvents(t *testing.T) {
+	setup(t, true)
+
+	audit.Record(newCtx("alice"), audit.CategoryUser, "role_change", "bob",
+		map[string]any{"role": "user"},
+		map[string]any{"role": "admin"},
+	)
+	audit.RecordFailure(newCtx(""), audit.CategoryAuth, "login", "bob", "invalid credentials")
+
+	file, err := os.Open(audit.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var events []audit.Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e audit.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.Actor != "alice" || first.Role != "admin" || first.Outcome != audit.OutcomeSuccess || first.ID == "" || first.SourceIP == "" {
+		t.Fatalf("unexpected event %+v", first)
+	}
+	if first.Before.(map[string]any)["role"] != "user" || first.After.(map[string]any)["role"] != "admin" {
+		t.Fatalf("unexpected before/after %+v", first)
+	}
+
+	second := events[1]
+	if second.Actor != "anonymous" || second.Outcome != audit.OutcomeFailure || second.Reason != "invalid credentials" {
+		t.Fatalf("unexpected event %+v", second)
+	}
+}
+
+func TestRecord_TokenActor(t *testing.T) {
+	setup(t, true)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "token"
+
+	audit.Record(newCtx(""), audit.CategoryDestructive, "reset", "", nil, nil)
+
+	events, err := audit.Query(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Actor != "token" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+}
+
+func TestQuery_Filters(t *testing.T) {
+	setup(t, true)
+
+	audit.Record(newCtx("alice"), audit.CategoryUser, "create", "bob", nil, nil)
+	audit.Record(newCtx("alice"), audit.CategoryACL, "update", "articles/user:bob", nil, nil)
+	audit.Record(newCtx("carol"), audit.CategoryACL, "update", "books/role:editor", nil, nil)
+	audit.RecordFailure(newCtx(""), audit.CategoryAuth, "login", "alice", "invalid credentials")
+
+	cases := []struct {
+		name   string
+		filter audit.Filter
+		want   int
+	}{
+		{"all", audit.Filter{}, 4},
+		{"actor", audit.Filter{Actor: "alice"}, 2},
+		{"category", audit.Filter{Category: audit.CategoryACL}, 2},
+		{"action", audit.Filter{Action: "login"}, 1},
+		{"target glob", audit.Filter{Target: "articles/*"}, 1},
+		{"outcome", audit.Filter{Outcome: audit.OutcomeFailure}, 1},
+		{"from future", audit.Filter{From: time.Now().Add(time.Hour)}, 0},
+		{"to past", audit.Filter{To: time.Now().Add(-time.Hour)}, 0},
+		{"limit", audit.Filter{Limit: 3}, 3},
+		{"offset", audit.Filter{Offset: 3}, 1},
+		{"offset beyond", audit.Filter{Offset: 10}, 0},
+	}
+
+	for _, c := range cases {
+		events, err := audit.Query(c.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != c.want {
+			t.Fatalf("%s: expected %d events, got %d", c.name, c.want, len(events))
+		}
+	}
+
+	events, _ := audit.Query(audit.Filter{Limit: 1})
+	if events[0].Category != audit.CategoryAuth {
+		t.Fatalf("expected newest event first, got %+v", events[0])
+	}
+}
+
+func TestQuery_NoFile(t *testing.T) {
+	setup(t, true)
+
+	events, err := audit.Query(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events, got %d", len(events))
+	}
+}
+
+func TestExport_NDJSON(t *testing.T) {
+	setup(t, true)
+
+	audit.Record(newCtx("alice"), audit.CategoryHook, "create", "articles", nil, nil)
+	audit.Record(newCtx("alice"), audit.CategoryHook, "delete", "h1", nil, nil)
+	audit.Record(newCtx("bob"), audit.CategorySchema, "update", "articles", nil, nil)
+
+	var buf bytes.Buffer
+	if err := audit.Export(&buf, audit.Filter{Category: audit.CategoryHook}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var e audit.Event
+	if err := json.Unmarshal(lines[0], &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Action != "create" {
+		t.Fatalf("expected chronological export, got %+v", e)
+	}
+}
+
+func TestStore_FollowsStoreFolder(t *testing.T) {
+	setup(t, true)
+	audit.Record(newCtx("alice"), audit.CategoryUser, "create", "bob", nil, nil)
+
+	setup(t, true)
+	events, _ := audit.Query(audit.Filter{})
+	if len(events) != 0 {
+		t.Fatalf("expected a fresh log, got %d events", len(events))
+	}
+
+	audit.Record(newCtx("alice"), audit.CategoryUser, "create", "carol", nil, nil)
+	events, _ = audit.Query(audit.Filter{})
+	if len(events) != 1 || events[0].Target != "carol" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+}
+
+func TestStore_EphemeralKeepsEventsInMemory(t *testing.T) {
+	setup(t, true)
+	globals.GetConfig().Store.Persistence = "none"
+
+	audit.Record(newCtx("alice"), audit.CategoryUser, "create", "bob", nil, nil)
+	audit.RecordFailure(newCtx(""), audit.CategoryAuth, "login", "carol", "invalid credentials")
+
+	if _, err := os.Stat(audit.Path()); !os.IsNotExist(err) {
+		t.Fatalf("audit file should not exist in ephemeral mode, got %v", err)
+	}
+
+	events, err := audit.Query(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Target != "carol" || events[1].Target != "bob" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	var buf bytes.Buffer
+	if err := audit.Export(&buf, audit.Filter{Outcome: audit.OutcomeFailure}); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Count(buf.Bytes(), []byte{'\n'}) != 1 {
+		t.Fatalf("unexpected export %q", buf.String())
+	}
+}
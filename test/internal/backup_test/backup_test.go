@@ -0,0 +1,404 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/router"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/backup"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/routing"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func setup(t *testing.T) string {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	return cfg.Store.Folder
+}
+
+func seed(t *testing.T, folder string) {
+	t.Helper()
+
+	_ = storage.PutKeyValue("plain", []byte("value"))
+	_ = storage.PutKeyValueWithTTL("session:1", []byte("ttl"), 3600)
+
+	for _, title := range []string{"b", "c", "a"} {
+		if errs := api_storage.WriteEntity("articles", map[string]any{"id": title, "title": title}); len(errs) > 0 {
+			t.Fatalf("write failed: %v", errs)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(folder, security.KeyFilename), []byte("secret-key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func titles(entity string) []string {
+	var out []string
+	for _, item := range api_storage.ListEntities(entity, 0, 0, "title", true, nil, "", "") {
+		out = append(out, item["title"].(string))
+	}
+
+	return out
+}
+
+func readEntries(t *testing.T, archive []byte) map[string][]byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := io.ReadAll(tr)
+		entries[h.Name] = data
+	}
+}
+
+func writeEntries(t *testing.T, entries map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write(data)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+
+	return buf.Bytes()
+}
+
+func TestBackupAndRestoreRoundTrip(t *testing.T) {
+	folder := setup(t)
+	seed(t, folder)
+
+	var buf bytes.Buffer
+	manifest, err := backup.Write(&buf)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	if manifest.Engine != "internal" || manifest.Format != backup.FormatVersion {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	names := map[string]bool{}
+	for _, f := range manifest.Files {
+		names[f.Name] = true
+	}
+	for _, name := range []string{storage.DataFile, storage.ExpirationDataFile, storage.JsonDataFile, security.KeyFilename} {
+		if !names[name] {
+			t.Fatalf("manifest misses %s: %+v", name, manifest.Files)
+		}
+	}
+
+	storage.DeleteByKey("plain")
+	_ = storage.PutKeyValue("added-later", []byte("x"))
+	api_storage.DeleteEntityById("articles", "a")
+	_ = os.WriteFile(filepath.Join(folder, security.KeyFilename), []byte("other-key"), 0o600)
+
+	archive, err := backup.Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if err := backup.Restore(archive); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	if v, err := storage.GetByKey("plain"); err != nil || string(v) != "value" {
+		t.Fatalf("plain key not restored: %q %v", v, err)
+	}
+
+	if _, err := storage.GetByKey("added-later"); err == nil {
+		t.Fatal("keys written after the backup should be gone")
+	}
+
+	if storage.KeyHasExpired("session:1") {
+		t.Fatal("ttl key should still be alive")
+	}
+
+	if got := strings.Join(titles("articles"), ","); got != "a,b,c" {
+		t.Fatalf("expected restored sorted articles a,b,c, got %s", got)
+	}
+
+	if key, _ := os.ReadFile(filepath.Join(folder, security.KeyFilename)); string(key) != "secret-key" {
+		t.Fatalf("users key not restored: %q", key)
+	}
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	if v, err := storage.GetByKey("plain"); err != nil || string(v) != "value" {
+		t.Fatalf("restore was not persisted to disk: %q %v", v, err)
+	}
+
+	if len(titles("articles")) != 3 {
+		t.Fatal("restored documents were not persisted to disk")
+	}
+}
+
+func TestSnapshotDetachesFromWriteStream(t *testing.T) {
+	setup(t)
+	seed(t, globals.GetConfig().Store.Folder)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = storage.PutKeyValue("concurrent", []byte{byte(i)})
+				_ = storage.PutJsonValue("concurrent", map[string]any{"i": i})
+			}
+		}
+	}()
+
+	snapshot := storage.TakeSnapshot()
+	close(stop)
+	<-done
+
+	if recovery.Streaming() {
+		t.Fatal("snapshot should stop listening to writes once taken")
+	}
+
+	if string(snapshot.KeyValues["plain"]) != "value" {
+		t.Fatalf("snapshot misses plain key: %v", snapshot.KeyValues)
+	}
+
+	if snapshot.Expirations["session:1"] <= time.Now().Unix() {
+		t.Fatalf("snapshot misses ttl: %v", snapshot.Expirations)
+	}
+
+	if _, ok := snapshot.Json[globals.ApiSingleEntityKey("articles", "a")]; !ok {
+		t.Fatal("snapshot misses documents")
+	}
+}
+
+func TestReadRejectsCorruptedArchives(t *testing.T) {
+	setup(t)
+	seed(t, globals.GetConfig().Store.Folder)
+
+	var buf bytes.Buffer
+	if _, err := backup.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backup.Read(strings.NewReader("not an archive")); !errors.Is(err, backup.ErrInvalidArchive) {
+		t.Fatalf("expected invalid archive, got %v", err)
+	}
+
+	entries := readEntries(t, buf.Bytes())
+	entries[storage.DataFile] = []byte(`{"plain":"dGFtcGVyZWQ="}`)
+	if _, err := backup.Read(bytes.NewReader(writeEntries(t, entries))); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+
+	entries = readEntries(t, buf.Bytes())
+	entries["extra.json"] = []byte("{}")
+	if _, err := backup.Read(bytes.NewReader(writeEntries(t, entries))); !errors.Is(err, backup.ErrInvalidArchive) {
+		t.Fatalf("expected unlisted entry to be rejected, got %v", err)
+	}
+
+	entries = readEntries(t, buf.Bytes())
+	delete(entries, backup.ManifestFile)
+	if _, err := backup.Read(bytes.NewReader(writeEntries(t, entries))); !errors.Is(err, backup.ErrInvalidArchive) {
+		t.Fatalf("expected missing manifest to be rejected, got %v", err)
+	}
+}
+
+func TestRestoreRejectsArchiveFromAnotherEngine(t *testing.T) {
+	setup(t)
+	seed(t, globals.GetConfig().Store.Folder)
+
+	var buf bytes.Buffer
+	if _, err := backup.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, buf.Bytes())
+	var manifest backup.Manifest
+	_ = json.Unmarshal(entries[backup.ManifestFile], &manifest)
+	manifest.Engine = "mongodb"
+	entries[backup.ManifestFile], _ = json.Marshal(manifest)
+
+	archive, err := backup.Read(bytes.NewReader(writeEntries(t, entries)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backup.Restore(archive); err == nil || !strings.Contains(err.Error(), "mongodb engine") {
+		t.Fatalf("expected engine mismatch, got %v", err)
+	}
+
+	if v, _ := storage.GetByKey("plain"); string(v) != "value" {
+		t.Fatal("a rejected restore must not touch the data")
+	}
+}
+
+func TestBackupEndpointStreamsArchive(t *testing.T) {
+	setup(t)
+	seed(t, globals.GetConfig().Store.Folder)
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+	srv := &fasthttp.Server{Handler: r.Handler}
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		_ = srv.Shutdown()
+	})
+
+	client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return ln.Dial() }}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("http://test/api/admin/backup")
+	req.Header.SetMethod(fasthttp.MethodPost)
+
+	if err := client.Do(req, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode(), resp.Body())
+	}
+
+	if !strings.Contains(string(resp.Header.Peek("Content-Disposition")), "elysiandb-backup-") {
+		t.Fatalf("missing attachment name: %s", resp.Header.Peek("Content-Disposition"))
+	}
+
+	archive, err := backup.Read(bytes.NewReader(resp.Body()))
+	if err != nil {
+		t.Fatalf("downloaded archive is invalid: %v", err)
+	}
+
+	if archive.Manifest.Engine != "internal" {
+		t.Fatalf("unexpected manifest: %+v", archive.Manifest)
+	}
+}
+
+func TestRestoreToPointReplaysArchivedWal(t *testing.T) {
+	folder := t.TempDir()
+	walFolder := filepath.Join(folder, "wal")
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = folder
+	cfg.Store.Shards = 4
+	cfg.Store.CrashRecovery = configuration.CrashRecoveryConfig{
+		Enabled:  true,
+		MaxLogMB: 100,
+		Archive:  configuration.WalArchiveConfig{Enabled: true, Folder: walFolder},
+	}
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+	recovery.InitSequence()
+	recovery.ActivateJsonRecoveryLog(storage.WriteJsonDB)
+	recovery.ActivateStoreRecoveryLog(storage.WriteStoreDB)
+
+	seed(t, folder)
+	storage.WriteToDB()
+
+	var buf bytes.Buffer
+	manifest, err := backup.Write(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !manifest.WalArchive {
+		t.Fatal("manifest should record that wal archiving was enabled")
+	}
+
+	_ = storage.PutKeyValue("step", []byte("before-import"))
+	api_storage.WriteEntity("articles", map[string]any{"id": "d", "title": "d"})
+	goodSeq := recovery.Sequence()
+	time.Sleep(5 * time.Millisecond)
+	goodTime := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	_ = storage.PutKeyValue("step", []byte("bad-import"))
+	api_storage.DeleteEntityById("articles", "a")
+	storage.WriteToDB()
+
+	archive, err := backup.Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(target recovery.Target, step, articles string) {
+		t.Helper()
+
+		result, err := backup.RestoreToPoint(archive, walFolder, target)
+		if err != nil {
+			t.Fatalf("restore to %+v failed: %v", target, err)
+		}
+		if len(result.Gaps) > 0 {
+			t.Fatalf("unexpected wal gaps: %+v", result.Gaps)
+		}
+
+		if v, _ := storage.GetByKey("step"); string(v) != step {
+			t.Fatalf("restore to %+v: expected step %q, got %q", target, step, v)
+		}
+		if got := strings.Join(titles("articles"), ","); got != articles {
+			t.Fatalf("restore to %+v: expected articles %s, got %s", target, articles, got)
+		}
+	}
+
+	check(recovery.Target{Seq: goodSeq}, "before-import", "a,b,c,d")
+	check(recovery.Target{Time: goodTime}, "before-import", "a,b,c,d")
+	check(recovery.Target{}, "bad-import", "b,c,d")
+
+	if _, err := backup.RestoreToPoint(archive, walFolder, recovery.Target{Seq: manifest.WalEnd - 1}); err == nil {
+		t.Fatal("a target before the base backup should be rejected")
+	}
+}
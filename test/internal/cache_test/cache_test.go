@@ -1,10 +1,14 @@
 package cache_test
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/metrics"
 )
 
 func TestCacheSetGet(t *testing.T) {
@@ -140,3 +144,69 @@ func TestCacheExpirationById(t *testing.T) {
 		t.Fatalf("expected nil after expiration, got %s", got)
 	}
 }
+
+func TestCacheHitRatioMetric(t *testing.T) {
+	metrics.SetEnabled(true)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+
+	cache.InitCache(10 * time.Second)
+	hash := cache.HashQuery("ratio_books", 10, 0, "", true, nil, "", "", "", false, "")
+
+	cache.CacheStore.Get("ratio_books", hash)
+	cache.CacheStore.Set("ratio_books", hash, []byte("[]"))
+	cache.CacheStore.Get("ratio_books", hash)
+	cache.CacheStore.Get("ratio_books", hash)
+	cache.CacheStore.GetById("ratio_books", "missing")
+
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`elysiandb_cache_hits_total{entity="ratio_books"} 2`,
+		`elysiandb_cache_misses_total{entity="ratio_books"} 2`,
+		`elysiandb_cache_hit_ratio{entity="ratio_books"} 0.5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCacheMissesOnUnknownEntitiesShareOneLabel(t *testing.T) {
+	metrics.SetEnabled(true)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+
+	cache.InitCache(10 * time.Second)
+	cache.EntityExistsFunc = func(entity string) bool { return entity == "label_books" }
+	t.Cleanup(func() { cache.EntityExistsFunc = nil })
+
+	for i := range 3 {
+		entity := fmt.Sprintf("random_%d", i)
+		cache.CacheStore.Get(entity, cache.HashQuery(entity, 10, 0, "", true, nil, "", "", "", false, ""))
+		cache.CacheStore.GetById(entity, "x")
+	}
+
+	cache.CacheStore.GetById("label_books", "b1")
+
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, `entity="random_`) {
+		t.Fatalf("unknown entity exported as a label:\n%s", out)
+	}
+
+	for _, want := range []string{
+		`elysiandb_cache_misses_total{entity="unknown"} 6`,
+		`elysiandb_cache_misses_total{entity="label_books"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
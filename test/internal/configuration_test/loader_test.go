@@ -109,6 +109,280 @@ func TestLoadConfig_InvalidYAML_FatalExit(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_JWTRequiresKeyMaterial(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "jwt.yaml")
+
+	yaml := []byte(`
+security:
+  authentication:
+    enabled: true
+    mode: jwt
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	if _, err := cfgpkg.LoadConfig(path); err == nil {
+		t.Fatalf("expected error when jwt mode has no secret or JWKS")
+	}
+
+	yaml = []byte(`
+security:
+  authentication:
+    enabled: true
+    mode: jwt
+    jwt:
+      secret: "s3cret"
+      usernameClaim: email
+      adminRoles: [ops]
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	cfg, err := cfgpkg.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	jwt := cfg.Security.Authentication.JWT
+	if jwt.Secret != "s3cret" || jwt.UsernameClaim != "email" || len(jwt.AdminRoles) != 1 || jwt.AdminRoles[0] != "ops" {
+		t.Errorf("JWT config parsed wrong: %+v", jwt)
+	}
+}
+
+func TestLoadConfig_CORS(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "cors.yaml")
+
+	yaml := []byte(`
+server:
+  http:
+    cors:
+      allowedOrigins: ["https://app.example.com", "https://*.example.org"]
+      exposedHeaders: [X-Elysian-Cache]
+      allowCredentials: true
+      maxAgeSeconds: 600
+      routes:
+        - path: /admin
+          allowedOrigins: []
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	cfg, err := cfgpkg.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	cors := cfg.Server.HTTP.CORS
+	if len(cors.AllowedOrigins) != 2 || !cors.AllowCredentials || cors.MaxAgeSeconds != 600 {
+		t.Errorf("CORS config parsed wrong: %+v", cors)
+	}
+	if len(cors.Routes) != 1 || cors.Routes[0].Path != "/admin" || len(cors.Routes[0].AllowedOrigins) != 0 {
+		t.Errorf("CORS routes parsed wrong: %+v", cors.Routes)
+	}
+
+	yaml = []byte(`
+server:
+  http:
+    cors:
+      allowedOrigins: ["*"]
+      allowCredentials: true
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	if _, err := cfgpkg.LoadConfig(path); err == nil {
+		t.Fatalf("expected error when wildcard origin is combined with credentials")
+	}
+}
+
+func TestLoadConfig_Log(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "log.yaml")
+
+	yaml := []byte(`
+log:
+  flushIntervalSeconds: 2
+  level: warn
+  format: json
+  accessLog: true
+  file:
+    enabled: true
+    path: /var/log/elysiandb.log
+    maxSizeMB: 10
+    maxBackups: 3
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	cfg, err := cfgpkg.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	l := cfg.Log
+	if l.Level != "warn" || l.Format != "json" || !l.AccessLog {
+		t.Errorf("log config parsed wrong: %+v", l)
+	}
+	if !l.File.Enabled || l.File.Path != "/var/log/elysiandb.log" || l.File.MaxSizeMB != 10 || l.File.MaxBackups != 3 {
+		t.Errorf("log file config parsed wrong: %+v", l.File)
+	}
+
+	for _, invalid := range []string{
+		"log:\n  level: loud\n",
+		"log:\n  format: xml\n",
+		"log:\n  file:\n    enabled: true\n",
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatalf("write yaml: %v", err)
+		}
+
+		if _, err := cfgpkg.LoadConfig(path); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_Tracing(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "tracing.yaml")
+
+	yaml := []byte(`
+tracing:
+  enabled: true
+  serviceName: elysiandb-test
+  endpoint: http://localhost:4318
+  headers:
+    x-api-key: secret
+  sampleRatio: 0.25
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	cfg, err := cfgpkg.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	tr := cfg.Tracing
+	if !tr.Enabled || tr.ServiceName != "elysiandb-test" || tr.Endpoint != "http://localhost:4318" || tr.Headers["x-api-key"] != "secret" {
+		t.Errorf("tracing config parsed wrong: %+v", tr)
+	}
+	if tr.SampleRatio == nil || *tr.SampleRatio != 0.25 {
+		t.Errorf("sampleRatio parsed wrong: %v", tr.SampleRatio)
+	}
+
+	for _, invalid := range []string{
+		"tracing:\n  sampleRatio: 1.5\n",
+		"tracing:\n  endpoint: localhost:4318\n",
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatalf("write yaml: %v", err)
+		}
+
+		if _, err := cfgpkg.LoadConfig(path); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_Replication(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "replication.yaml")
+
+	yaml := []byte(`
+replication:
+  role: follower
+  leader: http://10.0.0.1:8089
+  token: shared-secret
+  backlogSize: 5000
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	cfg, err := cfgpkg.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	r := cfg.Replication
+	if r.Role != "follower" || r.Leader != "http://10.0.0.1:8089" || r.Token != "shared-secret" || r.BacklogSize != 5000 {
+		t.Errorf("replication config parsed wrong: %+v", r)
+	}
+
+	for _, invalid := range []string{
+		"replication:\n  role: primary\n  token: x\n",
+		"replication:\n  role: leader\n",
+		"replication:\n  role: follower\n  token: x\n",
+		"replication:\n  role: follower\n  token: x\n  leader: 10.0.0.1:8089\n",
+		"engine:\n  name: sqlite\nreplication:\n  role: leader\n  token: x\n",
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatalf("write yaml: %v", err)
+		}
+
+		if _, err := cfgpkg.LoadConfig(path); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_Persistence(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "persistence.yaml")
+
+	yaml := []byte(`
+store:
+  persistence: none
+  crashRecovery: { enabled: true, maxLogMB: 100 }
+log:
+  file: { enabled: true, path: /tmp/elysiandb.log }
+seed:
+  files: [./fixtures/base.json, ./fixtures/articles.ndjson]
+`)
+	if err := os.WriteFile(path, yaml, 0o644); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	cfg, err := cfgpkg.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	if !cfg.Store.IsEphemeral() || cfg.Store.CrashRecovery.Enabled {
+		t.Errorf("expected an ephemeral store without crash recovery: %+v", cfg.Store)
+	}
+	if cfg.Log.File.Enabled {
+		t.Errorf("expected the log file to be disabled: %+v", cfg.Log.File)
+	}
+	if len(cfg.Seed.Files) != 2 {
+		t.Errorf("seed files parsed wrong: %+v", cfg.Seed)
+	}
+
+	for _, invalid := range []string{
+		"store:\n  persistence: memory\n",
+		"store:\n  persistence: none\nengine:\n  name: sqlite\n",
+		"store:\n  persistence: none\n  crashRecovery:\n    enabled: true\n    archive: { enabled: true, folder: /tmp/wal }\n",
+		"seed:\n  files: [a.json]\nreplication:\n  role: follower\n  token: x\n  leader: http://10.0.0.1:8089\n",
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatalf("write yaml: %v", err)
+		}
+
+		if _, err := cfgpkg.LoadConfig(path); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
 func TestConfigHelper(t *testing.T) {
 	mode := os.Getenv("TEST_CFG_MODE")
 	if mode == "" {
@@ -0,0 +1,84 @@
+package engine_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/engine/conformance"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+func newConfig(t *testing.T, name string) *configuration.Config {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Api.Schema.Enabled = true
+	cfg.Engine.Name = name
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+	cache.InitCache(30)
+
+	return cfg
+}
+
+func TestConformance_Internal(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) engine.Engine {
+		newConfig(t, engine.EngineInternal)
+
+		e, ok := engine.Get(engine.EngineInternal)
+		if !ok {
+			t.Fatal("internal engine not registered")
+		}
+
+		return e
+	})
+}
+
+func TestConformance_SQLite(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) engine.Engine {
+		newConfig(t, engine.EngineSQLite)
+
+		e, _ := engine.Get(engine.EngineSQLite)
+		if err := e.Open(); err != nil {
+			t.Fatalf("open: %v", err)
+		}
+
+		t.Cleanup(func() { _ = e.Close() })
+
+		return e
+	})
+}
+
+func TestConformance_MongoDB(t *testing.T) {
+	uri := os.Getenv("ELYSIANDB_TEST_MONGODB_URI")
+	if uri == "" {
+		t.Skip("ELYSIANDB_TEST_MONGODB_URI not set")
+	}
+
+	conformance.Run(t, func(t *testing.T) engine.Engine {
+		cfg := newConfig(t, engine.EngineMongoDB)
+		cfg.Engine.URI = uri
+
+		e, _ := engine.Get(engine.EngineMongoDB)
+		if err := e.Open(); err != nil {
+			t.Fatalf("open: %v", err)
+		}
+
+		for _, entity := range e.ListEntityTypes() {
+			_ = e.DeleteEntityType(entity)
+		}
+		e.DeleteAll()
+
+		t.Cleanup(func() { _ = e.Close() })
+
+		return e
+	})
+}
@@ -0,0 +1,94 @@
+package engine_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/engine"
+)
+
+type fakeEngine struct {
+	engine.Engine
+	name string
+}
+
+func (f fakeEngine) Name() string {
+	return f.name
+}
+
+func (f fakeEngine) Capabilities() engine.Capabilities {
+	return engine.Capabilities{ExternalSchema: true}
+}
+
+func (f fakeEngine) GetEntitySchema(entity string) map[string]any {
+	return map[string]any{"id": entity}
+}
+
+func TestRegistry_BuiltinEngines(t *testing.T) {
+	names := engine.Names()
+	for _, want := range []string{engine.EngineInternal, engine.EngineMongoDB} {
+		if !slices.Contains(names, want) {
+			t.Fatalf("engine %q not registered, got %v", want, names)
+		}
+	}
+
+	if caps := mustGet(t, engine.EngineInternal).Capabilities(); !caps.Migrations || caps.ExternalSchema {
+		t.Fatalf("unexpected internal capabilities %+v", caps)
+	}
+
+	if caps := mustGet(t, engine.EngineMongoDB).Capabilities(); caps.Migrations || !caps.ExternalSchema || !caps.ExternalStorage {
+		t.Fatalf("unexpected mongodb capabilities %+v", caps)
+	}
+}
+
+func TestRegistry_RegisterAndSelect(t *testing.T) {
+	engine.Register(fakeEngine{name: "fake"})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering the same name twice should panic")
+		}
+	}()
+
+	cfg := newConfig(t, "fake")
+	if engine.Current().Name() != "fake" {
+		t.Fatalf("current engine = %q", engine.Current().Name())
+	}
+
+	if got := engine.SchemaData("book"); got["id"] != "book" {
+		t.Fatalf("schema data should come from the engine, got %v", got)
+	}
+
+	cfg.Engine.Name = engine.EngineInternal
+	if engine.SchemaData("book") != nil {
+		t.Fatal("internal engine schema data should be resolved by the schema package")
+	}
+
+	engine.Register(fakeEngine{name: "fake"})
+}
+
+func TestCurrent_UnknownEnginePanics(t *testing.T) {
+	newConfig(t, "unknown")
+
+	defer func() {
+		r := recover()
+		msg, _ := r.(string)
+		if !strings.Contains(msg, "unknown") || !strings.Contains(msg, engine.EngineInternal) {
+			t.Fatalf("unexpected panic %v", r)
+		}
+	}()
+
+	engine.Current()
+}
+
+func mustGet(t *testing.T, name string) engine.Engine {
+	t.Helper()
+
+	e, ok := engine.Get(name)
+	if !ok {
+		t.Fatalf("engine %q not registered", name)
+	}
+
+	return e
+}
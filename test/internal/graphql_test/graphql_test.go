@@ -0,0 +1,266 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/graphql"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+func setup(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Api.Schema.Enabled = true
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	cache.InitCache(30)
+	api_storage.DeleteAll()
+}
+
+func seedLibrary() {
+	engine.WriteEntity("author", map[string]any{"id": "a1", "name": "Frank"})
+	engine.WriteEntity("author", map[string]any{"id": "a2", "name": "Ursula"})
+
+	for _, b := range []struct{ id, title, author string }{
+		{"b1", "Dune", "a1"},
+		{"b2", "Children of Dune", "a1"},
+		{"b3", "Earthsea", "a2"},
+	} {
+		engine.WriteEntity("book", map[string]any{
+			"id":     b.id,
+			"title":  b.title,
+			"author": map[string]any{"@entity": "author", "id": b.author},
+		})
+	}
+}
+
+var principal = &security.Principal{}
+
+func run(t *testing.T, query string, variables map[string]any) (map[string]any, []string) {
+	t.Helper()
+
+	ctx := security.WithPrincipal(context.Background(), principal)
+	result := graphql.Execute(ctx, graphql.Request{Query: query, Variables: variables})
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var out struct {
+		Data   map[string]any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	messages := []string{}
+	for _, e := range out.Errors {
+		messages = append(messages, e.Message)
+	}
+
+	return out.Data, messages
+}
+
+func mustRun(t *testing.T, query string, variables map[string]any) map[string]any {
+	t.Helper()
+
+	data, errs := run(t, query, variables)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	return data
+}
+
+func TestQuery_ListWithFilterSortAndPagination(t *testing.T) {
+	setup(t)
+	seedLibrary()
+
+	data := mustRun(t, `{
+		book(filter: [{field: "title", value: "*Dune*"}], sort: {field: "title", direction: desc}, limit: 1) { id title }
+		bookCount
+	}`, nil)
+
+	books := data["book"].([]any)
+	if len(books) != 1 || books[0].(map[string]any)["title"] != "Dune" {
+		t.Fatalf("unexpected books %v", books)
+	}
+
+	if data["bookCount"] != float64(3) {
+		t.Fatalf("unexpected count %v", data["bookCount"])
+	}
+
+	data = mustRun(t, `{ book(sort: {field: "title"}, offset: 1, limit: 1) { title } }`, nil)
+	if data["book"].([]any)[0].(map[string]any)["title"] != "Dune" {
+		t.Fatalf("unexpected page %v", data["book"])
+	}
+}
+
+func TestQuery_ForwardAndReverseLinks(t *testing.T) {
+	setup(t)
+	seedLibrary()
+
+	data := mustRun(t, `{
+		bookById(id: "b3") { title author { name } }
+		authorById(id: "a1") { name bookByAuthor(sort: {field: "title"}) { title } }
+	}`, nil)
+
+	book := data["bookById"].(map[string]any)
+	if book["author"].(map[string]any)["name"] != "Ursula" {
+		t.Fatalf("unexpected forward link %v", book)
+	}
+
+	reverse := data["authorById"].(map[string]any)["bookByAuthor"].([]any)
+	if len(reverse) != 2 || reverse[0].(map[string]any)["title"] != "Children of Dune" {
+		t.Fatalf("unexpected reverse link %v", reverse)
+	}
+}
+
+func TestQuery_LinksAreBatched(t *testing.T) {
+	setup(t)
+	seedLibrary()
+
+	original := graphql.ReadEntitiesByIds
+	t.Cleanup(func() { graphql.ReadEntitiesByIds = original })
+
+	calls := 0
+	graphql.ReadEntitiesByIds = func(entity string, ids []string) map[string]map[string]any {
+		calls++
+		return original(entity, ids)
+	}
+
+	data := mustRun(t, `{ book { title author { name bookByAuthor { id } } } }`, nil)
+
+	books := data["book"].([]any)
+	if len(books) != 3 {
+		t.Fatalf("unexpected books %v", books)
+	}
+	for _, b := range books {
+		if b.(map[string]any)["author"].(map[string]any)["name"] == nil {
+			t.Fatalf("author not resolved %v", b)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected a single batched read, got %d", calls)
+	}
+}
+
+func TestMutation_CreateUpdateDelete(t *testing.T) {
+	setup(t)
+	seedLibrary()
+
+	data := mustRun(t, `mutation { createBook(data: {title: "Tehanu", author: {id: "a2"}}) { id title author { name } } }`, nil)
+
+	created := data["createBook"].(map[string]any)
+	id := created["id"].(string)
+	if created["author"].(map[string]any)["name"] != "Ursula" {
+		t.Fatalf("link not resolved on create %v", created)
+	}
+
+	data = mustRun(t, `mutation($id: ID!) { updateBook(id: $id, data: {title: "Tehanu (2nd)"}) { title } }`, map[string]any{"id": id})
+	if data["updateBook"].(map[string]any)["title"] != "Tehanu (2nd)" {
+		t.Fatalf("unexpected update %v", data)
+	}
+
+	data = mustRun(t, `mutation($id: ID!) { deleteBook(id: $id) }`, map[string]any{"id": id})
+	if data["deleteBook"] != true {
+		t.Fatalf("unexpected delete %v", data)
+	}
+
+	if engine.ReadEntityById("book", id) != nil {
+		t.Fatal("book should be deleted")
+	}
+
+	_, errs := run(t, `mutation { deleteBook(id: "missing") }`, nil)
+	if len(errs) != 1 || errs[0] != "entity not found" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func TestSchema_RegeneratedWhenSchemaChanges(t *testing.T) {
+	setup(t)
+	seedLibrary()
+
+	_, errs := run(t, `{ book { isbn } }`, nil)
+	if len(errs) == 0 {
+		t.Fatal("isbn should not be queryable yet")
+	}
+
+	engine.WriteEntity("book", map[string]any{"id": "b4", "title": "Dune Messiah", "isbn": "123"})
+
+	data := mustRun(t, `{ bookById(id: "b4") { isbn } }`, nil)
+	if data["bookById"].(map[string]any)["isbn"] != "123" {
+		t.Fatalf("unexpected data %v", data)
+	}
+}
+
+func TestACL_FieldRulesApplied(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "user"
+	principal = &security.Principal{Username: "u", Role: security.RoleUser}
+	t.Cleanup(func() {
+		principal = &security.Principal{}
+	})
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionCreate] = true
+	perms[acl.PermissionRead] = true
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+		Username:    "u",
+		Entity:      "employee",
+		Permissions: perms,
+		Fields:      map[string]acl.FieldRule{"salary": acl.FieldRuleHidden},
+	}).ToDataMap())
+
+	api_storage.WriteEntity("employee", map[string]any{"id": "e1", "name": "a", "salary": 100.0})
+
+	data := mustRun(t, `{ employee { name salary } employeeById(id: "e1") { salary } }`, nil)
+
+	list := data["employee"].([]any)
+	if len(list) != 1 || list[0].(map[string]any)["salary"] != nil {
+		t.Fatalf("hidden field leaked in list %v", list)
+	}
+	if data["employeeById"].(map[string]any)["salary"] != nil {
+		t.Fatalf("hidden field leaked in get by id %v", data)
+	}
+
+	for _, q := range []string{
+		`{ employee(filter: [{field: "salary", op: gt, value: "50"}]) { name } }`,
+		`{ employee(sort: {field: "salary"}) { name } }`,
+		`{ employeeCount(filter: [{field: "salary", value: "100"}]) }`,
+	} {
+		if _, errs := run(t, q, nil); len(errs) != 1 || !strings.Contains(errs[0], "forbidden fields: salary") {
+			t.Fatalf("%s: unexpected errors %v", q, errs)
+		}
+	}
+
+	data = mustRun(t, `{ hidden: employee(search: "*100*") { name } visible: employee(search: "a") { name } }`, nil)
+	if len(data["hidden"].([]any)) != 0 || len(data["visible"].([]any)) != 1 {
+		t.Fatalf("search should only match visible fields %v", data)
+	}
+
+	_, errs := run(t, `mutation { createEmployee(data: {name: "b", salary: 5}) { id } }`, nil)
+	if len(errs) != 1 || !strings.Contains(errs[0], "forbidden fields: salary") {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
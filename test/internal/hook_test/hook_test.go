@@ -1,6 +1,7 @@
 package hook_test
 
 import (
+	"context"
 	"testing"
 
 	api_storage "github.com/taymour/elysiandb/internal/api"
@@ -165,7 +166,7 @@ func TestApplyPostReadScriptMissingPostReadIsNoop(t *testing.T) {
 
 	entity := map[string]any{"id": "1", "x": 0}
 	script := `function other(ctx){ return ctx.entity }`
-	if err := hook.ApplyPostReadScript(script, entity, true); err != nil {
+	if err := hook.ApplyPostReadScript(context.Background(), script, entity, true); err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	if entity["x"].(int) != 0 {
@@ -178,7 +179,7 @@ func TestApplyPostReadScriptSyntaxError(t *testing.T) {
 
 	entity := map[string]any{"id": "1"}
 	script := `function postRead(ctx) {`
-	if err := hook.ApplyPostReadScript(script, entity, true); err == nil {
+	if err := hook.ApplyPostReadScript(context.Background(), script, entity, true); err == nil {
 		t.Fatalf("expected error")
 	}
 }
@@ -192,7 +193,7 @@ function postRead(ctx) {
   ctx.query("order")
   return ctx.entity
 }`
-	if err := hook.ApplyPostReadScript(script, entity, true); err == nil {
+	if err := hook.ApplyPostReadScript(context.Background(), script, entity, true); err == nil {
 		t.Fatalf("expected error")
 	}
 }
@@ -217,7 +218,7 @@ function postRead(ctx) {
   ctx.entity.ok = true
   return ctx.entity
 }`
-	if err := hook.ApplyPostReadScript(script, entity, true); err != nil {
+	if err := hook.ApplyPostReadScript(context.Background(), script, entity, true); err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
@@ -425,7 +426,7 @@ func TestApplyPostReadHooksForEntityPriorityEnabledAndErrorTolerance(t *testing.
 
 	entity := map[string]any{"id": "1"}
 
-	out := hook.ApplyPostReadHooksForEntity("toto", entity)
+	out := hook.ApplyPostReadHooksForEntity(context.Background(), "toto", entity)
 	if out == nil {
 		t.Fatalf("expected non-nil entity")
 	}
@@ -517,7 +518,7 @@ func TestApplyPreReadHooksForEntityPriorityEnabledAndErrorTolerance(t *testing.T
 
 	entity := map[string]any{"id": "1"}
 
-	out := hook.ApplyPreReadHooksForEntity("toto", entity)
+	out := hook.ApplyPreReadHooksForEntity(context.Background(), "toto", entity)
 	if out == nil {
 		t.Fatalf("expected non-nil entity")
 	}
@@ -533,7 +534,7 @@ func TestApplyPreReadScriptMissingPreReadIsNoop(t *testing.T) {
 
 	entity := map[string]any{"id": "1", "x": 0}
 	script := `function other(ctx){ return ctx.entity }`
-	if err := hook.ApplyPreReadScript(script, entity, true); err != nil {
+	if err := hook.ApplyPreReadScript(context.Background(), script, entity, true); err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	if entity["x"].(int) != 0 {
@@ -546,7 +547,7 @@ func TestApplyPreReadScriptSyntaxError(t *testing.T) {
 
 	entity := map[string]any{"id": "1"}
 	script := `function preRead(ctx) {`
-	if err := hook.ApplyPreReadScript(script, entity, true); err == nil {
+	if err := hook.ApplyPreReadScript(context.Background(), script, entity, true); err == nil {
 		t.Fatalf("expected error")
 	}
 }
@@ -560,7 +561,7 @@ function preRead(ctx) {
   ctx.query("order")
   return ctx.entity
 }`
-	if err := hook.ApplyPreReadScript(script, entity, true); err == nil {
+	if err := hook.ApplyPreReadScript(context.Background(), script, entity, true); err == nil {
 		t.Fatalf("expected error")
 	}
 }
@@ -585,7 +586,7 @@ function preRead(ctx) {
   ctx.entity.ok = true
   return ctx.entity
 }`
-	if err := hook.ApplyPreReadScript(script, entity, true); err != nil {
+	if err := hook.ApplyPreReadScript(context.Background(), script, entity, true); err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
@@ -0,0 +1,216 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pkglog "github.com/taymour/elysiandb/internal/log"
+	"github.com/valyala/fasthttp"
+)
+
+func useBufferSink(t *testing.T, level pkglog.Level, encoder pkglog.Encoder) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	pkglog.Configure(level, pkglog.NewStreamSink(encoder, func() io.Writer { return buf }, false))
+	t.Cleanup(func() {
+		pkglog.Configure(pkglog.LevelDebug, pkglog.NewStdoutSink(pkglog.ConsoleEncoder{Color: true}, true))
+	})
+
+	return buf
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]pkglog.Level{
+		"":        pkglog.LevelDebug,
+		"debug":   pkglog.LevelDebug,
+		"INFO":    pkglog.LevelInfo,
+		"success": pkglog.LevelSuccess,
+		"warning": pkglog.LevelWarn,
+		"error":   pkglog.LevelError,
+		"fatal":   pkglog.LevelFatal,
+	}
+
+	for input, want := range cases {
+		got, err := pkglog.ParseLevel(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	if _, err := pkglog.ParseLevel("loud"); err == nil {
+		t.Fatalf("expected error for unknown level")
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	buf := useBufferSink(t, pkglog.LevelWarn, pkglog.ConsoleEncoder{})
+
+	pkglog.Debug("dropped-debug")
+	pkglog.Info("dropped-info")
+	pkglog.Warn("kept-warn")
+	pkglog.Error("kept-error")
+
+	out := buf.String()
+	if strings.Contains(out, "dropped") {
+		t.Fatalf("entries below the configured level were written:\n%s", out)
+	}
+	if !strings.Contains(out, "WARN kept-warn") || !strings.Contains(out, "ERROR kept-error") {
+		t.Fatalf("expected warn and error entries, got:\n%s", out)
+	}
+	if pkglog.Enabled(pkglog.LevelInfo) || !pkglog.Enabled(pkglog.LevelError) {
+		t.Fatalf("Enabled does not reflect the configured level")
+	}
+}
+
+func TestEntriesKeepCallOrder(t *testing.T) {
+	buf := useBufferSink(t, pkglog.LevelDebug, pkglog.ConsoleEncoder{})
+
+	for i := 0; i < 200; i++ {
+		pkglog.Info(fmt.Sprintf("line-%03d", i))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 200 {
+		t.Fatalf("expected 200 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, fmt.Sprintf("line-%03d", i)) {
+			t.Fatalf("line %d out of order: %q", i, line)
+		}
+	}
+}
+
+func TestJSONEncoderWritesFields(t *testing.T) {
+	buf := useBufferSink(t, pkglog.LevelDebug, pkglog.JSONEncoder{})
+
+	pkglog.WithFields(pkglog.Fields{"entity": "books", "error": fmt.Errorf("boom")}).
+		With("attempt", 2).
+		Error("write failed")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+
+	if entry["level"] != "error" || entry["msg"] != "write failed" {
+		t.Fatalf("unexpected level/msg: %v", entry)
+	}
+	if entry["entity"] != "books" || entry["error"] != "boom" || entry["attempt"] != float64(2) {
+		t.Fatalf("unexpected fields: %v", entry)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, entry["time"].(string)); err != nil {
+		t.Fatalf("time is not RFC3339: %v", entry["time"])
+	}
+}
+
+func TestConsoleEncoderSortsFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	pkglog.ConsoleEncoder{}.Encode(buf, pkglog.Entry{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   pkglog.LevelInfo,
+		Message: "hello",
+		Fields:  pkglog.Fields{"b": 2, "a": "x"},
+	})
+
+	want := "[2024-01-02 03:04:05] INFO hello a=x b=2\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	if enc, err := pkglog.NewEncoder("json"); err != nil || enc != (pkglog.JSONEncoder{}) {
+		t.Fatalf("expected JSON encoder, got %v, %v", enc, err)
+	}
+	if enc, err := pkglog.NewEncoder(""); err != nil || enc != (pkglog.ConsoleEncoder{Color: true}) {
+		t.Fatalf("expected console encoder, got %v, %v", enc, err)
+	}
+	if _, err := pkglog.NewEncoder("xml"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
+
+func TestRotatingFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "elysian.log")
+
+	sink, err := pkglog.NewRotatingFileSink(path, pkglog.ConsoleEncoder{}, 200, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 20; i++ {
+		if err := sink.Write(pkglog.Entry{Time: time.Now(), Level: pkglog.LevelInfo, Message: fmt.Sprintf("entry-%02d", i)}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", p, err)
+		}
+		if info.Size() > 200 {
+			t.Fatalf("%s exceeds max size: %d", p, info.Size())
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 backups")
+	}
+
+	current, _ := os.ReadFile(path)
+	if !strings.Contains(string(current), "entry-19") {
+		t.Fatalf("latest entry missing from active file:\n%s", current)
+	}
+}
+
+func TestEnsureRequestID(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(pkglog.RequestIDHeader, "abc-123")
+
+	if id := pkglog.EnsureRequestID(ctx); id != "abc-123" {
+		t.Fatalf("expected incoming request id to be honoured, got %q", id)
+	}
+	if string(ctx.Response.Header.Peek(pkglog.RequestIDHeader)) != "abc-123" {
+		t.Fatalf("request id not echoed in response")
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(pkglog.RequestIDHeader, "bad id\twith spaces")
+
+	id := pkglog.EnsureRequestID(ctx)
+	if len(id) != 32 || strings.Contains(id, " ") {
+		t.Fatalf("expected a generated request id, got %q", id)
+	}
+	if again := pkglog.EnsureRequestID(ctx); again != id {
+		t.Fatalf("request id changed within the same request: %q != %q", again, id)
+	}
+}
+
+func TestForRequestAddsRequestFields(t *testing.T) {
+	buf := useBufferSink(t, pkglog.LevelDebug, pkglog.JSONEncoder{})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set(pkglog.RequestIDHeader, "req-1")
+	ctx.SetUserValue("username", "alice")
+	ctx.SetUserValue("entity", "books")
+
+	pkglog.ForRequest(ctx).Info("handled")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if entry["request_id"] != "req-1" || entry["user"] != "alice" || entry["entity"] != "books" {
+		t.Fatalf("missing request fields: %v", entry)
+	}
+}
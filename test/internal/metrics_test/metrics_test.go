@@ -0,0 +1,124 @@
+package metrics_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/metrics"
+)
+
+var (
+	testCounter   = metrics.NewCounterVec("test_requests_total", "Test requests.", "route", "status")
+	testHistogram = metrics.NewHistogramVec("test_duration_seconds", "Test durations.", []float64{0.1, 1}, "op")
+	testGauge     = metrics.NewGaugeFunc("test_open", "Test gauge.", func() float64 { return 3 })
+	testVecGauge  = metrics.NewGaugeVecFunc("test_ratio", "Test ratio.", func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{`we"ird\name`}, Value: 0.5}}
+	}, "entity")
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := metrics.Default.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+
+	return buf.String()
+}
+
+func enable(t *testing.T) {
+	t.Helper()
+
+	metrics.SetEnabled(true)
+	testCounter.Reset()
+	testHistogram.Reset()
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+}
+
+func TestCounterVec(t *testing.T) {
+	enable(t)
+
+	testCounter.Inc("/api/{entity}", "200")
+	testCounter.Inc("/api/{entity}", "200")
+	testCounter.Add(5, "/api/{entity}", "404")
+
+	if v := testCounter.Value("/api/{entity}", "200"); v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+
+	out := scrape(t)
+	for _, want := range []string{
+		"# HELP test_requests_total Test requests.\n",
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{route="/api/{entity}",status="200"} 2` + "\n",
+		`test_requests_total{route="/api/{entity}",status="404"} 5` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	enable(t)
+
+	testHistogram.Observe(0.05, "save")
+	testHistogram.Observe(0.5, "save")
+	testHistogram.Observe(3, "save")
+
+	if c := testHistogram.Count("save"); c != 3 {
+		t.Fatalf("expected count 3, got %d", c)
+	}
+
+	out := scrape(t)
+	for _, want := range []string{
+		"# TYPE test_duration_seconds histogram\n",
+		`test_duration_seconds_bucket{op="save",le="0.1"} 1` + "\n",
+		`test_duration_seconds_bucket{op="save",le="1"} 2` + "\n",
+		`test_duration_seconds_bucket{op="save",le="+Inf"} 3` + "\n",
+		`test_duration_seconds_sum{op="save"} 3.55` + "\n",
+		`test_duration_seconds_count{op="save"} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestGaugeFuncs(t *testing.T) {
+	out := scrape(t)
+
+	for _, want := range []string{
+		"# TYPE test_open gauge\n",
+		"test_open 3\n",
+		`test_ratio{entity="we\"ird\\name"} 0.5` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecordingIsDisabledByDefault(t *testing.T) {
+	metrics.SetEnabled(false)
+	testCounter.Reset()
+
+	testCounter.Inc("/x", "200")
+	testHistogram.Observe(1, "noop")
+
+	if testCounter.Value("/x", "200") != 0 || testHistogram.Count("noop") != 0 {
+		t.Fatalf("expected no samples while metrics are disabled")
+	}
+}
+
+func TestDuplicateRegistrationPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected duplicate registration to panic")
+		}
+	}()
+
+	metrics.NewCounterVec("test_requests_total", "again")
+}
@@ -0,0 +1,201 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/openapi"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+func setup(t *testing.T) *configuration.Config {
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Api.Schema.Enabled = true
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	cache.InitCache(30)
+	api_storage.DeleteAll()
+	openapi.Reset()
+
+	return cfg
+}
+
+func spec(t *testing.T) map[string]any {
+	t.Helper()
+
+	data, err := openapi.Spec()
+	if err != nil {
+		t.Fatalf("spec: %v", err)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	return out
+}
+
+func dig(t *testing.T, m map[string]any, keys ...string) map[string]any {
+	t.Helper()
+
+	cur := m
+	for _, k := range keys {
+		next, ok := cur[k].(map[string]any)
+		if !ok {
+			t.Fatalf("missing %q in path %v", k, keys)
+		}
+		cur = next
+	}
+
+	return cur
+}
+
+func TestSpec_EntitySchemaAndPaths(t *testing.T) {
+	setup(t)
+
+	engine.WriteEntity("books", map[string]any{
+		"title":  "Dune",
+		"pages":  412.0,
+		"author": map[string]any{"name": "Frank"},
+		"tags":   []any{map[string]any{"label": "scifi"}},
+	})
+
+	doc := spec(t)
+
+	if doc["openapi"] != openapi.Version {
+		t.Fatalf("unexpected openapi version %v", doc["openapi"])
+	}
+
+	props := dig(t, doc, "components", "schemas", "books", "properties")
+	if dig(t, props, "title")["type"] != "string" || dig(t, props, "pages")["type"] != "number" {
+		t.Fatalf("unexpected scalar props: %v", props)
+	}
+	if dig(t, props, "author", "properties", "name")["type"] != "string" {
+		t.Fatalf("nested object not described: %v", props["author"])
+	}
+	if dig(t, props, "tags", "items", "properties", "label")["type"] != "string" {
+		t.Fatalf("array items not described: %v", props["tags"])
+	}
+
+	list := dig(t, doc, "paths", "/api/books", "get")
+	params, _ := list["parameters"].([]any)
+	names := map[string]bool{}
+	for _, p := range params {
+		ref, _ := p.(map[string]any)["$ref"].(string)
+		names[ref] = true
+	}
+	for _, want := range []string{"limit", "offset", "sort", "filter", "search", "fields", "includes", "countOnly"} {
+		if !names["#/components/parameters/"+want] {
+			t.Fatalf("list is missing %s parameter", want)
+		}
+	}
+
+	for _, path := range []string{"/api/books", "/api/books/{id}", "/api/books/count", "/api/books/{id}/exists", "/api/query", "/api/tx/begin", "/api/tx/{txId}/commit", "/kv/{key}", "/kv/mget"} {
+		dig(t, doc, "paths", path)
+	}
+
+	for _, method := range []string{"post", "put", "delete"} {
+		dig(t, doc, "paths", "/api/books", method)
+	}
+	for _, method := range []string{"get", "put", "delete"} {
+		dig(t, doc, "paths", "/api/books/{id}", method)
+	}
+
+	if _, ok := dig(t, doc, "paths")["/api/_elysiandb_core_schema"]; ok {
+		t.Fatal("core entities must not be exposed")
+	}
+}
+
+func TestSpec_RegeneratedOnSchemaUpdate(t *testing.T) {
+	setup(t)
+
+	engine.WriteEntity("books", map[string]any{"title": "Dune"})
+
+	doc := spec(t)
+	if _, ok := dig(t, doc, "components", "schemas", "books", "properties")["isbn"]; ok {
+		t.Fatal("isbn should not exist yet")
+	}
+
+	engine.UpdateEntitySchema("books", map[string]any{
+		"title": map[string]any{"name": "title", "type": "string", "required": true},
+		"isbn":  map[string]any{"name": "isbn", "type": "string", "required": true},
+	})
+
+	doc = spec(t)
+	books := dig(t, doc, "components", "schemas", "books")
+	if dig(t, books, "properties", "isbn")["type"] != "string" {
+		t.Fatalf("spec not regenerated: %v", books)
+	}
+
+	required, _ := books["required"].([]any)
+	if len(required) != 3 || required[0] != "id" || required[1] != "isbn" || required[2] != "title" {
+		t.Fatalf("unexpected required list: %v", required)
+	}
+
+	engine.WriteEntity("authors", map[string]any{"name": "Frank"})
+
+	doc = spec(t)
+	dig(t, doc, "paths", "/api/authors/{id}")
+}
+
+func TestSpec_SecuritySchemes(t *testing.T) {
+	cases := []struct {
+		mode   string
+		typ    string
+		scheme string
+	}{
+		{"basic", "http", "basic"},
+		{"token", "http", "bearer"},
+		{"jwt", "http", "bearer"},
+		{"user", "apiKey", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			cfg := setup(t)
+			cfg.Security.Authentication.Enabled = true
+			cfg.Security.Authentication.Mode = tc.mode
+
+			doc := spec(t)
+			scheme := dig(t, doc, "components", "securitySchemes", "elysiandb")
+			if scheme["type"] != tc.typ {
+				t.Fatalf("unexpected scheme %v", scheme)
+			}
+			if tc.scheme != "" && scheme["scheme"] != tc.scheme {
+				t.Fatalf("unexpected scheme %v", scheme)
+			}
+			if tc.mode == "jwt" && scheme["bearerFormat"] != "JWT" {
+				t.Fatalf("jwt scheme should declare bearerFormat: %v", scheme)
+			}
+			if tc.mode == "user" && (scheme["in"] != "cookie" || scheme["name"] != "edb_session") {
+				t.Fatalf("unexpected cookie scheme %v", scheme)
+			}
+			if _, ok := doc["security"]; !ok {
+				t.Fatal("global security requirement missing")
+			}
+		})
+	}
+}
+
+func TestSpec_NoSecurityWhenAuthDisabled(t *testing.T) {
+	setup(t)
+
+	doc := spec(t)
+	if _, ok := doc["security"]; ok {
+		t.Fatal("security must be omitted when authentication is disabled")
+	}
+	if _, ok := dig(t, doc, "components")["securitySchemes"]; ok {
+		t.Fatal("security schemes must be omitted when authentication is disabled")
+	}
+}
@@ -0,0 +1,92 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/query"
+)
+
+func TestParseFilterNode_LeafAndOr(t *testing.T) {
+	node, err := query.ParseFilterNode(map[string]any{
+		"or": []any{
+			map[string]any{"status": map[string]any{"eq": "published"}},
+			map[string]any{"team": map[string]any{"eq": "$user.team"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(node.Or) != 2 || node.Or[0].Leaf["status"]["eq"] != "published" {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+}
+
+func TestParseFilterNode_Invalid(t *testing.T) {
+	if _, err := query.ParseFilterNode(map[string]any{"or": "x"}); err == nil {
+		t.Fatalf("expected error for non array or")
+	}
+
+	if _, err := query.ParseFilterNode(map[string]any{"status": map[string]any{"eq": 1}}); err == nil {
+		t.Fatalf("expected error for non string value")
+	}
+}
+
+func TestAnd_SkipsEmptyNodes(t *testing.T) {
+	leaf := query.FilterNode{Leaf: map[string]map[string]string{"a": {"eq": "1"}}}
+
+	if out := query.And(); !out.IsEmpty() || out.Leaf == nil {
+		t.Fatalf("expected a match-all node, got %+v", out)
+	}
+
+	if out := query.And(query.FilterNode{}, leaf); out.Leaf == nil || len(out.And) != 0 {
+		t.Fatalf("expected single leaf, got %+v", out)
+	}
+
+	if out := query.And(leaf, leaf); len(out.And) != 2 {
+		t.Fatalf("expected and node, got %+v", out)
+	}
+}
+
+func TestResolveUserPlaceholders(t *testing.T) {
+	user := map[string]any{
+		"username": "alice",
+		"groups":   []string{"a", "b"},
+		"org":      map[string]any{"id": 42.0},
+	}
+
+	node := query.FilterNode{And: []query.FilterNode{
+		{Leaf: map[string]map[string]string{"owner": {"eq": "$user.username"}}},
+		{Leaf: map[string]map[string]string{"group": {"any": "$user.groups"}}},
+		{Leaf: map[string]map[string]string{"org": {"eq": "$user.org.id"}}},
+	}}
+
+	out, ok := query.ResolveUserPlaceholders(node, user)
+	if !ok {
+		t.Fatalf("expected placeholders to resolve")
+	}
+
+	if out.And[0].Leaf["owner"]["eq"] != "alice" ||
+		out.And[1].Leaf["group"]["any"] != "a,b" ||
+		out.And[2].Leaf["org"]["eq"] != "42" {
+		t.Fatalf("unexpected resolution: %+v", out)
+	}
+}
+
+func TestResolveUserPlaceholders_Missing(t *testing.T) {
+	leaf := query.FilterNode{Leaf: map[string]map[string]string{"team": {"eq": "$user.team"}}}
+
+	if _, ok := query.ResolveUserPlaceholders(leaf, map[string]any{}); ok {
+		t.Fatalf("missing attribute must not resolve")
+	}
+
+	or := query.FilterNode{Or: []query.FilterNode{
+		leaf,
+		{Leaf: map[string]map[string]string{"status": {"eq": "published"}}},
+	}}
+
+	out, ok := query.ResolveUserPlaceholders(or, map[string]any{})
+	if !ok || len(out.Or) != 1 {
+		t.Fatalf("unresolved or branches should be dropped, got %+v", out)
+	}
+}
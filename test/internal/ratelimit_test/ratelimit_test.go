@@ -0,0 +1,289 @@
+package ratelimit_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/ratelimit"
+	"github.com/valyala/fasthttp"
+)
+
+func setup(t *testing.T, cfg *configuration.Config) {
+	t.Helper()
+	globals.SetConfig(cfg)
+	ratelimit.Limiter.Reset()
+	ratelimit.Quotas.Reset()
+}
+
+func newCtx(method string, body string, username string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetBodyString(body)
+	if username != "" {
+		ctx.SetUserValue("username", username)
+	}
+
+	return ctx
+}
+
+func TestDisabledPassesThrough(t *testing.T) {
+	setup(t, &configuration.Config{})
+
+	called := 0
+	h := ratelimit.Read(func(ctx *fasthttp.RequestCtx) { called++ })
+
+	for i := 0; i < 100; i++ {
+		h(newCtx("GET", "", "alice"))
+	}
+
+	if called != 100 {
+		t.Fatalf("expected all requests to pass, got %d", called)
+	}
+}
+
+func TestPerClientLimit(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.RateLimit.Enabled = true
+	cfg.Security.RateLimit.PerClient = configuration.RateConfig{RequestsPerSecond: 1, Burst: 2}
+	setup(t, cfg)
+
+	h := ratelimit.Read(func(ctx *fasthttp.RequestCtx) {})
+
+	ctx := newCtx("GET", "", "alice")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("first request should pass")
+	}
+	if string(ctx.Response.Header.Peek("RateLimit-Limit")) != "2" || string(ctx.Response.Header.Peek("RateLimit-Remaining")) != "1" {
+		t.Fatalf("unexpected headers: limit=%s remaining=%s", ctx.Response.Header.Peek("RateLimit-Limit"), ctx.Response.Header.Peek("RateLimit-Remaining"))
+	}
+
+	h(newCtx("GET", "", "alice"))
+
+	ctx = newCtx("GET", "", "alice")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", ctx.Response.StatusCode())
+	}
+	if string(ctx.Response.Header.Peek("Retry-After")) != "1" {
+		t.Fatalf("expected Retry-After 1, got %s", ctx.Response.Header.Peek("Retry-After"))
+	}
+
+	ctx = newCtx("GET", "", "bob")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("other clients must have their own bucket")
+	}
+}
+
+func TestClientOverride(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.RateLimit.Enabled = true
+	cfg.Security.RateLimit.PerClient = configuration.RateConfig{RequestsPerSecond: 1, Burst: 1}
+	cfg.Security.RateLimit.Clients = map[string]configuration.RateConfig{
+		"batch": {RequestsPerSecond: 100, Burst: 100},
+	}
+	setup(t, cfg)
+
+	now := time.Now()
+	for i := 0; i < 50; i++ {
+		if !ratelimit.Limiter.Allow("user:batch", ratelimit.GroupRead, now).Allowed {
+			t.Fatalf("override should allow request %d", i)
+		}
+	}
+
+	ratelimit.Limiter.Allow("user:alice", ratelimit.GroupRead, now)
+	if ratelimit.Limiter.Allow("user:alice", ratelimit.GroupRead, now).Allowed {
+		t.Fatalf("default client limit should apply")
+	}
+}
+
+func TestClientOverrideByAPIKey(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.RateLimit.Enabled = true
+	cfg.Security.RateLimit.PerClient = configuration.RateConfig{RequestsPerSecond: 1, Burst: 1}
+	cfg.Security.RateLimit.Clients = map[string]configuration.RateConfig{
+		"key:" + ratelimit.KeyHash("batch-key"): {RequestsPerSecond: 100, Burst: 100},
+	}
+	cfg.Security.Quota.Enabled = true
+	cfg.Security.Quota.DailyWrites = 1
+	cfg.Security.Quota.Users = map[string]configuration.QuotaLimitConfig{
+		"key:" + ratelimit.KeyHash("batch-key"): {DailyWrites: 0},
+	}
+	setup(t, cfg)
+
+	called := 0
+	h := ratelimit.Write(func(ctx *fasthttp.RequestCtx) { called++ })
+
+	for i := 0; i < 10; i++ {
+		ctx := newCtx("POST", "{}", "")
+		ctx.Request.Header.Set("Authorization", "Bearer batch-key")
+		h(ctx)
+	}
+
+	if called != 10 {
+		t.Fatalf("API key override should apply to rate and quota, got %d calls", called)
+	}
+
+	for i := 0; i < 2; i++ {
+		ctx := newCtx("POST", "{}", "")
+		ctx.Request.Header.Set("Authorization", "Bearer other-key")
+		h(ctx)
+	}
+
+	if called != 11 {
+		t.Fatalf("default limits should apply to other keys, got %d calls", called)
+	}
+}
+
+func TestPerIPLimit(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.RateLimit.Enabled = true
+	cfg.Security.RateLimit.PerIP = configuration.RateConfig{RequestsPerSecond: 1, Burst: 2}
+	setup(t, cfg)
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if !ratelimit.Limiter.AllowIP("10.0.0.1", now).Allowed {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+
+	decision := ratelimit.Limiter.AllowIP("10.0.0.1", now)
+	if decision.Allowed || decision.RetryAfter != 1 {
+		t.Fatalf("expected rejection with Retry-After 1, got %+v", decision)
+	}
+
+	if !ratelimit.Limiter.AllowIP("10.0.0.2", now).Allowed {
+		t.Fatalf("other IPs must not share the bucket")
+	}
+
+	if !ratelimit.Limiter.AllowIP("10.0.0.1", now.Add(time.Second)).Allowed {
+		t.Fatalf("bucket should refill")
+	}
+
+	cfg.Security.RateLimit.PerIP = configuration.RateConfig{}
+	for i := 0; i < 10; i++ {
+		if !ratelimit.Limiter.AllowIP("10.0.0.3", now).Allowed {
+			t.Fatalf("unset perIP must not limit")
+		}
+	}
+}
+
+func TestGroupLimitAndRefill(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.RateLimit.Enabled = true
+	cfg.Security.RateLimit.Groups = map[string]configuration.RateConfig{
+		ratelimit.GroupQuery: {RequestsPerSecond: 2, Burst: 1},
+	}
+	setup(t, cfg)
+
+	now := time.Now()
+	if !ratelimit.Limiter.Allow("user:alice", ratelimit.GroupQuery, now).Allowed {
+		t.Fatalf("first query should pass")
+	}
+	if ratelimit.Limiter.Allow("user:alice", ratelimit.GroupQuery, now).Allowed {
+		t.Fatalf("second query should be limited")
+	}
+	if !ratelimit.Limiter.Allow("user:alice", ratelimit.GroupRead, now).Allowed {
+		t.Fatalf("reads are not limited by the query group")
+	}
+	if !ratelimit.Limiter.Allow("user:alice", ratelimit.GroupQuery, now.Add(500*time.Millisecond)).Allowed {
+		t.Fatalf("bucket should refill")
+	}
+}
+
+func TestGlobalLimitSharedAcrossClients(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.RateLimit.Enabled = true
+	cfg.Security.RateLimit.Global = configuration.RateConfig{RequestsPerSecond: 1, Burst: 2}
+	setup(t, cfg)
+
+	now := time.Now()
+	ratelimit.Limiter.Allow("user:a", ratelimit.GroupRead, now)
+	ratelimit.Limiter.Allow("user:b", ratelimit.GroupRead, now)
+
+	if ratelimit.Limiter.Allow("user:c", ratelimit.GroupRead, now).Allowed {
+		t.Fatalf("global bucket should be exhausted")
+	}
+}
+
+func TestDailyWriteQuota(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.Quota.Enabled = true
+	cfg.Security.Quota.DailyWrites = 2
+	cfg.Security.Quota.Users = map[string]configuration.QuotaLimitConfig{
+		"vip": {DailyWrites: 10},
+	}
+	setup(t, cfg)
+
+	called := 0
+	write := ratelimit.Write(func(ctx *fasthttp.RequestCtx) { called++ })
+	read := ratelimit.Read(func(ctx *fasthttp.RequestCtx) { called++ })
+
+	write(newCtx("POST", "{}", "alice"))
+	write(newCtx("POST", "{}", "alice"))
+
+	ctx := newCtx("POST", "{}", "alice")
+	write(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+		t.Fatalf("expected 429 once quota is exhausted")
+	}
+	if string(ctx.Response.Body()) != `{"error":"daily write quota exceeded"}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+
+	read(newCtx("GET", "", "alice"))
+	write(newCtx("POST", "{}", "vip"))
+
+	if called != 4 {
+		t.Fatalf("expected 4 handled requests, got %d", called)
+	}
+}
+
+func TestDailyWriteBytesQuota(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Security.Quota.Enabled = true
+	cfg.Security.Quota.DailyWriteBytes = 10
+	setup(t, cfg)
+
+	now := time.Date(2026, 1, 1, 23, 0, 0, 0, time.UTC)
+
+	if err := ratelimit.Quotas.Consume("user:alice", 8, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ratelimit.Quotas.Consume("user:alice", 8, now); err != ratelimit.ErrDailyWriteBytesQuotaExceeded {
+		t.Fatalf("expected storage quota error, got %v", err)
+	}
+	if err := ratelimit.Quotas.Consume("user:alice", 8, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("quota should reset on a new day: %v", err)
+	}
+
+	if usage := ratelimit.Quotas.Usage("user:alice", now.Add(2*time.Hour)); usage.Writes != 1 || usage.WriteBytes != 8 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	ctx := newCtx("GET", "", "alice")
+	if ratelimit.Identity(ctx) != "user:alice" {
+		t.Fatalf("expected user identity")
+	}
+
+	ctx = newCtx("GET", "", "")
+	ctx.Request.Header.Set("Authorization", "Bearer abc")
+	key := ratelimit.Identity(ctx)
+
+	other := newCtx("GET", "", "")
+	other.Request.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("u:p")))
+	if key[:4] != "key:" || key == ratelimit.Identity(other) {
+		t.Fatalf("expected distinct key identities, got %s", key)
+	}
+
+	if id := ratelimit.Identity(newCtx("GET", "", "")); id[:3] != "ip:" {
+		t.Fatalf("expected ip identity, got %s", id)
+	}
+}
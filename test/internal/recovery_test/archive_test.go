@@ -0,0 +1,163 @@
+package recovery_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/recovery"
+)
+
+func setupArchive(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "wal")
+	globals.SetConfig(&configuration.Config{
+		Store: configuration.StoreConfig{
+			Folder: dir,
+			CrashRecovery: configuration.CrashRecoveryConfig{
+				Enabled:  true,
+				MaxLogMB: 1,
+				Archive:  configuration.WalArchiveConfig{Enabled: true, Folder: archive},
+			},
+		},
+	})
+
+	recovery.ActivateJsonRecoveryLog(func() {})
+	recovery.ActivateStoreRecoveryLog(func() {})
+
+	return dir, archive
+}
+
+func TestRecoveryRecordsCarrySequenceAndTime(t *testing.T) {
+	dir := setupTempDir(t)
+	recovery.ActivateStoreRecoveryLog(func() {})
+
+	before := recovery.Sequence()
+	recovery.LogStorePut("a", []byte("1"))
+	recovery.LogStoreDelete("a")
+	t.Cleanup(recovery.ClearStoreRecoveryLog)
+
+	f, err := os.Open(filepath.Join(dir, recovery.StoreRecoveryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var seqs []uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec struct {
+			Seq  uint64 `json:"seq"`
+			Time int64  `json:"ts"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Time < time.Now().Add(-time.Minute).UnixMilli() {
+			t.Fatalf("record without a recent timestamp: %s", scanner.Text())
+		}
+		seqs = append(seqs, rec.Seq)
+	}
+
+	if len(seqs) != 2 || seqs[0] != before+1 || seqs[1] != before+2 {
+		t.Fatalf("expected consecutive sequences after %d, got %v", before, seqs)
+	}
+}
+
+func TestClearArchivesSegmentsAndReplayStopsAtTarget(t *testing.T) {
+	dir, archive := setupArchive(t)
+
+	start := recovery.Sequence()
+	recovery.LogStorePut("k", []byte("v1"))
+	recovery.LogJsonPut("doc", map[string]any{"n": 1.0})
+	recovery.ClearStoreRecoveryLog()
+	recovery.ClearJsonRecoveryLog()
+
+	if _, err := os.Stat(filepath.Join(dir, recovery.StoreRecoveryFile)); !os.IsNotExist(err) {
+		t.Fatalf("live log should have been moved, got %v", err)
+	}
+
+	middle := recovery.Sequence()
+	time.Sleep(5 * time.Millisecond)
+	cut := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	recovery.LogStorePut("k", []byte("v2"))
+	recovery.LogJsonDelete("doc")
+	recovery.LogStoreReset()
+	recovery.ClearStoreRecoveryLog()
+	recovery.ClearJsonRecoveryLog()
+
+	segments, err := recovery.ListSegments(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 4 {
+		t.Fatalf("expected 4 archived segments, got %+v", segments)
+	}
+
+	replay := func(target recovery.Target) ([]recovery.WalRecord, recovery.ReplayResult) {
+		var records []recovery.WalRecord
+		result, err := recovery.ReplayArchive(archive, start, target, func(r recovery.WalRecord) {
+			records = append(records, r)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return records, result
+	}
+
+	records, result := replay(recovery.Target{Seq: middle})
+	if len(records) != 2 || result.LastSeq != middle {
+		t.Fatalf("expected the two records up to %d, got %+v", middle, records)
+	}
+	if string(records[0].Value) != "v1" || records[1].Document["n"] != 1.0 {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	records, _ = replay(recovery.Target{Time: cut})
+	if len(records) != 2 {
+		t.Fatalf("expected records before %s only, got %+v", cut, records)
+	}
+
+	records, result = replay(recovery.Target{})
+	if len(records) != 5 || len(result.Gaps) != 0 {
+		t.Fatalf("expected every record and no gap, got %+v %+v", records, result.Gaps)
+	}
+
+	if err := os.Remove(segments[0].Path); err != nil {
+		t.Fatal(err)
+	}
+	_, result = replay(recovery.Target{})
+	if len(result.Gaps) != 1 || result.Gaps[0].From != start+1 {
+		t.Fatalf("expected a gap at the start, got %+v", result.Gaps)
+	}
+}
+
+func TestInitSequenceResumesFromArchive(t *testing.T) {
+	_, archive := setupArchive(t)
+
+	if err := os.MkdirAll(archive, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	next := recovery.Sequence() + 1000
+	name := filepath.Join(archive, fmt.Sprintf("kv-%020d-%020d.log", next-1, next))
+	if err := os.WriteFile(name, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	recovery.InitSequence()
+
+	if recovery.Sequence() != next {
+		t.Fatalf("expected sequence %d, got %d", next, recovery.Sequence())
+	}
+}
@@ -0,0 +1,457 @@
+package replication_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/recovery"
+	"github.com/taymour/elysiandb/internal/replication"
+	"github.com/taymour/elysiandb/internal/routing"
+	"github.com/taymour/elysiandb/internal/storage"
+	http_replication "github.com/taymour/elysiandb/internal/transport/http/api/replication"
+	"github.com/valyala/fasthttp"
+)
+
+const token = "secret"
+
+func setup(t *testing.T, role, leader string) {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Replication = configuration.ReplicationConfig{Role: role, Leader: leader, Token: token, BacklogSize: 16}
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	t.Cleanup(replication.Stop)
+}
+
+func streamRecords(t *testing.T, after uint64, epoch string) <-chan replication.Record {
+	t.Helper()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(replication.Stream(bufio.NewWriter(pw), after, epoch, "test"))
+	}()
+
+	out := make(chan replication.Record, 64)
+	go func() {
+		defer close(out)
+
+		dec := json.NewDecoder(pr)
+		for {
+			var r replication.Record
+			if dec.Decode(&r) != nil {
+				return
+			}
+			out <- r
+		}
+	}()
+
+	t.Cleanup(func() { _ = pr.Close() })
+
+	return out
+}
+
+func next(t *testing.T, records <-chan replication.Record, skipPings bool) replication.Record {
+	t.Helper()
+
+	for {
+		select {
+		case r, ok := <-records:
+			if !ok {
+				t.Fatal("stream closed")
+			}
+			if skipPings && r.Op == replication.OpPing {
+				continue
+			}
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a replication record")
+		}
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s: %+v", what, replication.CurrentStatus())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLeaderStreamsSnapshotThenWrites(t *testing.T) {
+	setup(t, replication.RoleLeader, "")
+	api_storage.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune"})
+
+	replication.Start()
+	records := streamRecords(t, 0, "")
+
+	first := next(t, records, false)
+	if first.Op != replication.OpSnapshot || first.Epoch == "" || first.Seq != 0 {
+		t.Fatalf("expected snapshot header, got %+v", first)
+	}
+
+	keys := []string{}
+	for r := next(t, records, false); r.Op != replication.OpReady; r = next(t, records, false) {
+		keys = append(keys, r.Store+":"+r.Key)
+	}
+
+	if !slices.Contains(keys, "json:"+globals.ApiSingleEntityKey("book", "b1")) ||
+		!slices.Contains(keys, "kv:"+globals.ApiAllEntityTypesListKey()) {
+		t.Fatalf("snapshot is missing data: %v", keys)
+	}
+
+	api_storage.WriteEntity("book", map[string]any{"id": "b2", "title": "Emma"})
+
+	r := next(t, records, true)
+	if r.Op != "put" || r.Store != "json" || r.Key != globals.ApiSingleEntityKey("book", "b2") || r.Seq != 1 {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+
+	api_storage.DeleteEntityById("book", "b1")
+
+	r = next(t, records, true)
+	if r.Op != "del" || r.Key != globals.ApiSingleEntityKey("book", "b1") || r.Seq != 2 {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+
+	waitFor(t, "follower position", func() bool {
+		s := replication.CurrentStatus()
+		return len(s.Followers) == 1 && s.Followers[0].Seq == 2 && s.Seq == 2
+	})
+}
+
+func TestStreamedWritesMatchStoreOrder(t *testing.T) {
+	setup(t, replication.RoleLeader, "")
+
+	var mu sync.Mutex
+	last := map[string]json.RawMessage{}
+	second := make(chan struct{})
+	stop := recovery.AddStreamSink(func(op recovery.StreamOp) {
+		if string(op.Value) == `{"n":1}` || string(op.Value) == `"MQ=="` {
+			select {
+			case <-second:
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		mu.Lock()
+		last[op.Store+":"+op.Key] = op.Value
+		mu.Unlock()
+	})
+	defer stop()
+
+	race := func(first, then func()) {
+		second = make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			first()
+			close(done)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		then()
+		close(second)
+		<-done
+	}
+
+	race(
+		func() { _ = storage.PutJsonValue("doc", map[string]any{"n": 1}) },
+		func() { _ = storage.PutJsonValue("doc", map[string]any{"n": 2}) },
+	)
+
+	race(
+		func() { _ = storage.PutKeyValue("kv", []byte("1")) },
+		func() { _ = storage.PutKeyValue("kv", []byte("2")) },
+	)
+
+	doc, _ := storage.GetJsonByKey("doc")
+	raw, _ := json.Marshal(doc)
+	if !bytes.Equal(last["json:doc"], raw) {
+		t.Fatalf("last streamed json put %s does not match stored %s", last["json:doc"], raw)
+	}
+
+	kv, _ := storage.GetByKey("kv")
+	streamed, _ := json.Marshal(kv)
+	if !bytes.Equal(last["kv:kv"], streamed) {
+		t.Fatalf("last streamed kv put %s does not match stored %s", last["kv:kv"], streamed)
+	}
+}
+
+func TestLeaderResumesInsideBacklogAndRejectsOverrun(t *testing.T) {
+	setup(t, replication.RoleLeader, "")
+	replication.Start()
+
+	for i := 1; i <= 3; i++ {
+		_ = storage.PutKeyValue(fmt.Sprintf("k%d", i), []byte("v"))
+	}
+
+	epoch := replication.CurrentStatus().Epoch
+	records := streamRecords(t, 1, epoch)
+
+	if r := next(t, records, false); r.Op != replication.OpPing || r.Seq != 3 {
+		t.Fatalf("expected ping with head, got %+v", r)
+	}
+
+	for _, want := range []string{"k2", "k3"} {
+		if r := next(t, records, false); r.Key != want {
+			t.Fatalf("expected %s, got %+v", want, r)
+		}
+	}
+
+	if r := next(t, streamRecords(t, 1, "another-epoch"), false); r.Op != replication.OpSnapshot {
+		t.Fatalf("unknown epoch should trigger a snapshot, got %+v", r)
+	}
+
+	for i := range 32 {
+		_ = storage.PutKeyValue(fmt.Sprintf("more%d", i), []byte("v"))
+	}
+
+	var buf bytes.Buffer
+	if err := replication.Stream(bufio.NewWriter(&buf), 1, epoch, "test"); err == nil {
+		t.Fatal("resuming behind the backlog should fail")
+	}
+}
+
+type fakeLeader struct {
+	mu          sync.Mutex
+	connections []url.Values
+	streams     []func(w io.Writer, flush func(), done <-chan struct{})
+}
+
+func (l *fakeLeader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(replication.TokenHeader) != token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	l.mu.Lock()
+	n := len(l.connections)
+	l.connections = append(l.connections, r.URL.Query())
+	l.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flush := w.(http.Flusher).Flush
+	if n < len(l.streams) {
+		l.streams[n](w, flush, r.Context().Done())
+		return
+	}
+
+	<-r.Context().Done()
+}
+
+func (l *fakeLeader) connection(i int) url.Values {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if i >= len(l.connections) {
+		return nil
+	}
+
+	return l.connections[i]
+}
+
+func write(w io.Writer, records ...replication.Record) {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		_ = enc.Encode(r)
+	}
+}
+
+func jsonPut(seq uint64, id string, data map[string]any) replication.Record {
+	data["id"] = id
+	raw, _ := json.Marshal(data)
+
+	return replication.Record{Seq: seq, Op: "put", Store: "json", Key: globals.ApiSingleEntityKey("book", id), Value: raw}
+}
+
+func TestFollowerAppliesSnapshotStreamAndPromotes(t *testing.T) {
+	more := make(chan struct{})
+	leader := &fakeLeader{streams: []func(io.Writer, func(), <-chan struct{}){
+		func(w io.Writer, flush func(), done <-chan struct{}) {
+			types, _ := json.Marshal([]byte("book"))
+			write(w,
+				replication.Record{Op: replication.OpSnapshot, Epoch: "e1", Seq: 2},
+				replication.Record{Op: "put", Store: "kv", Key: globals.ApiAllEntityTypesListKey(), Value: types},
+				jsonPut(0, "b1", map[string]any{"title": "Dune", "pages": float64(300)}),
+				jsonPut(0, "b2", map[string]any{"title": "Emma", "pages": float64(100)}),
+				replication.Record{Op: replication.OpReady, Epoch: "e1", Seq: 2},
+			)
+			flush()
+
+			select {
+			case <-more:
+			case <-done:
+				return
+			}
+
+			write(w,
+				jsonPut(3, "b3", map[string]any{"title": "Ulysses", "pages": float64(200)}),
+				replication.Record{Seq: 4, Op: "del", Store: "json", Key: globals.ApiSingleEntityKey("book", "b1")},
+				replication.Record{Op: replication.OpPing, Seq: 4},
+			)
+			flush()
+
+			<-done
+		},
+	}}
+
+	srv := httptest.NewServer(leader)
+	t.Cleanup(srv.Close)
+
+	setup(t, replication.RoleFollower, srv.URL)
+	api_storage.WriteEntity("book", map[string]any{"id": "local", "title": "Stale"})
+
+	replication.Start()
+
+	waitFor(t, "snapshot", func() bool {
+		s := replication.CurrentStatus()
+		return s.Connected && !s.Syncing && s.Seq == 2
+	})
+
+	if q := leader.connection(0); q.Get("after") != "0" {
+		t.Fatalf("first connection should ask for a snapshot: %v", q)
+	}
+
+	if api_storage.ReadEntityById("book", "local") != nil {
+		t.Fatal("snapshot should replace local data")
+	}
+
+	if got := api_storage.GetListOfIds("book", "pages", false); !slices.Equal(got, []string{"b1", "b2"}) {
+		t.Fatalf("sorted ids after snapshot: %v", got)
+	}
+
+	close(more)
+
+	waitFor(t, "stream", func() bool { return replication.CurrentStatus().Seq == 4 })
+
+	if got := api_storage.GetListOfIds("book", "pages", false); !slices.Equal(got, []string{"b3", "b2"}) {
+		t.Fatalf("sort index should follow the stream: %v", got)
+	}
+
+	if got := api_storage.GetListOfIds("book", "", true); !slices.Equal(got, []string{"b2", "b3"}) {
+		t.Fatalf("ids after stream: %v", got)
+	}
+
+	s := replication.CurrentStatus()
+	if s.Role != replication.RoleFollower || s.LagRecords != 0 || s.LagSeconds != 0 || s.LeaderSeq != 4 || s.Epoch != "e1" {
+		t.Fatalf("unexpected follower status: %+v", s)
+	}
+
+	handler := routing.ReadOnly(func(ctx *fasthttp.RequestCtx) { ctx.SetStatusCode(fasthttp.StatusOK) })
+
+	var write fasthttp.RequestCtx
+	write.Request.Header.SetMethod(fasthttp.MethodPost)
+	write.Request.SetRequestURI("/api/book")
+	handler(&write)
+	if write.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Fatalf("follower should reject writes, got %d", write.Response.StatusCode())
+	}
+
+	var query fasthttp.RequestCtx
+	query.Request.Header.SetMethod(fasthttp.MethodPost)
+	query.Request.SetRequestURI("/api/query")
+	handler(&query)
+	if query.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("follower should serve queries, got %d", query.Response.StatusCode())
+	}
+
+	if err := replication.Promote(); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+
+	if !replication.IsLeader() {
+		t.Fatalf("expected leader after promotion, got %s", replication.Role())
+	}
+
+	write.Response.Reset()
+	handler(&write)
+	if write.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("promoted node should accept writes, got %d", write.Response.StatusCode())
+	}
+
+	api_storage.WriteEntity("book", map[string]any{"id": "b4", "title": "Middlemarch"})
+	if s := replication.CurrentStatus(); s.Seq != 1 || s.Epoch == "e1" {
+		t.Fatalf("promoted node should start a new epoch: %+v", s)
+	}
+
+	if err := replication.Promote(); err == nil {
+		t.Fatal("promoting a leader should fail")
+	}
+}
+
+func TestFollowerResumesFromLastAppliedRecord(t *testing.T) {
+	previous := replication.RetryInterval
+	replication.RetryInterval = 10 * time.Millisecond
+	t.Cleanup(func() { replication.RetryInterval = previous })
+
+	leader := &fakeLeader{streams: []func(io.Writer, func(), <-chan struct{}){
+		func(w io.Writer, flush func(), done <-chan struct{}) {
+			write(w,
+				replication.Record{Op: replication.OpSnapshot, Epoch: "e1", Seq: 0},
+				replication.Record{Op: replication.OpReady, Epoch: "e1", Seq: 0},
+				jsonPut(1, "b1", map[string]any{"title": "Dune"}),
+			)
+		},
+	}}
+
+	srv := httptest.NewServer(leader)
+	t.Cleanup(srv.Close)
+
+	setup(t, replication.RoleFollower, srv.URL)
+	replication.Start()
+
+	waitFor(t, "reconnection", func() bool { return leader.connection(1) != nil })
+
+	if q := leader.connection(1); q.Get("after") != "1" || q.Get("epoch") != "e1" {
+		t.Fatalf("follower should resume after its last record: %v", q)
+	}
+
+	if api_storage.ReadEntityById("book", "b1") == nil {
+		t.Fatal("streamed entity missing")
+	}
+}
+
+func TestStreamRequiresReplicationToken(t *testing.T) {
+	setup(t, replication.RoleLeader, "")
+	replication.Start()
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/replication/stream?after=0")
+	ctx.Request.Header.Set(replication.TokenHeader, "wrong")
+	http_replication.StreamController(&ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx.Response.Reset()
+	ctx.Request.Header.Set(replication.TokenHeader, token)
+	http_replication.PromoteController(&ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusConflict {
+		t.Fatalf("promoting a leader should conflict, got %d", ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,84 @@
+package routing_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/routing"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func serveBufferBody(t *testing.T) *fasthttp.Client {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	srv := &fasthttp.Server{
+		StreamRequestBody:  true,
+		MaxRequestBodySize: routing.MaxBufferedBodySize,
+		Handler: routing.BufferBody(func(ctx *fasthttp.RequestCtx) {
+			if ctx.Request.IsBodyStream() {
+				n, _ := io.Copy(io.Discard, ctx.RequestBodyStream())
+				ctx.SetBodyString("streamed " + strconv.FormatInt(n, 10))
+				return
+			}
+
+			ctx.SetBodyString("buffered " + strconv.Itoa(len(ctx.PostBody())))
+		}),
+	}
+
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = ln.Close() })
+
+	return &fasthttp.Client{
+		MaxResponseBodySize: 1 << 20,
+		Dial:                func(string) (net.Conn, error) { return ln.Dial() },
+	}
+}
+
+func post(t *testing.T, c *fasthttp.Client, path string, size int) (int, string) {
+	t.Helper()
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("http://test" + path)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.SetBody(bytes.Repeat([]byte("x"), size))
+
+	if err := c.Do(req, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode(), string(resp.Body())
+}
+
+func TestBufferBodyKeepsTheLimitOutsideStreamingRoutes(t *testing.T) {
+	c := serveBufferBody(t)
+
+	if status, body := post(t, c, "/api/books", 1024); status != fasthttp.StatusOK || body != "buffered 1024" {
+		t.Fatalf("small bodies should be buffered, got %d %s", status, body)
+	}
+
+	if status, _ := post(t, c, "/api/books", routing.MaxBufferedBodySize+1); status != fasthttp.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 above the limit, got %d", status)
+	}
+
+	size := routing.MaxBufferedBodySize + 1024
+	if status, body := post(t, c, "/api/import", size); status != fasthttp.StatusOK || body != "streamed "+strconv.Itoa(size) {
+		t.Fatalf("import should stream large bodies, got %d %s", status, body)
+	}
+
+	if status, body := post(t, c, "/api/books/import", size); status != fasthttp.StatusOK || body != "streamed "+strconv.Itoa(size) {
+		t.Fatalf("entity import should stream large bodies, got %d %s", status, body)
+	}
+
+	if status, _ := post(t, c, "/api/books/import/extra", routing.MaxBufferedBodySize+1); status != fasthttp.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 outside the import routes, got %d", status)
+	}
+}
@@ -2,13 +2,22 @@ package routing_test
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
 	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fasthttp/router"
 	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/log"
+	"github.com/taymour/elysiandb/internal/metrics"
+	"github.com/taymour/elysiandb/internal/ratelimit"
 	"github.com/taymour/elysiandb/internal/routing"
+	"github.com/taymour/elysiandb/internal/security"
 	"github.com/taymour/elysiandb/internal/storage"
 	http_adminui "github.com/taymour/elysiandb/internal/transport/http/adminui"
 	"github.com/taymour/elysiandb/internal/transport/http/controller"
@@ -157,3 +166,304 @@ func TestRegisterRoutes_WithSchema(t *testing.T) {
 		t.Fatalf("missing put schema")
 	}
 }
+
+func TestRegisterRoutes_RoleFieldRoutesUseRoleNameParameter(t *testing.T) {
+	initEnv(t, false, false, false)
+	cfg := globals.GetConfig()
+	cfg.Security.Authentication.Enabled = true
+	cfg.Security.Authentication.Mode = "user"
+
+	session, err := security.CreateSession("admin", security.RoleAdmin, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod("GET")
+	req.SetRequestURI("/api/acl/role/user/books/fields")
+	req.Header.SetCookie(security.SessionCookieName, session.ID)
+	var ctx fasthttp.RequestCtx
+	ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+	r.Handler(&ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+	if !bytes.Contains(ctx.Response.Body(), []byte(`"role":"user"`)) {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+}
+
+func TestRegisterRoutes_RoleRoutesUseRoleNameParameter(t *testing.T) {
+	initEnv(t, false, false, false)
+	cfg := globals.GetConfig()
+	cfg.Security.Authentication.Enabled = true
+	cfg.Security.Authentication.Mode = "user"
+
+	if err := security.CreateRole("editor", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := security.CreateSession("admin", security.RoleAdmin, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod("GET")
+	req.SetRequestURI("/api/security/roles/editor")
+	req.Header.SetCookie(security.SessionCookieName, session.ID)
+	var ctx fasthttp.RequestCtx
+	ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+	r.Handler(&ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+	if !bytes.Contains(ctx.Response.Body(), []byte(`"name":"editor"`)) {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+}
+
+func TestRegisterRoutes_AccessLog(t *testing.T) {
+	initEnv(t, false, false, false)
+	globals.GetConfig().Log.AccessLog = true
+
+	buf := &bytes.Buffer{}
+	log.Configure(log.LevelDebug, log.NewStreamSink(log.JSONEncoder{}, func() io.Writer { return buf }, false))
+	t.Cleanup(func() {
+		log.Configure(log.LevelDebug, log.NewStdoutSink(log.ConsoleEncoder{Color: true}, true))
+	})
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod("GET")
+	req.SetRequestURI("/api/books/missing")
+	req.Header.Set(log.RequestIDHeader, "req-42")
+	var ctx fasthttp.RequestCtx
+	ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+	r.Handler(&ctx)
+
+	if string(ctx.Response.Header.Peek(log.RequestIDHeader)) != "req-42" {
+		t.Fatalf("request id not echoed in response")
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("access log is not JSON: %v\n%s", err, buf.String())
+	}
+
+	if entry["msg"] != "http request" || entry["method"] != "GET" || entry["path"] != "/api/books/missing" {
+		t.Fatalf("unexpected access log entry: %v", entry)
+	}
+	if entry["status"] != float64(fasthttp.StatusNotFound) || entry["request_id"] != "req-42" || entry["entity"] != "books" {
+		t.Fatalf("unexpected access log fields: %v", entry)
+	}
+	if _, ok := entry["latency_ms"].(float64); !ok {
+		t.Fatalf("missing latency_ms: %v", entry)
+	}
+}
+
+func TestRegisterRoutes_Metrics(t *testing.T) {
+	initEnv(t, false, false, false)
+	globals.GetConfig().Metrics.Enabled = true
+	metrics.SetEnabled(true)
+	t.Cleanup(func() { metrics.SetEnabled(false) })
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	get := func(path string) *fasthttp.RequestCtx {
+		req := fasthttp.AcquireRequest()
+		req.Header.SetMethod("GET")
+		req.SetRequestURI(path)
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+		r.Handler(ctx)
+		return ctx
+	}
+
+	get("/api/books/missing")
+
+	ctx := get("/metrics")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+	if !bytes.HasPrefix(ctx.Response.Header.ContentType(), []byte("text/plain")) {
+		t.Fatalf("unexpected content type %s", ctx.Response.Header.ContentType())
+	}
+
+	body := string(ctx.Response.Body())
+	for _, want := range []string{
+		`elysiandb_http_requests_total{method="GET",route="/api/{entity}/{id}",status="404"} 1`,
+		`elysiandb_http_request_duration_seconds_count{method="GET",route="/api/{entity}/{id}",status="404"} 1`,
+		"# TYPE elysiandb_transactions_open gauge",
+		"# TYPE elysiandb_cache_hit_ratio gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %q in metrics output:\n%s", want, body)
+		}
+	}
+}
+
+func TestRegisterRoutes_MetricsDisabled(t *testing.T) {
+	initEnv(t, false, false, false)
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	if perform(r, "GET", "/metrics") {
+		t.Fatalf("/metrics should not be registered when metrics are disabled")
+	}
+}
+
+func TestRegisterRoutes_OpenAPI(t *testing.T) {
+	initEnv(t, false, true, false)
+	globals.GetConfig().Api.OpenAPI.Enabled = true
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod("GET")
+	req.SetRequestURI("/api/openapi.json")
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+	r.Handler(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if doc["openapi"] == nil || doc["paths"] == nil {
+		t.Fatalf("unexpected document: %s", ctx.Response.Body())
+	}
+}
+
+func TestRegisterRoutes_GraphQL(t *testing.T) {
+	initEnv(t, false, true, false)
+	globals.GetConfig().Api.GraphQL.Enabled = true
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod("POST")
+	req.SetRequestURI("/graphql")
+	req.SetBodyString(`{"query":"{ entityTypes }"}`)
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+	r.Handler(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+	if !strings.Contains(string(ctx.Response.Body()), `"entityTypes"`) {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+}
+
+func TestBasicMode_DefaultAdminChangesPasswordThenUsesTheAPI(t *testing.T) {
+	initEnv(t, false, false, false)
+	cfg := globals.GetConfig()
+	cfg.Security.Authentication.Enabled = true
+	cfg.Security.Authentication.Mode = "basic"
+
+	if err := security.InitAdminUserIfNotExists(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	call := func(method, path, password, body string) *fasthttp.RequestCtx {
+		req := fasthttp.AcquireRequest()
+		req.Header.SetMethod(method)
+		req.SetRequestURI(path)
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:"+password)))
+		req.SetBodyString(body)
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+		r.Handler(ctx)
+
+		return ctx
+	}
+
+	ctx := call("GET", "/api/books", security.DefaultAdminPassword, "")
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden || string(ctx.Response.Body()) != `{"error":"password change required"}` {
+		t.Fatalf("expected forced password change, got %d %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	ctx = call("PUT", "/api/security/user/admin/password", security.DefaultAdminPassword, `{"password":"N3w-Passw0rd!"}`)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("password change failed: %d %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	if ctx = call("GET", "/api/books", security.DefaultAdminPassword, ""); ctx.Response.StatusCode() != fasthttp.StatusUnauthorized {
+		t.Fatalf("old password should be rejected, got %d", ctx.Response.StatusCode())
+	}
+
+	if ctx = call("GET", "/api/books", "N3w-Passw0rd!", ""); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200 after the password change, got %d %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+}
+
+func TestRateLimit_PerIPLimitsRequestsBeforeAuthentication(t *testing.T) {
+	for _, mode := range []string{"token", "user"} {
+		initEnv(t, false, false, false)
+		cfg := globals.GetConfig()
+		cfg.Security.Authentication.Enabled = true
+		cfg.Security.Authentication.Mode = mode
+		cfg.Security.Authentication.Token = "secret"
+		cfg.Security.RateLimit.Enabled = true
+		cfg.Security.RateLimit.PerIP = configuration.RateConfig{RequestsPerSecond: 0.001, Burst: 2}
+		ratelimit.Limiter.Reset()
+
+		r := router.New()
+		routing.RegisterRoutes(r)
+
+		call := func(ip string) int {
+			req := fasthttp.AcquireRequest()
+			if mode == "token" {
+				req.Header.SetMethod("GET")
+				req.SetRequestURI("/api/books")
+				req.Header.Set("Authorization", "Bearer wrong")
+			} else {
+				req.Header.SetMethod("POST")
+				req.SetRequestURI("/api/security/login")
+				req.SetBodyString(`{"username":"admin","password":"wrong"}`)
+			}
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Init(req, &net.TCPAddr{IP: net.ParseIP(ip), Port: 12345}, nil)
+			r.Handler(ctx)
+
+			return ctx.Response.StatusCode()
+		}
+
+		for i := 0; i < 2; i++ {
+			if status := call("10.0.0.1"); status != fasthttp.StatusUnauthorized {
+				t.Fatalf("%s: expected 401, got %d", mode, status)
+			}
+		}
+
+		if status := call("10.0.0.1"); status != fasthttp.StatusTooManyRequests {
+			t.Fatalf("%s: expected 429 once the IP bucket is empty, got %d", mode, status)
+		}
+
+		if status := call("10.0.0.2"); status != fasthttp.StatusUnauthorized {
+			t.Fatalf("%s: other IPs must not be limited, got %d", mode, status)
+		}
+	}
+}
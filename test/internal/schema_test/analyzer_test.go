@@ -128,6 +128,37 @@ func TestAnalyzeEntitySchema_ArrayNested(t *testing.T) {
 	}
 }
 
+func TestAnalyzeEntitySchema_LinksRecordTargetEntity(t *testing.T) {
+	restore := patchStrict(false)
+	defer restore()
+
+	data := map[string]interface{}{
+		"author": map[string]interface{}{"@entity": "author", "id": "a1"},
+		"tags": []interface{}{
+			map[string]interface{}{"@entity": "tag", "id": "t1"},
+		},
+		"meta": map[string]interface{}{"pages": 10},
+	}
+
+	result := schema.AnalyzeEntitySchema("books", data)
+	fields := result["fields"].(map[string]interface{})
+
+	if fields["author"].(map[string]interface{})["entity"] != "author" {
+		t.Fatalf("expected author link, got %v", fields["author"])
+	}
+	if fields["tags"].(map[string]interface{})["entity"] != "tag" {
+		t.Fatalf("expected tag link, got %v", fields["tags"])
+	}
+	if _, ok := fields["meta"].(map[string]interface{})["entity"]; ok {
+		t.Fatalf("plain objects must not be links")
+	}
+
+	parsed := schema.MapToFields(fields)
+	if parsed["author"].Entity != "author" || parsed["tags"].Entity != "tag" {
+		t.Fatalf("link target lost on round trip: %+v", parsed)
+	}
+}
+
 func TestDetectJSONType(t *testing.T) {
 	tests := []struct {
 		in  interface{}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	api_storage "github.com/taymour/elysiandb/internal/api"
 	"github.com/taymour/elysiandb/internal/configuration"
@@ -611,3 +612,39 @@ func TestBasicHashedUser_fromDataMap_InvalidRole(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestChangeUserPassword_RevokesSessions(t *testing.T) {
+	setup(t)
+
+	u := &security.BasicUser{Username: "revoked", Password: "oldpwd", Role: security.RoleUser}
+	if err := security.CreateBasicUser(u); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := security.CreateSession("revoked", security.RoleUser, time.Hour)
+
+	if err := security.ChangeUserPassword("revoked", "newpwd"); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, _ := security.GetSession(s.ID); res != nil {
+		t.Fatal("session should be revoked after password change")
+	}
+}
+
+func TestDeleteBasicUser_RevokesSessions(t *testing.T) {
+	setup(t)
+
+	u := &security.BasicUser{Username: "gone", Password: "x", Role: security.RoleUser}
+	if err := security.CreateBasicUser(u); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := security.CreateSession("gone", security.RoleUser, time.Hour)
+
+	security.DeleteBasicUser("gone")
+
+	if res, _ := security.GetSession(s.ID); res != nil {
+		t.Fatal("session should be revoked after user deletion")
+	}
+}
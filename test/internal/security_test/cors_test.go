@@ -3,11 +3,34 @@ package security_test
 import (
 	"testing"
 
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
 	"github.com/taymour/elysiandb/internal/security"
 	"github.com/valyala/fasthttp"
 )
 
+func setCORSConfig(cors configuration.CORSConfig) {
+	cfg := &configuration.Config{}
+	cfg.Server.HTTP.CORS = cors
+	globals.SetConfig(cfg)
+}
+
+func defaultCORSConfig() configuration.CORSConfig {
+	return configuration.CORSConfig{
+		CORSPolicyConfig: configuration.CORSPolicyConfig{
+			AllowedOrigins:   []string{"http://example.com", "https://*.example.org"},
+			AllowCredentials: true,
+			MaxAgeSeconds:    600,
+		},
+		Routes: []configuration.CORSRouteConfig{
+			{Path: "/admin"},
+		},
+	}
+}
+
 func TestCORSWithOrigin(t *testing.T) {
+	setCORSConfig(defaultCORSConfig())
+
 	called := false
 	next := func(ctx *fasthttp.RequestCtx) {
 		called = true
@@ -31,12 +54,56 @@ func TestCORSWithOrigin(t *testing.T) {
 	if string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")) != "true" {
 		t.Fatalf("credentials header not set")
 	}
-	if string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")) == "" {
-		t.Fatalf("methods header missing")
+	if string(ctx.Response.Header.Peek("Access-Control-Expose-Headers")) != "X-Elysian-Version, X-Elysian-Cache" {
+		t.Fatalf("expose headers not set: %s", ctx.Response.Header.Peek("Access-Control-Expose-Headers"))
+	}
+}
+
+func TestCORSUnknownOrigin(t *testing.T) {
+	setCORSConfig(defaultCORSConfig())
+
+	called := false
+	h := security.CORS(func(ctx *fasthttp.RequestCtx) {
+		called = true
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Origin", "http://evil.com")
+	ctx.Request.Header.SetMethod("GET")
+
+	h(ctx)
+
+	if !called {
+		t.Fatalf("next handler not called")
+	}
+
+	if v := ctx.Response.Header.Peek("Access-Control-Allow-Origin"); v != nil {
+		t.Fatalf("origin header should not be set for unknown origin")
+	}
+	if v := ctx.Response.Header.Peek("Access-Control-Allow-Credentials"); v != nil {
+		t.Fatalf("credentials header should not be set for unknown origin")
+	}
+}
+
+func TestCORSNoConfigDeniesCrossOrigin(t *testing.T) {
+	setCORSConfig(configuration.CORSConfig{})
+
+	h := security.CORS(func(ctx *fasthttp.RequestCtx) {})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Origin", "http://example.com")
+	ctx.Request.Header.SetMethod("GET")
+
+	h(ctx)
+
+	if v := ctx.Response.Header.Peek("Access-Control-Allow-Origin"); v != nil {
+		t.Fatalf("origin header should not be set without configuration")
 	}
 }
 
 func TestCORSNoOrigin(t *testing.T) {
+	setCORSConfig(defaultCORSConfig())
+
 	called := false
 	next := func(ctx *fasthttp.RequestCtx) {
 		called = true
@@ -59,6 +126,8 @@ func TestCORSNoOrigin(t *testing.T) {
 }
 
 func TestCORSOptions(t *testing.T) {
+	setCORSConfig(defaultCORSConfig())
+
 	called := false
 	next := func(ctx *fasthttp.RequestCtx) {
 		called = true
@@ -69,6 +138,7 @@ func TestCORSOptions(t *testing.T) {
 	ctx := &fasthttp.RequestCtx{}
 	ctx.Request.Header.SetMethod("OPTIONS")
 	ctx.Request.Header.Set("Origin", "http://example.com")
+	ctx.Request.Header.Set("Access-Control-Request-Method", "PATCH")
 
 	h(ctx)
 
@@ -79,4 +149,99 @@ func TestCORSOptions(t *testing.T) {
 	if ctx.Response.StatusCode() != 204 {
 		t.Fatalf("unexpected status code: %d", ctx.Response.StatusCode())
 	}
+	if string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")) != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+		t.Fatalf("unexpected methods header: %s", ctx.Response.Header.Peek("Access-Control-Allow-Methods"))
+	}
+	if string(ctx.Response.Header.Peek("Access-Control-Max-Age")) != "600" {
+		t.Fatalf("max age header not set")
+	}
+}
+
+func TestCORSOptionsRejectsDisallowedMethod(t *testing.T) {
+	cors := defaultCORSConfig()
+	cors.AllowedMethods = []string{"GET"}
+	setCORSConfig(cors)
+
+	h := security.CORS(func(ctx *fasthttp.RequestCtx) {})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("OPTIONS")
+	ctx.Request.Header.Set("Origin", "http://example.com")
+	ctx.Request.Header.Set("Access-Control-Request-Method", "DELETE")
+
+	h(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestCORSRouteOverride(t *testing.T) {
+	setCORSConfig(defaultCORSConfig())
+
+	h := security.CORS(func(ctx *fasthttp.RequestCtx) {})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/admin/users")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.Header.Set("Origin", "http://example.com")
+
+	h(ctx)
+
+	if v := ctx.Response.Header.Peek("Access-Control-Allow-Origin"); v != nil {
+		t.Fatalf("admin route should not allow cross origin requests")
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/administrator")
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.Header.Set("Origin", "http://example.com")
+
+	h(ctx)
+
+	if string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) != "http://example.com" {
+		t.Fatalf("override must only match whole path segments")
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	setCORSConfig(configuration.CORSConfig{
+		CORSPolicyConfig: configuration.CORSPolicyConfig{AllowedOrigins: []string{"*"}},
+	})
+
+	h := security.CORS(func(ctx *fasthttp.RequestCtx) {})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.Header.Set("Origin", "http://anything.test")
+
+	h(ctx)
+
+	if string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) != "*" {
+		t.Fatalf("expected wildcard allow origin")
+	}
+	if v := ctx.Response.Header.Peek("Access-Control-Allow-Credentials"); v != nil {
+		t.Fatalf("credentials must not be allowed with wildcard origin")
+	}
+}
+
+func TestOriginIsAllowed(t *testing.T) {
+	allowed := []string{"https://app.example.com", "https://*.example.org"}
+
+	cases := map[string]bool{
+		"https://app.example.com":       true,
+		"https://APP.example.com":       true,
+		"http://app.example.com":        false,
+		"https://a.example.org":         true,
+		"https://a.b.example.org":       true,
+		"https://example.org":           false,
+		"https://evil.com/.example.org": false,
+		"https://evilexample.org":       false,
+	}
+
+	for origin, expected := range cases {
+		if got := security.OriginIsAllowed(allowed, origin); got != expected {
+			t.Errorf("OriginIsAllowed(%q) = %v, expected %v", origin, got, expected)
+		}
+	}
 }
@@ -0,0 +1,448 @@
+package security_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func jwtSigningInput(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header := map[string]any{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+	}
+
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+
+	return b64(h) + "." + b64(c)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+
+	input := jwtSigningInput(t, "HS256", "", claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+
+	return input + "." + b64(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	input := jwtSigningInput(t, "RS256", kid, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return input + "." + b64(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	input := jwtSigningInput(t, "ES256", kid, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return input + "." + b64(sig)
+}
+
+func rsaJWKS(kid string, key *rsa.PublicKey) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   b64(key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+
+	return b
+}
+
+func ecJWKS(kid string, key *ecdsa.PublicKey) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"keys": []map[string]any{{
+			"kty": "EC",
+			"kid": kid,
+			"crv": "P-256",
+			"x":   b64(key.X.FillBytes(make([]byte, 32))),
+			"y":   b64(key.Y.FillBytes(make([]byte, 32))),
+		}},
+	})
+
+	return b
+}
+
+func setupJWT(jwt configuration.JWTConfig) {
+	cfg := &configuration.Config{}
+	cfg.Security.Authentication.Enabled = true
+	cfg.Security.Authentication.Mode = "jwt"
+	cfg.Security.Authentication.JWT = jwt
+	globals.SetConfig(cfg)
+}
+
+func TestParseJWT_HS256(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "s3cret", map[string]any{
+		"sub":  "alice",
+		"role": "admin",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	})
+
+	p, err := security.ParseJWT(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.Username != "alice" || p.Role != security.RoleAdmin {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+}
+
+func TestParseJWT_HS256_WrongSecret(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "other", map[string]any{"sub": "alice"})
+
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("expected signature error")
+	}
+}
+
+func TestParseJWT_Expired(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "s3cret", map[string]any{
+		"sub": "alice",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("expected expiration error")
+	}
+}
+
+func TestParseJWT_MissingExpiration(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "s3cret", map[string]any{"sub": "alice", "exp": nil})
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("expected missing expiration error")
+	}
+}
+
+func TestParseJWT_NotBefore(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "s3cret", map[string]any{
+		"sub": "alice",
+		"nbf": time.Now().Add(time.Hour).Unix(),
+	})
+
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("expected nbf error")
+	}
+}
+
+func TestParseJWT_IssuerAndAudience(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret", Issuer: "idp", Audience: "elysian"})
+
+	ok := signHS256(t, "s3cret", map[string]any{"sub": "a", "iss": "idp", "aud": []any{"x", "elysian"}})
+	if _, err := security.ParseJWT(ok); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	badIss := signHS256(t, "s3cret", map[string]any{"sub": "a", "iss": "evil", "aud": "elysian"})
+	if _, err := security.ParseJWT(badIss); err == nil {
+		t.Fatalf("expected issuer error")
+	}
+
+	badAud := signHS256(t, "s3cret", map[string]any{"sub": "a", "iss": "idp", "aud": "other"})
+	if _, err := security.ParseJWT(badAud); err == nil {
+		t.Fatalf("expected audience error")
+	}
+}
+
+func TestParseJWT_MissingUsernameClaim(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "s3cret", map[string]any{"role": "admin"})
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("expected missing claim error")
+	}
+}
+
+func TestParseJWT_CustomClaims(t *testing.T) {
+	setupJWT(configuration.JWTConfig{
+		Secret:        "s3cret",
+		UsernameClaim: "preferred_username",
+		RoleClaim:     "realm_access.roles",
+		AdminRoles:    []string{"db-admin"},
+	})
+
+	admin := signHS256(t, "s3cret", map[string]any{
+		"preferred_username": "bob",
+		"realm_access":       map[string]any{"roles": []any{"viewer", "db-admin"}},
+	})
+
+	p, err := security.ParseJWT(admin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.Username != "bob" || p.Role != security.RoleAdmin {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+
+	user := signHS256(t, "s3cret", map[string]any{
+		"preferred_username": "carol",
+		"realm_access":       map[string]any{"roles": []any{"admin"}},
+	})
+
+	p, err = security.ParseJWT(user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.Role != security.RoleUser {
+		t.Fatalf("expected user role, got %s", p.Role)
+	}
+}
+
+func TestParseJWT_MapsRoleClaimToCustomRoles(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+	_ = security.CreateRole("editor", "")
+	_ = security.CreateRole("auditor", "")
+
+	setupJWT(configuration.JWTConfig{
+		Secret:     "s3cret",
+		RoleClaim:  "realm_access.roles",
+		AdminRoles: []string{"db-admin"},
+	})
+
+	cases := []struct {
+		roles []any
+		want  security.Role
+	}{
+		{[]any{"viewer", "editor", "auditor"}, "editor"},
+		{[]any{"editor", "db-admin"}, security.RoleAdmin},
+		{[]any{"admin", "viewer"}, security.RoleUser},
+		{[]any{"ghost"}, security.RoleUser},
+	}
+
+	for _, c := range cases {
+		token := signHS256(t, "s3cret", map[string]any{
+			"sub":          "bob",
+			"realm_access": map[string]any{"roles": c.roles},
+		})
+
+		p, err := security.ParseJWT(token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if p.Role != c.want {
+			t.Fatalf("roles %v: expected %s, got %s", c.roles, c.want, p.Role)
+		}
+	}
+}
+
+func TestParseJWT_RejectsUnsupportedAlgorithm(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := jwtSigningInput(t, "none", "", map[string]any{"sub": "alice"}) + "."
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("expected unsupported algorithm error")
+	}
+}
+
+func TestParseJWT_RS256_JWKSFile(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	_ = os.WriteFile(path, rsaJWKS("k1", &key.PublicKey), 0o644)
+
+	setupJWT(configuration.JWTConfig{JWKSFile: path})
+
+	token := signRS256(t, key, "k1", map[string]any{"sub": "dave"})
+	p, err := security.ParseJWT(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.Username != "dave" || p.Role != security.RoleUser {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged := signRS256(t, other, "k1", map[string]any{"sub": "dave"})
+	if _, err := security.ParseJWT(forged); err == nil {
+		t.Fatalf("expected signature error")
+	}
+}
+
+func TestParseJWT_HS256_RejectedWithoutSecret(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	_ = os.WriteFile(path, rsaJWKS("k1", &key.PublicKey), 0o644)
+
+	setupJWT(configuration.JWTConfig{JWKSFile: path})
+
+	token := signHS256(t, "", map[string]any{"sub": "dave"})
+	if _, err := security.ParseJWT(token); err == nil {
+		t.Fatalf("HS256 token must be rejected when only JWKS is configured")
+	}
+}
+
+func TestParseJWT_ES256_JWKSURL(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(ecJWKS("ec1", &key.PublicKey))
+	}))
+	defer srv.Close()
+
+	setupJWT(configuration.JWTConfig{JWKSURL: srv.URL})
+
+	token := signES256(t, key, "ec1", map[string]any{"sub": "erin", "role": "admin"})
+	p, err := security.ParseJWT(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if p.Username != "erin" || p.Role != security.RoleAdmin {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+
+	unknownKid := signES256(t, key, "missing", map[string]any{"sub": "erin"})
+	if _, err := security.ParseJWT(unknownKid); err == nil {
+		t.Fatalf("expected unknown kid error")
+	}
+}
+
+func TestParseJWKS_UnsupportedKey(t *testing.T) {
+	if _, err := security.ParseJWKS([]byte(`{"keys":[{"kty":"oct","kid":"x"}]}`)); err == nil {
+		t.Fatalf("expected unsupported key type error")
+	}
+}
+
+func TestParseJWKS_SkipsUnsupportedKeys(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	var set map[string][]map[string]any
+	_ = json.Unmarshal(rsaJWKS("k1", &key.PublicKey), &set)
+	set["keys"] = append(set["keys"],
+		map[string]any{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "AA"},
+		map[string]any{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "AA", "y": "AA"},
+	)
+	raw, _ := json.Marshal(set)
+
+	keys, err := security.ParseJWKS(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(keys) != 1 || keys["k1"] == nil {
+		t.Fatalf("expected only the RSA key, got %v", keys)
+	}
+}
+
+func TestAuthenticate_JWT(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	token := signHS256(t, "s3cret", map[string]any{"sub": "frank", "role": "user"})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+
+	called := false
+	security.Authenticate(func(c *fasthttp.RequestCtx) {
+		called = true
+	})(ctx)
+
+	if !called {
+		t.Fatalf("handler should be called with a valid token")
+	}
+
+	if ctx.UserValue("username") != "frank" || security.CurrentPrincipal(ctx).Username != "frank" {
+		t.Fatalf("principal not propagated")
+	}
+
+	if security.CurrentPrincipal(ctx).Role != security.RoleUser || security.CurrentUserIsAdmin(ctx) {
+		t.Fatalf("expected user role")
+	}
+}
+
+func TestAuthenticate_JWT_Fail(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Authorization", "Bearer not.a.token")
+
+	called := false
+	security.Authenticate(func(c *fasthttp.RequestCtx) {
+		called = true
+	})(ctx)
+
+	if called {
+		t.Fatalf("handler should not be called with an invalid token")
+	}
+
+	if ctx.Response.StatusCode() != fasthttp.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestCurrentUserIsAdmin_JWT(t *testing.T) {
+	setupJWT(configuration.JWTConfig{Secret: "s3cret"})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetUserValue("role", security.RoleAdmin)
+
+	if !security.CurrentUserIsAdmin(ctx) {
+		t.Fatalf("expected admin")
+	}
+}
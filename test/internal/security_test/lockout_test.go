@@ -0,0 +1,151 @@
+package security_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+func setupLockout(t *testing.T, maxAttempts, base, max int) {
+	t.Helper()
+	setup(t)
+
+	cfg := globals.GetConfig()
+	cfg.Security.Lockout.Enabled = true
+	cfg.Security.Lockout.MaxAttempts = maxAttempts
+	cfg.Security.Lockout.BaseDelaySeconds = base
+	cfg.Security.Lockout.MaxDelaySeconds = max
+
+	security.Lockouts.Reset()
+	t.Cleanup(security.Lockouts.Reset)
+}
+
+func TestLockout_Disabled(t *testing.T) {
+	setup(t)
+	security.Lockouts.Reset()
+
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		if err := security.Lockouts.Fail("bob", now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if security.Lockouts.IsLocked("bob", now) {
+		t.Fatal("lockout should be disabled")
+	}
+}
+
+func TestLockout_ExponentialBackoff(t *testing.T) {
+	setupLockout(t, 3, 10, 35)
+
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if err := security.Lockouts.Fail("bob", now); err != nil {
+			t.Fatalf("unexpected lock after %d failures", i+1)
+		}
+	}
+
+	var locked *security.AccountLockedError
+	if err := security.Lockouts.Fail("bob", now); !errors.As(err, &locked) {
+		t.Fatalf("expected lock, got %v", err)
+	}
+	if got := locked.RetryAfter(now); got != 10 {
+		t.Fatalf("expected 10s lock, got %d", got)
+	}
+
+	if !security.Lockouts.IsLocked("bob", now.Add(9*time.Second)) {
+		t.Fatal("expected account to still be locked")
+	}
+	if security.Lockouts.IsLocked("bob", now.Add(10*time.Second)) {
+		t.Fatal("expected lock to expire")
+	}
+
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 2; i++ {
+		_ = security.Lockouts.Fail("bob", now)
+	}
+	if err := security.Lockouts.Fail("bob", now); !errors.As(err, &locked) || locked.RetryAfter(now) != 20 {
+		t.Fatalf("expected 20s lock, got %v", err)
+	}
+
+	now = now.Add(20 * time.Second)
+	for i := 0; i < 2; i++ {
+		_ = security.Lockouts.Fail("bob", now)
+	}
+	if err := security.Lockouts.Fail("bob", now); !errors.As(err, &locked) || locked.RetryAfter(now) != 35 {
+		t.Fatalf("expected lock capped at 35s, got %v", err)
+	}
+}
+
+func TestLoginUser_LocksAfterFailedAttempts(t *testing.T) {
+	setupLockout(t, 2, 60, 0)
+
+	if err := security.CreateBasicUser(&security.BasicUser{Username: "bob", Password: "secret", Role: security.RoleUser}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := security.LoginUser("bob", "wrong"); !errors.Is(err, security.ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+
+	var locked *security.AccountLockedError
+	if _, err := security.LoginUser("bob", "wrong"); !errors.As(err, &locked) {
+		t.Fatalf("expected lock, got %v", err)
+	}
+
+	if _, err := security.LoginUser("bob", "secret"); !errors.As(err, &locked) {
+		t.Fatalf("correct password should be rejected while locked, got %v", err)
+	}
+
+	if _, ok := security.AuthenticateUser("bob", "secret"); ok {
+		t.Fatal("AuthenticateUser should fail while locked")
+	}
+
+	if err := security.UnlockUser("bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := security.LoginUser("bob", "secret"); err != nil {
+		t.Fatalf("expected login after unlock, got %v", err)
+	}
+}
+
+func TestLoginUser_SuccessResetsFailures(t *testing.T) {
+	setupLockout(t, 2, 60, 0)
+
+	if err := security.CreateBasicUser(&security.BasicUser{Username: "bob", Password: "secret", Role: security.RoleUser}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = security.LoginUser("bob", "wrong")
+	if _, err := security.LoginUser("bob", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := security.LoginUser("bob", "wrong"); !errors.Is(err, security.ErrInvalidCredentials) {
+		t.Fatalf("failures should have been reset, got %v", err)
+	}
+}
+
+func TestLoginUser_UnknownUserIsNotTracked(t *testing.T) {
+	setupLockout(t, 1, 60, 0)
+
+	for i := 0; i < 3; i++ {
+		if _, err := security.LoginUser("ghost", "x"); !errors.Is(err, security.ErrInvalidCredentials) {
+			t.Fatalf("expected invalid credentials, got %v", err)
+		}
+	}
+}
+
+func TestUnlockUser_NotFound(t *testing.T) {
+	setup(t)
+
+	if err := security.UnlockUser("ghost"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,211 @@
+package security_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/valyala/fasthttp"
+)
+
+func setPasswordPolicy(policy configuration.PasswordPolicyConfig) {
+	globals.GetConfig().Security.Password = policy
+}
+
+func TestValidatePassword_NoPolicy(t *testing.T) {
+	setup(t)
+
+	if err := security.ValidatePassword("x"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidatePassword_Rules(t *testing.T) {
+	setup(t)
+	setPasswordPolicy(configuration.PasswordPolicyConfig{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	})
+
+	cases := map[string]bool{
+		"Ab1!":        false,
+		"abcdefg1!":   false,
+		"ABCDEFG1!":   false,
+		"Abcdefgh!":   false,
+		"Abcdefgh1":   false,
+		"Abcdefg1!":   true,
+		"Pässwörd9_x": true,
+	}
+
+	for password, valid := range cases {
+		err := security.ValidatePassword(password)
+		if valid && err != nil {
+			t.Fatalf("expected %q to be valid, got %v", password, err)
+		}
+		if !valid && err == nil {
+			t.Fatalf("expected %q to be rejected", password)
+		}
+	}
+}
+
+func TestCreateBasicUser_RejectsWeakPassword(t *testing.T) {
+	setup(t)
+	setPasswordPolicy(configuration.PasswordPolicyConfig{MinLength: 10})
+
+	err := security.CreateBasicUser(&security.BasicUser{Username: "weak", Password: "short", Role: security.RoleUser})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if api_storage.ReadEntityById(security.UserEntity, "weak") != nil {
+		t.Fatal("user should not be created")
+	}
+}
+
+func TestChangeUserPassword_RejectsWeakPassword(t *testing.T) {
+	setup(t)
+
+	if err := security.CreateBasicUser(&security.BasicUser{Username: "john", Password: "old", Role: security.RoleUser}); err != nil {
+		t.Fatal(err)
+	}
+
+	setPasswordPolicy(configuration.PasswordPolicyConfig{MinLength: 10})
+
+	if err := security.ChangeUserPassword("john", "short"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if _, ok := security.AuthenticateUser("john", "old"); !ok {
+		t.Fatal("old password should still be valid")
+	}
+}
+
+func TestInitAdminUserIfNotExists_FlagsDefaultAdmin(t *testing.T) {
+	setup(t)
+	setPasswordPolicy(configuration.PasswordPolicyConfig{MinLength: 10})
+
+	if err := security.InitAdminUserIfNotExists(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !security.UserMustChangePassword(security.DefaultAdminUsername) {
+		t.Fatal("default admin should be required to change password")
+	}
+
+	if err := security.ChangeUserPassword(security.DefaultAdminUsername, "a-much-longer-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if security.UserMustChangePassword(security.DefaultAdminUsername) {
+		t.Fatal("flag should be cleared after password change")
+	}
+
+	if err := security.InitAdminUserIfNotExists(); err != nil {
+		t.Fatal(err)
+	}
+
+	if security.UserMustChangePassword(security.DefaultAdminUsername) {
+		t.Fatal("flag should not be set again once the password was changed")
+	}
+}
+
+func TestInitAdminUserIfNotExists_FlagsExistingAdminWithDefaultPassword(t *testing.T) {
+	setup(t)
+
+	u := &security.BasicUser{Username: security.DefaultAdminUsername, Password: security.DefaultAdminPassword, Role: security.RoleAdmin}
+	if err := security.CreateBasicUser(u); err != nil {
+		t.Fatal(err)
+	}
+
+	if security.UserMustChangePassword(security.DefaultAdminUsername) {
+		t.Fatal("flag should not be set yet")
+	}
+
+	if err := security.InitAdminUserIfNotExists(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !security.UserMustChangePassword(security.DefaultAdminUsername) {
+		t.Fatal("existing admin with default password should be flagged")
+	}
+}
+
+func TestPasswordChangeRequired_AllowedPaths(t *testing.T) {
+	setup(t)
+
+	u := &security.BasicUser{Username: "bob", Password: "x", Role: security.RoleUser, MustChangePassword: true}
+	if err := security.CreateBasicUser(u); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		method   string
+		path     string
+		required bool
+	}{
+		{"GET", "/api/articles", true},
+		{"GET", "/api/security/me", false},
+		{"POST", "/api/security/logout", false},
+		{"PUT", "/api/security/user/bob/password", false},
+		{"PUT", "/api/security/user/alice/password", true},
+		{"PUT", "/api/security/user/bob/role", true},
+	}
+
+	for _, c := range cases {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(c.method)
+		ctx.Request.SetRequestURI(c.path)
+
+		if got := security.PasswordChangeRequired(ctx, "bob"); got != c.required {
+			t.Fatalf("%s %s: expected %v, got %v", c.method, c.path, c.required, got)
+		}
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/articles")
+	if security.PasswordChangeRequired(ctx, "nobody") {
+		t.Fatal("unknown users should not be flagged")
+	}
+}
+
+func TestAuthenticate_BasicAuthRequiresPasswordChange(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "basic"
+
+	u := &security.BasicUser{Username: "bob", Password: "secret", Role: security.RoleUser, MustChangePassword: true}
+	if err := security.CreateBasicUser(u); err != nil {
+		t.Fatal(err)
+	}
+
+	header := "Basic " + base64.StdEncoding.EncodeToString([]byte("bob:secret"))
+
+	for path, required := range map[string]bool{
+		"/api/articles":                   true,
+		"/api/security/user/bob/password": false,
+	} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(fasthttp.MethodPut)
+		ctx.Request.Header.Set("Authorization", header)
+		ctx.Request.SetRequestURI(path)
+
+		called := false
+		security.Authenticate(func(c *fasthttp.RequestCtx) {
+			called = true
+		})(ctx)
+
+		if called == required {
+			t.Fatalf("%s: expected the handler to be called: %v", path, !required)
+		}
+
+		if required && (ctx.Response.StatusCode() != fasthttp.StatusForbidden || string(ctx.Response.Body()) != `{"error":"password change required"}`) {
+			t.Fatalf("%s: expected 403 password change required, got %d %s", path, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+	}
+}
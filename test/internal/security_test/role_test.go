@@ -0,0 +1,163 @@
+package security_test
+
+import (
+	"reflect"
+	"testing"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/security"
+)
+
+func TestCreateRole(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+
+	if err := security.CreateRole("editor", "Edits content"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := security.CreateRole("editor", ""); err == nil {
+		t.Fatalf("expected duplicate role error")
+	}
+
+	if err := security.CreateRole(string(security.RoleAdmin), ""); err == nil {
+		t.Fatalf("expected builtin role error")
+	}
+
+	if !security.RoleExists("editor") || security.RoleExists("ghost") {
+		t.Fatalf("unexpected RoleExists result")
+	}
+
+	roles := security.ListRoles()
+	if len(roles) != 3 || roles[2]["name"] != "editor" || roles[2]["builtin"] != false {
+		t.Fatalf("unexpected roles %v", roles)
+	}
+}
+
+func TestCreateBasicUser_RequiresExistingRole(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+
+	if err := security.CreateBasicUser(&security.BasicUser{Username: "a", Password: "p", Role: "editor"}); err == nil {
+		t.Fatalf("expected error for unknown role")
+	}
+
+	_ = security.CreateRole("editor", "")
+
+	if err := security.CreateBasicUser(&security.BasicUser{Username: "a", Password: "p", Role: "editor"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := security.ChangeUserRole("a", "ghost"); err == nil {
+		t.Fatalf("expected error when changing to unknown role")
+	}
+}
+
+func TestDeleteRole(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+
+	_ = security.CreateRole("editor", "")
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "a", Password: "p", Role: "editor"})
+
+	if err := security.DeleteRole("editor"); err == nil {
+		t.Fatalf("expected error while role is assigned")
+	}
+
+	if err := security.DeleteRole(string(security.RoleUser)); err == nil {
+		t.Fatalf("expected error for builtin role")
+	}
+
+	_ = security.ChangeUserRole("a", string(security.RoleUser))
+
+	if err := security.DeleteRole("editor"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if security.RoleExists("editor") {
+		t.Fatalf("role should be deleted")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+
+	if err := security.CreateGroup("sales", "Sales team"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = security.CreateGroup("ops", "")
+
+	if err := security.CreateGroup("sales", ""); err == nil {
+		t.Fatalf("expected duplicate group error")
+	}
+
+	_ = security.AddUserToGroup("sales", "bob")
+	_ = security.AddUserToGroup("sales", "alice")
+	_ = security.AddUserToGroup("sales", "bob")
+	_ = security.AddUserToGroup("ops", "bob")
+
+	group, _ := security.GetGroup("sales")
+	if !reflect.DeepEqual(group["members"], []string{"alice", "bob"}) {
+		t.Fatalf("unexpected members %v", group["members"])
+	}
+
+	if groups := security.GroupsForUser("bob"); !reflect.DeepEqual(groups, []string{"ops", "sales"}) {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+
+	_ = security.RemoveUserFromGroup("sales", "bob")
+	if groups := security.GroupsForUser("bob"); !reflect.DeepEqual(groups, []string{"ops"}) {
+		t.Fatalf("unexpected groups after removal %v", groups)
+	}
+
+	if err := security.AddUserToGroup("missing", "bob"); err == nil {
+		t.Fatalf("expected error for unknown group")
+	}
+}
+
+func TestDeleteBasicUser_RemovesGroupMembership(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "bob", Password: "p", Role: security.RoleUser})
+	_ = security.CreateGroup("sales", "")
+	_ = security.AddUserToGroup("sales", "bob")
+
+	security.DeleteBasicUser("bob")
+
+	if groups := security.GroupsForUser("bob"); len(groups) != 0 {
+		t.Fatalf("expected no groups, got %v", groups)
+	}
+}
+
+func TestGroupsForUser_FollowsGroupChanges(t *testing.T) {
+	setup(t)
+	api_storage.DeleteAll()
+
+	_ = security.CreateGroup("sales", "")
+	_ = security.CreateGroup("ops", "")
+	_ = security.AddUserToGroup("sales", "bob")
+	_ = security.AddUserToGroup("ops", "bob")
+	_ = security.AddUserToGroup("ops", "alice")
+
+	if groups := security.GroupsForUser("alice"); !reflect.DeepEqual(groups, []string{"ops"}) {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+
+	if err := security.DeleteGroup("ops"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if groups := security.GroupsForUser("bob"); !reflect.DeepEqual(groups, []string{"sales"}) {
+		t.Fatalf("unexpected groups %v", groups)
+	}
+	if groups := security.GroupsForUser("alice"); len(groups) != 0 {
+		t.Fatalf("expected no groups, got %v", groups)
+	}
+
+	_ = security.CreateGroup("ops", "")
+	if groups := security.GroupsForUser("alice"); len(groups) != 0 {
+		t.Fatalf("recreated group must not restore membership, got %v", groups)
+	}
+}
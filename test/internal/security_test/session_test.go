@@ -1,6 +1,7 @@
 package security_test
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -34,10 +35,23 @@ func writeSessions(t *testing.T, dir string, sf security.SessionsFile) {
 	_ = os.WriteFile(filepath.Join(dir, security.SessionsFilename), b, 0o644)
 }
 
-func TestSetAndGetCurrentUsername(t *testing.T) {
-	security.SetCurrentUsername("alice")
-	if security.GetCurrentUsername() != "alice" {
-		t.Fatalf("expected alice")
+func TestCurrentPrincipalIsScopedToTheRequest(t *testing.T) {
+	alice := &fasthttp.RequestCtx{}
+	bob := &fasthttp.RequestCtx{}
+
+	security.SetCurrentPrincipal(alice, &security.Principal{Username: "alice", Role: security.RoleAdmin})
+	security.SetCurrentPrincipal(bob, &security.Principal{Username: "bob", Role: security.RoleUser})
+
+	if p := security.CurrentPrincipal(alice); p.Username != "alice" || p.Role != security.RoleAdmin {
+		t.Fatalf("unexpected principal for alice: %+v", p)
+	}
+
+	if p := security.CurrentPrincipal(bob); p.Username != "bob" || p.Role != security.RoleUser {
+		t.Fatalf("unexpected principal for bob: %+v", p)
+	}
+
+	if p := security.CurrentPrincipal(context.Background()); p.Username != "" || p.Role != "" {
+		t.Fatalf("expected an anonymous principal, got %+v", p)
 	}
 }
 
@@ -55,6 +69,10 @@ func TestCreateSession(t *testing.T) {
 		t.Fatalf("session id not generated")
 	}
 
+	if err := security.FlushSessions(); err != nil {
+		t.Fatalf("flush sessions: %v", err)
+	}
+
 	data, _ := os.ReadFile(filepath.Join(dir, security.SessionsFilename))
 	var sf security.SessionsFile
 	_ = json.Unmarshal(data, &sf)
@@ -79,6 +97,10 @@ func TestCreateSession_CleansExpired(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if err := security.FlushSessions(); err != nil {
+		t.Fatalf("flush sessions: %v", err)
+	}
+
 	data, _ := os.ReadFile(filepath.Join(dir, security.SessionsFilename))
 	var sf security.SessionsFile
 	_ = json.Unmarshal(data, &sf)
@@ -126,6 +148,10 @@ func TestGetSessionExpired(t *testing.T) {
 		t.Fatalf("expired session should be nil")
 	}
 
+	if err := security.FlushSessions(); err != nil {
+		t.Fatalf("flush sessions: %v", err)
+	}
+
 	data, _ := os.ReadFile(filepath.Join(dir, security.SessionsFilename))
 	var sf security.SessionsFile
 	_ = json.Unmarshal(data, &sf)
@@ -150,6 +176,10 @@ func TestDeleteSession(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
+	if err := security.FlushSessions(); err != nil {
+		t.Fatalf("flush sessions: %v", err)
+	}
+
 	data, _ := os.ReadFile(filepath.Join(dir, security.SessionsFilename))
 	var sf security.SessionsFile
 	_ = json.Unmarshal(data, &sf)
@@ -168,6 +198,10 @@ func TestDeleteSessionNonExisting(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
+	if err := security.FlushSessions(); err != nil {
+		t.Fatalf("flush sessions: %v", err)
+	}
+
 	data, _ := os.ReadFile(filepath.Join(dir, security.SessionsFilename))
 	var sf security.SessionsFile
 	_ = json.Unmarshal(data, &sf)
@@ -269,3 +303,236 @@ func TestCurrentUserCanManageUser_Other(t *testing.T) {
 		t.Fatalf("user should manage other user")
 	}
 }
+
+func TestGetSession_SlidingExpiration(t *testing.T) {
+	dir := setupTempStore(t)
+
+	now := time.Now().Unix()
+	s := security.Session{
+		ID:        "sliding",
+		Username:  "user",
+		Role:      security.RoleUser,
+		CreatedAt: now - 100,
+		ExpiresAt: now + 10,
+		TTL:       3600,
+	}
+	writeSessions(t, dir, security.SessionsFile{Sessions: []security.Session{s}})
+
+	res, err := security.GetSession("sliding")
+	if err != nil || res == nil {
+		t.Fatalf("session not returned: %v", err)
+	}
+
+	if res.ExpiresAt < now+3600 {
+		t.Fatalf("expiration not extended: %d", res.ExpiresAt)
+	}
+
+	if res.LastSeenAt < now {
+		t.Fatalf("last seen not updated")
+	}
+}
+
+func TestGetSession_SlidingExpirationCappedByMaxLifetime(t *testing.T) {
+	dir := setupTempStore(t)
+	globals.GetConfig().Security.Session.MaxLifetimeSeconds = 200
+
+	now := time.Now().Unix()
+	s := security.Session{
+		ID:        "capped",
+		Username:  "user",
+		Role:      security.RoleUser,
+		CreatedAt: now - 100,
+		ExpiresAt: now + 10,
+		TTL:       3600,
+	}
+	writeSessions(t, dir, security.SessionsFile{Sessions: []security.Session{s}})
+
+	res, _ := security.GetSession("capped")
+	if res == nil {
+		t.Fatalf("session not returned")
+	}
+
+	if res.ExpiresAt != s.CreatedAt+200 {
+		t.Fatalf("expected expiration capped at %d, got %d", s.CreatedAt+200, res.ExpiresAt)
+	}
+}
+
+func TestSessions_PersistedAndReloaded(t *testing.T) {
+	dir := setupTempStore(t)
+
+	s, _ := security.CreateSession("persisted", security.RoleUser, time.Hour)
+	if err := security.FlushSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	setupTempStore(t)
+	if res, _ := security.GetSession(s.ID); res != nil {
+		t.Fatalf("session leaked into another store")
+	}
+
+	cfg := globals.GetConfig()
+	cfg.Store.Folder = dir
+
+	res, err := security.GetSession(s.ID)
+	if err != nil || res == nil || res.Username != "persisted" {
+		t.Fatalf("session not reloaded from disk")
+	}
+
+	if res.CSRFToken == "" || res.CSRFToken != s.CSRFToken {
+		t.Fatalf("csrf token not persisted")
+	}
+}
+
+func TestSessions_ExternalRevocationIsReloaded(t *testing.T) {
+	dir := setupTempStore(t)
+	path := filepath.Join(dir, security.SessionsFilename)
+
+	revoked, _ := security.CreateSession("bob", security.RoleUser, time.Hour)
+	kept, _ := security.CreateSession("alice", security.RoleUser, time.Hour)
+	if err := security.FlushSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	created, _ := security.CreateSession("carol", security.RoleUser, time.Hour)
+
+	stored, _ := security.GetSession(kept.ID)
+	writeSessions(t, dir, security.SessionsFile{Sessions: []security.Session{*stored}})
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(path, future, future)
+
+	if err := security.FlushSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, _ := security.GetSession(revoked.ID); res != nil {
+		t.Fatalf("a session revoked on disk must not be restored")
+	}
+
+	for _, id := range []string{kept.ID, created.ID} {
+		if res, _ := security.GetSession(id); res == nil {
+			t.Fatalf("session %s should survive the reload", id)
+		}
+	}
+
+	raw, _ := os.ReadFile(path)
+	var sf security.SessionsFile
+	_ = json.Unmarshal(raw, &sf)
+	if len(sf.Sessions) != 2 {
+		t.Fatalf("expected the flushed file to hold 2 sessions, got %d", len(sf.Sessions))
+	}
+}
+
+func TestGetSession_DoesNotRewriteTheFileOnEveryRequest(t *testing.T) {
+	dir := setupTempStore(t)
+	path := filepath.Join(dir, security.SessionsFilename)
+
+	s, _ := security.CreateSession("bob", security.RoleUser, time.Hour)
+	if err := security.FlushSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	_ = os.Chtimes(path, past, past)
+
+	if res, _ := security.GetSession(s.ID); res == nil {
+		t.Fatalf("session not returned")
+	}
+
+	if err := security.FlushSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, _ := os.Stat(path); !info.ModTime().Equal(past) {
+		t.Fatalf("a small expiry slide should not be persisted")
+	}
+}
+
+func TestListAndDeleteUserSessions(t *testing.T) {
+	setupTempStore(t)
+
+	_, _ = security.CreateSession("bob", security.RoleUser, time.Hour)
+	_, _ = security.CreateSession("bob", security.RoleUser, time.Hour)
+	other, _ := security.CreateSession("alice", security.RoleUser, time.Hour)
+
+	list, err := security.ListUserSessions("bob")
+	if err != nil || len(list) != 2 {
+		t.Fatalf("expected 2 sessions for bob, got %d (%v)", len(list), err)
+	}
+
+	count, err := security.DeleteUserSessions("bob")
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 revoked sessions, got %d (%v)", count, err)
+	}
+
+	list, _ = security.ListUserSessions("bob")
+	if len(list) != 0 {
+		t.Fatalf("sessions not revoked")
+	}
+
+	if res, _ := security.GetSession(other.ID); res == nil {
+		t.Fatalf("other user's session must survive")
+	}
+}
+
+func TestCheckCSRF(t *testing.T) {
+	setupTempStore(t)
+
+	s, _ := security.CreateSession("csrf", security.RoleAdmin, time.Hour)
+
+	newCtx := func(method, token string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		if token != "" {
+			ctx.Request.Header.Set(security.CSRFHeaderName, token)
+		}
+		return ctx
+	}
+
+	if !security.CheckCSRF(newCtx("POST", ""), s) {
+		t.Fatalf("csrf must not be enforced when disabled")
+	}
+
+	globals.GetConfig().Security.Session.CSRF.Enabled = true
+
+	if !security.CheckCSRF(newCtx("GET", ""), s) {
+		t.Fatalf("safe methods must not require a csrf token")
+	}
+	if security.CheckCSRF(newCtx("POST", ""), s) {
+		t.Fatalf("missing csrf token must be rejected")
+	}
+	if security.CheckCSRF(newCtx("DELETE", "wrong"), s) {
+		t.Fatalf("wrong csrf token must be rejected")
+	}
+	if !security.CheckCSRF(newCtx("PUT", s.CSRFToken), s) {
+		t.Fatalf("valid csrf token must be accepted")
+	}
+}
+
+func TestUserAuth_CSRF(t *testing.T) {
+	setupTempStore(t)
+	globals.GetConfig().Security.Session.CSRF.Enabled = true
+
+	s, _ := security.CreateSession("u", security.RoleUser, time.Hour)
+
+	called := false
+	h := security.UserAuth(func(ctx *fasthttp.RequestCtx) { called = true })
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s.ID)
+	h(ctx)
+
+	if called || ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403 without csrf token")
+	}
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s.ID)
+	ctx.Request.Header.Set(security.CSRFHeaderName, s.CSRFToken)
+	h(ctx)
+
+	if !called {
+		t.Fatalf("expected handler to be called with csrf token")
+	}
+}
@@ -0,0 +1,177 @@
+package seed_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/seed"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+const baseFixture = `{
+  "schemas": [
+    {"entity": "authors", "fields": {"name": {"name": "name", "type": "string", "required": true}}}
+  ],
+  "entities": {
+    "authors": [{"id": "tolkien", "name": "Tolkien"}],
+    "books": [{"id": "hobbit", "title": "The Hobbit"}]
+  },
+  "users": [
+    {"username": "alice", "password": "alice-secret", "role": "user"}
+  ],
+  "acls": [
+    {"entity": "books", "role": "user", "permissions": {"read": true, "create": false}},
+    {"entity": "books", "username": "alice", "fields": {"title": "read_only"}}
+  ],
+  "hooks": [
+    {"id": "h1", "name": "stamp", "entity": "books", "event": "post_read", "language": "javascript", "enabled": true}
+  ]
+}`
+
+const booksFixture = `{"entity": "books", "data": {"id": "silmarillion", "title": "The Silmarillion"}}
+
+{"kind": "user", "data": {"username": "bob", "password": "bob-secret", "role": "admin"}}
+`
+
+func setup(t *testing.T) string {
+	t.Helper()
+
+	folder := filepath.Join(t.TempDir(), "data")
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = folder
+	cfg.Store.Shards = 4
+	cfg.Store.Persistence = "none"
+	cfg.Security.Authentication.Enabled = true
+	cfg.Security.Authentication.Mode = "user"
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	return folder
+}
+
+func writeFixture(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func ids(entity string) string {
+	var out []string
+	for _, doc := range engine.ListEntities(entity, 0, 0, "", true, nil, "", "") {
+		out = append(out, doc["id"].(string))
+	}
+
+	slices.Sort(out)
+
+	return strings.Join(out, ",")
+}
+
+func TestLoadAppliesEveryFixtureKind(t *testing.T) {
+	setup(t)
+
+	err := seed.Load([]string{
+		writeFixture(t, "base.json", baseFixture),
+		writeFixture(t, "books.ndjson", booksFixture),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ids("books"); got != "hobbit,silmarillion" {
+		t.Fatalf("unexpected books: %s", got)
+	}
+	if got := ids("authors"); got != "tolkien" {
+		t.Fatalf("unexpected authors: %s", got)
+	}
+	if !schema.IsManualSchema("authors", engine.GetEntitySchema("authors")) {
+		t.Fatal("expected the authors schema to be seeded")
+	}
+
+	if _, ok := security.AuthenticateUser("alice", "alice-secret"); !ok {
+		t.Fatal("alice should be able to log in")
+	}
+	if user, ok := security.AuthenticateUser("bob", "bob-secret"); !ok || user.Role != security.RoleAdmin {
+		t.Fatal("bob should be an admin")
+	}
+
+	role := acl.GetRoleACL("books", security.RoleUser)
+	if role == nil || !role.Permissions[acl.PermissionRead] || role.Permissions[acl.PermissionCreate] {
+		t.Fatalf("unexpected role acl: %+v", role)
+	}
+	if stored := acl.GetStoredACLForUsername("books", "alice"); stored == nil || stored.Fields["title"] != acl.FieldRuleReadOnly {
+		t.Fatalf("unexpected acl for alice: %+v", stored)
+	}
+
+	hooks, err := hook.GetHooksForEntity("books")
+	if err != nil || len(hooks) != 1 || hooks[0].Name != "stamp" {
+		t.Fatalf("unexpected hooks: %+v, %v", hooks, err)
+	}
+}
+
+func TestRestoreBringsBackSeededState(t *testing.T) {
+	folder := setup(t)
+
+	if err := seed.Load([]string{writeFixture(t, "base.json", baseFixture)}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine.WriteEntity("books", map[string]any{"id": "lotr", "title": "The Lord of the Rings"})
+	engine.DeleteEntityById("books", "hobbit")
+	engine.WriteEntity("movies", map[string]any{"id": "m1"})
+	_ = storage.PutKeyValue("counter", []byte("1"))
+	storage.WriteToDB()
+
+	if err := seed.Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ids("books"); got != "hobbit" {
+		t.Fatalf("expected only the seeded book, got %s", got)
+	}
+	if engine.EntityTypeExists("movies") {
+		t.Fatal("entity types created after seeding should be gone")
+	}
+	if _, err := storage.GetByKey("counter"); err == nil {
+		t.Fatal("keys written after seeding should be gone")
+	}
+	if _, ok := security.AuthenticateUser("alice", "alice-secret"); !ok {
+		t.Fatal("seeded users should survive a restore")
+	}
+
+	if _, err := os.Stat(folder); !os.IsNotExist(err) {
+		t.Fatalf("an ephemeral store should not create %s", folder)
+	}
+}
+
+func TestInvalidFixturesAreRejected(t *testing.T) {
+	setup(t)
+
+	for name, content := range map[string]string{
+		"unknown-field.json":  `{"entitites": {}}`,
+		"unknown-kind.ndjson": "{\"entity\": \"books\", \"data\": {\"id\": \"a\"}}\n{\"kind\": \"role\", \"data\": {}}\n",
+		"bad-acl.json":        `{"acls": [{"entity": "books", "role": "user", "group": "staff"}]}`,
+		"bad-permission.json": `{"acls": [{"entity": "books", "role": "user", "permissions": {"fly": true}}]}`,
+	} {
+		if err := seed.Load([]string{writeFixture(t, name, content)}); err == nil {
+			t.Errorf("expected %s to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/query"
+	"github.com/taymour/elysiandb/internal/sqlite"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+func setup(t *testing.T) *configuration.Config {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Engine.Name = "sqlite"
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+	cache.InitCache(30)
+
+	if err := sqlite.Connect(); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	t.Cleanup(func() { _ = sqlite.Disconnect() })
+
+	return cfg
+}
+
+func mixedDocuments() []map[string]any {
+	return []map[string]any{
+		{"id": "d01", "v": "apple", "tags": []any{"red", "fruit"}},
+		{"id": "d02", "v": "Banana", "tags": []any{"yellow", "fruit"}},
+		{"id": "d03", "v": float64(10), "tags": []any{"x"}},
+		{"id": "d04", "v": float64(2.5), "tags": []any{}},
+		{"id": "d05", "v": true},
+		{"id": "d06", "v": false},
+		{"id": "d07", "v": "2024-05-01"},
+		{"id": "d08", "v": "2024-05-01T10:00:00Z"},
+		{"id": "d09", "v": "2023-12-31T23:30:00-02:00"},
+		{"id": "d10", "v": nil},
+		{"id": "d11"},
+		{"id": "d12", "v": map[string]any{"nested": "apple"}},
+		{"id": "d13", "v": []any{"apple", float64(10), true}},
+		{"id": "d14", "v": float64(-3)},
+		{"id": "d15", "v": "10"},
+	}
+}
+
+func writeBoth(t *testing.T, entity string, docs []map[string]any) {
+	t.Helper()
+
+	for _, d := range docs {
+		internal := map[string]any{}
+		copyable := map[string]any{}
+		for k, v := range d {
+			internal[k] = v
+			copyable[k] = v
+		}
+
+		api_storage.WriteEntity(entity, internal)
+		if errs := sqlite.WriteEntity(entity, copyable); len(errs) > 0 {
+			t.Fatalf("write %v: %v", d["id"], errs)
+		}
+	}
+}
+
+func ids(items []map[string]any) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item["id"].(string))
+	}
+
+	return out
+}
+
+func sorted(in []string) []string {
+	out := slices.Clone(in)
+	slices.Sort(out)
+
+	return out
+}
+
+func TestFiltersMatchInternalSemantics(t *testing.T) {
+	setup(t)
+	writeBoth(t, "doc", mixedDocuments())
+
+	filters := []map[string]map[string]string{
+		{"v": {"eq": "apple"}},
+		{"v": {"eq": "APP*"}},
+		{"v": {"neq": "*an*"}},
+		{"v": {"eq": "10"}},
+		{"v": {"gt": "2"}},
+		{"v": {"gte": "2.5", "lt": "10"}},
+		{"v": {"lte": "abc"}},
+		{"v": {"eq": "true"}},
+		{"v": {"neq": "1"}},
+		{"v": {"eq": "2024-05-01"}},
+		{"v": {"gt": "2024-01-01"}},
+		{"v": {"lt": "2024-05-01T12:00:00Z"}},
+		{"v": {"eq": "2024-01-01"}},
+		{"v": {"contains": "apple"}},
+		{"v": {"not_contains": "apple"}},
+		{"tags": {"contains": "fruit"}},
+		{"tags": {"any": "red,x"}},
+		{"tags": {"all": "red,fruit"}},
+		{"tags": {"none": "red,x"}},
+		{"tags": {"eq": "fruit,yellow"}},
+		{"v": {"eq": "apple"}, "tags": {"contains": "red"}},
+		{"missing": {"eq": "anything"}},
+	}
+
+	all := api_storage.ListEntities("doc", 0, 0, "", true, nil, "", "")
+
+	for _, f := range filters {
+		want := []string{}
+		for _, d := range all {
+			if api_storage.FiltersMatchEntity(d, f) {
+				want = append(want, d["id"].(string))
+			}
+		}
+
+		got := sqlite.ListEntitiesWithFilterNodeContext(context.Background(), "doc", 0, 0, "", true, f, "", "", query.FilterNode{})
+		if !slices.Equal(sorted(ids(got)), sorted(want)) {
+			t.Errorf("filters %v: sqlite=%v internal=%v", f, sorted(ids(got)), sorted(want))
+		}
+
+		node := query.FilterNode{Leaf: f}
+		want = []string{}
+		for _, d := range all {
+			if api_storage.MatchFilterNode(node, d) {
+				want = append(want, d["id"].(string))
+			}
+		}
+
+		got = sqlite.ListEntitiesWithFilterNodeContext(context.Background(), "doc", 0, 0, "", true, nil, "", "", node)
+		if !slices.Equal(sorted(ids(got)), sorted(want)) {
+			t.Errorf("node %v: sqlite=%v internal=%v", f, sorted(ids(got)), sorted(want))
+		}
+	}
+}
+
+func TestSortMatchesInternalOrdering(t *testing.T) {
+	setup(t)
+	writeBoth(t, "doc", mixedDocuments())
+
+	for _, asc := range []bool{true, false} {
+		want := api_storage.GetListOfIds("doc", "v", asc)
+		got := sqlite.GetListOfIds("doc", "v", asc)
+		if !slices.Equal(got, want) {
+			t.Errorf("asc=%v: sqlite=%v internal=%v", asc, got, want)
+		}
+	}
+
+	if got, want := sqlite.GetListOfIds("doc", "", true), api_storage.GetListOfIds("doc", "", true); !slices.Equal(got, want) {
+		t.Errorf("insertion order: sqlite=%v internal=%v", got, want)
+	}
+}
+
+func TestSortAndFilterByLinkedField(t *testing.T) {
+	setup(t)
+
+	sqlite.WriteEntity("author", map[string]any{"id": "a1", "name": "Zola"})
+	sqlite.WriteEntity("author", map[string]any{"id": "a2", "name": "Austen"})
+	sqlite.WriteEntity("book", map[string]any{"id": "b1", "author": map[string]any{"@entity": "author", "id": "a1"}})
+	sqlite.WriteEntity("book", map[string]any{"id": "b2", "author": map[string]any{"@entity": "author", "id": "a2"}})
+	sqlite.WriteEntity("book", map[string]any{"id": "b3", "author": map[string]any{"name": "Inline"}})
+
+	if got := sqlite.GetListOfIds("book", "author.name", true); !slices.Equal(got, []string{"b2", "b3", "b1"}) {
+		t.Fatalf("sorted by linked field: %v", got)
+	}
+
+	items := sqlite.ListEntitiesWithFilterNodeContext(context.Background(), "book", 0, 0, "", true, map[string]map[string]string{"author.name": {"eq": "zo*"}}, "", "", query.FilterNode{})
+	if !slices.Equal(ids(items), []string{"b1"}) {
+		t.Fatalf("filtered by linked field: %v", ids(items))
+	}
+
+	if author, _ := items[0]["author"].(map[string]any); author["name"] != "Zola" {
+		t.Fatalf("filtered link should be included: %v", items[0]["author"])
+	}
+}
+
+func TestNestedIncludes(t *testing.T) {
+	setup(t)
+
+	sqlite.WriteEntity("book", map[string]any{"id": "b1", "authors": []any{map[string]any{"@entity": "author", "id": "a1"}}})
+	sqlite.WriteEntity("author", map[string]any{"id": "a1", "name": "Zola", "country": map[string]any{"@entity": "country", "id": "c1"}})
+	sqlite.WriteEntity("country", map[string]any{"id": "c1", "name": "France"})
+
+	items := sqlite.ListEntitiesWithFilterNodeContext(context.Background(), "book", 0, 0, "", true, nil, "", "authors.country", query.FilterNode{})
+
+	authors, _ := items[0]["authors"].([]any)
+	author, _ := authors[0].(map[string]any)
+	country, _ := author["country"].(map[string]any)
+	if author["name"] != "Zola" || country["name"] != "France" || country["@entity"] != "country" {
+		t.Fatalf("nested includes not resolved: %v", items[0])
+	}
+}
+
+func TestSearch(t *testing.T) {
+	setup(t)
+
+	sqlite.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune", "meta": map[string]any{"note": "desert planet"}})
+	sqlite.WriteEntity("book", map[string]any{"id": "b2", "title": "Emma"})
+
+	got := sqlite.ListEntitiesWithFilterNodeContext(context.Background(), "book", 0, 0, "", true, nil, "*DESERT*", "", query.FilterNode{})
+	if !slices.Equal(ids(got), []string{"b1"}) {
+		t.Fatalf("search: %v", ids(got))
+	}
+}
+
+func TestDataPersistsAcrossReopen(t *testing.T) {
+	cfg := setup(t)
+	cfg.Engine.URI = filepath.Join(t.TempDir(), "data.sqlite")
+	_ = sqlite.Disconnect()
+
+	if err := sqlite.Connect(); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	sqlite.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune"})
+
+	if err := sqlite.Disconnect(); err != nil {
+		t.Fatalf("disconnect: %v", err)
+	}
+
+	if err := sqlite.Connect(); err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+
+	if got := sqlite.ReadEntityById("book", "b1"); got == nil || got["title"] != "Dune" {
+		t.Fatalf("entity not persisted: %v", got)
+	}
+
+	if !slices.Contains(sqlite.ListEntityTypes(), "book") {
+		t.Fatal("entity type not persisted")
+	}
+}
+
+func TestSortUsesIndex(t *testing.T) {
+	cfg := setup(t)
+	cfg.Engine.SortIndexes = map[string][]string{"book": {"pages"}}
+
+	for i := range 300 {
+		sqlite.WriteEntity("note", map[string]any{"id": fmt.Sprintf("n%03d", i), "pages": float64(i)})
+	}
+	for i := range 100 {
+		sqlite.WriteEntity("book", map[string]any{"id": fmt.Sprintf("b%03d", i), "pages": float64(i % 7)})
+	}
+
+	sqlite.GetListOfIds("book", "pages", true)
+
+	rows, err := globals.SQLiteDB.Query("SELECT name FROM sqlite_master WHERE type = 'index' AND name LIKE 'ely_sort_%'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		t.Fatal("sort index not created")
+	}
+
+	plan := explain(t, "SELECT e.data FROM documents e WHERE e.entity = 'book' ORDER BY "+orderExpression("pages")+" LIMIT 10")
+	if !strings.Contains(plan, "ely_sort_") || strings.Contains(plan, "TEMP B-TREE") {
+		t.Fatalf("sort index not used: %s", plan)
+	}
+}
+
+func sortIndexCount(t *testing.T) int {
+	t.Helper()
+
+	var n int
+	if err := globals.SQLiteDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name LIKE 'ely_sort_%'").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+func TestSortIndexOnlyForDeclaredFields(t *testing.T) {
+	cfg := setup(t)
+	cfg.Api.Schema.Enabled = true
+
+	sqlite.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune", "pages": float64(412)})
+
+	for _, field := range []string{"title", "pages", "unknown", "random_field_42"} {
+		sqlite.GetListOfIds("book", field, true)
+	}
+
+	if n := sortIndexCount(t); n != 0 {
+		t.Fatalf("sorting on undeclared fields created %d indexes", n)
+	}
+
+	sqlite.UpdateEntitySchema("book", map[string]any{
+		"title": map[string]any{"name": "title", "type": "string"},
+		"pages": map[string]any{"name": "pages", "type": "number"},
+	})
+
+	for _, field := range []string{"title", "pages", "unknown"} {
+		sqlite.GetListOfIds("book", field, true)
+	}
+
+	if n := sortIndexCount(t); n != 2 {
+		t.Fatalf("expected indexes for the 2 declared fields, got %d", n)
+	}
+}
+
+func orderExpression(field string) string {
+	path := `'$."` + field + `"'`
+	value := "json_extract(e.data, " + path + ")"
+
+	return "CASE json_type(e.data, " + path + ") WHEN 'true' THEN 1 WHEN 'false' THEN 1 WHEN 'integer' THEN 2 WHEN 'real' THEN 2 " +
+		"WHEN 'text' THEN CASE WHEN ely_time(" + value + ") IS NULL THEN 4 ELSE 3 END " +
+		"WHEN 'object' THEN 5 WHEN 'array' THEN 5 ELSE 0 END ASC, COALESCE(ely_time(" + value + "), " + value + ") ASC, e.id ASC"
+}
+
+func explain(t *testing.T, stmt string) string {
+	t.Helper()
+
+	rows, err := globals.SQLiteDB.Query("EXPLAIN QUERY PLAN " + stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var sb strings.Builder
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			t.Fatal(err)
+		}
+
+		sb.WriteString(detail)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/stat"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+const valueSize = 200 * 1024
+
+func setupMemoryLimit(t *testing.T, policy string) {
+	t.Helper()
+
+	globals.SetConfig(&configuration.Config{
+		Store: configuration.StoreConfig{
+			Folder:         t.TempDir(),
+			Shards:         4,
+			MaxMemoryMB:    1,
+			EvictionPolicy: policy,
+		},
+		Stats: configuration.StatsConfig{Enabled: true},
+	})
+
+	stat.Init()
+	storage.LoadDB()
+}
+
+func value(b byte) []byte {
+	return bytes.Repeat([]byte{b}, valueSize)
+}
+
+func present(keys ...string) string {
+	var out []string
+	for _, k := range keys {
+		if _, err := storage.GetByKey(k); err == nil {
+			out = append(out, k)
+		}
+	}
+
+	return strings.Join(out, ",")
+}
+
+func TestMemoryUsageFollowsWrites(t *testing.T) {
+	setupMemoryLimit(t, storage.EvictionNone)
+
+	if got := storage.MemoryUsage(); got != 0 {
+		t.Fatalf("expected an empty store to use 0 bytes, got %d", got)
+	}
+
+	_ = storage.PutKeyValue("a", value('a'))
+	first := storage.MemoryUsage()
+	if first < valueSize {
+		t.Fatalf("expected at least %d bytes, got %d", valueSize, first)
+	}
+
+	_ = storage.PutKeyValue("a", []byte("small"))
+	if got := storage.MemoryUsage(); got >= first {
+		t.Fatalf("overwriting with a smaller value should lower usage, got %d after %d", got, first)
+	}
+
+	storage.DeleteByKey("a")
+	if got := storage.MemoryUsage(); got != 0 {
+		t.Fatalf("expected 0 bytes after delete, got %d", got)
+	}
+}
+
+func TestNoEvictionRejectsWritesAboveLimit(t *testing.T) {
+	setupMemoryLimit(t, storage.EvictionNone)
+
+	var err error
+	written := 0
+	for i := 0; i < 10 && err == nil; i++ {
+		err = storage.PutKeyValue(fmt.Sprintf("k%d", i), value('x'))
+		if err == nil {
+			written++
+		}
+	}
+
+	if !errors.Is(err, storage.ErrMemoryLimit) {
+		t.Fatalf("expected ErrMemoryLimit, got %v", err)
+	}
+	if written != 5 {
+		t.Fatalf("expected 5 values to fit in 1MB, got %d", written)
+	}
+
+	if err := storage.PutKeyValue("k0", []byte("smaller")); err != nil {
+		t.Fatalf("shrinking a value should be accepted, got %v", err)
+	}
+
+	if err := storage.PutKeyValue(globals.ApiEntityKey("books"), value('b')); err != nil {
+		t.Fatalf("entity keys should never be rejected, got %v", err)
+	}
+}
+
+func TestAllKeysLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	setupMemoryLimit(t, storage.EvictionAllKeysLRU)
+
+	protected := globals.ApiEntityKey("books")
+	_ = storage.PutKeyValue(protected, value('p'))
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		_ = storage.PutKeyValue(k, value(k[0]))
+		time.Sleep(time.Millisecond)
+	}
+
+	_, _ = storage.GetByKey("a")
+
+	if err := storage.PutKeyValue("e", value('e')); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := present(protected, "a", "b", "c", "d", "e"); got != protected+",a,c,d,e" {
+		t.Fatalf("expected b to be evicted, got %s", got)
+	}
+
+	if got := present(protected); got == "" {
+		t.Fatal("entity keys should never be evicted")
+	}
+}
+
+func TestAllKeysLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	setupMemoryLimit(t, storage.EvictionAllKeysLFU)
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		_ = storage.PutKeyValue(k, value(k[0]))
+	}
+
+	for _, k := range []string{"a", "b", "d", "e"} {
+		for range 5 {
+			_, _ = storage.GetByKey(k)
+		}
+	}
+
+	if err := storage.PutKeyValue("f", value('f')); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := present("a", "b", "c", "d", "e", "f"); got != "a,b,d,e,f" {
+		t.Fatalf("expected c to be evicted, got %s", got)
+	}
+}
+
+func TestVolatileTTLEvictsSoonestExpiringKeys(t *testing.T) {
+	setupMemoryLimit(t, storage.EvictionVolatileTTL)
+
+	_ = storage.PutKeyValueWithTTL("late", value('l'), 300)
+	_ = storage.PutKeyValueWithTTL("soon", value('s'), 100)
+	_ = storage.PutKeyValue("a", value('a'))
+	_ = storage.PutKeyValue("b", value('b'))
+	_ = storage.PutKeyValue("c", value('c'))
+
+	if err := storage.PutKeyValue("d", value('d')); err != nil {
+		t.Fatal(err)
+	}
+	if got := present("late", "soon", "a", "b", "c", "d"); got != "late,a,b,c,d" {
+		t.Fatalf("expected soon to be evicted, got %s", got)
+	}
+
+	_ = storage.PutKeyValue("e", value('e'))
+	if got := present("late"); got != "" {
+		t.Fatal("expected late to be evicted next")
+	}
+
+	if err := storage.PutKeyValue("f", value('f')); !errors.Is(err, storage.ErrMemoryLimit) {
+		t.Fatalf("keys without ttl should not be evicted, got %v", err)
+	}
+
+	if !strings.Contains(stat.Stats.ToJson(), `"evicted_keys":"2"`) {
+		t.Fatalf("expected 2 evictions in stats, got %s", stat.Stats.ToJson())
+	}
+}
@@ -0,0 +1,129 @@
+package storage_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/storage"
+)
+
+func setIndexTestConfig(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	globals.SetConfig(&configuration.Config{
+		Store: configuration.StoreConfig{
+			Folder: tmp,
+			Shards: 4,
+		},
+	})
+	storage.LoadDB()
+	storage.LoadJsonDB()
+	return tmp
+}
+
+func num(v float64) storage.SortKey {
+	return storage.SortKey{Kind: storage.SortKindNumber, Num: v}
+}
+
+func TestOrderedIndex_SetDeleteAndIds(t *testing.T) {
+	idx := storage.NewOrderedIndex()
+	idx.Set("a", num(3))
+	idx.Set("b", num(1))
+	idx.Set("c", num(2))
+	idx.Set("d", storage.SortKey{Kind: storage.SortKindString, Str: "x"})
+	idx.Set("e", storage.SortKey{Kind: storage.SortKindMissing})
+
+	if got := idx.Ids(true, 0, 0); !reflect.DeepEqual(got, []string{"e", "b", "c", "a", "d"}) {
+		t.Fatalf("asc=%v", got)
+	}
+	if got := idx.Ids(false, 1, 2); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Fatalf("desc page=%v", got)
+	}
+
+	if changed := idx.Set("c", num(2)); changed {
+		t.Fatal("setting the same key should be a no-op")
+	}
+
+	idx.Set("c", num(0))
+	idx.Delete("a")
+
+	if got := idx.Ids(true, 0, 0); !reflect.DeepEqual(got, []string{"e", "c", "b", "d"}) {
+		t.Fatalf("asc after update=%v", got)
+	}
+	if idx.Len() != 4 {
+		t.Fatalf("len=%d", idx.Len())
+	}
+	if got := idx.Ids(true, 10, 0); len(got) != 0 {
+		t.Fatalf("out of range page=%v", got)
+	}
+}
+
+func TestOrderedIndex_EqualKeysOrderedById(t *testing.T) {
+	idx := storage.NewOrderedIndex()
+	idx.Set("b", num(1))
+	idx.Set("a", num(1))
+	idx.Set("c", num(1))
+
+	if got := idx.Ids(true, 0, 0); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("asc=%v", got)
+	}
+	if got := idx.Ids(false, 0, 0); !reflect.DeepEqual(got, []string{"c", "b", "a"}) {
+		t.Fatalf("desc=%v", got)
+	}
+}
+
+func TestOrderedIndex_AppendKeepsInsertionOrder(t *testing.T) {
+	idx := storage.NewOrderedIndex()
+	idx.Append("z")
+	idx.Append("a")
+	idx.Append("z")
+	idx.Delete("a")
+	idx.Append("m")
+
+	if got := idx.Ids(true, 0, 0); !reflect.DeepEqual(got, []string{"z", "m"}) {
+		t.Fatalf("ids=%v", got)
+	}
+}
+
+func TestIndexes_PersistedWithJsonSnapshot(t *testing.T) {
+	setIndexTestConfig(t)
+
+	master := storage.GetOrCreateIndex("master")
+	master.Append("x")
+	master.Append("y")
+
+	field := storage.NewOrderedIndex()
+	field.Set("x", storage.SortKey{Kind: storage.SortKindString, Str: "b"})
+	field.Set("y", storage.SortKey{Kind: storage.SortKindString, Str: "a"})
+	storage.PutIndex("field", field)
+
+	storage.WriteJsonDB()
+	storage.LoadJsonDB()
+
+	loaded, ok := storage.GetIndex("field")
+	if !ok {
+		t.Fatal("field index should be loaded from the snapshot")
+	}
+	if got := loaded.Ids(true, 0, 0); !reflect.DeepEqual(got, []string{"y", "x"}) {
+		t.Fatalf("field=%v", got)
+	}
+
+	reloaded, _ := storage.GetIndex("master")
+	reloaded.Append("z")
+	if got := reloaded.Ids(true, 0, 0); !reflect.DeepEqual(got, []string{"x", "y", "z"}) {
+		t.Fatalf("master=%v", got)
+	}
+
+	storage.DeleteIndexesByPattern("fi*")
+	storage.WriteJsonDB()
+	storage.LoadJsonDB()
+
+	if _, ok := storage.GetIndex("field"); ok {
+		t.Fatal("deleted index should not be persisted")
+	}
+	if _, ok := storage.GetIndex("master"); !ok {
+		t.Fatal("master index should still be persisted")
+	}
+}
@@ -0,0 +1,162 @@
+package tabular_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/taymour/elysiandb/internal/tabular"
+)
+
+func setup(t *testing.T) {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = filepath.Join(t.TempDir(), "data")
+	cfg.Store.Shards = 4
+	cfg.Store.Persistence = "none"
+	cfg.Api.Schema.Enabled = true
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+}
+
+type row struct {
+	n   int
+	doc map[string]any
+	err error
+}
+
+func read(t *testing.T, input []byte, format string, opts tabular.ReadOptions) ([]row, error) {
+	t.Helper()
+
+	var rows []row
+	err := tabular.Read(bytes.NewReader(input), format, opts, func(n int, doc map[string]any, err error) {
+		rows = append(rows, row{n, doc, err})
+	})
+
+	return rows, err
+}
+
+var docs = []map[string]any{
+	{"id": "b1", "title": "Dune", "pages": float64(412), "available": true, "author": map[string]any{"name": "Herbert"}, "tags": []any{"sf"}},
+	{"id": "b2", "title": "Hyperion, Vol. 1", "pages": float64(482), "available": false},
+}
+
+func TestWriteCSVFlattensNestedFields(t *testing.T) {
+	var buf bytes.Buffer
+	if err := tabular.Write(&buf, tabular.FormatCSV, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "id,author.name,available,pages,tags,title\n" +
+		"b1,Herbert,true,412,\"[\"\"sf\"\"]\",Dune\n" +
+		"b2,,false,482,,\"Hyperion, Vol. 1\"\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+}
+
+func TestCSVAndParquetRoundTrip(t *testing.T) {
+	for _, format := range []string{tabular.FormatCSV, tabular.FormatParquet} {
+		setup(t)
+
+		var buf bytes.Buffer
+		if err := tabular.Write(&buf, format, docs); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := read(t, buf.Bytes(), format, tabular.ReadOptions{Entity: "books"})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if len(rows) != 2 {
+			t.Fatalf("%s: expected 2 rows, got %d", format, len(rows))
+		}
+
+		for i, r := range rows {
+			if r.err != nil || !reflect.DeepEqual(r.doc, docs[i]) {
+				t.Errorf("%s: row %d: expected %v, got %v (%v)", format, r.n, docs[i], r.doc, r.err)
+			}
+		}
+	}
+}
+
+func TestReadCSVInfersTypesAndMapsHeaders(t *testing.T) {
+	setup(t)
+
+	input := "\ufeffFull Name,Zip,Score,Active,Notes\n" +
+		"Ada,01234,1.5,true,x\n" +
+		"Bob,75001,2,false,\n"
+
+	rows, err := read(t, []byte(input), tabular.FormatCSV, tabular.ReadOptions{
+		Entity:  "people",
+		Mapping: map[string]string{"Full Name": "name.full", "Notes": "-"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{"name": map[string]any{"full": "Ada"}, "Zip": "01234", "Score": 1.5, "Active": true}
+	if rows[0].n != 2 || !reflect.DeepEqual(rows[0].doc, want) {
+		t.Fatalf("unexpected first row: %d %v", rows[0].n, rows[0].doc)
+	}
+
+	if rows[1].doc["Score"] != float64(2) || rows[1].doc["Zip"] != "75001" {
+		t.Fatalf("unexpected second row: %v", rows[1].doc)
+	}
+}
+
+func TestReadCSVUsesTheSchemaAndReportsBadRows(t *testing.T) {
+	setup(t)
+
+	engine.UpdateEntitySchema("books", map[string]any{
+		"title": map[string]any{"name": "title", "type": "string"},
+		"pages": map[string]any{"name": "pages", "type": "number"},
+	})
+
+	input := "title,pages\n1984,328\nUbik,many\nshort\n"
+
+	rows, err := read(t, []byte(input), tabular.FormatCSV, tabular.ReadOptions{Entity: "books"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rows[0].err != nil || rows[0].doc["title"] != "1984" || rows[0].doc["pages"] != float64(328) {
+		t.Fatalf("unexpected first row: %v %v", rows[0].doc, rows[0].err)
+	}
+
+	if rows[1].err == nil || !strings.Contains(rows[1].err.Error(), "Field 'pages'") || rows[1].n != 3 {
+		t.Fatalf("expected a type error on line 3, got %d %v", rows[1].n, rows[1].err)
+	}
+
+	if rows[2].err == nil || rows[2].n != 4 {
+		t.Fatalf("expected a column count error on line 4, got %d %v", rows[2].n, rows[2].err)
+	}
+}
+
+func TestReadRejectsInvalidInput(t *testing.T) {
+	setup(t)
+
+	for name, tc := range map[string]struct {
+		format, input string
+	}{
+		"conflicting columns": {tabular.FormatCSV, "author,author.name\nx,y\n"},
+		"duplicate columns":   {tabular.FormatCSV, "title,title\nx,y\n"},
+		"empty csv":           {tabular.FormatCSV, ""},
+		"invalid parquet":     {tabular.FormatParquet, "not a parquet file"},
+		"unknown format":      {"xlsx", "a\n1\n"},
+	} {
+		if _, err := read(t, []byte(tc.input), tc.format, tabular.ReadOptions{Entity: "books"}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
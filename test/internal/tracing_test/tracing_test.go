@@ -0,0 +1,193 @@
+package tracing_test
+
+import (
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/router"
+	api_storage "github.com/taymour/elysiandb/internal/api"
+	"github.com/taymour/elysiandb/internal/cache"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/routing"
+	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/taymour/elysiandb/internal/tracing"
+	"github.com/valyala/fasthttp"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func newCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+		c.mu.Unlock()
+
+		resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	return c, srv.URL
+}
+
+func (c *collector) byName() map[string]*tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := map[string]*tracepb.Span{}
+	for _, s := range c.spans {
+		out[s.Name] = s
+	}
+
+	return out
+}
+
+func setup(t *testing.T, endpoint string) {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Api.Schema.Enabled = false
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Endpoint = endpoint
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+	cache.InitCache(30 * time.Second)
+	api_storage.DeleteAll()
+
+	if err := tracing.Init(cfg.Tracing); err != nil {
+		t.Fatalf("tracing.Init: %v", err)
+	}
+	t.Cleanup(func() { _ = tracing.Shutdown(t.Context()) })
+}
+
+func TestListRequestProducesStageSpans(t *testing.T) {
+	c, endpoint := newCollector(t)
+	setup(t, endpoint)
+
+	api_storage.WriteEntity("authors", map[string]any{"id": "a1", "name": "Herbert"})
+	api_storage.WriteEntity("books", map[string]any{
+		"id":     "b1",
+		"title":  "Dune",
+		"author": map[string]any{"@entity": "authors", "id": "a1"},
+	})
+
+	r := router.New()
+	routing.RegisterRoutes(r)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentID = "00f067aa0ba902b7"
+
+	req := fasthttp.AcquireRequest()
+	req.Header.SetMethod("GET")
+	req.SetRequestURI("/api/books?includes=author")
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}, nil)
+	r.Handler(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	if err := tracing.Shutdown(t.Context()); err != nil {
+		t.Fatalf("tracing.Shutdown: %v", err)
+	}
+
+	spans := c.byName()
+
+	server, ok := spans["GET /api/{entity}"]
+	if !ok {
+		t.Fatalf("missing server span, got %v", spanNames(spans))
+	}
+	if hex.EncodeToString(server.TraceId) != traceID {
+		t.Fatalf("server span did not continue the incoming trace: %x", server.TraceId)
+	}
+	if hex.EncodeToString(server.ParentSpanId) != parentID {
+		t.Fatalf("server span parent = %x, want %s", server.ParentSpanId, parentID)
+	}
+	if server.Kind != tracepb.Span_SPAN_KIND_SERVER {
+		t.Fatalf("server span kind = %v", server.Kind)
+	}
+
+	for _, name := range []string{"acl.ReadFilter", "ListEntities", "acl.HideFields", "json.Marshal"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("missing %s span, got %v", name, spanNames(spans))
+		}
+		if string(span.ParentSpanId) != string(server.SpanId) {
+			t.Fatalf("%s is not a child of the server span", name)
+		}
+	}
+
+	includes, ok := spans["ApplyIncludes"]
+	if !ok {
+		t.Fatalf("missing ApplyIncludes span, got %v", spanNames(spans))
+	}
+	if string(includes.ParentSpanId) != string(spans["ListEntities"].SpanId) {
+		t.Fatalf("ApplyIncludes is not a child of ListEntities")
+	}
+}
+
+func TestMiddlewareIsNoopWhenTracingIsDisabled(t *testing.T) {
+	_ = tracing.Shutdown(t.Context())
+
+	called := false
+	h := tracing.Middleware(func(ctx *fasthttp.RequestCtx) { called = true })
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h(ctx)
+
+	if !called {
+		t.Fatalf("handler not called")
+	}
+	if tracing.TraceID(ctx) != "" {
+		t.Fatalf("expected no trace context when tracing is disabled")
+	}
+}
+
+func spanNames(spans map[string]*tracepb.Span) []string {
+	names := make([]string, 0, len(spans))
+	for name := range spans {
+		names = append(names, name)
+	}
+
+	return names
+}
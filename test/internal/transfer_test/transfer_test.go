@@ -0,0 +1,284 @@
+package transfer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/taymour/elysiandb/internal/acl"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/engine"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/hook"
+	"github.com/taymour/elysiandb/internal/schema"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/taymour/elysiandb/internal/transfer"
+)
+
+func setup(t *testing.T) {
+	t.Helper()
+
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = filepath.Join(t.TempDir(), "data")
+	cfg.Store.Shards = 4
+	cfg.Store.Persistence = "none"
+	cfg.Api.Schema.Enabled = true
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+}
+
+func seedData(t *testing.T) {
+	t.Helper()
+
+	engine.WriteEntity("books", map[string]any{"id": "b1", "title": "Dune", "pages": float64(412)})
+	engine.WriteEntity("books", map[string]any{"id": "b2", "title": "Hyperion", "pages": float64(482)})
+	engine.WriteEntity("authors", map[string]any{"id": "a1", "name": "Herbert"})
+
+	engine.UpdateEntitySchema("authors", map[string]any{
+		"name": map[string]any{"name": "name", "type": "string", "required": true},
+	})
+
+	acl.SetRoleACL(&acl.PrincipalACL{
+		Principal:   string(security.RoleUser),
+		Entity:      "books",
+		Permissions: map[acl.Permission]bool{acl.PermissionRead: true},
+		Fields:      map[string]acl.FieldRule{"pages": acl.FieldRuleHidden},
+	})
+
+	if err := hook.CreateHook(hook.Hook{ID: "h1", Name: "stamp", Entity: "books", Event: hook.HookEventPostRead, Language: "javascript"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func records(t *testing.T, out string) []transfer.Record {
+	t.Helper()
+
+	var recs []transfer.Record
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		var rec transfer.Record
+		if err := json.Unmarshal([]byte(l), &rec); err != nil {
+			t.Fatalf("invalid line %q: %v", l, err)
+		}
+
+		recs = append(recs, rec)
+	}
+
+	return recs
+}
+
+func ids(entity string) string {
+	var out []string
+	for _, doc := range engine.ListEntities(entity, 0, 0, "", true, nil, "", "") {
+		out = append(out, doc["id"].(string))
+	}
+
+	slices.Sort(out)
+
+	return strings.Join(out, ",")
+}
+
+func TestExportRoundTripsThroughImport(t *testing.T) {
+	setup(t)
+	seedData(t)
+
+	var buf bytes.Buffer
+	opts := transfer.ExportOptions{}
+	if err := opts.Include([]string{"schemas", "hooks", "acls"}); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := transfer.Export(&buf, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["books"] != 2 || counts["authors"] != 1 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+
+	var kinds []string
+	for _, rec := range records(t, buf.String()) {
+		kinds = append(kinds, rec.Kind)
+	}
+	if kinds[0] != "entity" || kinds[len(kinds)-1] != "hook" || !slices.Contains(kinds, "schema") || !slices.Contains(kinds, "acl") {
+		t.Fatalf("unexpected record order: %v", kinds)
+	}
+
+	setup(t)
+
+	report, err := transfer.ImportNDJSON(&buf, transfer.ImportOptions{CoreRecords: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed != 0 || report.Imported["books"] != 2 || report.Schemas != 1 || report.ACLs != 1 || report.Hooks != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if got := ids("books"); got != "b1,b2" {
+		t.Fatalf("unexpected books: %s", got)
+	}
+	if !schema.IsManualSchema("authors", engine.GetEntitySchema("authors")) {
+		t.Fatal("expected the authors schema to be imported")
+	}
+	if role := acl.GetRoleACL("books", security.RoleUser); role == nil || role.Fields["pages"] != acl.FieldRuleHidden {
+		t.Fatalf("unexpected role acl: %+v", role)
+	}
+	if h, err := hook.GetHookById("h1"); err != nil || h.Name != "stamp" {
+		t.Fatalf("unexpected hook: %+v, %v", h, err)
+	}
+}
+
+func TestExportSelectsEntitiesAndFiltersDocuments(t *testing.T) {
+	setup(t)
+	seedData(t)
+
+	if _, err := transfer.ResolveEntities([]string{"books", "movies"}); err == nil {
+		t.Fatal("expected unknown entities to be rejected")
+	}
+	if _, err := transfer.ResolveEntities([]string{acl.ACLEntity}); err == nil {
+		t.Fatal("expected core entities to be rejected")
+	}
+
+	var buf bytes.Buffer
+	_, err := transfer.Export(&buf, transfer.ExportOptions{
+		Entities: []string{"books"},
+		Filter: func(entity string, list []map[string]any) []map[string]any {
+			out := make([]map[string]any, 0, len(list))
+			for _, doc := range list {
+				if doc["id"] != "b2" {
+					delete(doc, "pages")
+					out = append(out, doc)
+				}
+			}
+			return out
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs := records(t, buf.String())
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+
+	for _, rec := range recs {
+		doc := rec.Data.(map[string]any)
+		if rec.Kind != "entity" || rec.Entity != "books" || doc["id"] != "b1" || doc["pages"] != nil {
+			t.Fatalf("unexpected record: %+v", rec)
+		}
+	}
+}
+
+func TestImportModes(t *testing.T) {
+	const input = `{"kind":"entity","entity":"books","data":{"id":"b1","title":"Dune Messiah"}}
+{"kind":"entity","entity":"books","data":{"id":"b3","title":"Ilium"}}
+`
+
+	for mode, want := range map[string]string{
+		transfer.ModeReplace:      "b1:Dune Messiah,b3:Ilium",
+		transfer.ModeUpsert:       "b1:Dune Messiah,b2:Hyperion,b3:Ilium",
+		transfer.ModeSkipExisting: "b1:Dune,b2:Hyperion,b3:Ilium",
+	} {
+		setup(t)
+		seedData(t)
+
+		report, err := transfer.ImportNDJSON(strings.NewReader(input), transfer.ImportOptions{Mode: mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, doc := range engine.ListEntities("books", 0, 0, "id", true, nil, "", "") {
+			got = append(got, doc["id"].(string)+":"+doc["title"].(string))
+		}
+
+		if strings.Join(got, ",") != want {
+			t.Errorf("%s: expected %s, got %s", mode, want, strings.Join(got, ","))
+		}
+
+		if mode == transfer.ModeSkipExisting && report.Skipped["books"] != 1 {
+			t.Errorf("%s: expected 1 skipped record, got %+v", mode, report.Skipped)
+		}
+	}
+
+	if _, err := transfer.ImportNDJSON(strings.NewReader(input), transfer.ImportOptions{Mode: "merge"}); err == nil {
+		t.Fatal("expected an unknown mode to be rejected")
+	}
+}
+
+func TestImportReportsFailedRecordsAndContinues(t *testing.T) {
+	setup(t)
+	seedData(t)
+
+	input := strings.Join([]string{
+		`{"entity":"books","data":{"id":"b9","title":"Ubik"}}`,
+		`{"entity":"books","data":`,
+		`{"kind":"entity","entity":"authors","data":{"id":"a2","name":42}}`,
+		`{"kind":"hook","entity":"books","data":{"name":"x","event":"post_read","language":"javascript"}}`,
+		`{"kind":"role","data":{}}`,
+		`{"kind":"entity","entity":"` + acl.ACLEntity + `","data":{"id":"x"}}`,
+		``,
+		`{"kind":"entity","entity":"authors","data":{"id":"a3","name":"Simmons"}}`,
+	}, "\n")
+
+	report, err := transfer.ImportNDJSON(strings.NewReader(input), transfer.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Failed != 5 || report.Imported["books"] != 1 || report.Imported["authors"] != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	var lines []int
+	for _, e := range report.Errors {
+		lines = append(lines, e.Line)
+	}
+	if !slices.Equal(lines, []int{2, 3, 4, 5, 6}) {
+		t.Fatalf("unexpected failed lines: %v", lines)
+	}
+
+	if got := ids("authors"); got != "a1,a3" {
+		t.Fatalf("unexpected authors: %s", got)
+	}
+
+	if _, err := transfer.ImportNDJSON(strings.NewReader(strings.Repeat("x", 65<<20)), transfer.ImportOptions{}); err == nil {
+		t.Fatal("expected an oversized line to abort the import")
+	}
+}
+
+func TestImporterDryRunValidatesWithoutWriting(t *testing.T) {
+	setup(t)
+	seedData(t)
+
+	prepared := 0
+	im, err := transfer.NewImporter(transfer.ImportOptions{
+		Mode:   transfer.ModeReplace,
+		DryRun: true,
+		Prepare: func(entity string, doc, existing map[string]any) error {
+			prepared++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	im.WriteEntity(1, "authors", map[string]any{"id": "a2", "name": "Simmons"})
+	im.WriteEntity(2, "authors", map[string]any{"id": "a3", "name": 42})
+
+	report := im.Finish()
+	if !report.DryRun || report.Imported["authors"] != 1 || report.Failed != 1 || prepared != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if got := ids("authors"); got != "a1" {
+		t.Fatalf("a dry run should leave authors untouched, got %s", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/taymour/elysiandb/internal/acl"
 	api_storage "github.com/taymour/elysiandb/internal/api"
 	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
@@ -246,3 +247,180 @@ func TestSetDefaultACLForUsernameAndEntity_Forbidden(t *testing.T) {
 		t.Fatalf("expected 403")
 	}
 }
+
+func TestUpdateACLForUsernameAndEntity_Fields(t *testing.T) {
+	setupACLTest(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"permissions": map[string]bool{"read": true},
+		"fields":      map[string]string{"title": "read_only"},
+	})
+
+	ctx := adminCtx("PUT", body)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("user_name", "admin")
+
+	http_acl.UpdateACLForUsernameAndEntityController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200")
+	}
+
+	a := acl.GetACLEntityForUsername("doc", "admin")
+	if a.Fields["title"] != acl.FieldRuleReadOnly {
+		t.Fatalf("field rule not stored: %v", a.Fields)
+	}
+
+	body, _ = json.Marshal(map[string]any{
+		"permissions": map[string]bool{"read": true},
+		"fields":      map[string]string{"title": "invisible"},
+	})
+
+	ctx = adminCtx("PUT", body)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("user_name", "admin")
+
+	http_acl.UpdateACLForUsernameAndEntityController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown field rule")
+	}
+}
+
+func TestRoleFieldRulesControllers(t *testing.T) {
+	setupACLTest(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"fields": map[string]string{"title": "hidden"},
+	})
+
+	ctx := adminCtx("PUT", body)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("role_name", "user")
+
+	http_acl.UpdateRoleFieldRulesController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200")
+	}
+
+	ctx = adminCtx("GET", nil)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("role_name", "user")
+
+	http_acl.GetRoleFieldRulesController(ctx)
+
+	if string(ctx.Response.Body()) != `{"entity":"doc","fields":{"title":"hidden"},"role":"user"}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+
+	ctx = userCtx("GET")
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("role_name", "user")
+
+	http_acl.GetRoleFieldRulesController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403")
+	}
+}
+
+func TestRoleACLControllers(t *testing.T) {
+	setupACLTest(t)
+	_ = security.CreateRole("editor", "")
+
+	body, _ := json.Marshal(map[string]any{
+		"permissions": map[string]bool{"read": true},
+		"fields":      map[string]string{"title": "read_only"},
+	})
+
+	ctx := adminCtx("PUT", body)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("role_name", "editor")
+	http_acl.UpdateRoleACLController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = adminCtx("GET", nil)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("role_name", "editor")
+	http_acl.GetRoleACLController(ctx)
+
+	var out map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &out)
+	if out["permissions"].(map[string]any)["read"] != true || out["fields"].(map[string]any)["title"] != "read_only" {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+
+	ctx = adminCtx("GET", nil)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("role_name", "ghost")
+	http_acl.GetRoleACLController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Fatalf("expected 404 for unknown role")
+	}
+}
+
+func TestGroupACLControllers(t *testing.T) {
+	setupACLTest(t)
+	_ = security.CreateGroup("sales", "")
+
+	body, _ := json.Marshal(map[string]any{"permissions": map[string]bool{"delete": true}})
+
+	ctx := adminCtx("PUT", body)
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("group", "sales")
+	http_acl.UpdateGroupACLController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+
+	if g := acl.GetGroupACL("doc", "sales"); g == nil || !g.Permissions[acl.PermissionDelete] {
+		t.Fatalf("group ACL not stored")
+	}
+
+	ctx = userCtx("PUT")
+	ctx.SetUserValue("entity", "doc")
+	ctx.SetUserValue("group", "sales")
+	http_acl.UpdateGroupACLController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403")
+	}
+}
+
+func TestRoleACLControllers_RowFilter(t *testing.T) {
+	setupACLTest(t)
+
+	body, _ := json.Marshal(map[string]any{
+		"filter": map[string]any{"team": map[string]any{"eq": "$user.team"}},
+	})
+
+	ctx := adminCtx("PUT", body)
+	ctx.SetUserValue("entity", "order")
+	ctx.SetUserValue("role_name", "user")
+	http_acl.UpdateRoleACLController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = adminCtx("GET", nil)
+	ctx.SetUserValue("entity", "order")
+	ctx.SetUserValue("role_name", "user")
+	http_acl.GetRoleACLController(ctx)
+
+	var out map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &out)
+	filter, _ := out["filter"].(map[string]any)
+	if filter["team"].(map[string]any)["eq"] != "$user.team" {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+
+	ctx = adminCtx("PUT", []byte(`{"filter":{"team":"red"}}`))
+	ctx.SetUserValue("entity", "order")
+	ctx.SetUserValue("role_name", "user")
+	http_acl.UpdateRoleACLController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid filter, got %d", ctx.Response.StatusCode())
+	}
+}
@@ -199,3 +199,139 @@ func TestMeController_OK(t *testing.T) {
 		t.Fatalf("wrong role")
 	}
 }
+
+func TestLoginController_SetsCSRFToken(t *testing.T) {
+	setup(t)
+	u := &security.BasicUser{Username: "admin", Password: "x", Role: security.RoleAdmin}
+	security.CreateBasicUser(u)
+	ctx := newCtx("POST", "/login", `{"username":"admin","password":"x"}`)
+	adminui.LoginController(ctx)
+
+	var m map[string]interface{}
+	json.Unmarshal(ctx.Response.Body(), &m)
+	token, _ := m["csrf_token"].(string)
+	if token == "" {
+		t.Fatalf("csrf token missing from response")
+	}
+
+	if !bytes.Contains(ctx.Response.Header.PeekCookie(security.CSRFCookieName), []byte(token)) {
+		t.Fatalf("csrf cookie not set")
+	}
+}
+
+func TestAdminAuth_CSRFRequiredForUnsafeMethods(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Session.CSRF.Enabled = true
+	s, _ := security.CreateSession("a", security.RoleAdmin, time.Hour)
+	called := false
+	h := adminui.AdminAuth(func(ctx *fasthttp.RequestCtx) { called = true })
+
+	ctx := newCtx("POST", "/api/security/user", "")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s.ID)
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden || called {
+		t.Fatalf("expected 403 without csrf token")
+	}
+
+	ctx = newCtx("POST", "/api/security/user", "")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s.ID)
+	ctx.Request.Header.Set(security.CSRFHeaderName, s.CSRFToken)
+	h(ctx)
+	if !called {
+		t.Fatalf("expected next to be called with csrf token")
+	}
+}
+
+func TestLogoutEverywhereController(t *testing.T) {
+	setup(t)
+
+	s1, _ := security.CreateSession("a", security.RoleAdmin, time.Hour)
+	s2, _ := security.CreateSession("a", security.RoleAdmin, time.Hour)
+	other, _ := security.CreateSession("b", security.RoleAdmin, time.Hour)
+
+	ctx := newCtx("POST", "/logout/all", "")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s1.ID)
+
+	adminui.LogoutEverywhereController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusNoContent {
+		t.Fatalf("expected 204")
+	}
+
+	for _, id := range []string{s1.ID, s2.ID} {
+		if res, _ := security.GetSession(id); res != nil {
+			t.Fatalf("session %s should be revoked", id)
+		}
+	}
+
+	if res, _ := security.GetSession(other.ID); res == nil {
+		t.Fatalf("other user's session must survive")
+	}
+}
+
+func TestLoginController_AccountLocked(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Lockout.Enabled = true
+	globals.GetConfig().Security.Lockout.MaxAttempts = 2
+	security.Lockouts.Reset()
+	t.Cleanup(security.Lockouts.Reset)
+
+	security.CreateBasicUser(&security.BasicUser{Username: "admin", Password: "x", Role: security.RoleAdmin})
+
+	for i := 0; i < 2; i++ {
+		ctx := newCtx("POST", "/login", `{"username":"admin","password":"bad"}`)
+		adminui.LoginController(ctx)
+	}
+
+	ctx := newCtx("POST", "/login", `{"username":"admin","password":"x"}`)
+	adminui.LoginController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", ctx.Response.StatusCode())
+	}
+	if len(ctx.Response.Header.Peek("Retry-After")) == 0 {
+		t.Fatalf("expected Retry-After header")
+	}
+	if string(ctx.Response.Body()) != `{"error":"account locked"}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+}
+
+func TestLoginController_MustChangePassword(t *testing.T) {
+	setup(t)
+	security.CreateBasicUser(&security.BasicUser{Username: "admin", Password: "x", Role: security.RoleAdmin, MustChangePassword: true})
+
+	ctx := newCtx("POST", "/login", `{"username":"admin","password":"x"}`)
+	adminui.LoginController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200")
+	}
+
+	var resp map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &resp)
+	if resp["must_change_password"] != true {
+		t.Fatalf("expected must_change_password in response, got %v", resp)
+	}
+}
+
+func TestAdminAuth_PasswordChangeRequired(t *testing.T) {
+	setup(t)
+	security.CreateBasicUser(&security.BasicUser{Username: "a", Password: "x", Role: security.RoleAdmin, MustChangePassword: true})
+	s, _ := security.CreateSession("a", security.RoleAdmin, time.Hour)
+
+	called := false
+	h := adminui.AdminAuth(func(ctx *fasthttp.RequestCtx) { called = true })
+
+	ctx := newCtx("GET", "/api/security/user", "")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s.ID)
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden || called {
+		t.Fatalf("expected 403, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("GET", "/api/security/me", "")
+	ctx.Request.Header.SetCookie(security.SessionCookieName, s.ID)
+	h(ctx)
+	if !called {
+		t.Fatalf("expected /me to be allowed")
+	}
+}
@@ -3,12 +3,15 @@ package api_test
 import (
 	"encoding/hex"
 	"encoding/json"
+	"net"
 	"testing"
 
+	"github.com/taymour/elysiandb/internal/acl"
 	api_storage "github.com/taymour/elysiandb/internal/api"
 	"github.com/taymour/elysiandb/internal/cache"
 	"github.com/taymour/elysiandb/internal/configuration"
 	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
 	"github.com/taymour/elysiandb/internal/storage"
 	api_controller "github.com/taymour/elysiandb/internal/transport/http/api"
 	"github.com/valyala/fasthttp"
@@ -29,8 +32,12 @@ func setup(t *testing.T) {
 
 	cache.InitCache(30)
 	api_storage.DeleteAll()
+
+	principal = &security.Principal{}
 }
 
+var principal = &security.Principal{}
+
 func newCtx(method, uri, body string) *fasthttp.RequestCtx {
 	req := fasthttp.AcquireRequest()
 	req.SetRequestURI(uri)
@@ -40,6 +47,29 @@ func newCtx(method, uri, body string) *fasthttp.RequestCtx {
 	}
 	ctx := &fasthttp.RequestCtx{}
 	ctx.Init(req, nil, nil)
+	if principal.Username != "" {
+		current := *principal
+		security.SetCurrentPrincipal(ctx, &current)
+	}
+	return ctx
+}
+
+func newStreamingCtx(t *testing.T, method, uri string) *fasthttp.RequestCtx {
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init2(server, nil, true)
+	ctx.Request.SetRequestURI(uri)
+	ctx.Request.Header.SetMethod(method)
+	if principal.Username != "" {
+		current := *principal
+		security.SetCurrentPrincipal(ctx, &current)
+	}
+
 	return ctx
 }
 
@@ -759,3 +789,594 @@ func TestQueryPayload_HashDeterministic(t *testing.T) {
 		t.Fatalf("hash must be stable, got %s vs %s", h1, h2)
 	}
 }
+
+func setupFieldRules(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "user"
+	principal = &security.Principal{Username: "u", Role: security.RoleUser}
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionCreate] = true
+	perms[acl.PermissionRead] = true
+	perms[acl.PermissionUpdate] = true
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+		Username:    "u",
+		Entity:      "employee",
+		Permissions: perms,
+		Fields: map[string]acl.FieldRule{
+			"salary": acl.FieldRuleHidden,
+			"status": acl.FieldRuleReadOnly,
+		},
+	}).ToDataMap())
+
+	api_storage.WriteEntity("employee", map[string]any{"id": "e1", "name": "a", "salary": 100, "status": "active"})
+}
+
+func TestFieldRules_CreateRejectsForbiddenFields(t *testing.T) {
+	setupFieldRules(t)
+
+	ctx := newCtx("POST", "/api/employee", `{"name":"b","salary":5,"status":"x"}`)
+	ctx.SetUserValue("entity", "employee")
+	api_controller.CreateController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403, got %d", ctx.Response.StatusCode())
+	}
+	if string(ctx.Response.Body()) != `{"error":"forbidden fields","fields":["salary","status"]}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+}
+
+func TestFieldRules_UpdateRejectsReadOnlyField(t *testing.T) {
+	setupFieldRules(t)
+
+	ctx := newCtx("PUT", "/api/employee/e1", `{"status":"inactive"}`)
+	ctx.SetUserValue("entity", "employee")
+	ctx.SetUserValue("id", "e1")
+	api_controller.UpdateByIdController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("PUT", "/api/employee/e1", `{"name":"z"}`)
+	ctx.SetUserValue("entity", "employee")
+	ctx.SetUserValue("id", "e1")
+	api_controller.UpdateByIdController(ctx)
+
+	var obj map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &obj)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK || obj["name"] != "z" {
+		t.Fatalf("expected allowed update, got %d %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if _, ok := obj["salary"]; ok {
+		t.Fatalf("hidden field leaked in update response")
+	}
+}
+
+func TestFieldRules_ReadHidesFields(t *testing.T) {
+	setupFieldRules(t)
+	globals.GetConfig().Api.Cache.Enabled = true
+
+	ctx := newCtx("GET", "/api/employee/e1", "")
+	ctx.SetUserValue("entity", "employee")
+	ctx.SetUserValue("id", "e1")
+	api_controller.GetByIdController(ctx)
+
+	var obj map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &obj)
+	if _, ok := obj["salary"]; ok || obj["name"] != "a" {
+		t.Fatalf("unexpected get by id response %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("GET", "/api/employee", "")
+	ctx.SetUserValue("entity", "employee")
+	api_controller.ListController(ctx)
+
+	var list []map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &list)
+	if len(list) != 1 {
+		t.Fatalf("expected one item, got %s", ctx.Response.Body())
+	}
+	if _, ok := list[0]["salary"]; ok {
+		t.Fatalf("hidden field leaked in list")
+	}
+
+	ctx = newCtx("GET", "/api/export", "")
+	api_controller.ExportController(ctx)
+
+	var dump map[string][]map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &dump)
+	if _, ok := dump["employee"][0]["salary"]; ok {
+		t.Fatalf("hidden field leaked in export")
+	}
+}
+
+func TestFieldRules_ListRejectsQueriesOnHiddenFields(t *testing.T) {
+	setupFieldRules(t)
+
+	for _, uri := range []string{
+		"/api/employee?filter[salary][gt]=50",
+		"/api/employee?sort[salary]=asc",
+	} {
+		ctx := newCtx("GET", uri, "")
+		ctx.SetUserValue("entity", "employee")
+		api_controller.ListController(ctx)
+
+		if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d", uri, ctx.Response.StatusCode())
+		}
+		if string(ctx.Response.Body()) != `{"error":"forbidden fields","fields":["salary"]}` {
+			t.Fatalf("%s: unexpected body %s", uri, ctx.Response.Body())
+		}
+	}
+
+	ctx := newCtx("GET", "/api/employee?filter[status]=active&sort[name]=asc", "")
+	ctx.SetUserValue("entity", "employee")
+	api_controller.ListController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	for _, body := range []string{
+		`{"entity":"employee","filters":{"salary":{"gt":"50"}}}`,
+		`{"entity":"employee","filters":{"or":[{"name":{"eq":"a"}},{"salary":{"eq":"100"}}]}}`,
+		`{"entity":"employee","sorts":{"salary":"asc"}}`,
+	} {
+		ctx := newCtx("POST", "/api/query", body)
+		api_controller.QueryController(ctx)
+
+		if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d %s", body, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+	}
+
+	for search, want := range map[string]int{"*100*": 0, "a": 1} {
+		ctx := newCtx("GET", "/api/employee?search="+search, "")
+		ctx.SetUserValue("entity", "employee")
+		api_controller.ListController(ctx)
+
+		var list []map[string]any
+		_ = json.Unmarshal(ctx.Response.Body(), &list)
+		if ctx.Response.StatusCode() != fasthttp.StatusOK || len(list) != want {
+			t.Fatalf("search %s: expected %d visible matches, got %d %s", search, want, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+	}
+}
+
+func TestFieldRules_RoleRuleChangePurgesCachedLists(t *testing.T) {
+	setupFieldRules(t)
+	globals.GetConfig().Api.Cache.Enabled = true
+
+	list := func() []map[string]any {
+		ctx := newCtx("GET", "/api/employee", "")
+		ctx.SetUserValue("entity", "employee")
+		api_controller.ListController(ctx)
+
+		var out []map[string]any
+		_ = json.Unmarshal(ctx.Response.Body(), &out)
+
+		return out
+	}
+
+	if got := list(); len(got) != 1 || got[0]["name"] != "a" {
+		t.Fatalf("unexpected list %v", got)
+	}
+
+	acl.SetFieldRulesForRole("employee", security.RoleUser, map[string]acl.FieldRule{"name": acl.FieldRuleHidden})
+
+	if got := list(); len(got) != 1 || got[0]["name"] != nil {
+		t.Fatalf("cached list served after the role field rules changed: %v", got)
+	}
+}
+
+func setupRowFilters(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "user"
+	principal = &security.Principal{Username: "u", Role: security.RoleUser}
+
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+		Username:    "u",
+		Entity:      "order",
+		Permissions: acl.NewPermissions(),
+	}).ToDataMap())
+
+	acl.SetRoleACL(&acl.PrincipalACL{
+		Principal: string(security.RoleUser),
+		Entity:    "order",
+		Filter:    map[string]any{"team": map[string]any{"eq": "$user.username"}},
+	})
+
+	api_storage.WriteEntity("order", map[string]any{"id": "o1", "team": "other"})
+	api_storage.WriteEntity("order", map[string]any{"id": "o2", "team": "u"})
+	api_storage.WriteEntity("order", map[string]any{"id": "o3", "team": "u"})
+}
+
+func TestQueryController_UnfilteredQueryWithACLReturnsAllRows(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "user"
+	principal = &security.Principal{Username: "u", Role: security.RoleUser}
+
+	perms := acl.NewPermissions()
+	perms[acl.PermissionRead] = true
+	api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{Username: "u", Entity: "book", Permissions: perms}).ToDataMap())
+
+	api_storage.WriteEntity("book", map[string]any{"id": "1", "title": "Go"})
+	api_storage.WriteEntity("book", map[string]any{"id": "2", "title": "Rust"})
+
+	for _, body := range []string{`{"entity":"book"}`, `{"entity":"book","filters":{}}`} {
+		ctx := newCtx("POST", "/api/query", body)
+		api_controller.QueryController(ctx)
+
+		var out []map[string]any
+		_ = json.Unmarshal(ctx.Response.Body(), &out)
+		if ctx.Response.StatusCode() != fasthttp.StatusOK || len(out) != 2 {
+			t.Fatalf("%s: expected every row, got %d %s", body, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+	}
+}
+
+func TestRowFilters_ListCountQueryAndGet(t *testing.T) {
+	setupRowFilters(t)
+
+	ctx := newCtx("GET", "/api/order?limit=1&offset=1&sort[id]=asc", "")
+	ctx.SetUserValue("entity", "order")
+	api_controller.ListController(ctx)
+
+	var list []map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &list)
+	if len(list) != 1 || list[0]["id"] != "o3" {
+		t.Fatalf("row filter should be applied before pagination, got %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("GET", "/api/order/count", "")
+	ctx.SetUserValue("entity", "order")
+	api_controller.CountController(ctx)
+	if string(ctx.Response.Body()) != `{"count":2}` {
+		t.Fatalf("unexpected count %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("POST", "/api/query", `{"entity":"order","filters":{"id":{"eq":"o*"}},"limit":1}`)
+	api_controller.QueryController(ctx)
+
+	list = nil
+	_ = json.Unmarshal(ctx.Response.Body(), &list)
+	if len(list) != 1 || list[0]["team"] != "u" {
+		t.Fatalf("unexpected query result %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("GET", "/api/order/o1", "")
+	ctx.SetUserValue("entity", "order")
+	ctx.SetUserValue("id", "o1")
+	api_controller.GetByIdController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403 outside of row filter, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("GET", "/api/order/o2", "")
+	ctx.SetUserValue("entity", "order")
+	ctx.SetUserValue("id", "o2")
+	api_controller.GetByIdController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200 inside row filter, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestRowFilters_Export(t *testing.T) {
+	setupRowFilters(t)
+	api_storage.WriteEntity("secret", map[string]any{"id": "s1"})
+
+	for _, uri := range []string{"/api/export", "/api/export?entities=order,secret"} {
+		ctx := newCtx("GET", uri, "")
+		api_controller.ExportController(ctx)
+
+		var dump map[string][]map[string]any
+		_ = json.Unmarshal(ctx.Response.Body(), &dump)
+		if len(dump["order"]) != 2 || len(dump["secret"]) != 0 {
+			t.Fatalf("%s: row filters should be applied, got %s", uri, ctx.Response.Body())
+		}
+	}
+}
+
+func setupSharing(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Authentication.Enabled = true
+	globals.GetConfig().Security.Authentication.Mode = "user"
+	principal = &security.Principal{Role: security.RoleUser}
+
+	for _, username := range []string{"alice", "bob"} {
+		_ = security.CreateBasicUser(&security.BasicUser{Username: username, Password: "p", Role: security.RoleUser})
+		api_storage.WriteEntity(acl.ACLEntity, (&acl.ACL{
+			Username:    username,
+			Entity:      "note",
+			Permissions: acl.DefaultPermissionsForRole(security.RoleUser),
+		}).ToDataMap())
+	}
+
+	api_storage.WriteEntity("note", map[string]any{"id": "n1", "title": "a", acl.UsernameField: "alice"})
+}
+
+func TestSharing_ShareUpdateAndTransfer(t *testing.T) {
+	setupSharing(t)
+
+	principal.Username = "bob"
+	ctx := newCtx("PUT", "/api/note/n1", `{"title":"b","`+acl.UsernameField+`":"bob"}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.UpdateByIdController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("ownership must be checked on the stored document, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("POST", "/api/note/n1/share", `{"users":["bob"],"permissions":["update"]}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.ShareController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("only the owner can share, got %d", ctx.Response.StatusCode())
+	}
+
+	principal.Username = "alice"
+	ctx = newCtx("POST", "/api/note/n1/share", `{"users":["bob"],"permissions":["write"]}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.ShareController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid permission, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("POST", "/api/note/n1/share", `{"users":["bob"],"permissions":["update"]}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.ShareController(ctx)
+	if string(ctx.Response.Body()) != `{"entity":"note","id":"n1","owner":"alice","shares":{"read":["user:bob"],"update":["user:bob"]}}` {
+		t.Fatalf("unexpected share response %s", ctx.Response.Body())
+	}
+
+	principal.Username = "bob"
+	ctx = newCtx("GET", "/api/note", "")
+	ctx.SetUserValue("entity", "note")
+	api_controller.ListController(ctx)
+
+	var list []map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &list)
+	if len(list) != 1 {
+		t.Fatalf("shared note should be listed, got %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("PUT", "/api/note/n1", `{"title":"b","`+acl.SharesField+`":{}}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.UpdateByIdController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("shared update should be allowed, got %d", ctx.Response.StatusCode())
+	}
+	if stored := api_storage.ReadEntityById("note", "n1"); stored["title"] != "b" || len(acl.GetShares(stored)) == 0 {
+		t.Fatalf("shares must not be changed through updates: %v", stored)
+	}
+
+	principal.Username = "alice"
+	ctx = newCtx("PUT", "/api/note/n1/owner", `{"username":"ghost"}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.TransferOwnershipController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Fatalf("expected 404 for unknown user, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("PUT", "/api/note/n1/owner", `{"username":"bob"}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.TransferOwnershipController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("DELETE", "/api/note/n1/share", `{"users":["bob"]}`)
+	ctx.SetUserValue("entity", "note")
+	ctx.SetUserValue("id", "n1")
+	api_controller.UnshareController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("previous owner must not manage shares, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestExportController_SelectsEntitiesAndFormats(t *testing.T) {
+	setup(t)
+
+	api_storage.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune"})
+	api_storage.WriteEntity("movie", map[string]any{"id": "m1", "title": "Alien"})
+
+	ctx := newCtx("GET", "/api/export?entities=book", "")
+	api_controller.ExportController(ctx)
+
+	var dump map[string][]map[string]any
+	_ = json.Unmarshal(ctx.Response.Body(), &dump)
+	if len(dump) != 1 || len(dump["book"]) != 1 {
+		t.Fatalf("expected only books, got %s", ctx.Response.Body())
+	}
+
+	for uri, status := range map[string]int{
+		"/api/export?entities=book,missing":       fasthttp.StatusNotFound,
+		"/api/export?format=xml":                  fasthttp.StatusBadRequest,
+		"/api/export?include=users&format=ndjson": fasthttp.StatusBadRequest,
+		"/api/export?include=hooks":               fasthttp.StatusBadRequest,
+	} {
+		ctx = newCtx("GET", uri, "")
+		api_controller.ExportController(ctx)
+
+		if ctx.Response.StatusCode() != status {
+			t.Errorf("%s: expected %d, got %d", uri, status, ctx.Response.StatusCode())
+		}
+	}
+}
+
+func TestImportController_NDJSONReportsFailedRecords(t *testing.T) {
+	setup(t)
+
+	api_storage.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune"})
+
+	body := `{"entity":"book","data":{"id":"b1","title":"Changed"}}
+{"entity":"book","data":{"id":"b2","title":"Hyperion"}}
+not json
+`
+
+	ctx := newCtx("POST", "/api/import?mode=skip-existing", body)
+	ctx.Request.Header.SetContentType("application/x-ndjson")
+	api_controller.ImportController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("unexpected status %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	var report struct {
+		Status   string         `json:"status"`
+		Mode     string         `json:"mode"`
+		Imported map[string]int `json:"imported"`
+		Skipped  map[string]int `json:"skipped"`
+		Failed   int            `json:"failed"`
+		Errors   []struct {
+			Line int `json:"line"`
+		} `json:"errors"`
+	}
+	_ = json.Unmarshal(ctx.Response.Body(), &report)
+
+	if report.Status != "import completed with errors" || report.Mode != "skip-existing" ||
+		report.Imported["book"] != 1 || report.Skipped["book"] != 1 || report.Failed != 1 || report.Errors[0].Line != 3 {
+		t.Fatalf("unexpected report: %s", ctx.Response.Body())
+	}
+
+	if got := api_storage.ReadEntityById("book", "b1")["title"]; got != "Dune" {
+		t.Fatalf("skip-existing should keep b1, got %v", got)
+	}
+
+	ctx = newCtx("POST", "/api/import?mode=merge", body)
+	api_controller.ImportController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown mode, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestImportController_AppliesACLsToEntityRecords(t *testing.T) {
+	setupFieldRules(t)
+
+	body := `{"entity":"employee","data":{"id":"e2","name":"b"}}
+{"entity":"employee","data":{"id":"e3","name":"c","salary":1}}
+{"entity":"book","data":{"id":"b1","title":"Dune"}}
+`
+
+	ctx := newCtx("POST", "/api/import", body)
+	ctx.Request.Header.SetContentType("application/x-ndjson")
+	api_controller.ImportController(ctx)
+
+	var report struct {
+		Imported map[string]int `json:"imported"`
+		Failed   int            `json:"failed"`
+	}
+	_ = json.Unmarshal(ctx.Response.Body(), &report)
+
+	if report.Imported["employee"] != 1 || report.Failed != 2 {
+		t.Fatalf("unexpected report: %s", ctx.Response.Body())
+	}
+
+	if stored := api_storage.ReadEntityById("employee", "e2"); stored[acl.UsernameField] != "u" {
+		t.Fatalf("imported records should be owned by the caller, got %v", stored)
+	}
+
+	ctx = newCtx("POST", "/api/import?mode=replace", body)
+	ctx.Request.Header.SetContentType("application/x-ndjson")
+	api_controller.ImportController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403 for replace mode without admin, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestEntityExportController_ValidatesFormatAndEntity(t *testing.T) {
+	setup(t)
+
+	api_storage.WriteEntity("book", map[string]any{"id": "b1", "title": "Dune"})
+
+	for _, tc := range []struct {
+		entity, uri string
+		status      int
+	}{
+		{"book", "/api/book/export?format=xlsx", fasthttp.StatusBadRequest},
+		{"missing", "/api/missing/export?format=csv", fasthttp.StatusNotFound},
+		{"book", "/api/book/export?format=parquet", fasthttp.StatusOK},
+	} {
+		ctx := newStreamingCtx(t, "GET", tc.uri)
+		ctx.SetUserValue("entity", tc.entity)
+		api_controller.EntityExportController(ctx)
+
+		if ctx.Response.StatusCode() != tc.status {
+			t.Errorf("%s: expected %d, got %d", tc.uri, tc.status, ctx.Response.StatusCode())
+		}
+	}
+
+	ctx := newStreamingCtx(t, "GET", "/api/book/export")
+	ctx.SetUserValue("entity", "book")
+	api_controller.EntityExportController(ctx)
+
+	if ct := string(ctx.Response.Header.ContentType()); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("expected csv by default, got %s", ct)
+	}
+}
+
+func TestEntityImportController_CSVDryRunAndImport(t *testing.T) {
+	setup(t)
+
+	api_storage.WriteEntity("book", map[string]any{"id": "b0", "title": "Hyperion", "pages": float64(482)})
+
+	body := "Title,pages,author.name\nDune,412,Herbert\nUbik,many,Dick\n"
+
+	ctx := newCtx("POST", "/api/book/import?dryRun=true&map[Title]=title", body)
+	ctx.SetUserValue("entity", "book")
+	api_controller.EntityImportController(ctx)
+
+	var report struct {
+		Status   string         `json:"status"`
+		DryRun   bool           `json:"dry_run"`
+		Imported map[string]int `json:"imported"`
+		Failed   int            `json:"failed"`
+		Errors   []struct {
+			Line  int    `json:"line"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	_ = json.Unmarshal(ctx.Response.Body(), &report)
+
+	if report.Status != "dry run found errors" || !report.DryRun || report.Imported["book"] != 1 || report.Failed != 1 || report.Errors[0].Line != 3 {
+		t.Fatalf("unexpected dry run report: %s", ctx.Response.Body())
+	}
+
+	if n := len(api_storage.ListEntities("book", 0, 0, "", true, nil, "", "")); n != 1 {
+		t.Fatalf("a dry run should not write anything, got %d books", n)
+	}
+
+	ctx = newCtx("POST", "/api/book/import?map[Title]=title", body)
+	ctx.SetUserValue("entity", "book")
+	api_controller.EntityImportController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("unexpected status %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	list := api_storage.ListEntities("book", 0, 0, "pages", true, nil, "", "")
+	if len(list) != 2 || list[0]["title"] != "Dune" || list[0]["pages"] != float64(412) {
+		t.Fatalf("unexpected books: %v", list)
+	}
+
+	if author, _ := list[0]["author"].(map[string]any); author["name"] != "Herbert" {
+		t.Fatalf("expected a nested author, got %v", list[0]["author"])
+	}
+
+	ctx = newCtx("POST", "/api/book/import?format=xlsx", body)
+	ctx.SetUserValue("entity", "book")
+	api_controller.EntityImportController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown format, got %d", ctx.Response.StatusCode())
+	}
+}
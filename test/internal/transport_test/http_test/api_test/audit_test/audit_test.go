@@ -0,0 +1,186 @@
+package http_audit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/taymour/elysiandb/internal/audit"
+	"github.com/taymour/elysiandb/internal/configuration"
+	"github.com/taymour/elysiandb/internal/globals"
+	"github.com/taymour/elysiandb/internal/security"
+	"github.com/taymour/elysiandb/internal/storage"
+	"github.com/taymour/elysiandb/internal/transport/http/adminui"
+	"github.com/taymour/elysiandb/internal/transport/http/api"
+	http_audit "github.com/taymour/elysiandb/internal/transport/http/api/audit"
+	http_security "github.com/taymour/elysiandb/internal/transport/http/api/security"
+	"github.com/valyala/fasthttp"
+)
+
+func setup(t *testing.T) {
+	cfg := &configuration.Config{}
+	cfg.Store.Folder = t.TempDir()
+	cfg.Store.Shards = 4
+	cfg.Security.Authentication.Enabled = true
+	cfg.Security.Authentication.Mode = "user"
+	cfg.Security.Audit.Enabled = true
+	globals.SetConfig(cfg)
+
+	storage.LoadDB()
+	storage.LoadJsonDB()
+
+	_ = security.InitAdminUserIfNotExists()
+
+	t.Cleanup(func() { _ = audit.Store.Close() })
+}
+
+func newCtx(method, uri, body string, session *security.Session) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	req.SetRequestURI(uri)
+	req.Header.SetMethod(method)
+	if body != "" {
+		req.SetBodyString(body)
+	}
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	if session != nil {
+		ctx.Request.Header.SetCookie(security.SessionCookieName, session.ID)
+		ctx.SetUserValue("username", session.Username)
+		ctx.SetUserValue("role", session.Role)
+	}
+	return ctx
+}
+
+func login(t *testing.T, username string, role security.Role) *security.Session {
+	s, err := security.CreateSession(username, role, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func listEvents(t *testing.T, query string, session *security.Session) []audit.Event {
+	t.Helper()
+
+	ctx := newCtx("GET", "/api/audit"+query, "", session)
+	http_audit.ListAuditController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	var resp struct {
+		Events []audit.Event `json:"events"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.Events
+}
+
+func TestAudit_RecordsSecurityAndDestructiveOperations(t *testing.T) {
+	setup(t)
+	admin := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+
+	ctx := newCtx("POST", "/api/security/login", `{"username":"admin","password":"wrong"}`, nil)
+	adminui.LoginController(ctx)
+
+	ctx = newCtx("POST", "/api/security/user", `{"username":"bob","password":"secret","role":"user"}`, admin)
+	http_security.CreateUserController(ctx)
+
+	ctx = newCtx("PUT", "/api/security/user/bob/role", `{"role":"admin"}`, admin)
+	ctx.SetUserValue("user_name", "bob")
+	http_security.ChangeUserRoleController(ctx)
+
+	ctx = newCtx("DELETE", "/api/articles", "", admin)
+	ctx.SetUserValue("entity", "articles")
+	api.DestroyController(ctx)
+
+	events := listEvents(t, "", admin)
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d: %+v", len(events), events)
+	}
+
+	destroy := events[0]
+	if destroy.Category != audit.CategoryDestructive || destroy.Action != "destroy" || destroy.Target != "articles" || destroy.Actor != "admin" {
+		t.Fatalf("unexpected destroy event %+v", destroy)
+	}
+
+	roleChange := events[1]
+	if roleChange.Action != "role_change" ||
+		roleChange.Before.(map[string]any)["role"] != "user" ||
+		roleChange.After.(map[string]any)["role"] != "admin" {
+		t.Fatalf("unexpected role change event %+v", roleChange)
+	}
+
+	failedLogin := events[3]
+	if failedLogin.Category != audit.CategoryAuth || failedLogin.Outcome != audit.OutcomeFailure || failedLogin.Target != "admin" {
+		t.Fatalf("unexpected login event %+v", failedLogin)
+	}
+
+	filtered := listEvents(t, "?category=user&action=create", admin)
+	if len(filtered) != 1 || filtered[0].Target != "bob" {
+		t.Fatalf("unexpected filtered events %+v", filtered)
+	}
+}
+
+func TestAudit_ExportNDJSON(t *testing.T) {
+	setup(t)
+	admin := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+
+	ctx := newCtx("POST", "/api/security/roles", `{"name":"editor"}`, admin)
+	http_security.CreateRoleController(ctx)
+
+	ctx = newCtx("DELETE", "/api/security/roles/editor", "", admin)
+	ctx.SetUserValue("role_name", "editor")
+	http_security.DeleteRoleController(ctx)
+
+	ctx = newCtx("GET", "/api/audit/export?category=role", "", admin)
+	http_audit.ExportAuditController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+	if string(ctx.Response.Header.ContentType()) != "application/x-ndjson" {
+		t.Fatalf("unexpected content type %s", ctx.Response.Header.ContentType())
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(ctx.Response.Body()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var e audit.Event
+	_ = json.Unmarshal(lines[1], &e)
+	if e.Action != "delete" || e.Target != "editor" || e.Before == nil {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+func TestAudit_ForbiddenAndInvalidParameters(t *testing.T) {
+	setup(t)
+
+	user := login(t, "bob", security.RoleUser)
+	ctx := newCtx("GET", "/api/audit", "", user)
+	http_audit.ListAuditController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403, got %d", ctx.Response.StatusCode())
+	}
+
+	admin := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+	for _, query := range []string{"?from=yesterday", "?to=nope", "?limit=-1", "?offset=x"} {
+		ctx = newCtx("GET", "/api/audit"+query, "", admin)
+		http_audit.ListAuditController(ctx)
+		if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, ctx.Response.StatusCode())
+		}
+	}
+
+	ctx = newCtx("GET", "/api/audit?from=2020-01-01T00:00:00Z&to=1893456000", "", admin)
+	http_audit.ListAuditController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+}
@@ -1,6 +1,7 @@
 package http_security_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -330,3 +331,238 @@ func TestChangeUserRoleController_UserNotFound(t *testing.T) {
 		t.Fatal("expected 404")
 	}
 }
+
+func TestUserSessionsControllers(t *testing.T) {
+	setup(t)
+
+	_, _ = security.CreateSession("bob", security.RoleUser, time.Hour)
+	_, _ = security.CreateSession("bob", security.RoleUser, time.Hour)
+
+	s := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+
+	ctx := newCtx("GET", "/api/security/user/bob/sessions", "", s)
+	ctx.SetUserValue("user_name", "bob")
+	http_security.GetUserSessionsController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatal("expected 200")
+	}
+
+	var body struct {
+		Sessions []map[string]any `json:"sessions"`
+	}
+	_ = json.Unmarshal(ctx.Response.Body(), &body)
+	if len(body.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(body.Sessions))
+	}
+	if _, ok := body.Sessions[0]["id"]; ok {
+		t.Fatal("session ids must not be exposed")
+	}
+
+	ctx = newCtx("DELETE", "/api/security/user/bob/sessions", "", s)
+	ctx.SetUserValue("user_name", "bob")
+	http_security.DeleteUserSessionsController(ctx)
+
+	if string(ctx.Response.Body()) != `{"revoked":2}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+}
+
+func TestUserSessionsControllers_Forbidden(t *testing.T) {
+	setup(t)
+
+	s := login(t, "bob", security.RoleUser)
+
+	ctx := newCtx("DELETE", "/api/security/user/alice/sessions", "", s)
+	ctx.SetUserValue("user_name", "alice")
+	http_security.DeleteUserSessionsController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatal("expected 403")
+	}
+}
+
+func TestRolesControllers(t *testing.T) {
+	setup(t)
+
+	s := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+
+	ctx := newCtx("POST", "/api/security/roles", `{"name":"editor","description":"Edits"}`, s)
+	http_security.CreateRoleController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusCreated {
+		t.Fatalf("expected 201, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("GET", "/api/security/roles", "", s)
+	http_security.GetRolesController(ctx)
+
+	var body struct {
+		Roles []map[string]any `json:"roles"`
+	}
+	_ = json.Unmarshal(ctx.Response.Body(), &body)
+	if len(body.Roles) != 3 {
+		t.Fatalf("expected 3 roles, got %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("DELETE", "/api/security/roles/admin", "", s)
+	ctx.SetUserValue("role_name", "admin")
+	http_security.DeleteRoleController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("builtin role must not be deleted")
+	}
+
+	ctx = newCtx("DELETE", "/api/security/roles/editor", "", s)
+	ctx.SetUserValue("role_name", "editor")
+	http_security.DeleteRoleController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestGroupsControllers(t *testing.T) {
+	setup(t)
+
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "bob", Password: "p", Role: security.RoleUser})
+	s := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+
+	ctx := newCtx("POST", "/api/security/groups", `{"name":"sales","members":["bob"]}`, s)
+	http_security.CreateGroupController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusCreated {
+		t.Fatalf("expected 201, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("PUT", "/api/security/groups/sales/members/ghost", "", s)
+	ctx.SetUserValue("group", "sales")
+	ctx.SetUserValue("user_name", "ghost")
+	http_security.AddGroupMemberController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Fatalf("expected 404 for unknown user")
+	}
+
+	ctx = newCtx("GET", "/api/security/groups/sales", "", s)
+	ctx.SetUserValue("group", "sales")
+	http_security.GetGroupController(ctx)
+	if string(ctx.Response.Body()) != `{"description":"","members":["bob"],"name":"sales"}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("DELETE", "/api/security/groups/sales/members/bob", "", s)
+	ctx.SetUserValue("group", "sales")
+	ctx.SetUserValue("user_name", "bob")
+	http_security.RemoveGroupMemberController(ctx)
+	if len(security.GroupsForUser("bob")) != 0 {
+		t.Fatalf("bob should have been removed")
+	}
+
+	user := login(t, "bob", security.RoleUser)
+	ctx = newCtx("GET", "/api/security/groups", "", user)
+	http_security.GetGroupsController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403")
+	}
+}
+
+func TestUserAttributesControllers(t *testing.T) {
+	setup(t)
+
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "alice", Password: "p", Role: security.RoleUser})
+	s := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+
+	ctx := newCtx("PUT", "/api/security/user/alice/attributes", `{"attributes":{"team":"red"}}`, s)
+	ctx.SetUserValue("user_name", "alice")
+	http_security.ChangeUserAttributesController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newCtx("GET", "/api/security/user/alice/attributes", "", s)
+	ctx.SetUserValue("user_name", "alice")
+	http_security.GetUserAttributesController(ctx)
+	if string(ctx.Response.Body()) != `{"attributes":{"team":"red"},"username":"alice"}` {
+		t.Fatalf("unexpected body %s", ctx.Response.Body())
+	}
+
+	ctx = newCtx("PUT", "/api/security/user/ghost/attributes", `{"attributes":{}}`, s)
+	ctx.SetUserValue("user_name", "ghost")
+	http_security.ChangeUserAttributesController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Fatalf("expected 404, got %d", ctx.Response.StatusCode())
+	}
+
+	u := login(t, "alice", security.RoleUser)
+	ctx = newCtx("PUT", "/api/security/user/alice/attributes", `{"attributes":{"team":"blue"}}`, u)
+	ctx.SetUserValue("user_name", "alice")
+	http_security.ChangeUserAttributesController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("users must not change their own attributes")
+	}
+}
+
+func TestChangeUserPasswordController_PolicyViolation(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Password.MinLength = 12
+
+	s := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+	ctx := newCtx("PUT", "/api/security/user/admin/password", `{"password":"short"}`, s)
+	ctx.SetUserValue("user_name", security.DefaultAdminUsername)
+
+	http_security.ChangeUserPasswordController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestCreateUserController_PolicyViolation(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Password.RequireDigit = true
+
+	s := login(t, security.DefaultAdminUsername, security.RoleAdmin)
+	ctx := newCtx("POST", "/api/security/user", `{"username":"bob","password":"nodigits","role":"user"}`, s)
+
+	http_security.CreateUserController(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestUnlockUserController(t *testing.T) {
+	setup(t)
+	globals.GetConfig().Security.Lockout.Enabled = true
+	globals.GetConfig().Security.Lockout.MaxAttempts = 1
+	security.Lockouts.Reset()
+	t.Cleanup(security.Lockouts.Reset)
+
+	_ = security.CreateBasicUser(&security.BasicUser{Username: "bob", Password: "secret", Role: security.RoleUser})
+	_, _ = security.LoginUser("bob", "wrong")
+	if !security.Lockouts.IsLocked("bob", time.Now()) {
+		t.Fatal("expected bob to be locked")
+	}
+
+	s := login(t, "bob", security.RoleUser)
+	ctx := newCtx("POST", "/api/security/user/bob/unlock", "", s)
+	ctx.SetUserValue("user_name", "bob")
+	http_security.UnlockUserController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Fatalf("expected 403, got %d", ctx.Response.StatusCode())
+	}
+
+	s = login(t, security.DefaultAdminUsername, security.RoleAdmin)
+	ctx = newCtx("POST", "/api/security/user/bob/unlock", "", s)
+	ctx.SetUserValue("user_name", "bob")
+	http_security.UnlockUserController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected 200, got %d", ctx.Response.StatusCode())
+	}
+	if security.Lockouts.IsLocked("bob", time.Now()) {
+		t.Fatal("expected bob to be unlocked")
+	}
+
+	ctx = newCtx("POST", "/api/security/user/ghost/unlock", "", s)
+	ctx.SetUserValue("user_name", "ghost")
+	http_security.UnlockUserController(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Fatalf("expected 404, got %d", ctx.Response.StatusCode())
+	}
+}